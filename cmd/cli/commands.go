@@ -0,0 +1,133 @@
+package main
+
+import (
+	"a2a-go/pkg/cli"
+	"a2a-go/pkg/utils"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// programName is the name completions and the man page are generated for
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// exportFlagSet defines the flags of the export command
+func exportFlagSet() (*flag.FlagSet, *string, *string) {
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := exportFlags.String("format", "markdown", "Export format (jsonl or markdown)")
+	output := exportFlags.String("o", "", "Output file (stdout if empty)")
+	return exportFlags, format, output
+}
+
+// commands describes the CLI for shell completion and man page generation
+func commands() *cli.Command {
+	exportFlags, _, _ := exportFlagSet()
+	return &cli.Command{
+		Name:    programName(),
+		Summary: "interactive client for A2A agents",
+		Flags:   flag.CommandLine,
+		FlagCompletions: map[string]cli.Completion{
+			"session":  cli.CompleteSessionIDs,
+			"replay":   cli.CompleteTaskIDs,
+			"inbox":    cli.CompleteFiles,
+			"id-cache": cli.CompleteFiles,
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:    "export",
+				Summary: "Export the transcript of a task",
+				Flags:   exportFlags,
+				Args:    []cli.Arg{{Name: "task-id", Completion: cli.CompleteTaskIDs}},
+				FlagCompletions: map[string]cli.Completion{
+					"o": cli.CompleteFiles,
+				},
+				FlagValues: map[string][]string{
+					"format": {string(utils.ExportJSONL), string(utils.ExportMarkdown)},
+				},
+			},
+			{
+				Name:    "notifications",
+				Summary: "Read push notifications stored in the local inbox",
+				Subcommands: []*cli.Command{
+					{Name: "list", Summary: "List stored notifications"},
+					{
+						Name:    "show",
+						Summary: "Show a stored notification",
+						Args:    []cli.Arg{{Name: "id", Completion: cli.CompleteNotifications}},
+					},
+				},
+			},
+			{
+				Name:    "completion",
+				Summary: "Print the shell completion script",
+				Args:    []cli.Arg{{Name: "shell", Values: []string{"bash", "zsh", "fish"}}},
+			},
+			{
+				Name:    "man",
+				Summary: "Print the man page",
+			},
+		},
+	}
+}
+
+// completionIDs returns cached ids for dynamic completion
+func completionIDs(config Config) cli.IDSource {
+	return func(kind cli.Completion) []string {
+		if kind != cli.CompleteNotifications {
+			return cli.NewIDCache(config.idCache).IDs(kind)
+		}
+		inbox, err := cli.NewInbox(config.inbox)
+		if err != nil {
+			return nil
+		}
+		notifications, err := inbox.List()
+		if err != nil {
+			return nil
+		}
+		ids := make([]string, 0, len(notifications))
+		for i := len(notifications) - 1; i >= 0; i-- {
+			ids = append(ids, notifications[i].ID)
+		}
+		return ids
+	}
+}
+
+// runToolingCommand handles the commands that work without an agent; it reports whether one ran
+func runToolingCommand(config Config, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case cli.CompleteCommand:
+		for _, candidate := range commands().Complete(args[1:], completionIDs(config)) {
+			os.Stdout.WriteString(candidate + "\n")
+		}
+	case "completion":
+		if len(args) < 2 {
+			log.Fatalf("Usage: completion bash|zsh|fish")
+		}
+		if err := cli.WriteCompletionScript(os.Stdout, args[1], programName()); err != nil {
+			log.Fatalf("Error writing completion script: %v", err)
+		}
+	case "man":
+		if err := cli.WriteManPage(os.Stdout, programName(), commands(), time.Now()); err != nil {
+			log.Fatalf("Error writing man page: %v", err)
+		}
+	case "notifications":
+		notificationsCommand(config.inbox, args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// rememberID caches a session or task id for completion; failures only cost completions
+func rememberID(config Config, kind cli.Completion, id string) {
+	if err := cli.NewIDCache(config.idCache).Remember(kind, id); err != nil {
+		log.Printf("Error caching %s id: %v", kind, err)
+	}
+}
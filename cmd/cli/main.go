@@ -1,138 +1,260 @@
 package main
 
 import (
-	"a2a-go/pkg/client"
 	"a2a-go/pkg/cli"
+	"a2a-go/pkg/client"
 	"a2a-go/pkg/types"
 	"a2a-go/pkg/utils"
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/url"
 	"os"
 	"strings"
-
-	"github.com/google/uuid"
+	"time"
 )
 
 type Config struct {
-	agent                   string
-	session                 string
-	history                 bool
-	usePushNotifications    bool
+	agent                    string
+	session                  string
+	history                  bool
+	usePushNotifications     bool
 	pushNotificationReceiver string
+	replay                   string
+	replaySpeed              float64
+	inbox                    string
+	idCache                  string
+	pretty                   bool
+	jwtLeeway                time.Duration
+	tokenURL                 string
+	clientID                 string
+	clientSecret             string
+	scopes                   string
 }
 
 func completeTask(
-	client *client.A2AClient,
+	a2aClient *client.A2AClient,
 	streaming bool,
 	usePushNotifications bool,
 	notificationReceiverHost string,
 	notificationReceiverPort string,
 	taskID string,
 	sessionID string,
+	pretty bool,
 ) (bool, error) {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("\nWhat do you want to send to the agent? (:q or quit to exit)\n")
-	prompt, err := reader.ReadString('\n')
-	if err != nil {
-		return false, fmt.Errorf("error reading input: %v", err)
-	}
+	input := func(ctx context.Context, task *types.Task) (*types.Message, error) {
+		fmt.Print("\nWhat do you want to send to the agent? (:q or quit to exit)\n")
+		prompt, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("error reading input: %v", err)
+		}
 
-	prompt = strings.TrimSpace(prompt)
-	if prompt == ":q" || prompt == "quit" {
-		return false, nil
-	}
+		prompt = strings.TrimSpace(prompt)
+		if prompt == ":q" || prompt == "quit" {
+			return nil, nil
+		}
 
-	payload := map[string]interface{}{
-		"id":                taskID,
-		"sessionId":        sessionID,
-		"acceptedOutputModes": []string{"text"},
-		"message": map[string]interface{}{
-			"role": "user",
-			"parts": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": prompt,
+		return &types.Message{
+			Role: "user",
+			Parts: []types.Part{
+				types.TextPart{
+					Type: "text",
+					Text: prompt,
 				},
 			},
-		},
+		}, nil
+	}
+
+	opts := []client.ConversationRunnerOption{
+		client.WithAcceptedOutputModes("text"),
 	}
 
 	if usePushNotifications {
-		payload["pushNotification"] = map[string]interface{}{
-			"url": fmt.Sprintf("http://%s:%s/notify", notificationReceiverHost, notificationReceiverPort),
-			"authentication": map[string]interface{}{
-				"schemes": []string{"bearer"},
+		opts = append(opts, client.WithPushNotificationConfig(&types.PushNotificationConfig{
+			URL: fmt.Sprintf("http://%s/notify", net.JoinHostPort(notificationReceiverHost, notificationReceiverPort)),
+			Authentication: &types.AuthenticationInfo{
+				Schemes: []string{"bearer"},
 			},
-		}
+		}))
 	}
 
-	var taskResult *types.GetTaskResponse
-
 	if streaming {
-		responseChan, err := client.SendTaskStreaming(payload)
-		if err != nil {
-			return false, fmt.Errorf("error sending streaming task: %v", err)
-		}
-
-		for response := range responseChan {
+		opts = append(opts, client.WithStreaming(func(response *types.SendTaskStreamingResponse) {
+			if pretty {
+				printPrettyEvent(response)
+				return
+			}
 			jsonBytes, err := json.Marshal(response)
 			if err != nil {
 				log.Printf("Error marshaling stream event: %v", err)
-				continue
+				return
 			}
 			fmt.Printf("stream event => %s\n", string(jsonBytes))
-		}
+		}))
+	} else {
+		opts = append(opts, client.WithTaskResult(func(task *types.Task) {
+			if pretty {
+				printPrettyTask(task)
+				return
+			}
+			jsonBytes, err := json.Marshal(&types.SendTaskResponse{Result: task})
+			if err != nil {
+				log.Printf("Error marshaling result: %v", err)
+				return
+			}
+			fmt.Printf("\n%s\n", string(jsonBytes))
+		}))
+	}
 
-		taskResult, err = client.GetTask(map[string]interface{}{"id": taskID})
-		if err != nil {
-			return false, fmt.Errorf("error getting task: %v", err)
+	runner := client.NewConversationRunner(a2aClient, input, opts...)
+	if _, err := runner.Run(context.Background(), taskID, sessionID); err != nil {
+		if errors.Is(err, client.ErrNoInput) {
+			return false, nil
 		}
-	} else {
-		sendResult, err := client.SendTask(payload)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func replayTask(a2aClient *client.A2AClient, taskID string, speed float64) {
+	responseChan, err := a2aClient.ReplayTask(context.Background(), map[string]interface{}{
+		"id":    taskID,
+		"speed": speed,
+	})
+	if err != nil {
+		log.Fatalf("Error replaying task: %v", err)
+	}
+
+	fmt.Printf("=========  replaying task %s ======== \n", taskID)
+	for response := range responseChan {
+		jsonBytes, err := json.Marshal(response)
 		if err != nil {
-			return false, fmt.Errorf("error sending task: %v", err)
+			log.Printf("Error marshaling replayed event: %v", err)
+			continue
 		}
+		fmt.Printf("replay event => %s\n", string(jsonBytes))
+	}
+}
+
+// exportTask handles `export <task-id> [-format jsonl|markdown] [-o file]`
+func exportTask(a2aClient *client.A2AClient, args []string) {
+	exportFlags, format, output := exportFlagSet()
 
-		jsonBytes, err := json.Marshal(sendResult)
+	if len(args) == 0 {
+		log.Fatalf("Usage: export <task-id> [-format jsonl|markdown] [-o file]")
+	}
+	taskID := args[0]
+	if err := exportFlags.Parse(args[1:]); err != nil {
+		log.Fatalf("Error parsing export flags: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
 		if err != nil {
-			return false, fmt.Errorf("error marshaling result: %v", err)
+			log.Fatalf("Error creating export file: %v", err)
 		}
-		fmt.Printf("\n%s\n", string(jsonBytes))
+		defer file.Close()
+		out = file
+	}
 
-		taskResult = &types.GetTaskResponse{Result: sendResult.Result}
+	if err := a2aClient.ExportTask(context.Background(), taskID, utils.ExportFormat(*format), out); err != nil {
+		log.Fatalf("Error exporting task: %v", err)
 	}
+}
 
-	if taskResult.Result.Status.State == types.TaskInputNeeded {
-		return completeTask(
-			client,
-			streaming,
-			usePushNotifications,
-			notificationReceiverHost,
-			notificationReceiverPort,
-			taskID,
-			sessionID,
-		)
+// notificationsCommand handles `notifications list` and `notifications show <id>`
+func notificationsCommand(inboxPath string, args []string) {
+	inbox, err := cli.NewInbox(inboxPath)
+	if err != nil {
+		log.Fatalf("Error opening notification inbox: %v", err)
 	}
 
-	return true, nil
+	if len(args) == 0 {
+		log.Fatalf("Usage: notifications list | notifications show <id>")
+	}
+	switch args[0] {
+	case "list":
+		notifications, err := inbox.List()
+		if err != nil {
+			log.Fatalf("Error listing notifications: %v", err)
+		}
+		if len(notifications) == 0 {
+			fmt.Printf("No notifications in %s\n", inbox.Path())
+			return
+		}
+		for _, notification := range notifications {
+			fmt.Printf("%s  %s  task=%s  state=%s\n",
+				notification.ID,
+				notification.ReceivedAt.Local().Format("2006-01-02 15:04:05"),
+				notification.TaskID,
+				notification.State,
+			)
+		}
+	case "show":
+		if len(args) < 2 {
+			log.Fatalf("Usage: notifications show <id>")
+		}
+		notification, err := inbox.Get(args[1])
+		if err != nil {
+			log.Fatalf("Error reading notification %s: %v", args[1], err)
+		}
+		jsonBytes, err := json.MarshalIndent(notification, "", "  ")
+		if err != nil {
+			log.Fatalf("Error marshaling notification: %v", err)
+		}
+		fmt.Printf("%s\n", string(jsonBytes))
+	default:
+		log.Fatalf("Unknown notifications command %q, expected list or show", args[0])
+	}
 }
 
 func main() {
 	config := Config{}
-	flag.StringVar(&config.agent, "agent", "http://localhost:10000", "Agent URL")
+	flag.StringVar(&config.agent, "agent", "http://localhost:10000", "Agent URL, or a domain to discover the agent from DNS")
 	flag.StringVar(&config.session, "session", "", "Session ID (0 for new session)")
 	flag.BoolVar(&config.history, "history", false, "Show history")
 	flag.BoolVar(&config.usePushNotifications, "use-push-notifications", false, "Use push notifications")
-	flag.StringVar(&config.pushNotificationReceiver, "push-notification-receiver", "http://localhost:5000", "Push notification receiver URL")
+	flag.StringVar(&config.pushNotificationReceiver, "push-notification-receiver", "http://localhost:5000", "Push notification receiver URL, agents only call loopback receivers if their URL policy allows loopback")
+	flag.StringVar(&config.replay, "replay", "", "Replay the recorded event stream of a task ID and exit")
+	flag.Float64Var(&config.replaySpeed, "replay-speed", 1, "Replay speed multiplier (0 for no delays)")
+	flag.StringVar(&config.inbox, "inbox", cli.DefaultInboxPath(), "File storing received push notifications")
+	flag.StringVar(&config.idCache, "id-cache", cli.DefaultIDCachePath(), "File caching recent session and task ids for shell completion")
+	flag.BoolVar(&config.pretty, "pretty", false, "Print the text of agent replies instead of raw JSON")
+	flag.DurationVar(&config.jwtLeeway, "jwt-leeway", utils.DefaultJWTLeeway, "Clock skew tolerated when validating push notification tokens")
+	flag.StringVar(&config.tokenURL, "token-url", "", "OAuth2 token endpoint authorizing requests to the agent with client credentials")
+	flag.StringVar(&config.clientID, "client-id", "", "OAuth2 client ID")
+	flag.StringVar(&config.clientSecret, "client-secret", "", "Reference to the OAuth2 client secret, e.g. env:A2A_CLIENT_SECRET, file:/run/secrets/a2a, vault:a2a/cli#secret or aws:a2a/cli#secret")
+	flag.StringVar(&config.scopes, "scopes", "", "Space separated OAuth2 scopes")
 	flag.Parse()
 
+	// Completion, man page and inbox commands are local, no agent is needed
+	if runToolingCommand(config, flag.Args()) {
+		return
+	}
+
+	exporting := flag.Arg(0) == "export"
+
+	// A bare domain is discovered through its DNS records
+	agentURL, cardPath := config.agent, "/.well-known/agent.json"
+	if !strings.Contains(config.agent, "://") {
+		agent, err := client.DiscoverAgent(context.Background(), nil, config.agent)
+		if err != nil {
+			log.Fatalf("Error discovering agent: %v", err)
+		}
+		agentURL, cardPath = agent.BaseURL, agent.CardPath
+	}
+
 	// Create card resolver and get agent card
-	cardResolver := client.NewA2ACardResolver(config.agent, "/.well-known/agent.json")
-	card, err := cardResolver.GetAgentCard()
+	cardResolver := client.NewA2ACardResolver(agentURL, cardPath)
+	card, err := cardResolver.GetAgentCard(context.Background())
 	if err != nil {
 		log.Fatalf("Error getting agent card: %v", err)
 	}
@@ -141,7 +263,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error marshaling agent card: %v", err)
 	}
-	fmt.Printf("======= Agent Card ========\n%s\n", string(jsonBytes))
+	if !exporting {
+		fmt.Printf("======= Agent Card ========\n%s\n", string(jsonBytes))
+	}
 
 	// Parse notification receiver URL
 	notifReceiverURL, err := url.Parse(config.pushNotificationReceiver)
@@ -149,42 +273,80 @@ func main() {
 		log.Fatalf("Error parsing notification receiver URL: %v", err)
 	}
 
+	// Create A2A client
+	var clientOptions []client.ClientOption
+	if config.tokenURL != "" {
+		credentials := &utils.ClientCredentials{
+			TokenURL: config.tokenURL,
+			ClientID: config.clientID,
+			Scopes:   strings.Fields(config.scopes),
+		}
+		if config.clientSecret != "" {
+			credentials.ClientSecret = utils.NewSecretResolver().Ref(config.clientSecret)
+		}
+		clientOptions = append(clientOptions, client.WithTokens(utils.NewTokenManager(credentials)))
+	}
+	a2aClient, err := client.NewA2AClient(card, "", clientOptions...)
+	if err != nil {
+		log.Fatalf("Error creating A2A client: %v", err)
+	}
+
 	var pushNotificationListener *cli.PushNotificationListener
 	if config.usePushNotifications {
 		notificationReceiverAuth := &utils.PushNotificationReceiverAuth{}
-		jwksURL := fmt.Sprintf("%s/.well-known/jwks.json", config.agent)
+		notificationReceiverAuth.SetLeeway(config.jwtLeeway)
+		jwksURL, err := card.ResolveJWKSURL(strings.TrimSuffix(agentURL, "/") + types.DefaultJWKSPath)
+		if err != nil {
+			log.Fatalf("Error resolving JWKS URL: %v", err)
+		}
 		if err := notificationReceiverAuth.LoadJWKS(jwksURL); err != nil {
 			log.Fatalf("Error loading JWKS: %v", err)
 		}
 
+		inbox, err := cli.NewInbox(config.inbox)
+		if err != nil {
+			log.Fatalf("Error opening notification inbox: %v", err)
+		}
+
 		pushNotificationListener = cli.NewPushNotificationListener(
 			notifReceiverURL.Hostname(),
 			notifReceiverURL.Port(),
 			notificationReceiverAuth,
+			cli.WithInbox(inbox),
+			cli.WithOrdering(client.GetTaskResync(context.Background(), a2aClient)),
 		)
 		pushNotificationListener.Start()
 		defer pushNotificationListener.Stop()
 	}
 
-	// Create A2A client
-	a2aClient, err := client.NewA2AClient(card, "")
-	if err != nil {
-		log.Fatalf("Error creating A2A client: %v", err)
+	if exporting {
+		if flag.NArg() > 1 {
+			rememberID(config, cli.CompleteTaskIDs, flag.Arg(1))
+		}
+		exportTask(a2aClient, flag.Args()[1:])
+		return
+	}
+
+	if config.replay != "" {
+		replayTask(a2aClient, config.replay, config.replaySpeed)
+		return
 	}
 
 	// Set up session ID
 	sessionID := config.session
 	if sessionID == "" || sessionID == "0" {
-		sessionID = uuid.New().String()
+		sessionID = utils.NewID()
 	}
+	rememberID(config, cli.CompleteSessionIDs, sessionID)
 
 	continueLoop := true
 	streaming := card.Capabilities.Streaming
 
 	for continueLoop {
-		taskID := uuid.New().String()
+		taskID := utils.NewID()
+		rememberID(config, cli.CompleteTaskIDs, taskID)
 		fmt.Println("=========  starting a new task ======== ")
-		
+
 		continueLoop, err = completeTask(
 			a2aClient,
 			streaming,
@@ -193,6 +355,7 @@ func main() {
 			notifReceiverURL.Port(),
 			taskID,
 			sessionID,
+			config.pretty,
 		)
 		if err != nil {
 			log.Printf("Error completing task: %v", err)
@@ -201,7 +364,7 @@ func main() {
 
 		if config.history && continueLoop {
 			fmt.Println("========= history ======== ")
-			taskResponse, err := a2aClient.GetTask(map[string]interface{}{
+			taskResponse, err := a2aClient.GetTask(context.Background(), map[string]interface{}{
 				"id":            taskID,
 				"historyLength": 10,
 			})
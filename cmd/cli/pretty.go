@@ -0,0 +1,67 @@
+package main
+
+import (
+	"a2a-go/pkg/types"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// prettyModes are the content modes the pretty printer renders, in order of preference
+var prettyModes = []string{"text", "text/*", "data", "image/*", "*"}
+
+// printPrettyEvent prints the renderable content of a streaming event
+func printPrettyEvent(response *types.SendTaskStreamingResponse) {
+	event, err := types.DecodeTaskEvent(response)
+	if err != nil {
+		log.Printf("Error decoding stream event: %v", err)
+		return
+	}
+	switch {
+	case event.IsError():
+		fmt.Printf("[error] %s\n", event.Error.Message)
+	case event.IsStatus():
+		fmt.Printf("[%s]", event.State())
+		if content, ok := event.Status.Status.Message.BestContent(prettyModes...); ok {
+			fmt.Printf(" %s", renderContent(content))
+		}
+		fmt.Println()
+	case event.IsArtifact():
+		if content, ok := types.BestContent(event.Artifact.Artifact.Parts, prettyModes...); ok {
+			fmt.Print(renderContent(content))
+		}
+		if event.Artifact.Artifact.LastChunk != nil && *event.Artifact.Artifact.LastChunk {
+			fmt.Println()
+		}
+	}
+}
+
+// printPrettyTask prints the state and the best renderable output of a task
+func printPrettyTask(task *types.Task) {
+	fmt.Printf("\n[%s]\n", task.Status.State)
+	if content, ok := task.BestContent(prettyModes...); ok {
+		fmt.Println(renderContent(content))
+	}
+}
+
+// renderContent formats part content for the terminal
+func renderContent(content types.PartContent) string {
+	switch {
+	case content.File != nil:
+		name := "file"
+		if content.File.Name != nil {
+			name = *content.File.Name
+		}
+		if content.File.URI != nil {
+			return fmt.Sprintf("<%s %s: %s>", content.Mode, name, *content.File.URI)
+		}
+		return fmt.Sprintf("<%s %s>", content.Mode, name)
+	case content.Data != nil:
+		jsonBytes, err := json.MarshalIndent(content.Data, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", content.Data)
+		}
+		return string(jsonBytes)
+	}
+	return content.Text
+}
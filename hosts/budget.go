@@ -0,0 +1,206 @@
+package hosts
+
+import (
+	"a2a-go/pkg/server"
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is matched by every BudgetExceededError through errors.Is
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetLimit names the limit of a Budget that was exceeded
+type BudgetLimit string
+
+const (
+	LimitTasks     BudgetLimit = "tasks"
+	LimitTokens    BudgetLimit = "tokens"
+	LimitCost      BudgetLimit = "cost"
+	LimitWallClock BudgetLimit = "wallClock"
+)
+
+// BudgetExceededError is returned when dispatching a task would exceed a budget
+type BudgetExceededError struct {
+	Key   string // Session or tenant the budget applies to
+	Limit BudgetLimit
+	Used  float64 // Seconds for LimitWallClock
+	Max   float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("budget of %q exceeded: %s used %g of %g", e.Key, e.Limit, e.Used, e.Max)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Budget limits the delegated work of a session or tenant. Zero values mean unlimited.
+// Token and cost limits are enforced against the usage remote agents report in task metadata.
+type Budget struct {
+	MaxTasks     int
+	MaxTokens    int64
+	MaxCost      float64
+	MaxWallClock time.Duration // Measured from the first task dispatched for the key
+}
+
+// BudgetKeyFunc selects the key a task is accounted under
+type BudgetKeyFunc func(ctx context.Context, params *types.TaskSendParams) string
+
+// SessionBudgetKey accounts tasks per session
+func SessionBudgetKey(ctx context.Context, params *types.TaskSendParams) string {
+	return params.SessionID
+}
+
+// TenantBudgetKey accounts tasks per tenant, as carried by ctx (see server.WithTenant)
+func TenantBudgetKey(ctx context.Context, params *types.TaskSendParams) string {
+	return server.TenantFromContext(ctx)
+}
+
+// BudgetEnforcer tracks the spending of each key and rejects tasks that would exceed the budget.
+// One enforcer can be shared by the connections to several remote agents.
+type BudgetEnforcer struct {
+	budget Budget
+	key    BudgetKeyFunc
+	clock  utils.Clock
+
+	lock     sync.Mutex
+	accounts map[string]*budgetAccount
+}
+
+// budgetAccount is the spending of one key
+type budgetAccount struct {
+	tasks   int
+	started time.Time
+	usage   types.Usage
+	perTask map[string]types.Usage // Cumulative usage last reported per task
+}
+
+// BudgetOption configures optional BudgetEnforcer behavior
+type BudgetOption func(*BudgetEnforcer)
+
+// WithBudgetClock sets the clock used for wall-clock limits
+func WithBudgetClock(clock utils.Clock) BudgetOption {
+	return func(b *BudgetEnforcer) {
+		b.clock = clock
+	}
+}
+
+// NewBudgetEnforcer creates a BudgetEnforcer; key defaults to SessionBudgetKey
+func NewBudgetEnforcer(budget Budget, key BudgetKeyFunc, opts ...BudgetOption) *BudgetEnforcer {
+	if key == nil {
+		key = SessionBudgetKey
+	}
+	b := &BudgetEnforcer{
+		budget:   budget,
+		key:      key,
+		clock:    utils.DefaultClock(),
+		accounts: make(map[string]*budgetAccount),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Usage returns the usage recorded for key
+func (b *BudgetEnforcer) Usage(key string) types.Usage {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if account := b.accounts[key]; account != nil {
+		return account.usage
+	}
+	return types.Usage{}
+}
+
+// Reset forgets the spending of key
+func (b *BudgetEnforcer) Reset(key string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.accounts, key)
+}
+
+// BudgetReservation is a task counted against a budget
+type BudgetReservation struct {
+	enforcer *BudgetEnforcer
+	key      string
+	cancel   context.CancelFunc
+}
+
+// Record accounts the usage reported by a task received from the agent; repeated
+// reports of the same task only add what changed
+func (r *BudgetReservation) Record(task *types.Task) {
+	if task != nil {
+		r.enforcer.record(r.key, task)
+	}
+}
+
+// Release ends the reservation and its wall-clock deadline
+func (r *BudgetReservation) Release() {
+	r.cancel()
+}
+
+// Reserve checks the budget before a task is dispatched and counts the task against it.
+// The returned context expires when the wall-clock budget runs out.
+func (b *BudgetEnforcer) Reserve(ctx context.Context, params *types.TaskSendParams) (context.Context, *BudgetReservation, error) {
+	key := b.key(ctx, params)
+	now := b.clock.Now()
+
+	b.lock.Lock()
+	account := b.accounts[key]
+	if account == nil {
+		account = &budgetAccount{started: now, perTask: make(map[string]types.Usage)}
+		b.accounts[key] = account
+	}
+	if err := b.check(key, account, now); err != nil {
+		b.lock.Unlock()
+		return ctx, nil, err
+	}
+	account.tasks++
+	deadline := account.started.Add(b.budget.MaxWallClock)
+	b.lock.Unlock()
+
+	reservation := &BudgetReservation{enforcer: b, key: key, cancel: func() {}}
+	if b.budget.MaxWallClock > 0 {
+		ctx, reservation.cancel = context.WithTimeout(ctx, deadline.Sub(now))
+	}
+	return ctx, reservation, nil
+}
+
+// check returns the first limit account has reached. The caller must hold b.lock.
+func (b *BudgetEnforcer) check(key string, account *budgetAccount, now time.Time) error {
+	budget := b.budget
+	switch {
+	case budget.MaxTasks > 0 && account.tasks >= budget.MaxTasks:
+		return &BudgetExceededError{Key: key, Limit: LimitTasks, Used: float64(account.tasks), Max: float64(budget.MaxTasks)}
+	case budget.MaxTokens > 0 && account.usage.TotalTokens >= budget.MaxTokens:
+		return &BudgetExceededError{Key: key, Limit: LimitTokens, Used: float64(account.usage.TotalTokens), Max: float64(budget.MaxTokens)}
+	case budget.MaxCost > 0 && account.usage.Cost >= budget.MaxCost:
+		return &BudgetExceededError{Key: key, Limit: LimitCost, Used: account.usage.Cost, Max: budget.MaxCost}
+	case budget.MaxWallClock > 0 && now.Sub(account.started) >= budget.MaxWallClock:
+		return &BudgetExceededError{Key: key, Limit: LimitWallClock, Used: now.Sub(account.started).Seconds(), Max: budget.MaxWallClock.Seconds()}
+	}
+	return nil
+}
+
+// record adds the usage a task reported since it was last recorded
+func (b *BudgetEnforcer) record(key string, task *types.Task) {
+	usage, ok := task.Usage()
+	if !ok {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	account := b.accounts[key]
+	if account == nil {
+		return
+	}
+	account.usage = account.usage.Add(usage.Sub(account.perTask[task.ID]))
+	account.perTask[task.ID] = usage
+}
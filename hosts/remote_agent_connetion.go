@@ -3,9 +3,9 @@ package hosts
 import (
 	"a2a-go/pkg/client"
 	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
 	"context"
 	"fmt"
-	"github.com/google/uuid"
 	"sync"
 )
 
@@ -20,7 +20,7 @@ type TaskWrapper struct {
 	*types.Task
 }
 
-func (t TaskWrapper) IsTaskCallbackArg() {}
+func (t TaskWrapper) IsTaskCallbackArg()  {}
 func (t TaskWrapper) Unwrap() interface{} { return t.Task }
 
 // TaskStatusUpdateWrapper wraps types.TaskStatusUpdateEvent to implement TaskCallbackArg
@@ -28,7 +28,7 @@ type TaskStatusUpdateWrapper struct {
 	*types.TaskStatusUpdateEvent
 }
 
-func (t TaskStatusUpdateWrapper) IsTaskCallbackArg() {}
+func (t TaskStatusUpdateWrapper) IsTaskCallbackArg()  {}
 func (t TaskStatusUpdateWrapper) Unwrap() interface{} { return t.TaskStatusUpdateEvent }
 
 // TaskArtifactUpdateWrapper wraps types.TaskArtifactUpdateEvent to implement TaskCallbackArg
@@ -36,7 +36,7 @@ type TaskArtifactUpdateWrapper struct {
 	*types.TaskArtifactUpdateEvent
 }
 
-func (t TaskArtifactUpdateWrapper) IsTaskCallbackArg() {}
+func (t TaskArtifactUpdateWrapper) IsTaskCallbackArg()  {}
 func (t TaskArtifactUpdateWrapper) Unwrap() interface{} { return t.TaskArtifactUpdateEvent }
 
 // TaskUpdateCallback is a function type that handles task updates
@@ -49,11 +49,16 @@ type RemoteAgentConnections struct {
 
 	conversationName string
 	pendingTasks     sync.Map // Using sync.Map for thread-safe set operations
+
+	budget *BudgetEnforcer
 }
 
-// NewRemoteAgentConnections creates a new RemoteAgentConnections instance
-func NewRemoteAgentConnections(agentCard *types.AgentCard) (*RemoteAgentConnections, error) {
-	client, err := client.NewA2AClient(agentCard, "")
+// NewRemoteAgentConnections creates a new RemoteAgentConnections instance; opts configure
+// the underlying client, e.g. client.WithUsageCallback for cost accounting.
+// Throttled requests are retried with client.DefaultRetryPolicy unless opts set another policy.
+func NewRemoteAgentConnections(agentCard *types.AgentCard, opts ...client.ClientOption) (*RemoteAgentConnections, error) {
+	opts = append([]client.ClientOption{client.WithRetryPolicy(client.DefaultRetryPolicy)}, opts...)
+	client, err := client.NewA2AClient(agentCard, "", opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create A2A client: %v", err)
 	}
@@ -63,13 +68,66 @@ func NewRemoteAgentConnections(agentCard *types.AgentCard) (*RemoteAgentConnecti
 	}, nil
 }
 
+// NewRemoteAgentConnectionsFromDomain discovers an agent through the DNS records of domain and connects to it
+func NewRemoteAgentConnectionsFromDomain(ctx context.Context, domain string, opts ...client.ClientOption) (*RemoteAgentConnections, error) {
+	resolver, err := client.NewA2ACardResolverFromDNS(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover agent of %s: %v", domain, err)
+	}
+	card, err := resolver.GetAgentCard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agent card: %v", err)
+	}
+	return NewRemoteAgentConnections(card, opts...)
+}
+
+// SessionUsage returns the usage the remote agent reported for the tasks of a session
+func (r *RemoteAgentConnections) SessionUsage(sessionID string) types.Usage {
+	return r.agentClient.SessionUsage(sessionID)
+}
+
+// SetBudget enforces budget before every task sent to the remote agent; the enforcer
+// may be shared with other connections so the budget spans all of them
+func (r *RemoteAgentConnections) SetBudget(budget *BudgetEnforcer) {
+	r.budget = budget
+}
+
 // GetAgent returns the agent card
 func (r *RemoteAgentConnections) GetAgent() *types.AgentCard {
 	return r.card
 }
 
+// ReplyContent returns the content of a task from the remote agent best matching the
+// preferred modes, or the agent's default output modes if none are given
+func (r *RemoteAgentConnections) ReplyContent(task *types.Task, preferred ...string) (types.PartContent, bool) {
+	if len(preferred) == 0 && r.card != nil {
+		preferred = r.card.DefaultOutputModes
+	}
+	return task.BestContent(preferred...)
+}
+
 // SendTask sends a task to the remote agent
 func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.TaskSendParams, taskCallback TaskUpdateCallback) (*types.Task, error) {
+	if r.budget != nil {
+		budgetCtx, reservation, err := r.budget.Reserve(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		defer reservation.Release()
+		ctx = budgetCtx
+
+		callback := taskCallback
+		taskCallback = func(arg TaskCallbackArg) *types.Task {
+			if task, ok := arg.Unwrap().(*types.Task); ok {
+				reservation.Record(task)
+			}
+			if callback == nil {
+				return nil
+			}
+			return callback(arg)
+		}
+	}
+
 	if r.card.Capabilities.Streaming {
 		var task *types.Task
 		if taskCallback != nil {
@@ -85,19 +143,28 @@ func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.Ta
 			task = taskCallback(TaskWrapper{initialTask})
 		}
 
-		// Create a channel to receive streaming responses
-		responseChan := make(chan *types.Task)
-		errorChan := make(chan error)
+		// Create a channel to receive streaming responses; buffered so the goroutine
+		// can finish after SendTask returned on cancellation
+		responseChan := make(chan *types.Task, 1)
+		errorChan := make(chan error, 1)
+
+		// The stream is aborted once SendTask returns
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		defer cancelStream()
 
 		// Start streaming in a goroutine
 		go func() {
-			streamChan, err := r.agentClient.SendTaskStreaming(request.Metadata)
+			streamChan, err := r.agentClient.SendTaskStreaming(streamCtx, request.Metadata)
 			if err != nil {
 				errorChan <- fmt.Errorf("failed to start streaming: %v", err)
 				return
 			}
 
 			for response := range streamChan {
+				if response.DecodeError != nil {
+					// A malformed event doesn't end the task's stream
+					continue
+				}
 				if response.Error != nil {
 					errorChan <- fmt.Errorf("streaming error: %v", response.Error)
 					return
@@ -105,7 +172,7 @@ func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.Ta
 
 				if taskResult, ok := response.Result.(*types.Task); ok {
 					mergeMetadata(taskResult, request)
-					
+
 					// Handle task status updates
 					if taskResult.Status.Message != nil {
 						mergeMetadata(taskResult.Status.Message, request.Message)
@@ -115,7 +182,7 @@ func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.Ta
 						if messageID, exists := taskResult.Status.Message.Metadata["message_id"]; exists {
 							taskResult.Status.Message.Metadata["last_message_id"] = messageID
 						}
-						taskResult.Status.Message.Metadata["message_id"] = uuid.New().String()
+						taskResult.Status.Message.Metadata["message_id"] = utils.NewID()
 					}
 
 					if taskCallback != nil {
@@ -141,7 +208,7 @@ func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.Ta
 		}
 	} else {
 		// Non-streaming case
-		response, err := r.agentClient.SendTask(request.Metadata)
+		response, err := r.agentClient.SendTask(ctx, request.Metadata)
 		if err != nil {
 			return nil, err
 		}
@@ -158,7 +225,7 @@ func (r *RemoteAgentConnections) SendTask(ctx context.Context, request *types.Ta
 				if messageID, exists := response.Result.Status.Message.Metadata["message_id"]; exists {
 					response.Result.Status.Message.Metadata["last_message_id"] = messageID
 				}
-				response.Result.Status.Message.Metadata["message_id"] = uuid.New().String()
+				response.Result.Status.Message.Metadata["message_id"] = utils.NewID()
 			}
 
 			if taskCallback != nil {
@@ -199,4 +266,4 @@ func mergeMetadata(target, source interface{}) {
 			}
 		}
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,176 @@
+// Command methodgen generates the typed client methods and the server dispatch stubs of the
+// built-in JSON-RPC methods from the method definition table in methods.go.
+//
+//	go run ./internal/methodgen -side client -o pkg/client/methods_gen.go
+//	go run ./internal/methodgen -side server -o pkg/server/methods_gen.go
+//
+// The generated files are kept up to date with go generate ./...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strconv"
+	"text/template"
+)
+
+func main() {
+	side := flag.String("side", "", "Code to generate: client or server")
+	out := flag.String("o", "", "Output file")
+	flag.Parse()
+
+	var tmpl *template.Template
+	switch *side {
+	case "client":
+		tmpl = clientTemplate
+	case "server":
+		tmpl = serverTemplate
+	default:
+		log.Fatalf("methodgen: unknown side %q, want client or server", *side)
+	}
+	if *out == "" {
+		log.Fatal("methodgen: -o is required")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, methods); err != nil {
+		log.Fatalf("methodgen: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("methodgen: formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("methodgen: %v", err)
+	}
+}
+
+var funcs = template.FuncMap{
+	// methodName is the Go expression naming a method
+	"methodName": func(m method) string {
+		if m.Const != "" {
+			return "types." + m.Const
+		}
+		return strconv.Quote(m.Name)
+	},
+	"result": func(m method) string {
+		if m.Response == "" {
+			return "chan *types.SendTaskStreamingResponse"
+		}
+		return fmt.Sprintf("*types.%s", m.Response)
+	},
+}
+
+const header = `// Code generated by methodgen from internal/methodgen/methods.go. DO NOT EDIT.
+
+`
+
+var clientTemplate = template.Must(template.New("client").Funcs(funcs).Parse(header + `package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+)
+{{range .}}{{if not .ClientCustom}}
+// {{.Func}} {{.Doc}}
+func (c *A2AClient) {{.Func}}(ctx context.Context, payload map[string]interface{}) ({{result .}}, error) {
+{{- if .Check}}
+{{- if eq .Check "pushNotifications"}}
+	if err := c.checkPushNotifications(); err != nil {
+{{- else}}
+	if err := c.checkPayloadPushNotification(payload); err != nil {
+{{- end}}
+		return nil, err
+	}
+{{end}}
+	var result types.{{.Response}}
+	if err := c.call(ctx, c.newRequest({{methodName .}}, payload), &result); err != nil {
+		return nil, err
+	}
+{{- if .Task}}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+{{- end}}
+
+	return &result, nil
+}
+{{end}}{{end}}
+{{- range .}}{{if .Func}}
+// {{.Func}}WithParams calls {{.Func}} with typed params
+func (c *A2AClient) {{.Func}}WithParams(ctx context.Context, params *types.{{.Params}}) ({{result .}}, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.{{.Func}}(ctx, payload)
+}
+{{end}}{{end}}`))
+
+var serverTemplate = template.Must(template.New("server").Funcs(funcs).Parse(header + `package server
+
+import (
+	"context"
+
+	"a2a-go/pkg/types"
+)
+
+// builtinMethods are the methods handled by processRequest itself; they cannot be registered
+var builtinMethods = map[string]bool{
+{{- range .}}
+	{{methodName .}}: true,
+{{- end}}
+}
+
+// methodParams creates the typed params of each built-in method. Method handlers can
+// assert request.Params to the type of their method.
+var methodParams = map[string]func() interface{}{
+{{- range .}}
+	{{methodName .}}: func() interface{} { return &types.{{.Params}}{} },
+{{- end}}
+}
+
+// supportedMethods returns the built-in methods served with tm, leaving out the methods of
+// optional interfaces it doesn't implement
+func supportedMethods(tm TaskManager) []string {
+	methods := make([]string, 0, len(builtinMethods))
+{{- range .}}
+{{- if .Interface}}
+	if _, ok := tm.({{.Interface}}); ok {
+		methods = append(methods, {{methodName .}})
+	}
+{{- else}}
+	methods = append(methods, {{methodName .}})
+{{- end}}
+{{- end}}
+	return methods
+}
+
+// dispatchMethod calls the handler of a built-in method processRequest doesn't dispatch
+// itself. It reports false for other methods and for methods of optional interfaces tm
+// doesn't implement.
+func dispatchMethod(ctx context.Context, tm TaskManager, request *types.JSONRPCRequest) (result interface{}, ok bool, err error) {
+	switch request.Method {
+{{- range .}}{{if not .ServerCustom}}
+	case {{methodName .}}:
+{{- if .Interface}}
+		handler, ok := tm.({{.Interface}})
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.{{.Handler}}(ctx, request)
+{{- else}}
+		result, err := tm.{{.Handler}}(ctx, request)
+{{- end}}
+		return result, true, err
+{{- end}}{{end}}
+	}
+	return nil, false, nil
+}
+`))
@@ -0,0 +1,113 @@
+package main
+
+// method describes a built-in JSON-RPC method of the protocol
+type method struct {
+	Name     string // JSON-RPC method name
+	Const    string // Constant of the types package naming the method, if any
+	Params   string // Params type of the types package
+	Response string // Response type of the types package; the result of streaming methods is a channel
+
+	Func         string // A2AClient method calling it
+	Doc          string // Doc comment of the client method, after its name
+	Check        string // Client capability check: "pushNotifications" or "payloadPushNotification"
+	Task         bool   // The result is a task, validated and accounted for by the client
+	ClientCustom bool   // The client method is handwritten; only its typed params variant is generated
+
+	Handler      string // TaskManager method handling it on the server
+	Interface    string // Optional interface declaring Handler, "" for TaskManager
+	ServerCustom bool   // processRequest dispatches the method itself
+}
+
+// methods is the method definition table the client methods and server stubs are generated from
+var methods = []method{
+	{
+		Name: "get_task", Params: "TaskQueryParams", Response: "GetTaskResponse",
+		Func: "GetTask", ClientCustom: true,
+		Handler: "OnGetTask", ServerCustom: true,
+	},
+	{
+		Name: "send_task", Params: "TaskSendParams", Response: "SendTaskResponse",
+		Func: "SendTask", Doc: "sends a task to the A2A server", Check: "payloadPushNotification", Task: true,
+		Handler: "OnSendTask",
+	},
+	{
+		Name: "send_task_streaming", Const: "SendTaskStreamingMethod", Params: "TaskSendParams",
+		Func: "SendTaskStreaming", ClientCustom: true,
+		Handler: "OnSendTaskSubscribe", ServerCustom: true,
+	},
+	{
+		Name: "cancel_task", Params: "TaskIdParams", Response: "CancelTaskResponse",
+		Func: "CancelTask", Doc: "cancels a task on the A2A server", Task: true,
+		Handler: "OnCancelTask",
+	},
+	{
+		Name: "set_task_push_notification", Params: "TaskPushNotificationConfig", Response: "SetTaskPushNotificationResponse",
+		Func: "SetTaskCallback", Doc: "sets a callback for a task", Check: "pushNotifications",
+		Handler: "OnSetTaskPushNotification",
+	},
+	{
+		Name: "get_task_push_notification", Params: "TaskIdParams", Response: "GetTaskPushNotificationResponse",
+		Func: "GetTaskCallback", Doc: "retrieves a task's callback configuration", Check: "pushNotifications",
+		Handler: "OnGetTaskPushNotification",
+	},
+	{
+		Name: "resubscribe_to_task", Const: "ResubscribeMethod", Params: "TaskQueryParams",
+		Func: "ResubscribeToTask", ClientCustom: true,
+		Handler: "OnResubscribeToTask", ServerCustom: true,
+	},
+	{
+		Name: "tasks/pause", Params: "TaskIdParams", Response: "PauseTaskResponse",
+		Func: "PauseTask", Doc: "suspends a working task on the A2A server", Task: true,
+		Handler: "OnPauseTask",
+	},
+	{
+		Name: "tasks/resume", Params: "TaskIdParams", Response: "ResumeTaskResponse",
+		Func: "ResumeTask", Doc: "continues a suspended task on the A2A server", Task: true,
+		Handler: "OnResumeTask",
+	},
+	{
+		Name: "tasks/transfer", Params: "TaskTransferParams", Response: "TransferTaskResponse",
+		Func: "TransferTask", Doc: "moves a task to a different session on the A2A server", Task: true,
+		Handler: "OnTransferTask",
+	},
+	{
+		Name: "tasks/replay", Const: "ReplayTaskMethod", Params: "TaskReplayParams",
+		Func: "ReplayTask", ClientCustom: true,
+		Handler: "OnReplayTask", Interface: "EventReplayer",
+	},
+	{
+		Name: "tasks/pushNotificationConfig/status", Params: "TaskIdParams", Response: "GetPushNotificationStatusResponse",
+		Func: "GetTaskCallbackStatus", Doc: "retrieves the push notification delivery receipts of a task", Check: "pushNotifications",
+		Handler: "OnGetPushNotificationStatus", Interface: "PushDeliveryReporter",
+	},
+	{
+		Name: "tasks/list", Const: "ListTasksMethod", Params: "TaskListParams", Response: "ListTasksResponse",
+		Func: "ListTasks", Doc: "searches the tasks on the A2A server by session, state and metadata, see types.TaskListParams",
+		Handler: "OnListTasks", Interface: "TaskLister",
+	},
+	{
+		Name: "tasks/events", Const: "PollTaskEventsMethod", Params: "TaskEventsParams", Response: "PollTaskEventsResponse",
+		Func: "PollTaskEvents", ClientCustom: true,
+		Handler: "OnPollTaskEvents", Interface: "EventPoller",
+	},
+	{
+		Name: "tasks/batchGet", Const: "BatchGetTasksMethod", Params: "TaskBatchParams", Response: "BatchGetTasksResponse",
+		Func: "BatchGetTasks", Doc: "retrieves several tasks in one round trip, with a task or an error per id",
+		ServerCustom: true,
+	},
+	{
+		Name: "tasks/batchCancel", Const: "BatchCancelTasksMethod", Params: "TaskBatchParams", Response: "BatchCancelTasksResponse",
+		Func: "BatchCancelTasks", Doc: "cancels several tasks in one round trip, with the canceled task or an error per id",
+		ServerCustom: true,
+	},
+	{
+		Name: "sessions/close", Const: "CloseSessionMethod", Params: "SessionCloseParams", Response: "CloseSessionResponse",
+		Func: "CloseSession", Doc: "ends a session on the A2A server, canceling its outstanding tasks",
+		Handler: "OnCloseSession", Interface: "SessionCloser",
+	},
+	{
+		Name: "agent/capabilities", Const: "AgentCapabilitiesMethod", Params: "AgentCapabilitiesParams", Response: "AgentCapabilitiesResponse",
+		Func: "GetAgentCapabilities", Doc: "retrieves the live capabilities of the agent, see NegotiateCapabilities",
+		ServerCustom: true,
+	},
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+// Completion describes how the value of a flag or positional argument is completed
+type Completion string
+
+const (
+	CompleteNone          Completion = ""
+	CompleteFiles         Completion = "files"
+	CompleteTaskIDs       Completion = "tasks"
+	CompleteSessionIDs    Completion = "sessions"
+	CompleteNotifications Completion = "notifications"
+)
+
+// Arg is a positional argument of a command
+type Arg struct {
+	Name       string
+	Completion Completion
+	Values     []string // Accepted values, completed instead of Completion when set
+}
+
+// Command describes a CLI command; completions and man pages are generated from it
+type Command struct {
+	Name        string
+	Summary     string
+	Flags       *flag.FlagSet
+	Args        []Arg
+	Subcommands []*Command
+
+	// FlagCompletions maps flag names to the completion of their values
+	FlagCompletions map[string]Completion
+	// FlagValues lists the accepted values of enumerated flags
+	FlagValues map[string][]string
+}
+
+// Subcommand returns the direct subcommand called name, or nil
+func (c *Command) Subcommand(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Usage returns the one-line synopsis of the command, e.g. "export <task-id> [flags]"
+func (c *Command) Usage() string {
+	parts := []string{c.Name}
+	for _, arg := range c.Args {
+		parts = append(parts, "<"+arg.Name+">")
+	}
+	if len(c.Subcommands) > 0 {
+		names := make([]string, len(c.Subcommands))
+		for i, sub := range c.Subcommands {
+			names[i] = sub.Name
+		}
+		parts = append(parts, strings.Join(names, "|"))
+	}
+	if c.hasFlags() {
+		parts = append(parts, "[flags]")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) hasFlags() bool {
+	has := false
+	if c.Flags != nil {
+		c.Flags.VisitAll(func(*flag.Flag) { has = true })
+	}
+	return has
+}
+
+// flags returns the flags of the command sorted by name
+func (c *Command) flags() []*flag.Flag {
+	var flags []*flag.Flag
+	if c.Flags != nil {
+		c.Flags.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// takesValue reports whether flag name of the command expects a value
+func (c *Command) takesValue(name string) (*flag.Flag, bool) {
+	if c.Flags == nil {
+		return nil, false
+	}
+	f := c.Flags.Lookup(name)
+	if f == nil {
+		return nil, false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return f, false
+	}
+	return f, true
+}
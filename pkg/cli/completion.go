@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CompleteCommand is the hidden command the generated shell scripts call to compute candidates
+const CompleteCommand = "__complete"
+
+// fileCompletionDirective tells the shell scripts to fall back to file name completion
+const fileCompletionDirective = ":files"
+
+// IDSource returns the known ids of a kind, most recent first
+type IDSource func(kind Completion) []string
+
+// Complete returns the completion candidates for words, the arguments typed after the
+// program name; the last word is the one being completed and may be empty
+func (c *Command) Complete(words []string, ids IDSource) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	cmd := c
+	argIndex := 0
+	pending := ""
+	for i := 0; i < len(words)-1; i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "-") {
+			name := strings.TrimLeft(word, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			if f, ok := cmd.takesValue(name); ok {
+				if i+1 == len(words)-1 {
+					pending = f.Name
+					break
+				}
+				i++
+			}
+			continue
+		}
+		if sub := cmd.Subcommand(word); sub != nil && argIndex == 0 {
+			cmd = sub
+			continue
+		}
+		argIndex++
+	}
+
+	var candidates []string
+	switch {
+	case pending != "":
+		if values, ok := cmd.FlagValues[pending]; ok {
+			candidates = values
+		} else {
+			candidates = completionCandidates(cmd.FlagCompletions[pending], ids)
+		}
+	case strings.HasPrefix(current, "-"):
+		for _, f := range cmd.flags() {
+			candidates = append(candidates, "-"+f.Name)
+		}
+	case argIndex == 0 && len(cmd.Subcommands) > 0:
+		for _, sub := range cmd.Subcommands {
+			candidates = append(candidates, sub.Name)
+		}
+	case argIndex < len(cmd.Args):
+		arg := cmd.Args[argIndex]
+		if len(arg.Values) > 0 {
+			candidates = arg.Values
+		} else {
+			candidates = completionCandidates(arg.Completion, ids)
+		}
+	}
+
+	if len(candidates) == 1 && candidates[0] == fileCompletionDirective {
+		return candidates
+	}
+	matches := candidates[:0:0]
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func completionCandidates(kind Completion, ids IDSource) []string {
+	switch kind {
+	case CompleteNone:
+		return nil
+	case CompleteFiles:
+		return []string{fileCompletionDirective}
+	}
+	if ids == nil {
+		return nil
+	}
+	return ids(kind)
+}
+
+// WriteCompletionScript writes the completion script of program for shell (bash, zsh or fish).
+// The scripts call `program __complete` so completions always match the installed binary.
+func WriteCompletionScript(w io.Writer, shell, program string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", shell)
+	}
+	fn := strings.NewReplacer("-", "_", ".", "_").Replace(program)
+	script = strings.NewReplacer(
+		"{{program}}", program,
+		"{{fn}}", fn,
+		"{{complete}}", CompleteCommand,
+		"{{files}}", fileCompletionDirective,
+	).Replace(script)
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+const bashCompletion = `# bash completion for {{program}}
+_{{fn}}_complete() {
+    local IFS=$'\n'
+    local candidates
+    candidates=$("${COMP_WORDS[0]}" {{complete}} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+    if [[ "$candidates" == "{{files}}" ]]; then
+        COMPREPLY=($(compgen -f -- "${COMP_WORDS[COMP_CWORD]}"))
+    else
+        COMPREPLY=($candidates)
+    fi
+}
+complete -F _{{fn}}_complete {{program}}
+`
+
+const zshCompletion = `#compdef {{program}}
+_{{fn}}() {
+    local -a candidates
+    candidates=("${(@f)$(${words[1]} {{complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ "${candidates[1]}" == "{{files}}" ]]; then
+        _files
+    elif (( ${#candidates} )) && [[ -n "${candidates[1]}" ]]; then
+        compadd -- "${candidates[@]}"
+    fi
+}
+compdef _{{fn}} {{program}}
+`
+
+const fishCompletion = `# fish completion for {{program}}
+function __{{fn}}_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    set -l candidates ($tokens[1] {{complete}} $tokens[2..-1] 2>/dev/null)
+    if test "$candidates[1]" = "{{files}}"
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+complete -c {{program}} -f -a '(__{{fn}}_complete)'
+`
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxCachedIDs bounds how many ids of each kind the cache remembers
+const maxCachedIDs = 100
+
+// IDCache remembers recently used session and task ids for shell completion
+type IDCache struct {
+	path string
+	lock sync.Mutex
+}
+
+// DefaultIDCachePath returns the id cache file next to the default inbox
+func DefaultIDCachePath() string {
+	return filepath.Join(filepath.Dir(DefaultInboxPath()), "recent_ids.json")
+}
+
+// NewIDCache opens the id cache stored at path
+func NewIDCache(path string) *IDCache {
+	return &IDCache{path: path}
+}
+
+// Remember records id as the most recently used id of its kind
+func (c *IDCache) Remember(kind Completion, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	ids := []string{id}
+	for _, existing := range entries[kind] {
+		if existing != id && len(ids) < maxCachedIDs {
+			ids = append(ids, existing)
+		}
+	}
+	entries[kind] = ids
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode id cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create id cache directory: %w", err)
+	}
+	// Write through a temporary file so concurrent readers never see a partial cache
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write id cache: %w", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// IDs returns the remembered ids of kind, most recent first
+func (c *IDCache) IDs(kind Completion) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return nil
+	}
+	return entries[kind]
+}
+
+// load reads the cache file; a missing file is an empty cache. The caller must hold c.lock.
+func (c *IDCache) load() (map[Completion][]string, error) {
+	entries := make(map[Completion][]string)
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read id cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		// A corrupt cache only costs completions; start over
+		return make(map[Completion][]string), nil
+	}
+	return entries, nil
+}
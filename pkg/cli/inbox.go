@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"a2a-go/pkg/utils"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotificationNotFound is returned by Inbox.Get for unknown ids
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// StoredNotification is a verified push notification kept in the inbox
+type StoredNotification struct {
+	ID         string                 `json:"id"`
+	ReceivedAt time.Time              `json:"receivedAt"`
+	TaskID     string                 `json:"taskId,omitempty"`
+	State      string                 `json:"state,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+}
+
+// Inbox persists push notifications as JSON lines in a local file, so notifications
+// delivered while no CLI session was watching the stream are not lost
+type Inbox struct {
+	path string
+	lock sync.Mutex
+}
+
+// DefaultInboxPath returns the inbox file in the user's config directory
+func DefaultInboxPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "a2a", "notifications.jsonl")
+}
+
+// NewInbox opens the inbox stored at path, creating its directory if needed
+func NewInbox(path string) (*Inbox, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create inbox directory: %w", err)
+	}
+	return &Inbox{path: path}, nil
+}
+
+// Path returns the file backing the inbox
+func (i *Inbox) Path() string {
+	return i.path
+}
+
+// Add stores a notification and returns it with its assigned id
+func (i *Inbox) Add(payload map[string]interface{}) (*StoredNotification, error) {
+	notification := &StoredNotification{
+		ID:         utils.NewID(),
+		ReceivedAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+	notification.TaskID, notification.State = describeNotification(payload)
+
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	file, err := os.OpenFile(i.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inbox: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write inbox: %w", err)
+	}
+	return notification, nil
+}
+
+// List returns the stored notifications, oldest first
+func (i *Inbox) List() ([]*StoredNotification, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	file, err := os.Open(i.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inbox: %w", err)
+	}
+	defer file.Close()
+
+	var notifications []*StoredNotification
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var notification StoredNotification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			// Skip lines truncated by a crash while writing
+			continue
+		}
+		notifications = append(notifications, &notification)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+	return notifications, nil
+}
+
+// Get returns the notification with the given id; a unique id prefix is accepted too
+func (i *Inbox) Get(id string) (*StoredNotification, error) {
+	notifications, err := i.List()
+	if err != nil {
+		return nil, err
+	}
+	var match *StoredNotification
+	for _, notification := range notifications {
+		if notification.ID == id {
+			return notification, nil
+		}
+		if strings.HasPrefix(notification.ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("notification id %q is ambiguous", id)
+			}
+			match = notification
+		}
+	}
+	if match == nil {
+		return nil, ErrNotificationNotFound
+	}
+	return match, nil
+}
+
+// describeNotification extracts the task id and state from a task or status update payload
+func describeNotification(payload map[string]interface{}) (taskID, state string) {
+	body := payload
+	if result, ok := payload["result"].(map[string]interface{}); ok {
+		body = result
+	}
+	taskID, _ = body["id"].(string)
+	if status, ok := body["status"].(map[string]interface{}); ok {
+		state, _ = status["state"].(string)
+	}
+	return taskID, state
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteManPage writes a section 1 man page in roff format describing root and its subcommands
+func WriteManPage(w io.Writer, program string, root *Command, date time.Time) error {
+	out := bufio.NewWriter(w)
+	name := strings.ToUpper(program)
+
+	fmt.Fprintf(out, ".TH %s 1 %q %q \"User Commands\"\n", name, date.Format("2006-01-02"), program)
+	fmt.Fprintf(out, ".SH NAME\n%s \\- %s\n", roffEscape(program), roffEscape(root.Summary))
+
+	fmt.Fprintf(out, ".SH SYNOPSIS\n")
+	fmt.Fprintf(out, ".B %s\n[flags]\n", roffEscape(program))
+	for _, sub := range root.Subcommands {
+		writeSynopsis(out, program, nil, sub)
+	}
+
+	if root.hasFlags() {
+		fmt.Fprintf(out, ".SH OPTIONS\n")
+		writeFlags(out, root)
+	}
+
+	if len(root.Subcommands) > 0 {
+		fmt.Fprintf(out, ".SH COMMANDS\n")
+		for _, sub := range root.Subcommands {
+			writeCommand(out, nil, sub)
+		}
+	}
+	return out.Flush()
+}
+
+func writeSynopsis(out *bufio.Writer, program string, parents []string, cmd *Command) {
+	if cmd.Name == CompleteCommand {
+		return
+	}
+	path := append(append([]string{}, parents...), cmd.Name)
+	if len(cmd.Subcommands) > 0 {
+		for _, sub := range cmd.Subcommands {
+			writeSynopsis(out, program, path, sub)
+		}
+		return
+	}
+	fmt.Fprintf(out, ".br\n.B %s\n%s\n", roffEscape(program), roffEscape(strings.Join(append(parents, cmd.Usage()), " ")))
+}
+
+func writeCommand(out *bufio.Writer, parents []string, cmd *Command) {
+	if cmd.Name == CompleteCommand {
+		return
+	}
+	path := append(append([]string{}, parents...), cmd.Name)
+	fmt.Fprintf(out, ".TP\n.B %s\n%s\n", roffEscape(strings.Join(append(parents, cmd.Usage()), " ")), roffEscape(cmd.Summary))
+	if cmd.hasFlags() {
+		fmt.Fprintf(out, ".RS\n")
+		writeFlags(out, cmd)
+		fmt.Fprintf(out, ".RE\n")
+	}
+	for _, sub := range cmd.Subcommands {
+		writeCommand(out, path, sub)
+	}
+}
+
+func writeFlags(out *bufio.Writer, cmd *Command) {
+	for _, f := range cmd.flags() {
+		label := "\\-" + roffEscape(f.Name)
+		if _, takesValue := cmd.takesValue(f.Name); takesValue {
+			label += " \\fIvalue\\fR"
+		}
+		usage := roffEscape(f.Usage)
+		if values, ok := cmd.FlagValues[f.Name]; ok {
+			usage += " One of: " + roffEscape(strings.Join(values, ", ")) + "."
+		}
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += " (default: " + roffEscape(f.DefValue) + ")"
+		}
+		fmt.Fprintf(out, ".TP\n.B %s\n%s\n", label, usage)
+	}
+}
+
+// roffEscape escapes text so roff prints it literally
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"a2a-go/pkg/client"
 	"a2a-go/pkg/utils"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -12,20 +14,44 @@ import (
 
 // PushNotificationListener handles incoming push notifications from the agent
 type PushNotificationListener struct {
-	host                   string
-	port                   string
+	host                     string
+	port                     string
 	notificationReceiverAuth *utils.PushNotificationReceiverAuth
-	server                 *http.Server
-	wg                     sync.WaitGroup
+	server                   *http.Server
+	wg                       sync.WaitGroup
+	inbox                    *Inbox
+	sequencer                *client.NotificationSequencer
+}
+
+// ListenerOption configures optional PushNotificationListener behavior
+type ListenerOption func(*PushNotificationListener)
+
+// WithInbox stores every verified notification in inbox
+func WithInbox(inbox *Inbox) ListenerOption {
+	return func(l *PushNotificationListener) {
+		l.inbox = inbox
+	}
+}
+
+// WithOrdering delivers the notifications of each task in sequence order, calling resync
+// (e.g. client.GetTaskResync) to re-sync a task whose notifications went missing
+func WithOrdering(resync client.ResyncFunc, opts ...client.SequencerOption) ListenerOption {
+	return func(l *PushNotificationListener) {
+		l.sequencer = client.NewNotificationSequencer(l.deliver, resync, opts...)
+	}
 }
 
 // NewPushNotificationListener creates a new push notification listener
-func NewPushNotificationListener(host, port string, auth *utils.PushNotificationReceiverAuth) *PushNotificationListener {
-	return &PushNotificationListener{
-		host:                   host,
-		port:                   port,
+func NewPushNotificationListener(host, port string, auth *utils.PushNotificationReceiverAuth, opts ...ListenerOption) *PushNotificationListener {
+	l := &PushNotificationListener{
+		host:                     host,
+		port:                     port,
 		notificationReceiverAuth: auth,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // Start starts the push notification listener server
@@ -35,14 +61,14 @@ func (l *PushNotificationListener) Start() {
 	mux.HandleFunc("/.well-known/validation-token", l.handleValidationCheck)
 
 	l.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%s", l.host, l.port),
+		Addr:    net.JoinHostPort(l.host, l.port),
 		Handler: mux,
 	}
 
 	l.wg.Add(1)
 	go func() {
 		defer l.wg.Done()
-		log.Printf("Starting push notification listener on %s:%s", l.host, l.port)
+		log.Printf("Starting push notification listener on %s", l.server.Addr)
 		if err := l.server.ListenAndServe(); err != http.ErrServerClosed {
 			log.Printf("Push notification listener error: %v", err)
 		}
@@ -100,6 +126,33 @@ func (l *PushNotificationListener) handleNotification(w http.ResponseWriter, r *
 		return
 	}
 
-	log.Printf("Received notification: %+v", notification)
+	if l.sequencer != nil {
+		// Delivery may wait for earlier notifications, so storage errors are only logged
+		l.sequencer.Receive(notification)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := l.store(notification); err != nil {
+		http.Error(w, "Error storing notification", http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
-} 
\ No newline at end of file
+}
+
+// store logs a notification and adds it to the inbox, if any
+func (l *PushNotificationListener) store(notification map[string]interface{}) error {
+	log.Printf("Received notification: %+v", notification)
+	if l.inbox == nil {
+		return nil
+	}
+	if _, err := l.inbox.Add(notification); err != nil {
+		log.Printf("Error storing notification: %v", err)
+		return err
+	}
+	return nil
+}
+
+// deliver stores a notification released by the sequencer
+func (l *PushNotificationListener) deliver(notification map[string]interface{}) {
+	l.store(notification)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ErrStreamingNotSupported is returned when streaming against an agent whose card does not declare streaming
+var ErrStreamingNotSupported = errors.New("agent does not support streaming")
+
+// ErrPushNotSupported is returned when configuring push notifications on an agent whose card does not declare them
+var ErrPushNotSupported = errors.New("agent does not support push notifications")
+
+// Card returns the agent card the client was created with, or nil if it was created from a URL
+func (c *A2AClient) Card() *types.AgentCard {
+	c.cardLock.Lock()
+	defer c.cardLock.Unlock()
+	return c.card
+}
+
+// GetAgentCard returns the agent card, fetching it from the agent's well-known path
+// when the client was created from a URL
+func (c *A2AClient) GetAgentCard(ctx context.Context) (*types.AgentCard, error) {
+	if card := c.Card(); card != nil {
+		return card, nil
+	}
+
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, err
+	}
+	base := url.URL{Scheme: u.Scheme, Host: u.Host}
+	resolver := NewA2ACardResolver(base.String(), "/.well-known/agent.json", WithCardResolverHTTPClient(c.httpClient))
+	card, err := resolver.GetAgentCard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cardLock.Lock()
+	defer c.cardLock.Unlock()
+	if c.card == nil {
+		c.card = card
+	}
+	return c.card, nil
+}
+
+// checkStreaming fails fast when the known agent card says streaming is unsupported
+func (c *A2AClient) checkStreaming() error {
+	if card := c.Card(); card != nil && !card.Capabilities.Streaming {
+		return ErrStreamingNotSupported
+	}
+	return nil
+}
+
+// checkPushNotifications fails fast when the known agent card says push notifications are unsupported
+func (c *A2AClient) checkPushNotifications() error {
+	if card := c.Card(); card != nil && !card.Capabilities.PushNotifications {
+		return ErrPushNotSupported
+	}
+	return nil
+}
+
+// checkPayloadPushNotification guards send payloads that register a push notification config
+func (c *A2AClient) checkPayloadPushNotification(payload map[string]interface{}) error {
+	if payload["pushNotification"] == nil {
+		return nil
+	}
+	return c.checkPushNotifications()
+}
@@ -2,38 +2,136 @@ package client
 
 import (
 	"a2a-go/pkg/types"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 )
 
+// DefaultCardFallbackPaths are tried in order when the configured agent card path fails
+var DefaultCardFallbackPaths = []string{
+	".well-known/agent.json",
+	".well-known/agent-card.json",
+	"agent.json",
+	"agent-card.json",
+}
+
+// defaultCardMaxRedirects caps the redirects followed while fetching an agent card
+const defaultCardMaxRedirects = 5
+
 // A2ACardResolver handles fetching and parsing agent cards from A2A servers
 type A2ACardResolver struct {
 	baseURL       string
 	agentCardPath string
+	fallbackPaths []string
+	maxRedirects  int
 	client        *http.Client
+	optionErr     error
+
+	resolvedURL string
+}
+
+// WithCardFallbackPaths replaces the paths tried after the configured agent card path.
+// Entries may be paths relative to the base URL or absolute http(s) URLs.
+func WithCardFallbackPaths(paths ...string) CardResolverOption {
+	return func(r *A2ACardResolver) {
+		r.fallbackPaths = paths
+	}
+}
+
+// WithCardMaxRedirects caps the number of redirects followed per agent card request
+func WithCardMaxRedirects(n int) CardResolverOption {
+	return func(r *A2ACardResolver) {
+		r.maxRedirects = n
+	}
 }
 
-// NewA2ACardResolver creates a new A2ACardResolver instance
-func NewA2ACardResolver(baseURL, agentCardPath string) *A2ACardResolver {
+// NewA2ACardResolver creates a new A2ACardResolver instance.
+// agentCardPath may also be an absolute card URL.
+// Proxies from the environment (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) are honored unless overridden.
+func NewA2ACardResolver(baseURL, agentCardPath string, opts ...CardResolverOption) *A2ACardResolver {
 	// Clean up the URLs
 	baseURL = strings.TrimRight(baseURL, "/")
-	agentCardPath = strings.TrimLeft(agentCardPath, "/")
+	if !isAbsoluteURL(agentCardPath) {
+		agentCardPath = strings.TrimLeft(agentCardPath, "/")
+	}
 
-	return &A2ACardResolver{
+	r := &A2ACardResolver{
 		baseURL:       baseURL,
 		agentCardPath: agentCardPath,
+		fallbackPaths: DefaultCardFallbackPaths,
+		maxRedirects:  defaultCardMaxRedirects,
 		client:        &http.Client{},
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ResolvedURL returns the URL the last successful GetAgentCard call fetched the card from
+func (r *A2ACardResolver) ResolvedURL() string {
+	return r.resolvedURL
 }
 
-// GetAgentCard fetches and parses the agent card from the A2A server
-func (r *A2ACardResolver) GetAgentCard() (*types.AgentCard, error) {
-	url := fmt.Sprintf("%s/%s", r.baseURL, r.agentCardPath)
-	
-	resp, err := r.client.Get(url)
+// GetAgentCard fetches and parses the agent card from the A2A server, trying the
+// configured path first and then the fallback paths in order
+func (r *A2ACardResolver) GetAgentCard(ctx context.Context) (*types.AgentCard, error) {
+	if r.optionErr != nil {
+		return nil, r.optionErr
+	}
+
+	var errs []error
+	for _, cardURL := range r.candidateURLs() {
+		card, err := r.fetchCard(ctx, cardURL)
+		if err == nil {
+			r.resolvedURL = cardURL
+			return card, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", cardURL, err))
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, fmt.Errorf("no agent card found: %w", errors.Join(errs...))
+}
+
+// candidateURLs returns the distinct card URLs to try, in order
+func (r *A2ACardResolver) candidateURLs() []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, p := range append([]string{r.agentCardPath}, r.fallbackPaths...) {
+		cardURL := p
+		if !isAbsoluteURL(p) {
+			cardURL = fmt.Sprintf("%s/%s", r.baseURL, strings.TrimLeft(p, "/"))
+		}
+		if !seen[cardURL] {
+			seen[cardURL] = true
+			urls = append(urls, cardURL)
+		}
+	}
+	return urls
+}
+
+// fetchCard fetches and parses one agent card URL
+func (r *A2ACardResolver) fetchCard(ctx context.Context, url string) (*types.AgentCard, error) {
+	client := *r.client
+	maxRedirects := r.maxRedirects
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
 	}
@@ -56,4 +154,9 @@ func (r *A2ACardResolver) GetAgentCard() (*types.AgentCard, error) {
 	}
 
 	return &card, nil
-} 
\ No newline at end of file
+}
+
+// isAbsoluteURL reports whether p is an absolute http(s) URL
+func isAbsoluteURL(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ArtifactFunc receives one artifact of a task decoded incrementally by GetTaskArtifacts
+type ArtifactFunc func(artifact types.Artifact) error
+
+// GetTaskArtifacts retrieves a task like GetTask, but decodes the response while it is received
+// and hands each artifact to onArtifact instead of buffering the whole response.
+// The returned task has no artifacts. An error from onArtifact aborts the request.
+func (c *A2AClient) GetTaskArtifacts(ctx context.Context, payload map[string]interface{}, onArtifact ArtifactFunc) (*types.GetTaskResponse, error) {
+	request := c.newRequest("get_task", payload)
+
+	var result types.GetTaskResponse
+	err := c.sendRequestStream(ctx, request, func(r io.Reader) error {
+		return decodeGetTaskResponse(json.NewDecoder(r), &result, onArtifact)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// decodeGetTaskResponse walks a get_task response, streaming the artifacts of its result
+func decodeGetTaskResponse(dec *json.Decoder, result *types.GetTaskResponse, onArtifact ArtifactFunc) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		key, err := objectKey(dec)
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case "result":
+			task, err := decodeTaskStream(dec, onArtifact)
+			if err != nil {
+				return err
+			}
+			result.Result = task
+		case "error":
+			var rpcErr types.JSONRPCError
+			if err := dec.Decode(&rpcErr); err != nil {
+				return jsonError(err)
+			}
+			return &rpcErr
+		case "notModified":
+			err = dec.Decode(&result.NotModified)
+		case "events":
+			err = dec.Decode(&result.Events)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return jsonError(err)
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeTaskStream decodes a task object, passing its artifacts to onArtifact one at a time
+func decodeTaskStream(dec *json.Decoder, onArtifact ArtifactFunc) (*types.Task, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, jsonError(err)
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, jsonError(fmt.Errorf("expected task object, got %v", token))
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		key, err := objectKey(dec)
+		if err != nil {
+			return nil, err
+		}
+		if key != "artifacts" {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, jsonError(err)
+			}
+			fields[key] = value
+			continue
+		}
+
+		token, err := dec.Token()
+		if err != nil {
+			return nil, jsonError(err)
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return nil, jsonError(fmt.Errorf("expected artifacts array, got %v", token))
+		}
+		for dec.More() {
+			var artifact types.Artifact
+			if err := dec.Decode(&artifact); err != nil {
+				return nil, jsonError(err)
+			}
+			if err := onArtifact(artifact); err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, jsonError(err)
+	}
+	var task types.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, jsonError(err)
+	}
+	return &task, nil
+}
+
+func objectKey(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", jsonError(err)
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", jsonError(fmt.Errorf("expected object key, got %v", token))
+	}
+	return key, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return jsonError(err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return jsonError(fmt.Errorf("expected %v, got %v", want, token))
+	}
+	return nil
+}
+
+func jsonError(err error) error {
+	return &types.A2AClientJSONError{
+		Message: fmt.Sprintf("failed to parse response: %v", err),
+	}
+}
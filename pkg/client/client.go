@@ -1,256 +1,510 @@
 package client
 
 import (
+	"a2a-go/pkg/codec"
 	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // A2AClient represents an A2A client for interacting with A2A servers
 type A2AClient struct {
-	url string
+	url               string
+	progress          ProgressFunc
+	strict            bool
+	stopOnDecodeError bool
+
+	dialTimeout           time.Duration
+	responseHeaderTimeout time.Duration
+	requestTimeout        time.Duration
+	streamIdleTimeout     time.Duration
+	transportOptions      []func(*http.Transport)
+	egress                utils.EgressPolicy
+	tokens                *utils.TokenManager
+	httpClient            *http.Client
+	streamClient          *http.Client
+	tlsConfig             *tls.Config
+	rootCAs               *x509.CertPool
+	clientCerts           []tls.Certificate
+	optionErr             error
+
+	card            *types.AgentCard
+	cardLock        sync.Mutex
+	protocolVersion string
+	capabilities    *types.AgentCapabilitiesResult // Reported by agent/capabilities, guarded by cardLock
+
+	noStreamCompression bool
+	retryPolicy         RetryPolicy
+	codec               codec.Codec
+
+	onUsage UsageFunc
+	usage   usageLedger
+
+	fileResolvers *utils.FileResolvers
+
+	resubscribeTokens sync.Map // Task id to the resubscribe token of its last stream
+	lastEventIDs      sync.Map // Task id to the id of the last SSE event received for it
+
+	longPoll longPoll
+
+	taskCache *taskCache
+
+	calls callRegistry
+
+	extensions []ClientExtension
+}
+
+// ClientOption configures optional A2AClient behavior
+type ClientOption func(*A2AClient)
+
+// WithStrictValidation makes the client reject tasks and events with unknown states,
+// missing required fields or invalid parts instead of passing them on
+func WithStrictValidation() ClientOption {
+	return func(c *A2AClient) {
+		c.strict = true
+	}
+}
+
+// WithStopOnDecodeError ends streams at the first event that fails to decode. By default
+// the event is reported with a *types.DecodeError and the stream goes on.
+func WithStopOnDecodeError() ClientOption {
+	return func(c *A2AClient) {
+		c.stopOnDecodeError = true
+	}
 }
 
 // NewA2AClient creates a new A2AClient instance
-func NewA2AClient(agentCard *types.AgentCard, url string) (*A2AClient, error) {
+func NewA2AClient(agentCard *types.AgentCard, url string, opts ...ClientOption) (*A2AClient, error) {
+	c := &A2AClient{
+		dialTimeout:    defaultDialTimeout,
+		requestTimeout: defaultRequestTimeout,
+	}
+	c.longPoll.after = DefaultLongPollFallbackAfter
 	if agentCard != nil {
-		return &A2AClient{url: agentCard.URL}, nil
+		c.url = agentCard.URL
+		c.card = agentCard
+	} else if url != "" {
+		c.url = url
+	} else {
+		return nil, fmt.Errorf("must provide either agent_card or url")
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.optionErr != nil {
+		return nil, c.optionErr
 	}
-	if url != "" {
-		return &A2AClient{url: url}, nil
+	c.buildHTTPClients()
+	if c.fileResolvers == nil {
+		c.fileResolvers = utils.NewFileResolvers(utils.WithFileHTTPClient(c.streamClient))
 	}
-	return nil, fmt.Errorf("must provide either agent_card or url")
+	return c, nil
 }
 
-// SendTask sends a task to the A2A server
-func (c *A2AClient) SendTask(payload map[string]interface{}) (*types.SendTaskResponse, error) {
-	request := &types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "send_task",
-		Params:  payload,
+// SendTaskStreaming sends a task and streams the response until the stream ends or ctx is
+// canceled. Canceling ctx aborts the HTTP request and closes the channel, so consumers that
+// stop reading early must cancel it to release the connection.
+func (c *A2AClient) SendTaskStreaming(ctx context.Context, payload map[string]interface{}) (chan *types.SendTaskStreamingResponse, error) {
+	if err := c.checkStreaming(); err != nil {
+		return nil, err
+	}
+	if err := c.checkPayloadPushNotification(payload); err != nil {
+		return nil, err
 	}
 
-	response, err := c.sendRequest(request)
+	request := c.newRequest("send_task_streaming", payload)
+	sessionID, _ := payload["sessionId"].(string)
+	taskID := requestTaskID(request.Params)
+
+	return c.trackCallStream(ctx, request.Method, taskID, func(ctx context.Context) (chan *types.SendTaskStreamingResponse, error) {
+		if taskID != "" && c.LongPolling() {
+			return c.observeStreamUsage(ctx, sessionID, c.sendTaskLongPoll(ctx, request, taskID)), nil
+		}
+		responseChan, err := c.sendStreamingRequest(ctx, request)
+		if err != nil {
+			c.streamRequestFailed(err)
+			return nil, err
+		}
+		return c.observeStreamUsage(ctx, sessionID, c.trackStream(ctx, responseChan)), nil
+	})
+}
+
+// SendTaskStreamingEvents sends a task and streams decoded TaskEvents instead of raw responses.
+// Events that cannot be decoded are delivered as error events.
+func (c *A2AClient) SendTaskStreamingEvents(ctx context.Context, payload map[string]interface{}) (<-chan types.TaskEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	responseChan, err := c.SendTaskStreaming(ctx, payload)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
+	return c.decodeTaskEvents(ctx, cancel, responseChan), nil
+}
 
-	var result types.SendTaskResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to parse response: %v", err),
+// validateTask checks a received task when strict validation is enabled
+func (c *A2AClient) validateTask(task *types.Task) error {
+	if !c.strict {
+		return nil
+	}
+	if err := types.ValidateTask(task); err != nil {
+		return &types.A2AClientJSONError{
+			Message: fmt.Sprintf("invalid task in response: %v", err),
 		}
 	}
+	return nil
+}
 
-	return &result, nil
+// decodeTaskEvents converts a channel of raw streaming responses into TaskEvents until ctx
+// is canceled. cancel ends the stream of responseChan.
+func (c *A2AClient) decodeTaskEvents(ctx context.Context, cancel context.CancelFunc, responseChan chan *types.SendTaskStreamingResponse) <-chan types.TaskEvent {
+	eventChan := make(chan types.TaskEvent)
+	go func() {
+		defer close(eventChan)
+		defer func() {
+			cancel()
+			for range responseChan {
+			}
+		}()
+		for response := range responseChan {
+			event, err := types.DecodeTaskEvent(response)
+			if err == nil && c.strict {
+				err = types.ValidateTaskEvent(event)
+			}
+			if err != nil {
+				data, _ := json.Marshal(response.Result)
+				decodeErr := &types.DecodeError{EventID: response.EventID, Data: data, Err: err}
+				event = types.TaskEvent{
+					RequestID:   response.ID,
+					Error:       decodeErr.JSONRPCError(),
+					DecodeError: decodeErr,
+				}
+			}
+			select {
+			case eventChan <- event:
+			case <-ctx.Done():
+				return
+			}
+			if event.DecodeError != nil && c.stopOnDecodeError {
+				return
+			}
+		}
+	}()
+	return eventChan
 }
 
-// SendTaskStreaming sends a task and streams the response
-func (c *A2AClient) SendTaskStreaming(payload map[string]interface{}) (chan *types.SendTaskStreamingResponse, error) {
+// ReplayTask streams the recorded events of a task; speed 1 keeps the original timing, 0 replays without delays.
+// The stream is canceled with ctx like that of SendTaskStreaming.
+func (c *A2AClient) ReplayTask(ctx context.Context, payload map[string]interface{}) (chan *types.SendTaskStreamingResponse, error) {
+	if err := c.checkStreaming(); err != nil {
+		return nil, err
+	}
+
 	request := &types.JSONRPCRequest{
 		JSONRPC: "2.0",
-		Method:  "send_task_streaming",
+		Method:  "tasks/replay",
 		Params:  payload,
 	}
 
-	client := &http.Client{
-		Timeout: 0, // No timeout for streaming
+	return c.trackCallStream(ctx, request.Method, requestTaskID(request.Params), func(ctx context.Context) (chan *types.SendTaskStreamingResponse, error) {
+		return c.sendStreamingRequest(ctx, request)
+	})
+}
+
+// ResubscribeToTask resumes the event stream of a task, e.g. after a dropped connection.
+// The resubscribe token of the task's previous stream is sent along so the request reaches
+// the replica streaming the task. When long polling, the "cursor" of the payload, e.g. from
+// an EventCursor, skips the events up to it; without one, the stream resumes after the last
+// event id received for the task. The stream is canceled with ctx like that of
+// SendTaskStreaming.
+func (c *A2AClient) ResubscribeToTask(ctx context.Context, payload map[string]interface{}) (chan *types.SendTaskStreamingResponse, error) {
+	if err := c.checkStreaming(); err != nil {
+		return nil, err
 	}
 
-	reqBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to marshal request: %v", err),
+	request := c.newRequest(types.ResubscribeMethod, payload)
+	taskID := requestTaskID(request.Params)
+
+	return c.trackCallStream(ctx, request.Method, taskID, func(ctx context.Context) (chan *types.SendTaskStreamingResponse, error) {
+		if taskID != "" && c.LongPolling() {
+			cursor := payloadCursor(payload)
+			if lastEventID, ok := c.lastEventIDs.Load(taskID); ok && cursor == 0 {
+				cursor, _ = strconv.Atoi(lastEventID.(string))
+			}
+			return c.pollEvents(ctx, request.ID, taskID, cursor, nil, func() {}), nil
 		}
+		responseChan, err := c.sendStreamingRequest(ctx, request)
+		if err != nil {
+			c.streamRequestFailed(err)
+			return nil, err
+		}
+		return c.trackStream(ctx, responseChan), nil
+	})
+}
+
+// requestTaskID returns the task id of request params built from a payload
+func requestTaskID(params interface{}) string {
+	p, ok := params.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if id, ok := p["id"].(string); ok {
+		return id
+	}
+	if message, ok := p["message"].(map[string]interface{}); ok {
+		id, _ := message["taskId"].(string)
+		return id
 	}
+	return ""
+}
 
-	req, err := http.NewRequest("POST", c.url, nil)
+// sendStreamingRequest sends a JSON-RPC request and streams the SSE responses.
+// The request is aborted and the channel closed once ctx is canceled.
+func (c *A2AClient) sendStreamingRequest(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error) {
+	extensions, err := c.prepareExtensions(ctx, request)
 	if err != nil {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: 400,
-			Message:    fmt.Sprintf("failed to create request: %v", err),
-		}
+		return nil, err
 	}
-
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Content-Type", "application/json")
-	req.Body = io.NopCloser(bytes.NewReader(reqBody))
-
-	resp, err := client.Do(req)
+	reqBody, err := json.Marshal(request)
 	if err != nil {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: 400,
-			Message:    fmt.Sprintf("failed to send request: %v", err),
+		return nil, &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to marshal request: %v", err),
 		}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("unexpected status code: %d", resp.StatusCode),
+	progress := c.newProgressTracker(request.Method, int64(len(reqBody)))
+	taskID := requestTaskID(request.Params)
+
+	consumerCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	resp, err := c.do(ctx, c.streamClient, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		if !c.noStreamCompression {
+			req.Header.Set("Accept-Encoding", streamAcceptEncoding)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if extensions != "" {
+			req.Header.Set(types.ExtensionsHeader, extensions)
 		}
+		if token, ok := c.resubscribeTokens.Load(taskID); ok {
+			req.Header.Set(types.ResubscribeTokenHeader, token.(string))
+		}
+		if lastEventID, ok := c.lastEventIDs.Load(taskID); ok && isResubscribe(request.Method) {
+			req.Header.Set("Last-Event-ID", lastEventID.(string))
+		}
+		req.Body = io.NopCloser(progress.wrapRequest(bytes.NewReader(reqBody)))
+		req.ContentLength = int64(len(reqBody))
+		return req, nil
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		defer cancel()
+		defer resp.Body.Close()
+		return nil, streamRejected(resp)
+	}
+	if token := resp.Header.Get(types.ResubscribeTokenHeader); token != "" && taskID != "" {
+		c.resubscribeTokens.Store(taskID, token)
 	}
 
 	responseChan := make(chan *types.SendTaskStreamingResponse)
 
 	go func() {
 		defer close(responseChan)
+		defer cancel()
 		defer resp.Body.Close()
+		defer progress.report(true)
+
+		// send delivers a response unless the consumer canceled the stream
+		send := func(response *types.SendTaskStreamingResponse) bool {
+			select {
+			case responseChan <- response:
+				return true
+			case <-consumerCtx.Done():
+				return false
+			}
+		}
 
-		decoder := json.NewDecoder(resp.Body)
+		body, idleTimedOut := newIdleTimeoutReader(resp.Body, c.streamIdleTimeout, cancel)
+		body, err := decompressStream(resp.Header.Get("Content-Encoding"), body)
+		if err != nil {
+			send(&types.SendTaskStreamingResponse{Error: types.InternalError(err.Error()), Broken: true})
+			return
+		}
+		events := newSSEReader(progress.wrapResponse(body))
 		for {
-			var response types.SendTaskStreamingResponse
-			if err := decoder.Decode(&response); err != nil {
-				if err == io.EOF {
+			event, err := events.next()
+			if err != nil {
+				if err == io.EOF || consumerCtx.Err() != nil {
 					break
 				}
-				responseChan <- &types.SendTaskStreamingResponse{
-					Error: &types.JSONRPCError{
-						Code:    500,
-						Message: fmt.Sprintf("failed to decode response: %v", err),
-					},
+				message := fmt.Sprintf("failed to read stream: %v", err)
+				if idleTimedOut() {
+					message = fmt.Sprintf("stream idle for more than %s", c.streamIdleTimeout)
 				}
+				send(&types.SendTaskStreamingResponse{Error: types.InternalError(message), Broken: true})
+				break
+			}
+			if event.Type != "message" {
+				continue
+			}
+
+			response := types.SendTaskStreamingResponse{EventID: event.ID}
+			if err := json.Unmarshal(event.Data, &response); err != nil {
+				// The next event starts cleanly after an event that isn't JSON
+				decodeErr := &types.DecodeError{EventID: event.ID, Data: event.Data, Err: err}
+				response = types.SendTaskStreamingResponse{
+					EventID:     event.ID,
+					Error:       decodeErr.JSONRPCError(),
+					DecodeError: decodeErr,
+				}
+			}
+			if event.ID != "" && taskID != "" {
+				c.lastEventIDs.Store(taskID, event.ID)
+			}
+			if isFinalEvent(response.Result) {
+				c.forgetStream(taskID)
+			}
+			progress.eventProcessed()
+			if !send(&response) || (response.DecodeError != nil && c.stopOnDecodeError) {
 				break
 			}
-			responseChan <- &response
 		}
 	}()
 
 	return responseChan, nil
 }
 
-// sendRequest sends a JSON-RPC request to the A2A server
-func (c *A2AClient) sendRequest(request *types.JSONRPCRequest) ([]byte, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	reqBody, err := json.Marshal(request)
-	if err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to marshal request: %v", err),
-		}
-	}
-
-	resp, err := client.Post(c.url, "application/json", bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: 400,
-			Message:    fmt.Sprintf("failed to send request: %v", err),
-		}
-	}
-	defer resp.Body.Close()
+// forgetStream drops the resubscribe token and last event id kept for a task once its stream
+// delivered the final event, as there is nothing left to resume
+func (c *A2AClient) forgetStream(taskID string) {
+	c.resubscribeTokens.Delete(taskID)
+	c.lastEventIDs.Delete(taskID)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("unexpected status code: %d", resp.StatusCode),
+// sendRequest sends a JSON-RPC request to the A2A server
+func (c *A2AClient) sendRequest(ctx context.Context, request *types.JSONRPCRequest) ([]byte, error) {
+	var body []byte
+	err := c.sendRequestStream(ctx, request, func(r io.Reader) error {
+		var err error
+		body, err = io.ReadAll(r)
+		if err != nil {
+			return &types.A2AClientHTTPError{
+				StatusCode: 500,
+				Message:    fmt.Sprintf("failed to read response: %v", err),
+			}
 		}
-	}
-
-	body, err := io.ReadAll(resp.Body)
+		return nil
+	})
 	if err != nil {
-		return nil, &types.A2AClientHTTPError{
-			StatusCode: 500,
-			Message:    fmt.Sprintf("failed to read response: %v", err),
-		}
+		return nil, err
 	}
-
 	return body, nil
 }
 
-// GetTask retrieves a task from the A2A server
-func (c *A2AClient) GetTask(payload map[string]interface{}) (*types.GetTaskResponse, error) {
-	request := &types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "get_task",
-		Params:  payload,
-	}
-
-	response, err := c.sendRequest(request)
+// call sends a JSON-RPC request and decodes the response into result. A JSON-RPC error in
+// the response is returned as a *types.JSONRPCError.
+func (c *A2AClient) call(ctx context.Context, request *types.JSONRPCRequest, result interface{}) error {
+	response, err := c.sendRequest(ctx, request)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	var result types.GetTaskResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to parse response: %v", err),
-		}
+	var envelope struct {
+		Error *types.JSONRPCError `json:"error"`
 	}
-
-	return &result, nil
-}
-
-// CancelTask cancels a task on the A2A server
-func (c *A2AClient) CancelTask(payload map[string]interface{}) (*types.CancelTaskResponse, error) {
-	request := &types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "cancel_task",
-		Params:  payload,
+	if err := json.Unmarshal(response, &envelope); err == nil && envelope.Error != nil {
+		return envelope.Error
 	}
-
-	response, err := c.sendRequest(request)
-	if err != nil {
-		return nil, err
-	}
-
-	var result types.CancelTaskResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, &types.A2AClientJSONError{
+	if err := json.Unmarshal(response, result); err != nil {
+		return &types.A2AClientJSONError{
 			Message: fmt.Sprintf("failed to parse response: %v", err),
 		}
 	}
+	return nil
+}
 
-	return &result, nil
+// sendRequestStream sends a JSON-RPC request and passes the response body to consume
+// while it is still being received
+func (c *A2AClient) sendRequestStream(ctx context.Context, request *types.JSONRPCRequest, consume func(io.Reader) error) error {
+	return c.sendRequestStreamWith(ctx, c.httpClient, request, consume)
 }
 
-// SetTaskCallback sets a callback for a task
-func (c *A2AClient) SetTaskCallback(payload map[string]interface{}) (*types.SetTaskPushNotificationResponse, error) {
-	request := &types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "set_task_push_notification",
-		Params:  payload,
-	}
+// sendRequestStreamWith is sendRequestStream with the HTTP client to use
+func (c *A2AClient) sendRequestStreamWith(ctx context.Context, httpClient *http.Client, request *types.JSONRPCRequest, consume func(io.Reader) error) error {
+	ctx, done := c.calls.start(ctx, request.Method, requestTaskID(request.Params), false)
+	defer done()
 
-	response, err := c.sendRequest(request)
+	extensions, err := c.prepareExtensions(ctx, request)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	var result types.SetTaskPushNotificationResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to parse response: %v", err),
+	if request.ID == nil {
+		// Retries resend the same body, so agents deduplicating requests recognize them
+		request.ID = utils.NewID()
+	}
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to marshal request: %v", err),
 		}
 	}
-
-	return &result, nil
-}
-
-// GetTaskCallback retrieves a task's callback configuration
-func (c *A2AClient) GetTaskCallback(payload map[string]interface{}) (*types.GetTaskPushNotificationResponse, error) {
-	request := &types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "get_task_push_notification",
-		Params:  payload,
+	reqBody, contentType, err := c.encodeRequestBody(reqBody)
+	if err != nil {
+		return err
 	}
 
-	response, err := c.sendRequest(request)
+	progress := c.newProgressTracker(request.Method, int64(len(reqBody)))
+	defer progress.report(true)
+
+	resp, err := c.do(ctx, httpClient, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", c.acceptHeader())
+		if extensions != "" {
+			req.Header.Set(types.ExtensionsHeader, extensions)
+		}
+		req.Body = io.NopCloser(progress.wrapRequest(bytes.NewReader(reqBody)))
+		req.ContentLength = int64(len(reqBody))
+		return req, nil
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer resp.Body.Close()
 
-	var result types.GetTaskPushNotificationResponse
-	if err := json.Unmarshal(response, &result); err != nil {
-		return nil, &types.A2AClientJSONError{
-			Message: fmt.Sprintf("failed to parse response: %v", err),
-		}
+	progress.setResponseSize(resp.ContentLength)
+	body, err := decodeResponseBody(resp, progress.wrapResponse(resp.Body))
+	if err != nil {
+		return err
 	}
+	return consume(body)
+}
 
-	return &result, nil
-} 
\ No newline at end of file
+// GetTaskIfModified retrieves a task only if its version advanced past sinceVersion.
+// The response has NotModified set and no result when the task is unchanged.
+func (c *A2AClient) GetTaskIfModified(ctx context.Context, taskID string, sinceVersion uint64) (*types.GetTaskResponse, error) {
+	return c.GetTask(ctx, map[string]interface{}{
+		"id":           taskID,
+		"sinceVersion": sinceVersion,
+	})
+}
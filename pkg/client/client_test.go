@@ -0,0 +1,134 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestIDsSurviveRetries(t *testing.T) {
+	var ids []interface{}
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request types.JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		ids = append(ids, request.ID)
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  map[string]interface{}{"id": "t1", "status": map[string]interface{}{"state": "working"}},
+		})
+	}))
+	defer agent.Close()
+
+	c, err := NewA2AClient(nil, agent.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("NewA2AClient: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetTask(context.Background(), map[string]interface{}{"id": "t1"}); err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+	}
+
+	if len(ids) != 3 {
+		t.Fatalf("agent received %d requests, want 3", len(ids))
+	}
+	if ids[0] == nil || ids[0] != ids[1] {
+		t.Errorf("retry was sent with id %v, want the id %v of the first attempt", ids[1], ids[0])
+	}
+	if ids[2] == ids[0] {
+		t.Errorf("second call reused the id %v", ids[0])
+	}
+}
+
+func TestStreamStateIsDroppedAfterFinalEvent(t *testing.T) {
+	for _, final := range []bool{false, true} {
+		t.Run(fmt.Sprintf("final %v", final), func(t *testing.T) {
+			agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set(types.ResubscribeTokenHeader, "token")
+				fmt.Fprintf(w, "id: 1\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"id\":\"t1\",\"status\":{\"state\":\"working\"},\"final\":false}}\n\n")
+				if final {
+					fmt.Fprintf(w, "id: 2\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"id\":\"t1\",\"status\":{\"state\":\"completed\"},\"final\":true}}\n\n")
+				}
+			}))
+			defer agent.Close()
+
+			c, err := NewA2AClient(nil, agent.URL)
+			if err != nil {
+				t.Fatalf("NewA2AClient: %v", err)
+			}
+			responses, err := c.SendTaskStreaming(context.Background(), map[string]interface{}{
+				"id":      "t1",
+				"message": map[string]interface{}{"role": "user", "parts": []interface{}{}},
+			})
+			if err != nil {
+				t.Fatalf("SendTaskStreaming: %v", err)
+			}
+			for range responses {
+			}
+
+			_, hasToken := c.resubscribeTokens.Load("t1")
+			_, hasEventID := c.lastEventIDs.Load("t1")
+			if hasToken == final || hasEventID == final {
+				t.Fatalf("token kept %v, last event id kept %v; want both kept only until the final event", hasToken, hasEventID)
+			}
+		})
+	}
+}
+
+func TestBrokenStreamsCountTowardsLongPolling(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		encoding    string
+		body        string
+		wantPolling bool
+	}{
+		{"error from the agent", "", "data: {\"jsonrpc\":\"2.0\",\"id\":1,\"error\":{\"code\":-32603,\"message\":\"boom\"}}\n\n", false},
+		{"unreadable stream", "gzip", "not gzip", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				if test.encoding != "" {
+					w.Header().Set("Content-Encoding", test.encoding)
+				}
+				fmt.Fprint(w, test.body)
+			}))
+			defer agent.Close()
+
+			c, err := NewA2AClient(nil, agent.URL, WithLongPollFallback(1))
+			if err != nil {
+				t.Fatalf("NewA2AClient: %v", err)
+			}
+			responses, err := c.SendTaskStreaming(context.Background(), map[string]interface{}{
+				"id":      "t1",
+				"message": map[string]interface{}{"role": "user", "parts": []interface{}{}},
+			})
+			if err != nil {
+				t.Fatalf("SendTaskStreaming: %v", err)
+			}
+			var rpcErr *types.JSONRPCError
+			for response := range responses {
+				rpcErr = response.Error
+			}
+			if rpcErr == nil || rpcErr.Code != types.InternalErrorCode {
+				t.Fatalf("stream error = %+v, want code %d", rpcErr, types.InternalErrorCode)
+			}
+			if c.LongPolling() != test.wantPolling {
+				t.Fatalf("LongPolling = %v, want %v", c.LongPolling(), test.wantPolling)
+			}
+		})
+	}
+}
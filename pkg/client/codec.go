@@ -0,0 +1,60 @@
+package client
+
+import (
+	"a2a-go/pkg/codec"
+	"a2a-go/pkg/types"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithCodec sends non-streaming requests encoded with c and asks the server to respond in
+// it. Servers that do not support the codec answer in JSON, which is always understood.
+// Streams stay JSON server-sent events.
+func WithCodec(wireCodec codec.Codec) ClientOption {
+	return func(c *A2AClient) {
+		c.codec = wireCodec
+	}
+}
+
+// encodeRequestBody re-encodes a JSON request body with the client's codec
+func (c *A2AClient) encodeRequestBody(body []byte) ([]byte, string, error) {
+	if codec.IsJSON(c.codec) {
+		return body, codec.JSONContentType, nil
+	}
+	encoded, err := codec.FromJSON(c.codec, body)
+	if err != nil {
+		return nil, "", &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to encode request: %v", err),
+		}
+	}
+	return encoded, c.codec.ContentType(), nil
+}
+
+// acceptHeader lists the client's codec first, falling back to JSON
+func (c *A2AClient) acceptHeader() string {
+	if codec.IsJSON(c.codec) {
+		return codec.JSONContentType
+	}
+	return c.codec.ContentType() + ", " + codec.JSONContentType + ";q=0.5"
+}
+
+// decodeResponseBody returns the response body as JSON, transcoding other registered codecs
+func decodeResponseBody(resp *http.Response, body io.Reader) (io.Reader, error) {
+	responseCodec, ok := codec.Lookup(resp.Header.Get("Content-Type"))
+	if !ok || codec.IsJSON(responseCodec) {
+		return body, nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	data, err = codec.ToJSON(responseCodec, data)
+	if err != nil {
+		return nil, &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to decode response: %v", err),
+		}
+	}
+	return bytes.NewReader(data), nil
+}
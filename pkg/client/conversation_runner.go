@@ -0,0 +1,163 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoInput is returned by ConversationRunner.Run when the InputProvider ends the conversation
+var ErrNoInput = errors.New("input provider returned no message")
+
+// InputProvider produces the next user message of a conversation.
+// task is nil for the first message and otherwise holds the task awaiting input.
+// Returning a nil message ends the conversation.
+type InputProvider func(ctx context.Context, task *types.Task) (*types.Message, error)
+
+// ConversationRunnerOption configures a ConversationRunner
+type ConversationRunnerOption func(*ConversationRunner)
+
+// WithStreaming makes the runner use SendTaskStreaming and report events to onEvent
+func WithStreaming(onEvent func(*types.SendTaskStreamingResponse)) ConversationRunnerOption {
+	return func(r *ConversationRunner) {
+		r.streaming = true
+		r.onEvent = onEvent
+	}
+}
+
+// WithAcceptedOutputModes sets the output modes sent with every message
+func WithAcceptedOutputModes(modes ...string) ConversationRunnerOption {
+	return func(r *ConversationRunner) {
+		r.acceptedOutputModes = modes
+	}
+}
+
+// WithPushNotificationConfig registers a push notification config with every message
+func WithPushNotificationConfig(config *types.PushNotificationConfig) ConversationRunnerOption {
+	return func(r *ConversationRunner) {
+		r.pushNotification = config
+	}
+}
+
+// WithTaskResult is called with the task after every turn
+func WithTaskResult(onTask func(*types.Task)) ConversationRunnerOption {
+	return func(r *ConversationRunner) {
+		r.onTask = onTask
+	}
+}
+
+// ConversationRunner drives a task through input-required turns until it reaches another state
+type ConversationRunner struct {
+	client              *A2AClient
+	input               InputProvider
+	streaming           bool
+	onEvent             func(*types.SendTaskStreamingResponse)
+	onTask              func(*types.Task)
+	acceptedOutputModes []string
+	pushNotification    *types.PushNotificationConfig
+}
+
+// NewConversationRunner creates a ConversationRunner sending messages from input through client
+func NewConversationRunner(client *A2AClient, input InputProvider, opts ...ConversationRunnerOption) *ConversationRunner {
+	r := &ConversationRunner{
+		client: client,
+		input:  input,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run submits messages for taskID until the task leaves the input-required state.
+// Empty ids are generated with the default IDGenerator.
+// It returns the last task received, and ErrNoInput if the InputProvider ended the conversation.
+func (r *ConversationRunner) Run(ctx context.Context, taskID, sessionID string) (*types.Task, error) {
+	if taskID == "" {
+		taskID = utils.NewID()
+	}
+	if sessionID == "" {
+		sessionID = utils.NewID()
+	}
+
+	var task *types.Task
+	for {
+		if err := ctx.Err(); err != nil {
+			return task, err
+		}
+
+		message, err := r.input(ctx, task)
+		if err != nil {
+			return task, fmt.Errorf("failed to get input: %w", err)
+		}
+		if message == nil {
+			return task, ErrNoInput
+		}
+
+		task, err = r.sendTurn(ctx, &types.TaskSendParams{
+			ID:                  taskID,
+			SessionID:           sessionID,
+			Message:             *message,
+			AcceptedOutputModes: r.acceptedOutputModes,
+			PushNotification:    r.pushNotification,
+		})
+		if err != nil {
+			return task, err
+		}
+		if r.onTask != nil {
+			r.onTask(task)
+		}
+
+		if task == nil || task.Status.State != types.TaskInputNeeded {
+			return task, nil
+		}
+	}
+}
+
+// sendTurn sends one message and returns the resulting task
+func (r *ConversationRunner) sendTurn(ctx context.Context, params *types.TaskSendParams) (*types.Task, error) {
+	if !r.streaming {
+		response, err := r.client.SendTaskWithParams(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("error sending task: %w", err)
+		}
+		return response.Result, nil
+	}
+
+	responseChan, err := r.client.SendTaskStreamingWithParams(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("error sending streaming task: %w", err)
+	}
+	for response := range responseChan {
+		if r.onEvent != nil {
+			r.onEvent(response)
+		}
+	}
+
+	response, err := r.client.GetTaskWithParams(ctx, &types.TaskQueryParams{TaskIdParams: types.TaskIdParams{ID: params.ID}})
+	if err != nil {
+		return nil, fmt.Errorf("error getting task: %w", err)
+	}
+	return response.Result, nil
+}
+
+// toPayload converts typed params into the map payload accepted by A2AClient methods
+func toPayload(params interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to marshal params: %v", err),
+		}
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, &types.A2AClientJSONError{
+			Message: fmt.Sprintf("failed to convert params: %v", err),
+		}
+	}
+	return payload, nil
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DNSLookup is the subset of *net.Resolver used for agent discovery
+type DNSLookup interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DiscoveredAgent is the location of an agent found through DNS
+type DiscoveredAgent struct {
+	BaseURL  string
+	CardPath string // Relative to BaseURL, or an absolute card URL
+}
+
+// DiscoverAgent looks up the agent of a domain from DNS records:
+//
+//	_a2a._tcp.example.com. SRV 0 0 443 agent.example.com.
+//	_a2a._tcp.example.com. TXT "path=/.well-known/agent.json" "scheme=https"
+//
+// The SRV record supplies host and port. TXT key=value pairs optionally set the scheme,
+// the card path, or the full card url. Without an SRV record, a TXT url is required.
+// lookup may be nil to use net.DefaultResolver.
+func DiscoverAgent(ctx context.Context, lookup DNSLookup, domain string) (*DiscoveredAgent, error) {
+	if lookup == nil {
+		lookup = net.DefaultResolver
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	name := "_a2a._tcp." + domain
+
+	attrs := make(map[string]string)
+	if records, err := lookup.LookupTXT(ctx, name); err == nil {
+		for _, record := range records {
+			for _, field := range strings.Fields(record) {
+				if key, value, ok := strings.Cut(field, "="); ok {
+					attrs[strings.ToLower(key)] = value
+				}
+			}
+		}
+	}
+
+	if cardURL := attrs["url"]; cardURL != "" {
+		u, err := url.Parse(cardURL)
+		if err != nil || !isAbsoluteURL(cardURL) {
+			return nil, fmt.Errorf("invalid agent card url in TXT record of %s: %q", name, cardURL)
+		}
+		return &DiscoveredAgent{
+			BaseURL:  (&url.URL{Scheme: u.Scheme, Host: u.Host}).String(),
+			CardPath: cardURL,
+		}, nil
+	}
+
+	_, srvs, err := lookup.LookupSRV(ctx, "a2a", "tcp", domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", name, err)
+	}
+	if len(srvs) == 0 {
+		return nil, errors.New("no SRV records for " + name)
+	}
+	// LookupSRV returns records sorted by priority and randomized by weight
+	srv := srvs[0]
+	host := strings.TrimSuffix(srv.Target, ".")
+
+	scheme := attrs["scheme"]
+	if scheme == "" {
+		scheme = "https"
+		if srv.Port == 80 {
+			scheme = "http"
+		}
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q in TXT record of %s", scheme, name)
+	}
+
+	hostPort := host
+	if (scheme == "https" && srv.Port != 443) || (scheme == "http" && srv.Port != 80) {
+		hostPort = net.JoinHostPort(host, strconv.Itoa(int(srv.Port)))
+	}
+
+	cardPath := attrs["path"]
+	if cardPath == "" {
+		cardPath = "/.well-known/agent.json"
+	}
+	return &DiscoveredAgent{
+		BaseURL:  (&url.URL{Scheme: scheme, Host: hostPort}).String(),
+		CardPath: cardPath,
+	}, nil
+}
+
+// NewA2ACardResolverFromDNS creates a card resolver for the agent a domain advertises in DNS
+func NewA2ACardResolverFromDNS(ctx context.Context, domain string, opts ...CardResolverOption) (*A2ACardResolver, error) {
+	agent, err := DiscoverAgent(ctx, nil, domain)
+	if err != nil {
+		return nil, err
+	}
+	return NewA2ACardResolver(agent.BaseURL, agent.CardPath, opts...), nil
+}
@@ -0,0 +1,239 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CursorStore persists the event cursors of tasks, so deduplication survives client restarts
+type CursorStore interface {
+	// Load returns the cursor of a task, 0 if none was saved
+	Load(taskID string) (int, error)
+	Save(taskID string, cursor int) error
+}
+
+// MemoryCursorStore keeps cursors in memory
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+// NewMemoryCursorStore creates an empty MemoryCursorStore
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]int)}
+}
+
+func (s *MemoryCursorStore) Load(taskID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[taskID], nil
+}
+
+func (s *MemoryCursorStore) Save(taskID string, cursor int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[taskID] = cursor
+	return nil
+}
+
+// FileCursorStore keeps cursors in a JSON file, rewritten on every save
+type FileCursorStore struct {
+	mu      sync.Mutex
+	path    string
+	cursors map[string]int
+}
+
+// NewFileCursorStore creates a FileCursorStore on path, loading the cursors already saved there
+func NewFileCursorStore(path string) (*FileCursorStore, error) {
+	s := &FileCursorStore{path: path, cursors: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.cursors); err != nil {
+		return nil, fmt.Errorf("invalid cursor file %s: %v", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileCursorStore) Load(taskID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursors[taskID], nil
+}
+
+func (s *FileCursorStore) Save(taskID string, cursor int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[taskID] = cursor
+	data, err := json.Marshal(s.cursors)
+	if err != nil {
+		return err
+	}
+	// Write and rename so a crash never leaves a truncated file
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// EventCursor tracks the last event delivered for each task, so events arriving again
+// through a reconnect, a resubscribe or a push notification are delivered once. Events the
+// agent doesn't number and error events always pass.
+type EventCursor struct {
+	mu    sync.Mutex
+	store CursorStore
+}
+
+// NewEventCursor creates an EventCursor saving to store, in memory if store is nil
+func NewEventCursor(store CursorStore) *EventCursor {
+	if store == nil {
+		store = NewMemoryCursorStore()
+	}
+	return &EventCursor{store: store}
+}
+
+// Get returns the cursor of a task, e.g. for the "cursor" of a resubscribe payload
+func (c *EventCursor) Get(taskID string) int {
+	cursor, _ := c.store.Load(taskID)
+	return cursor
+}
+
+// Advance reports whether event is new and, if so, moves its task's cursor to it
+func (c *EventCursor) Advance(event types.TaskEvent) bool {
+	seq := event.Seq()
+	if seq == 0 || event.IsError() {
+		return true
+	}
+	taskID := event.TaskID()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cursor, err := c.store.Load(taskID)
+	if err != nil {
+		return true
+	}
+	if seq <= cursor {
+		return false
+	}
+	// A failed save only risks a duplicate after a restart
+	c.store.Save(taskID, seq)
+	return true
+}
+
+// Filter forwards the new events of events until it is closed or ctx is canceled
+func (c *EventCursor) Filter(ctx context.Context, events <-chan types.TaskEvent) <-chan types.TaskEvent {
+	return MergeTaskEvents(ctx, c, events)
+}
+
+// MergeTaskEvents merges event sources, such as a stream, its resubscribes and
+// NotificationEvents, into one stream without duplicates. The stream closes once all
+// sources are closed or ctx is canceled.
+func MergeTaskEvents(ctx context.Context, cursor *EventCursor, sources ...<-chan types.TaskEvent) <-chan types.TaskEvent {
+	merged := make(chan types.TaskEvent)
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for event := range source {
+				if !cursor.Advance(event) {
+					continue
+				}
+				select {
+				case merged <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+	return merged
+}
+
+// NotificationEvent converts a push notification payload into a status event carrying its
+// event cursor, so notifications can be merged with streamed events
+func NotificationEvent(payload map[string]interface{}) (types.TaskEvent, error) {
+	body := payload
+	if result, ok := payload["result"].(map[string]interface{}); ok {
+		body = result
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return types.TaskEvent{}, err
+	}
+	var task types.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return types.TaskEvent{}, fmt.Errorf("invalid notification: %v", err)
+	}
+	if task.ID == "" {
+		return types.TaskEvent{}, errors.New("notification has no task id")
+	}
+	seq, _ := utils.NotificationEventSeq(payload)
+	state := task.Status.State
+	return types.TaskEvent{
+		Status: &types.TaskStatusUpdateEvent{
+			ID:       task.ID,
+			Status:   task.Status,
+			Final:    state == types.TaskCompleted || state == types.TaskCanceled || state == types.TaskFailed,
+			Metadata: task.Metadata,
+			Seq:      seq,
+		},
+	}, nil
+}
+
+// NotificationEvents converts push notification payloads into events for MergeTaskEvents,
+// skipping payloads that aren't task notifications
+func NotificationEvents(ctx context.Context, payloads <-chan map[string]interface{}) <-chan types.TaskEvent {
+	events := make(chan types.TaskEvent)
+	go func() {
+		defer close(events)
+		for payload := range payloads {
+			event, err := NotificationEvent(payload)
+			if err != nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// payloadCursor returns the "cursor" of a request payload, 0 if it has none
+func payloadCursor(payload map[string]interface{}) int {
+	switch v := payload["cursor"].(type) {
+	case int:
+		return max(v, 0)
+	case float64:
+		return max(int(v), 0)
+	}
+	return 0
+}
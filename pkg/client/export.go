@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"a2a-go/pkg/utils"
+)
+
+// ExportTask fetches a task with its full history and writes it to w in format
+func (c *A2AClient) ExportTask(ctx context.Context, taskID string, format utils.ExportFormat, w io.Writer) error {
+	response, err := c.GetTask(ctx, map[string]interface{}{
+		"id":            taskID,
+		"historyLength": math.MaxInt32,
+	})
+	if err != nil {
+		return err
+	}
+	if response.Result == nil {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+	return utils.ExportTask(w, response.Result, format)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrExtensionRequired is returned when the agent card declares a required extension the client doesn't activate
+var ErrExtensionRequired = errors.New("agent requires an extension that is not activated")
+
+// ClientExtension is a protocol extension activated by the client
+type ClientExtension struct {
+	URI string
+
+	// Prepare runs for every request activating the extension, before it is sent, e.g. to
+	// add the extension's params. Optional.
+	Prepare func(ctx context.Context, request *types.JSONRPCRequest) error
+}
+
+// WithExtension activates an extension on every request
+func WithExtension(extension ClientExtension) ClientOption {
+	return func(c *A2AClient) {
+		if extension.URI == "" {
+			c.optionErr = errors.New("extension URI is required")
+			return
+		}
+		c.extensions = append(c.extensions, extension)
+	}
+}
+
+// WithExtensions activates the extensions with the given URIs on every request
+func WithExtensions(uris ...string) ClientOption {
+	return func(c *A2AClient) {
+		for _, uri := range uris {
+			WithExtension(ClientExtension{URI: uri})(c)
+		}
+	}
+}
+
+type extensionsKey struct{}
+
+// ContextWithExtensions returns a copy of ctx activating more extensions for the calls made with it
+func ContextWithExtensions(ctx context.Context, uris ...string) context.Context {
+	active, _ := ctx.Value(extensionsKey{}).([]string)
+	return context.WithValue(ctx, extensionsKey{}, append(slices.Clip(active), uris...))
+}
+
+// prepareExtensions runs the hooks of the extensions a request activates and returns the
+// value of its types.ExtensionsHeader, "" for none. It fails if the known agent card
+// declares a required extension that isn't among them.
+func (c *A2AClient) prepareExtensions(ctx context.Context, request *types.JSONRPCRequest) (string, error) {
+	var uris []string
+	for _, extension := range c.extensions {
+		if extension.Prepare != nil {
+			if err := extension.Prepare(ctx, request); err != nil {
+				return "", fmt.Errorf("extension %s: %w", extension.URI, err)
+			}
+		}
+		uris = append(uris, extension.URI)
+	}
+	if active, ok := ctx.Value(extensionsKey{}).([]string); ok {
+		uris = append(uris, active...)
+	}
+	uris = types.ParseExtensionsHeader(uris)
+
+	if card := c.Card(); card != nil {
+		for _, declared := range card.Capabilities.Extensions {
+			if declared.Required && !slices.Contains(uris, declared.URI) {
+				return "", fmt.Errorf("%w: %s", ErrExtensionRequired, declared.URI)
+			}
+		}
+	}
+	return types.FormatExtensionsHeader(uris), nil
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"io"
+)
+
+// WithFileResolvers sets the registry OpenFile resolves file part URIs with. By default
+// http, https and data URIs are resolved, with http requests sent like the client's own.
+func WithFileResolvers(resolvers *utils.FileResolvers) ClientOption {
+	return func(c *A2AClient) {
+		c.fileResolvers = resolvers
+	}
+}
+
+// OpenFile returns the content of a file part received from the agent, whether inline or by URI
+func (c *A2AClient) OpenFile(ctx context.Context, file *types.FileContent) (io.ReadCloser, error) {
+	return c.fileResolvers.Open(ctx, file)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InFlightCall is a call of an A2AClient still waiting for its response, or a stream still open
+type InFlightCall struct {
+	Method  string
+	TaskID  string // Empty for calls not about a single task
+	Stream  bool
+	Started time.Time
+	Elapsed time.Duration
+}
+
+// callRegistry tracks the in-flight calls of a client so they can be listed and canceled
+type callRegistry struct {
+	lock    sync.Mutex
+	calls   map[*trackedCall]struct{}
+	changed chan struct{} // Closed when a call ends, for Drain
+}
+
+type trackedCall struct {
+	method  string
+	taskID  string
+	stream  bool
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// trackedKey marks contexts of tracked calls, so the requests a call makes internally,
+// e.g. the polls of a long polled stream, are not listed separately
+type trackedKey struct{}
+
+// start registers a call, returning the context to run it with and the func ending it
+func (r *callRegistry) start(ctx context.Context, method, taskID string, stream bool) (context.Context, func()) {
+	if ctx.Value(trackedKey{}) != nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, trackedKey{}, true))
+	call := &trackedCall{method: method, taskID: taskID, stream: stream, started: time.Now(), cancel: cancel}
+
+	r.lock.Lock()
+	if r.calls == nil {
+		r.calls = make(map[*trackedCall]struct{})
+	}
+	r.calls[call] = struct{}{}
+	r.lock.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			delete(r.calls, call)
+			if r.changed != nil {
+				close(r.changed)
+				r.changed = nil
+			}
+		})
+	}
+}
+
+// trackCallStream registers a stream opened by open, ending the registration once the
+// returned channel is closed
+func (c *A2AClient) trackCallStream(ctx context.Context, method, taskID string, open func(context.Context) (chan *types.SendTaskStreamingResponse, error)) (chan *types.SendTaskStreamingResponse, error) {
+	ctx, done := c.calls.start(ctx, method, taskID, true)
+	responses, err := open(ctx)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	tracked := make(chan *types.SendTaskStreamingResponse)
+	go func() {
+		defer done()
+		defer close(tracked)
+		for response := range responses {
+			select {
+			case tracked <- response:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return tracked, nil
+}
+
+// InFlight lists the calls waiting for a response and the open streams of the client, oldest first
+func (c *A2AClient) InFlight() []InFlightCall {
+	now := time.Now()
+	c.calls.lock.Lock()
+	calls := make([]InFlightCall, 0, len(c.calls.calls))
+	for call := range c.calls.calls {
+		calls = append(calls, InFlightCall{
+			Method:  call.method,
+			TaskID:  call.taskID,
+			Stream:  call.stream,
+			Started: call.started,
+			Elapsed: now.Sub(call.started),
+		})
+	}
+	c.calls.lock.Unlock()
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].Started.Before(calls[j].Started)
+	})
+	return calls
+}
+
+// CancelAll cancels every in-flight call and closes every open stream. Calls started
+// afterwards are not affected.
+func (c *A2AClient) CancelAll() {
+	c.calls.lock.Lock()
+	defer c.calls.lock.Unlock()
+	for call := range c.calls.calls {
+		call.cancel()
+	}
+}
+
+// Drain waits until no calls are in flight and all streams are closed, or ctx is done.
+// Daemons shutting down can Drain with a deadline and CancelAll what is left.
+func (c *A2AClient) Drain(ctx context.Context) error {
+	for {
+		c.calls.lock.Lock()
+		if len(c.calls.calls) == 0 {
+			c.calls.lock.Unlock()
+			return nil
+		}
+		if c.calls.changed == nil {
+			c.calls.changed = make(chan struct{})
+		}
+		changed := c.calls.changed
+		c.calls.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultLongPollFallbackAfter is how many streams in a row must fail before the client
+	// switches to long polling
+	DefaultLongPollFallbackAfter = 3
+	// longPollWait is how many seconds each tasks/events request blocks on the agent
+	longPollWait = 25
+	// longPollStartTimeout bounds how long polls wait for a sent task to appear on the agent
+	longPollStartTimeout = 30 * time.Second
+	// maxPollFailures is how many polls in a row may fail before the stream ends with an error
+	maxPollFailures = 3
+)
+
+// longPoll tracks whether streaming calls should use long polling. Once the fallback
+// threshold is reached the client keeps polling, since the intermediaries breaking streams
+// rarely go away.
+type longPoll struct {
+	after    int32 // Failed streams in a row that switch to polling, 0 to never switch
+	failures atomic.Int32
+	forced   atomic.Bool
+}
+
+// WithLongPollFallback switches streaming calls to long polling with tasks/events once
+// failures streams in a row failed, e.g. because a proxy buffers or cuts SSE responses.
+// DefaultLongPollFallbackAfter applies by default; 0 disables the fallback.
+func WithLongPollFallback(failures int) ClientOption {
+	return func(c *A2AClient) {
+		c.longPoll.after = int32(max(failures, 0))
+	}
+}
+
+// WithLongPolling makes streaming calls long poll from the start, for networks known to break SSE
+func WithLongPolling() ClientOption {
+	return func(c *A2AClient) {
+		c.longPoll.forced.Store(true)
+	}
+}
+
+// LongPolling reports whether streaming calls currently use long polling
+func (c *A2AClient) LongPolling() bool {
+	if c.longPoll.forced.Load() {
+		return true
+	}
+	return c.longPoll.after > 0 && c.longPoll.failures.Load() >= c.longPoll.after
+}
+
+// streamFailed records a failed stream
+func (c *A2AClient) streamFailed() {
+	if c.longPoll.failures.Add(1) == c.longPoll.after && c.longPoll.after > 0 {
+		c.longPoll.forced.Store(true)
+	}
+}
+
+// streamRequestFailed records a stream that could not be opened, unless the agent rejected
+// the request with a JSON-RPC error
+func (c *A2AClient) streamRequestFailed(err error) {
+	if _, ok := types.AsJSONRPCError(err); !ok {
+		c.streamFailed()
+	}
+}
+
+// trackStream forwards responses and records whether the stream failed: it did if it ended
+// without a final event or error from the agent, unless the consumer canceled ctx
+func (c *A2AClient) trackStream(ctx context.Context, responseChan chan *types.SendTaskStreamingResponse) chan *types.SendTaskStreamingResponse {
+	tracked := make(chan *types.SendTaskStreamingResponse)
+	go func() {
+		defer close(tracked)
+		completed := false
+		for response := range responseChan {
+			switch {
+			case response.Error != nil:
+				completed = !response.Broken && response.DecodeError == nil
+			case isFinalEvent(response.Result):
+				completed = true
+			}
+			select {
+			case tracked <- response:
+			case <-ctx.Done():
+			}
+		}
+		switch {
+		case completed:
+			c.longPoll.failures.Store(0)
+		case ctx.Err() == nil:
+			c.streamFailed()
+		}
+	}()
+	return tracked
+}
+
+// isFinalEvent reports whether a streamed result is a final status event
+func isFinalEvent(result interface{}) bool {
+	switch event := result.(type) {
+	case map[string]interface{}:
+		final, _ := event["final"].(bool)
+		return final
+	case *types.TaskStatusUpdateEvent:
+		return event.Final
+	}
+	return false
+}
+
+// PollTaskEvents fetches the events of a task after a cursor with tasks/events, blocking on
+// the agent for up to the "wait" seconds of the payload while there are none
+func (c *A2AClient) PollTaskEvents(ctx context.Context, payload map[string]interface{}) (*types.PollTaskEventsResponse, error) {
+	request := c.newRequest(types.PollTaskEventsMethod, payload)
+
+	var result struct {
+		types.PollTaskEventsResponse
+		Error *types.JSONRPCError `json:"error"`
+	}
+	err := c.sendRequestStreamWith(ctx, c.streamClient, request, func(r io.Reader) error {
+		if err := json.NewDecoder(r).Decode(&result); err != nil {
+			return &types.A2AClientJSONError{
+				Message: fmt.Sprintf("failed to parse response: %v", err),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.Result == nil {
+		return nil, &types.A2AClientJSONError{Message: "tasks/events response has no result"}
+	}
+	return &result.PollTaskEventsResponse, nil
+}
+
+// sendTaskLongPoll sends a streaming task request whose response is ignored, keeping the
+// task running on the agent, and delivers the task's events by polling instead
+func (c *A2AClient) sendTaskLongPoll(ctx context.Context, request *types.JSONRPCRequest, taskID string) chan *types.SendTaskStreamingResponse {
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	streamErr := make(chan error, 1)
+	go func() {
+		responses, err := c.sendStreamingRequest(streamCtx, request)
+		if err != nil {
+			streamErr <- err
+			return
+		}
+		for range responses {
+			// Events are delivered by polling; proxies may hold these back until the end
+		}
+	}()
+	return c.pollEvents(ctx, request.ID, taskID, 0, streamErr, cancelStream)
+}
+
+// pollEvents long polls the events of a task after cursor into a stream until its final
+// event. Polls for a task that doesn't exist yet are retried until the request sending it fails.
+func (c *A2AClient) pollEvents(ctx context.Context, requestID interface{}, taskID string, cursor int, sendErr <-chan error, done func()) chan *types.SendTaskStreamingResponse {
+	responseChan := make(chan *types.SendTaskStreamingResponse)
+	go func() {
+		defer close(responseChan)
+		defer done()
+
+		fail := func(err error) {
+			select {
+			case responseChan <- &types.SendTaskStreamingResponse{
+				ID:     requestID,
+				Error:  types.InternalError(err.Error()),
+				Broken: true,
+			}:
+			case <-ctx.Done():
+			}
+		}
+
+		failures := 0
+		started := time.Now()
+		for ctx.Err() == nil {
+			select {
+			case err := <-sendErr:
+				fail(err)
+				return
+			default:
+			}
+
+			response, err := c.PollTaskEvents(ctx, map[string]interface{}{
+				"id":     taskID,
+				"cursor": cursor,
+				"wait":   longPollWait,
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if rpcErr, ok := types.AsJSONRPCError(err); ok && rpcErr.Code == types.TaskNotFoundErrorCode && cursor == 0 && time.Since(started) < longPollStartTimeout {
+					// The task isn't created yet
+					if !sleepContext(ctx, 200*time.Millisecond) {
+						return
+					}
+					continue
+				}
+				if failures++; failures >= maxPollFailures {
+					fail(fmt.Errorf("polling task events failed: %w", err))
+					return
+				}
+				if !sleepContext(ctx, time.Duration(failures)*time.Second) {
+					return
+				}
+				continue
+			}
+			failures = 0
+
+			events := response.Result
+			for _, record := range events.Events {
+				select {
+				case responseChan <- &types.SendTaskStreamingResponse{ID: requestID, Result: record.Event, EventID: strconv.Itoa(record.Seq)}:
+				case <-ctx.Done():
+					return
+				}
+				c.lastEventIDs.Store(taskID, strconv.Itoa(record.Seq))
+			}
+			cursor = events.Cursor
+			if events.Final {
+				c.forgetStream(taskID)
+				return
+			}
+		}
+	}()
+	return responseChan
+}
+
+// sleepContext waits for d and reports whether ctx is still live
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
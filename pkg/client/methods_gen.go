@@ -0,0 +1,341 @@
+// Code generated by methodgen from internal/methodgen/methods.go. DO NOT EDIT.
+
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+)
+
+// SendTask sends a task to the A2A server
+func (c *A2AClient) SendTask(ctx context.Context, payload map[string]interface{}) (*types.SendTaskResponse, error) {
+	if err := c.checkPayloadPushNotification(payload); err != nil {
+		return nil, err
+	}
+
+	var result types.SendTaskResponse
+	if err := c.call(ctx, c.newRequest("send_task", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+
+	return &result, nil
+}
+
+// CancelTask cancels a task on the A2A server
+func (c *A2AClient) CancelTask(ctx context.Context, payload map[string]interface{}) (*types.CancelTaskResponse, error) {
+	var result types.CancelTaskResponse
+	if err := c.call(ctx, c.newRequest("cancel_task", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+
+	return &result, nil
+}
+
+// SetTaskCallback sets a callback for a task
+func (c *A2AClient) SetTaskCallback(ctx context.Context, payload map[string]interface{}) (*types.SetTaskPushNotificationResponse, error) {
+	if err := c.checkPushNotifications(); err != nil {
+		return nil, err
+	}
+
+	var result types.SetTaskPushNotificationResponse
+	if err := c.call(ctx, c.newRequest("set_task_push_notification", payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetTaskCallback retrieves a task's callback configuration
+func (c *A2AClient) GetTaskCallback(ctx context.Context, payload map[string]interface{}) (*types.GetTaskPushNotificationResponse, error) {
+	if err := c.checkPushNotifications(); err != nil {
+		return nil, err
+	}
+
+	var result types.GetTaskPushNotificationResponse
+	if err := c.call(ctx, c.newRequest("get_task_push_notification", payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PauseTask suspends a working task on the A2A server
+func (c *A2AClient) PauseTask(ctx context.Context, payload map[string]interface{}) (*types.PauseTaskResponse, error) {
+	var result types.PauseTaskResponse
+	if err := c.call(ctx, c.newRequest("tasks/pause", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+
+	return &result, nil
+}
+
+// ResumeTask continues a suspended task on the A2A server
+func (c *A2AClient) ResumeTask(ctx context.Context, payload map[string]interface{}) (*types.ResumeTaskResponse, error) {
+	var result types.ResumeTaskResponse
+	if err := c.call(ctx, c.newRequest("tasks/resume", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+
+	return &result, nil
+}
+
+// TransferTask moves a task to a different session on the A2A server
+func (c *A2AClient) TransferTask(ctx context.Context, payload map[string]interface{}) (*types.TransferTaskResponse, error) {
+	var result types.TransferTaskResponse
+	if err := c.call(ctx, c.newRequest("tasks/transfer", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+
+	return &result, nil
+}
+
+// GetTaskCallbackStatus retrieves the push notification delivery receipts of a task
+func (c *A2AClient) GetTaskCallbackStatus(ctx context.Context, payload map[string]interface{}) (*types.GetPushNotificationStatusResponse, error) {
+	if err := c.checkPushNotifications(); err != nil {
+		return nil, err
+	}
+
+	var result types.GetPushNotificationStatusResponse
+	if err := c.call(ctx, c.newRequest("tasks/pushNotificationConfig/status", payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListTasks searches the tasks on the A2A server by session, state and metadata, see types.TaskListParams
+func (c *A2AClient) ListTasks(ctx context.Context, payload map[string]interface{}) (*types.ListTasksResponse, error) {
+	var result types.ListTasksResponse
+	if err := c.call(ctx, c.newRequest(types.ListTasksMethod, payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// BatchGetTasks retrieves several tasks in one round trip, with a task or an error per id
+func (c *A2AClient) BatchGetTasks(ctx context.Context, payload map[string]interface{}) (*types.BatchGetTasksResponse, error) {
+	var result types.BatchGetTasksResponse
+	if err := c.call(ctx, c.newRequest(types.BatchGetTasksMethod, payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// BatchCancelTasks cancels several tasks in one round trip, with the canceled task or an error per id
+func (c *A2AClient) BatchCancelTasks(ctx context.Context, payload map[string]interface{}) (*types.BatchCancelTasksResponse, error) {
+	var result types.BatchCancelTasksResponse
+	if err := c.call(ctx, c.newRequest(types.BatchCancelTasksMethod, payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CloseSession ends a session on the A2A server, canceling its outstanding tasks
+func (c *A2AClient) CloseSession(ctx context.Context, payload map[string]interface{}) (*types.CloseSessionResponse, error) {
+	var result types.CloseSessionResponse
+	if err := c.call(ctx, c.newRequest(types.CloseSessionMethod, payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetAgentCapabilities retrieves the live capabilities of the agent, see NegotiateCapabilities
+func (c *A2AClient) GetAgentCapabilities(ctx context.Context, payload map[string]interface{}) (*types.AgentCapabilitiesResponse, error) {
+	var result types.AgentCapabilitiesResponse
+	if err := c.call(ctx, c.newRequest(types.AgentCapabilitiesMethod, payload), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GetTaskWithParams calls GetTask with typed params
+func (c *A2AClient) GetTaskWithParams(ctx context.Context, params *types.TaskQueryParams) (*types.GetTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTask(ctx, payload)
+}
+
+// SendTaskWithParams calls SendTask with typed params
+func (c *A2AClient) SendTaskWithParams(ctx context.Context, params *types.TaskSendParams) (*types.SendTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.SendTask(ctx, payload)
+}
+
+// SendTaskStreamingWithParams calls SendTaskStreaming with typed params
+func (c *A2AClient) SendTaskStreamingWithParams(ctx context.Context, params *types.TaskSendParams) (chan *types.SendTaskStreamingResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.SendTaskStreaming(ctx, payload)
+}
+
+// CancelTaskWithParams calls CancelTask with typed params
+func (c *A2AClient) CancelTaskWithParams(ctx context.Context, params *types.TaskIdParams) (*types.CancelTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.CancelTask(ctx, payload)
+}
+
+// SetTaskCallbackWithParams calls SetTaskCallback with typed params
+func (c *A2AClient) SetTaskCallbackWithParams(ctx context.Context, params *types.TaskPushNotificationConfig) (*types.SetTaskPushNotificationResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.SetTaskCallback(ctx, payload)
+}
+
+// GetTaskCallbackWithParams calls GetTaskCallback with typed params
+func (c *A2AClient) GetTaskCallbackWithParams(ctx context.Context, params *types.TaskIdParams) (*types.GetTaskPushNotificationResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTaskCallback(ctx, payload)
+}
+
+// ResubscribeToTaskWithParams calls ResubscribeToTask with typed params
+func (c *A2AClient) ResubscribeToTaskWithParams(ctx context.Context, params *types.TaskQueryParams) (chan *types.SendTaskStreamingResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.ResubscribeToTask(ctx, payload)
+}
+
+// PauseTaskWithParams calls PauseTask with typed params
+func (c *A2AClient) PauseTaskWithParams(ctx context.Context, params *types.TaskIdParams) (*types.PauseTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.PauseTask(ctx, payload)
+}
+
+// ResumeTaskWithParams calls ResumeTask with typed params
+func (c *A2AClient) ResumeTaskWithParams(ctx context.Context, params *types.TaskIdParams) (*types.ResumeTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.ResumeTask(ctx, payload)
+}
+
+// TransferTaskWithParams calls TransferTask with typed params
+func (c *A2AClient) TransferTaskWithParams(ctx context.Context, params *types.TaskTransferParams) (*types.TransferTaskResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.TransferTask(ctx, payload)
+}
+
+// ReplayTaskWithParams calls ReplayTask with typed params
+func (c *A2AClient) ReplayTaskWithParams(ctx context.Context, params *types.TaskReplayParams) (chan *types.SendTaskStreamingResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.ReplayTask(ctx, payload)
+}
+
+// GetTaskCallbackStatusWithParams calls GetTaskCallbackStatus with typed params
+func (c *A2AClient) GetTaskCallbackStatusWithParams(ctx context.Context, params *types.TaskIdParams) (*types.GetPushNotificationStatusResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTaskCallbackStatus(ctx, payload)
+}
+
+// ListTasksWithParams calls ListTasks with typed params
+func (c *A2AClient) ListTasksWithParams(ctx context.Context, params *types.TaskListParams) (*types.ListTasksResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.ListTasks(ctx, payload)
+}
+
+// PollTaskEventsWithParams calls PollTaskEvents with typed params
+func (c *A2AClient) PollTaskEventsWithParams(ctx context.Context, params *types.TaskEventsParams) (*types.PollTaskEventsResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.PollTaskEvents(ctx, payload)
+}
+
+// BatchGetTasksWithParams calls BatchGetTasks with typed params
+func (c *A2AClient) BatchGetTasksWithParams(ctx context.Context, params *types.TaskBatchParams) (*types.BatchGetTasksResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.BatchGetTasks(ctx, payload)
+}
+
+// BatchCancelTasksWithParams calls BatchCancelTasks with typed params
+func (c *A2AClient) BatchCancelTasksWithParams(ctx context.Context, params *types.TaskBatchParams) (*types.BatchCancelTasksResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.BatchCancelTasks(ctx, payload)
+}
+
+// CloseSessionWithParams calls CloseSession with typed params
+func (c *A2AClient) CloseSessionWithParams(ctx context.Context, params *types.SessionCloseParams) (*types.CloseSessionResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.CloseSession(ctx, payload)
+}
+
+// GetAgentCapabilitiesWithParams calls GetAgentCapabilities with typed params
+func (c *A2AClient) GetAgentCapabilitiesWithParams(ctx context.Context, params *types.AgentCapabilitiesParams) (*types.AgentCapabilitiesResponse, error) {
+	payload, err := validPayload(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetAgentCapabilities(ctx, payload)
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultGapTimeout is how long out-of-order notifications wait for the missing ones
+	defaultGapTimeout = 2 * time.Second
+	// defaultMaxPending bounds the notifications buffered per task while waiting for a gap to fill
+	defaultMaxPending = 64
+)
+
+// ResyncFunc fetches the current state of a task after notifications were lost
+type ResyncFunc func(taskID string) (*types.Task, error)
+
+// GetTaskResync returns a ResyncFunc that calls get_task on c with ctx
+func GetTaskResync(ctx context.Context, c *A2AClient) ResyncFunc {
+	return func(taskID string) (*types.Task, error) {
+		response, err := c.GetTask(ctx, map[string]interface{}{"id": taskID})
+		if err != nil {
+			return nil, err
+		}
+		if response.Result == nil {
+			return nil, fmt.Errorf("get_task returned no task for %s", taskID)
+		}
+		return response.Result, nil
+	}
+}
+
+// SequencerOption configures a NotificationSequencer
+type SequencerOption func(*NotificationSequencer)
+
+// WithGapTimeout sets how long out-of-order notifications wait for missing ones before resyncing
+func WithGapTimeout(timeout time.Duration) SequencerOption {
+	return func(s *NotificationSequencer) {
+		s.gapTimeout = timeout
+	}
+}
+
+// WithMaxPending resyncs immediately once more than n notifications of a task are waiting on a gap
+func WithMaxPending(n int) SequencerOption {
+	return func(s *NotificationSequencer) {
+		s.maxPending = n
+	}
+}
+
+// NotificationSequencer delivers the push notifications of each task in sequence order.
+// Out-of-order notifications are held back until the missing ones arrive; if they don't
+// arrive in time, the task is re-synced with resync and its current state is delivered as
+// a notification marked with utils.NotificationResyncedKey. Duplicates are dropped, and
+// notifications without a sequence number are delivered as they arrive.
+type NotificationSequencer struct {
+	deliver    func(payload map[string]interface{})
+	resync     ResyncFunc
+	gapTimeout time.Duration
+	maxPending int
+
+	lock  sync.Mutex
+	tasks map[string]*taskSequence
+}
+
+// taskSequence is the ordering state of one task
+type taskSequence struct {
+	next      uint64
+	pending   map[uint64]map[string]interface{}
+	timer     *time.Timer
+	resyncing bool
+}
+
+// NewNotificationSequencer creates a sequencer passing ordered notifications to deliver
+func NewNotificationSequencer(deliver func(payload map[string]interface{}), resync ResyncFunc, opts ...SequencerOption) *NotificationSequencer {
+	s := &NotificationSequencer{
+		deliver:    deliver,
+		resync:     resync,
+		gapTimeout: defaultGapTimeout,
+		maxPending: defaultMaxPending,
+		tasks:      make(map[string]*taskSequence),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Receive accepts a notification in arrival order and delivers every notification it unblocks
+func (s *NotificationSequencer) Receive(payload map[string]interface{}) {
+	taskID := utils.NotificationTaskID(payload)
+	sequence, ok := utils.NotificationSequence(payload)
+	if taskID == "" || !ok {
+		s.deliver(payload)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	seq := s.tasks[taskID]
+	if seq == nil {
+		seq = &taskSequence{next: 1, pending: make(map[uint64]map[string]interface{})}
+		s.tasks[taskID] = seq
+	}
+	if _, buffered := seq.pending[sequence]; sequence < seq.next || buffered {
+		log.Printf("Dropping duplicate notification %d of task %s", sequence, taskID)
+		return
+	}
+	seq.pending[sequence] = payload
+	s.drain(seq)
+
+	switch {
+	case len(seq.pending) == 0:
+		if seq.timer != nil {
+			seq.timer.Stop()
+			seq.timer = nil
+		}
+	case len(seq.pending) > s.maxPending:
+		s.startResync(taskID, seq)
+	case seq.timer == nil && !seq.resyncing:
+		seq.timer = time.AfterFunc(s.gapTimeout, func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			s.startResync(taskID, seq)
+		})
+	}
+}
+
+// drain delivers the consecutive notifications starting at next; s.lock must be held
+func (s *NotificationSequencer) drain(seq *taskSequence) {
+	for {
+		payload, ok := seq.pending[seq.next]
+		if !ok {
+			return
+		}
+		delete(seq.pending, seq.next)
+		seq.next++
+		s.deliver(payload)
+	}
+}
+
+// startResync fetches the task in the background to get past a gap; s.lock must be held
+func (s *NotificationSequencer) startResync(taskID string, seq *taskSequence) {
+	if seq.timer != nil {
+		seq.timer.Stop()
+		seq.timer = nil
+	}
+	if seq.resyncing || len(seq.pending) == 0 {
+		return
+	}
+	seq.resyncing = true
+	log.Printf("Notifications of task %s missing from sequence %d, re-syncing", taskID, seq.next)
+
+	go func() {
+		var task *types.Task
+		err := fmt.Errorf("no resync function")
+		if s.resync != nil {
+			task, err = s.resync(taskID)
+		}
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		seq.resyncing = false
+
+		last := seq.next
+		for sequence := range seq.pending {
+			if sequence > last {
+				last = sequence
+			}
+		}
+		if err != nil {
+			// Without the current state, deliver what arrived in order and accept the gap
+			log.Printf("Error re-syncing task %s: %v", taskID, err)
+			for seq.next <= last {
+				if payload, ok := seq.pending[seq.next]; ok {
+					delete(seq.pending, seq.next)
+					s.deliver(payload)
+				}
+				seq.next++
+			}
+			return
+		}
+
+		// The fetched task supersedes everything that was waiting on the gap
+		seq.pending = make(map[uint64]map[string]interface{})
+		seq.next = last + 1
+		payload, err := taskPayload(task)
+		if err != nil {
+			log.Printf("Error encoding re-synced task %s: %v", taskID, err)
+			return
+		}
+		payload[utils.NotificationResyncedKey] = true
+		s.deliver(payload)
+	}()
+}
+
+// taskPayload converts a task into the map form notifications are delivered in
+func taskPayload(task *types.Task) (map[string]interface{}, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultMaxClockSkew is the clock difference to the agent Preflight tolerates. JWTs are
+// typically validated with less than a minute of leeway.
+const DefaultMaxClockSkew = 30 * time.Second
+
+// PreflightCheck is the outcome of one preflight check
+type PreflightCheck struct {
+	Name   string // "card", "auth" or "clock"
+	OK     bool
+	Detail string // What was found and, for failures, what to do about it
+}
+
+// PreflightReport describes whether the agent is ready for traffic from this client
+type PreflightReport struct {
+	Card        *types.AgentCard // The card served by the agent, nil if it could not be fetched
+	CardLatency time.Duration
+	RPCLatency  time.Duration
+	ClockSkew   time.Duration // Agent clock minus local clock, from the Date headers
+	Checks      []PreflightCheck
+}
+
+// OK reports whether every check passed
+func (r *PreflightReport) OK() bool {
+	for _, check := range r.Checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the failed checks as an error, or nil if all passed
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if !check.OK {
+			errs = append(errs, fmt.Errorf("%s: %s", check.Name, check.Detail))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *PreflightReport) String() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		status := "ok"
+		if !check.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%-5s %-4s %s\n", check.Name, status, check.Detail)
+	}
+	return b.String()
+}
+
+func (r *PreflightReport) add(name string, ok bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, OK: ok, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Preflight checks that the agent is reachable and serves its card, that the client's
+// credentials are accepted, using a get_task call for a task that doesn't exist, and that
+// the local clock is within DefaultMaxClockSkew of the agent's. Failed checks are reported,
+// not returned as errors; the error is only set if ctx ended.
+func (c *A2AClient) Preflight(ctx context.Context) (*PreflightReport, error) {
+	report := &PreflightReport{}
+	var skews []time.Duration
+
+	card, skew, err := c.preflightCard(ctx, report)
+	switch {
+	case err != nil:
+		report.add("card", false, "%v", err)
+	default:
+		report.Card = card
+		if skew != nil {
+			skews = append(skews, *skew)
+		}
+		if card.URL != "" && strings.TrimRight(card.URL, "/") != strings.TrimRight(c.url, "/") {
+			report.add("card", true, "%s serves its card; note the card URL %s differs from the client URL %s", card.Name, card.URL, c.url)
+		} else {
+			report.add("card", true, "%s serves its card (%s)", card.Name, report.CardLatency.Round(time.Millisecond))
+		}
+	}
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+
+	skew, err = c.preflightAuth(ctx, report)
+	if err != nil {
+		report.add("auth", false, "%v", err)
+	}
+	if skew != nil {
+		skews = append(skews, *skew)
+	}
+	if ctx.Err() != nil {
+		return report, ctx.Err()
+	}
+
+	if len(skews) == 0 {
+		report.add("clock", false, "the agent sent no Date header, clock skew is unknown")
+		return report, nil
+	}
+	report.ClockSkew = skews[len(skews)-1]
+	if abs := report.ClockSkew.Abs(); abs > DefaultMaxClockSkew {
+		report.add("clock", false, "local clock is %s %s the agent's; sync it (e.g. with NTP) or tokens will be rejected as not yet valid or expired",
+			abs.Round(time.Second), skewDirection(report.ClockSkew))
+	} else {
+		report.add("clock", true, "within %s of the agent", DefaultMaxClockSkew)
+	}
+	return report, nil
+}
+
+// preflightCard fetches the card from the agent's well-known path
+func (c *A2AClient) preflightCard(ctx context.Context, report *PreflightReport) (*types.AgentCard, *time.Duration, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid agent URL: %v", err)
+	}
+	cardURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/.well-known/agent.json"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("agent unreachable: %v; check the URL, DNS and proxy settings", err)
+	}
+	defer resp.Body.Close()
+	report.CardLatency = time.Since(start)
+	skew := dateSkew(resp, start)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, skew, fmt.Errorf("%s returned status %d", cardURL.String(), resp.StatusCode)
+	}
+	var card types.AgentCard
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&card); err != nil {
+		return nil, skew, fmt.Errorf("invalid agent card: %v", err)
+	}
+	return &card, skew, nil
+}
+
+// preflightAuth sends a get_task for a task that can't exist. Agents accepting the
+// credentials answer with a task not found error; rejected credentials get 401 or 403.
+func (c *A2AClient) preflightAuth(ctx context.Context, report *PreflightReport) (*time.Duration, error) {
+	request := c.newRequest("get_task", map[string]interface{}{"id": "preflight-" + utils.NewID()})
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	report.RPCLatency = time.Since(start)
+	skew := dateSkew(resp, start)
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		detail := fmt.Sprintf("credentials rejected with status %d", resp.StatusCode)
+		if challenge := resp.Header.Get("WWW-Authenticate"); challenge != "" {
+			detail += fmt.Sprintf(" (%s)", challenge)
+		}
+		return skew, errors.New(detail + "; check the token, its audience and expiry")
+	}
+
+	var rpc types.JSONRPCResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&rpc); err != nil {
+		return skew, fmt.Errorf("status %d with a body that is not JSON-RPC: %v", resp.StatusCode, err)
+	}
+	switch {
+	case rpc.Error == nil:
+		report.add("auth", true, "accepted (%s)", report.RPCLatency.Round(time.Millisecond))
+	case rpc.Error.Code == types.TaskNotFoundErrorCode:
+		report.add("auth", true, "accepted (%s)", report.RPCLatency.Round(time.Millisecond))
+	default:
+		report.add("auth", true, "not rejected; get_task answered %q (code %d)", rpc.Error.Message, rpc.Error.Code)
+	}
+	return skew, nil
+}
+
+// dateSkew returns the agent clock minus the local clock at the midpoint of the request,
+// or nil if the response has no Date header
+func dateSkew(resp *http.Response, start time.Time) *time.Duration {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return nil
+	}
+	end := time.Now()
+	local := start.Add(end.Sub(start) / 2)
+	skew := date.Sub(local)
+	// Date has a resolution of one second
+	if skew.Abs() < time.Second {
+		skew = 0
+	}
+	return &skew
+}
+
+func skewDirection(skew time.Duration) string {
+	if skew > 0 {
+		return "behind"
+	}
+	return "ahead of"
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Progress reports the transfer state of a single client call
+type Progress struct {
+	Method          string
+	BytesSent       int64
+	TotalBytesSent  int64 // Size of the request body
+	BytesReceived   int64
+	TotalBytes      int64 // Size of the response body, -1 if unknown (e.g. SSE streams)
+	EventsProcessed int64
+	Done            bool
+}
+
+// ProgressFunc receives progress updates; it is called from the goroutine performing the transfer
+type ProgressFunc func(Progress)
+
+// WithProgress registers a callback receiving upload, download and stream event progress
+func WithProgress(fn ProgressFunc) ClientOption {
+	return func(c *A2AClient) {
+		c.progress = fn
+	}
+}
+
+// progressTracker accumulates the progress of one call and reports it to a ProgressFunc
+type progressTracker struct {
+	fn       ProgressFunc
+	method   string
+	total    int64
+	sent     atomic.Int64
+	received atomic.Int64
+	events   atomic.Int64
+	size     atomic.Int64
+}
+
+// newProgressTracker returns nil when no callback is configured
+func (c *A2AClient) newProgressTracker(method string, requestSize int64) *progressTracker {
+	if c.progress == nil {
+		return nil
+	}
+	t := &progressTracker{fn: c.progress, method: method, total: requestSize}
+	t.size.Store(-1)
+	return t
+}
+
+func (t *progressTracker) report(done bool) {
+	if t == nil {
+		return
+	}
+	t.fn(Progress{
+		Method:          t.method,
+		BytesSent:       t.sent.Load(),
+		TotalBytesSent:  t.total,
+		BytesReceived:   t.received.Load(),
+		TotalBytes:      t.size.Load(),
+		EventsProcessed: t.events.Load(),
+		Done:            done,
+	})
+}
+
+// setResponseSize records the expected response size
+func (t *progressTracker) setResponseSize(size int64) {
+	if t != nil {
+		t.size.Store(size)
+	}
+}
+
+// eventProcessed counts a decoded stream event
+func (t *progressTracker) eventProcessed() {
+	if t == nil {
+		return
+	}
+	t.events.Add(1)
+	t.report(false)
+}
+
+// wrapRequest counts bytes read from a request body
+func (t *progressTracker) wrapRequest(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &countingReader{reader: r, count: &t.sent, onRead: func() { t.report(false) }}
+}
+
+// wrapResponse counts bytes read from a response body
+func (t *progressTracker) wrapResponse(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &countingReader{reader: r, count: &t.received, onRead: func() { t.report(false) }}
+}
+
+// countingReader counts the bytes flowing through it
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+	onRead func()
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.count.Add(int64(n))
+		r.onRead()
+	}
+	return n, err
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"errors"
+)
+
+const (
+	// LegacyProtocolVersion is assumed for agent cards that do not declare a protocolVersion
+	LegacyProtocolVersion = "0.1.0"
+	// messageMethodsVersion is the first protocol version using message/send style method names
+	messageMethodsVersion = "0.2.0"
+)
+
+// legacyToCurrentMethods maps legacy method names to their current protocol equivalents
+var legacyToCurrentMethods = map[string]string{
+	"send_task":                  "message/send",
+	"send_task_streaming":        "message/stream",
+	"get_task":                   "tasks/get",
+	"cancel_task":                "tasks/cancel",
+	"set_task_push_notification": "tasks/pushNotificationConfig/set",
+	"get_task_push_notification": "tasks/pushNotificationConfig/get",
+	"resubscribe_to_task":        "tasks/resubscribe",
+}
+
+// supportedProtocolVersions are the protocol versions the client speaks
+var supportedProtocolVersions = []string{LegacyProtocolVersion, messageMethodsVersion}
+
+// Features is the protocol version and feature set negotiated with the agent
+type Features struct {
+	ProtocolVersion        string
+	MessageMethods         bool // Uses message/send style method names instead of send_task
+	Streaming              bool
+	PushNotifications      bool
+	StateTransitionHistory bool
+
+	Negotiated bool              // The agent reported the features below through agent/capabilities
+	Methods    []string          // Methods the agent serves, if Negotiated
+	Limits     types.AgentLimits // Limits the agent enforces, if Negotiated
+}
+
+// WithProtocolVersion overrides the protocol version read from the agent card
+func WithProtocolVersion(version string) ClientOption {
+	return func(c *A2AClient) {
+		c.protocolVersion = version
+	}
+}
+
+// Features returns the feature set negotiated from the agent card and client options, updated
+// by NegotiateCapabilities. Without a known agent card only the protocol version is negotiated
+// and capabilities are reported as unsupported.
+func (c *A2AClient) Features() Features {
+	card := c.Card()
+	c.cardLock.Lock()
+	live := c.capabilities
+	c.cardLock.Unlock()
+
+	version := c.protocolVersion
+	if version == "" && live != nil {
+		version = live.ProtocolVersion
+	}
+	if version == "" && card != nil {
+		version = card.ProtocolVersion
+	}
+	if version == "" {
+		version = LegacyProtocolVersion
+	}
+
+	features := Features{
+		ProtocolVersion: version,
+		MessageMethods:  types.CompareProtocolVersions(version, messageMethodsVersion) >= 0,
+	}
+	var capabilities *types.AgentCapabilities
+	if live != nil {
+		capabilities = &live.Capabilities
+	} else if card != nil {
+		capabilities = &card.Capabilities
+	}
+	if capabilities != nil {
+		features.Streaming = capabilities.Streaming
+		features.PushNotifications = capabilities.PushNotifications
+		features.StateTransitionHistory = capabilities.StateTransitionHistory
+	}
+	if live != nil {
+		features.Negotiated = true
+		features.Methods = live.Methods
+		features.Limits = live.Limits
+	}
+	return features
+}
+
+// NegotiateCapabilities asks the agent for its live capabilities with agent/capabilities and
+// merges them into Features, preferring them over the agent card. Agents without the method
+// keep the features negotiated from the card.
+func (c *A2AClient) NegotiateCapabilities(ctx context.Context) (Features, error) {
+	response, err := c.GetAgentCapabilitiesWithParams(ctx, &types.AgentCapabilitiesParams{
+		ProtocolVersions: supportedProtocolVersions,
+	})
+	var rpcErr *types.JSONRPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == types.MethodNotFoundErrorCode {
+		return c.Features(), nil
+	}
+	if err != nil {
+		return c.Features(), err
+	}
+	if response.Result == nil {
+		return c.Features(), errors.New("agent/capabilities returned no result")
+	}
+
+	c.cardLock.Lock()
+	c.capabilities = response.Result
+	c.cardLock.Unlock()
+	return c.Features(), nil
+}
+
+// isResubscribe reports whether method resumes the event stream of a task
+func isResubscribe(method string) bool {
+	return method == types.ResubscribeMethod || method == legacyToCurrentMethods[types.ResubscribeMethod]
+}
+
+// newRequest builds a request for a legacy method, translating the method name and
+// params for agents speaking the current protocol
+func (c *A2AClient) newRequest(method string, payload map[string]interface{}) *types.JSONRPCRequest {
+	request := &types.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  payload,
+	}
+
+	current, ok := legacyToCurrentMethods[method]
+	if !ok || !c.Features().MessageMethods {
+		return request
+	}
+	request.Method = current
+
+	switch method {
+	case "send_task", "send_task_streaming":
+		request.Params = toMessageSendParams(payload)
+	case "set_task_push_notification":
+		params := make(map[string]interface{}, len(payload))
+		for k, v := range payload {
+			params[k] = v
+		}
+		if id, ok := params["id"]; ok {
+			delete(params, "id")
+			params["taskId"] = id
+		}
+		request.Params = params
+	}
+	return request
+}
+
+// toMessageSendParams converts legacy TaskSendParams into current MessageSendParams
+func toMessageSendParams(payload map[string]interface{}) map[string]interface{} {
+	message := map[string]interface{}{}
+	if m, ok := payload["message"].(map[string]interface{}); ok {
+		for k, v := range m {
+			message[k] = v
+		}
+	}
+	message["kind"] = "message"
+	if _, ok := message["messageId"]; !ok {
+		message["messageId"] = utils.NewID()
+	}
+	if id, ok := payload["id"]; ok {
+		message["taskId"] = id
+	}
+	if sessionID, ok := payload["sessionId"]; ok {
+		message["contextId"] = sessionID
+	}
+
+	configuration := map[string]interface{}{}
+	if modes, ok := payload["acceptedOutputModes"]; ok {
+		configuration["acceptedOutputModes"] = modes
+	}
+	if push, ok := payload["pushNotification"]; ok {
+		configuration["pushNotificationConfig"] = push
+	}
+	if historyLength, ok := payload["historyLength"]; ok {
+		configuration["historyLength"] = historyLength
+	}
+
+	params := map[string]interface{}{"message": message}
+	if len(configuration) > 0 {
+		params["configuration"] = configuration
+	}
+	if metadata, ok := payload["metadata"]; ok {
+		params["metadata"] = metadata
+	}
+	return params
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// parseProxyURL validates a proxy URL; http, https, socks5 and socks5h schemes are supported
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy url %q has no host", proxyURL)
+	}
+	return u, nil
+}
+
+// WithProxy routes all client traffic, including SSE streams, through the given
+// http(s):// or socks5:// proxy instead of the environment's HTTP_PROXY settings
+func WithProxy(proxyURL string) ClientOption {
+	return func(c *A2AClient) {
+		u, err := parseProxyURL(proxyURL)
+		if err != nil {
+			c.optionErr = err
+			return
+		}
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			t.Proxy = http.ProxyURL(u)
+		})
+	}
+}
+
+// WithoutProxy disables proxies, ignoring HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+func WithoutProxy() ClientOption {
+	return func(c *A2AClient) {
+		c.transportOptions = append(c.transportOptions, func(t *http.Transport) {
+			t.Proxy = nil
+		})
+	}
+}
+
+// CardResolverOption configures optional A2ACardResolver behavior
+type CardResolverOption func(*A2ACardResolver)
+
+// WithCardResolverProxy fetches agent cards through the given http(s):// or socks5:// proxy
+func WithCardResolverProxy(proxyURL string) CardResolverOption {
+	return func(r *A2ACardResolver) {
+		u, err := parseProxyURL(proxyURL)
+		if err != nil {
+			r.optionErr = err
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(u)
+		r.client = &http.Client{Transport: transport}
+	}
+}
+
+// WithCardResolverHTTPClient sets the HTTP client used to fetch agent cards
+func WithCardResolverHTTPClient(client *http.Client) CardResolverOption {
+	return func(r *A2ACardResolver) {
+		r.client = client
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how throttled requests are retried. Server retry hints
+// (Retry-After headers and retryAfterMs error data) take precedence over the backoff.
+type RetryPolicy struct {
+	MaxAttempts    int           // Including the first attempt; values below 2 disable retries
+	InitialBackoff time.Duration // Delay before the first retry without a server hint, doubled on each retry
+	MaxBackoff     time.Duration // Upper bound for computed backoffs and server hints
+}
+
+// DefaultRetryPolicy retries throttled requests up to twice
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+}
+
+// WithRetryPolicy retries requests rejected with HTTP 429 or 503 or a throttling JSON-RPC error
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *A2AClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// delay returns how long to wait before retry number attempt (starting at 1)
+func (p RetryPolicy) delay(attempt int, hint time.Duration, hasHint bool) time.Duration {
+	delay := hint
+	if !hasHint {
+		delay = p.InitialBackoff << (attempt - 1)
+		if delay <= 0 {
+			delay = p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// retryAfter reports whether err signals throttling, and the server's retry hint if it sent one
+func retryAfter(err error) (hint time.Duration, hasHint bool, throttled bool) {
+	var httpErr *types.A2AClientHTTPError
+	if !errors.As(err, &httpErr) {
+		return 0, false, false
+	}
+	throttled = httpErr.StatusCode == http.StatusTooManyRequests ||
+		httpErr.StatusCode == http.StatusServiceUnavailable ||
+		(httpErr.RPCError != nil && httpErr.RPCError.Code == types.ThrottledErrorCode)
+	return httpErr.RetryAfter, httpErr.RetryAfter > 0, throttled
+}
+
+// do sends the request built by newRequest, retrying throttled attempts according to the
+// retry policy. Responses other than 200 OK are returned as *types.A2AClientHTTPError.
+func (c *A2AClient) do(ctx context.Context, httpClient *http.Client, newRequest func(context.Context) (*http.Request, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doOnce(ctx, httpClient, newRequest)
+		if err == nil {
+			return resp, nil
+		}
+		hint, hasHint, throttled := retryAfter(err)
+		if !throttled || attempt >= c.retryPolicy.MaxAttempts {
+			return nil, err
+		}
+
+		timer := time.NewTimer(c.retryPolicy.delay(attempt, hint, hasHint))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+func (c *A2AClient) doOnce(ctx context.Context, httpClient *http.Client, newRequest func(context.Context) (*http.Request, error)) (*http.Response, error) {
+	req, err := newRequest(ctx)
+	if err != nil {
+		return nil, &types.A2AClientHTTPError{
+			StatusCode: 400,
+			Message:    fmt.Sprintf("failed to create request: %v", err),
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &types.A2AClientHTTPError{
+			StatusCode: 400,
+			Message:    fmt.Sprintf("failed to send request: %v", err),
+			Err:        err,
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, httpError(resp)
+	}
+	return resp, nil
+}
+
+// maxErrorBodySize bounds how much of an error response is read looking for a JSON-RPC error
+const maxErrorBodySize = 64 << 10
+
+// httpError converts a non-200 response into an A2AClientHTTPError, keeping the JSON-RPC
+// error and retry hint the server sent
+func httpError(resp *http.Response) *types.A2AClientHTTPError {
+	httpErr := &types.A2AClientHTTPError{
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("unexpected status code: %d", resp.StatusCode),
+	}
+
+	if rpcErr := readRPCError(resp); rpcErr != nil {
+		httpErr.RPCError = rpcErr
+		httpErr.Message = fmt.Sprintf("%s (code %d)", rpcErr.Message, rpcErr.Code)
+		if hint, ok := rpcErr.RetryAfter(); ok {
+			httpErr.RetryAfter = hint
+		}
+	}
+	if httpErr.RetryAfter == 0 {
+		httpErr.RetryAfter = parseRetryAfterHeader(resp.Header.Get("Retry-After"))
+	}
+	return httpErr
+}
+
+// streamRejected returns the error of a streaming request answered with a JSON-RPC response
+// instead of an event stream. Agents send JSON-RPC errors with status 200.
+func streamRejected(resp *http.Response) error {
+	if rpcErr := readRPCError(resp); rpcErr != nil {
+		return rpcErr
+	}
+	return &types.A2AClientJSONError{
+		Message: fmt.Sprintf("expected an event stream, got %s", resp.Header.Get("Content-Type")),
+	}
+}
+
+// readRPCError reads the JSON-RPC error in a response body, nil if there is none
+func readRPCError(resp *http.Response) *types.JSONRPCError {
+	var body types.JSONRPCResponse
+	reader, err := decodeResponseBody(resp, io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return nil
+	}
+	if data, err := io.ReadAll(reader); err != nil || json.Unmarshal(data, &body) != nil {
+		return nil
+	}
+	return body.Error
+}
+
+// parseRetryAfterHeader reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfterHeader(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
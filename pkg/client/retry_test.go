@@ -0,0 +1,29 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+		hint      time.Duration
+	}{
+		{"429", &types.A2AClientHTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}, true, time.Second},
+		{"503", &types.A2AClientHTTPError{StatusCode: http.StatusServiceUnavailable}, true, 0},
+		{"throttling error", &types.A2AClientHTTPError{StatusCode: http.StatusOK, RPCError: &types.JSONRPCError{Code: types.ThrottledErrorCode}}, true, 0},
+		{"other error", &types.A2AClientHTTPError{StatusCode: http.StatusOK, RPCError: &types.JSONRPCError{Code: types.InternalErrorCode}}, false, 0},
+		{"not http", &types.A2AClientJSONError{Message: "bad"}, false, 0},
+	}
+	for _, tt := range tests {
+		hint, hasHint, throttled := retryAfter(tt.err)
+		if throttled != tt.throttled || hint != tt.hint || hasHint != (tt.hint > 0) {
+			t.Errorf("%s: retryAfter = %v, %v, %v; want %v, %v, %v", tt.name, hint, hasHint, throttled, tt.hint, tt.hint > 0, tt.throttled)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"time"
+)
+
+// sseEvent is a message of a server-sent event stream
+type sseEvent struct {
+	Type  string        // "message" unless set with an event field
+	ID    string        // Last event id of the stream, "" if none was sent
+	Data  []byte        // Data lines joined with newlines
+	Retry time.Duration // Reconnection delay sent with the event, 0 if none
+}
+
+// sseReader parses a server-sent event stream as specified by the HTML living standard:
+// data, event, id and retry fields, multi-line data, comments such as keep-alives, and
+// LF, CRLF or CR line endings
+type sseReader struct {
+	r      *bufio.Reader
+	lastID string
+	lastCR bool // The previous line ended with CR, so a following LF ends nothing
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event with data, or io.EOF once the stream ends. An event cut off
+// by the end of the stream is dropped.
+func (s *sseReader) next() (*sseEvent, error) {
+	event := &sseEvent{}
+	var data bytes.Buffer
+	hasData := false
+	for {
+		line, err := s.line()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) == 0 {
+			if !hasData {
+				event.Type = ""
+				event.Retry = 0
+				continue
+			}
+			event.Data = bytes.TrimSuffix(data.Bytes(), []byte{'\n'})
+			event.ID = s.lastID
+			if event.Type == "" {
+				event.Type = "message"
+			}
+			return event, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte{':'})
+		value = bytes.TrimPrefix(value, []byte{' '})
+		switch string(field) {
+		case "data":
+			data.Write(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "event":
+			event.Type = string(value)
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				s.lastID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(string(value), 10, 32); err == nil {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// line reads a line without its line ending
+func (s *sseReader) line() ([]byte, error) {
+	if s.lastCR {
+		// A LF right after a CR is part of the same line ending
+		s.lastCR = false
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != '\n' {
+			s.r.UnreadByte()
+		}
+	}
+
+	line := []byte{}
+	for {
+		if _, err := s.r.Peek(1); err != nil {
+			return nil, err
+		}
+		buffered, _ := s.r.Peek(s.r.Buffered())
+		if i := bytes.IndexAny(buffered, "\r\n"); i >= 0 {
+			line = append(line, buffered[:i]...)
+			s.lastCR = buffered[i] == '\r'
+			s.r.Discard(i + 1)
+			return line, nil
+		}
+		line = append(line, buffered...)
+		s.r.Discard(len(buffered))
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// streamAcceptEncoding is advertised on streaming requests unless compression is disabled
+const streamAcceptEncoding = "gzip, deflate"
+
+// WithoutStreamCompression stops the client from asking servers to compress SSE streams
+func WithoutStreamCompression() ClientOption {
+	return func(c *A2AClient) {
+		c.noStreamCompression = true
+	}
+}
+
+// decompressStream wraps a streaming response body according to its Content-Encoding.
+// It reads the compression header, so it blocks until the first bytes arrive.
+func decompressStream(contentEncoding string, body io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err == io.EOF {
+			return strings.NewReader(""), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip stream: %w", err)
+		}
+		// SSE streams are a single gzip member; stop at its end instead of looking for more
+		reader.Multistream(false)
+		return reader, nil
+	case "deflate":
+		reader, err := zlib.NewReader(body)
+		if err == io.EOF {
+			return strings.NewReader(""), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid deflate stream: %w", err)
+		}
+		return reader, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// taskCache keeps the final results of get_task, see WithTaskCache
+type taskCache struct {
+	cache utils.Cache
+	lock  sync.Mutex          // Serializes updates of cached entries
+	keys  map[string]struct{} // Cache keys of the tasks this client cached
+}
+
+// cachedTask is the cache entry of a task, one copy per requested history length
+type cachedTask map[string]*types.Task
+
+// WithTaskCache answers repeat get_task calls for tasks in a final state from cache instead
+// of the agent, since those tasks no longer change. Tasks are cached per requested history
+// length; calls asking for events always reach the agent. Call InvalidateTask after changing
+// a finished task, e.g. moving it with TransferTask.
+func WithTaskCache(cache utils.Cache) ClientOption {
+	return func(c *A2AClient) {
+		c.taskCache = &taskCache{cache: cache, keys: make(map[string]struct{})}
+	}
+}
+
+// GetTask retrieves a task from the A2A server, or from the task cache once it has finished
+func (c *A2AClient) GetTask(ctx context.Context, payload map[string]interface{}) (*types.GetTaskResponse, error) {
+	if response := c.cachedTask(payload); response != nil {
+		return response, nil
+	}
+
+	var result types.GetTaskResponse
+	if err := c.call(ctx, c.newRequest("get_task", payload), &result); err != nil {
+		return nil, err
+	}
+
+	if err := c.validateTask(result.Result); err != nil {
+		return nil, err
+	}
+	c.recordTaskUsage(result.Result)
+	c.cacheTask(payload, result.Result)
+
+	return &result, nil
+}
+
+// InvalidateTask drops the cached copies of a task, so the next GetTask fetches it again
+func (c *A2AClient) InvalidateTask(taskID string) {
+	if c.taskCache == nil {
+		return
+	}
+	key := c.taskCacheKey(taskID)
+
+	c.taskCache.lock.Lock()
+	defer c.taskCache.lock.Unlock()
+	c.taskCache.cache.Delete(key)
+	delete(c.taskCache.keys, key)
+}
+
+// InvalidateTaskCache drops every task this client cached, leaving other entries of a
+// shared cache alone
+func (c *A2AClient) InvalidateTaskCache() {
+	if c.taskCache == nil {
+		return
+	}
+
+	c.taskCache.lock.Lock()
+	defer c.taskCache.lock.Unlock()
+	for key := range c.taskCache.keys {
+		c.taskCache.cache.Delete(key)
+	}
+	c.taskCache.keys = make(map[string]struct{})
+}
+
+// taskCacheKey is the cache key of a task, distinct per agent so clients can share a cache
+func (c *A2AClient) taskCacheKey(taskID string) string {
+	return "a2a:task:" + c.url + ":" + taskID
+}
+
+// taskQuery decodes the get_task params relevant to the cache: the task id, the requested
+// history length as the variant of the entry, and the version the caller already has.
+// It reports false for queries that can't be answered from the cache.
+func taskQuery(payload map[string]interface{}) (taskID, variant string, sinceVersion *uint64, ok bool) {
+	taskID, _ = payload["id"].(string)
+	if taskID == "" {
+		return "", "", nil, false
+	}
+	if include, _ := payload["includeEvents"].(bool); include {
+		return "", "", nil, false
+	}
+	length, err := json.Marshal(payload["historyLength"])
+	if err != nil {
+		return "", "", nil, false
+	}
+	if since, present := payload["sinceVersion"]; present {
+		data, err := json.Marshal(since)
+		if err != nil || json.Unmarshal(data, &sinceVersion) != nil {
+			return "", "", nil, false
+		}
+	}
+	return taskID, string(length), sinceVersion, true
+}
+
+// cachedTask answers a get_task call from the task cache, or returns nil
+func (c *A2AClient) cachedTask(payload map[string]interface{}) *types.GetTaskResponse {
+	if c.taskCache == nil {
+		return nil
+	}
+	taskID, variant, sinceVersion, ok := taskQuery(payload)
+	if !ok {
+		return nil
+	}
+
+	entry, _ := c.taskCache.cache.Get(c.taskCacheKey(taskID), nil).(cachedTask)
+	task := entry[variant]
+	if task == nil {
+		return nil
+	}
+	if sinceVersion != nil && task.Version <= *sinceVersion {
+		return &types.GetTaskResponse{NotModified: true}
+	}
+	return &types.GetTaskResponse{Result: copyCachedTask(task)}
+}
+
+// cacheTask stores a task returned by get_task if it has finished
+func (c *A2AClient) cacheTask(payload map[string]interface{}, task *types.Task) {
+	if c.taskCache == nil || task == nil || !isTerminalState(task.Status.State) {
+		return
+	}
+	taskID, variant, _, ok := taskQuery(payload)
+	if !ok || taskID != task.ID {
+		return
+	}
+	key := c.taskCacheKey(taskID)
+
+	c.taskCache.lock.Lock()
+	defer c.taskCache.lock.Unlock()
+	current, _ := c.taskCache.cache.Get(key, nil).(cachedTask)
+	entry := make(cachedTask, len(current)+1)
+	for v, t := range current {
+		entry[v] = t
+	}
+	entry[variant] = copyCachedTask(task)
+	c.taskCache.cache.Set(key, entry, nil)
+	c.taskCache.keys[key] = struct{}{}
+}
+
+// copyCachedTask copies a task so callers can't change the cached copy through the result
+func copyCachedTask(task *types.Task) *types.Task {
+	copied := *task
+	copied.History = append([]types.Message(nil), task.History...)
+	copied.Artifacts = append([]types.Artifact(nil), task.Artifacts...)
+	return &copied
+}
+
+// isTerminalState reports whether a task has finished and no longer changes
+func isTerminalState(state types.TaskState) bool {
+	switch state {
+	case types.TaskCompleted, types.TaskCanceled, types.TaskFailed:
+		return true
+	}
+	return false
+}
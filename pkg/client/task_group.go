@@ -0,0 +1,245 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultGroupPollInterval is how often non-streaming group tasks are polled until they settle
+const defaultGroupPollInterval = time.Second
+
+// groupCancelTimeout bounds the cancel_task calls of a canceled group
+const groupCancelTimeout = 10 * time.Second
+
+// GroupEvent is an event of one task in a TaskGroup
+type GroupEvent struct {
+	TaskID string
+	Event  types.TaskEvent
+}
+
+// TaskGroupResult is the outcome of one task in a TaskGroup
+type TaskGroupResult struct {
+	TaskID string
+	Task   *types.Task
+	Err    error
+}
+
+// TaskGroupOption configures a TaskGroup
+type TaskGroupOption func(*TaskGroup)
+
+// WithGroupConcurrency bounds how many tasks of the group run at the same time
+func WithGroupConcurrency(n int) TaskGroupOption {
+	return func(g *TaskGroup) {
+		if n > 0 {
+			g.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithGroupStreaming submits tasks with SendTaskStreaming and merges their events into Events
+func WithGroupStreaming() TaskGroupOption {
+	return func(g *TaskGroup) {
+		g.streaming = true
+	}
+}
+
+// WithGroupPollInterval sets how often non-streaming tasks are polled until they settle
+func WithGroupPollInterval(d time.Duration) TaskGroupOption {
+	return func(g *TaskGroup) {
+		g.pollInterval = d
+	}
+}
+
+// TaskGroup submits several tasks, merges their events into one channel and waits until
+// every task is completed, canceled, failed or waiting for input.
+// Callers must drain Events concurrently with Wait.
+type TaskGroup struct {
+	client       *A2AClient
+	streaming    bool
+	pollInterval time.Duration
+	sem          chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	events chan GroupEvent
+
+	lock    sync.Mutex
+	results map[string]*TaskGroupResult
+	waited  bool
+}
+
+// NewTaskGroup creates a TaskGroup; canceling ctx cancels the whole group
+func NewTaskGroup(ctx context.Context, client *A2AClient, opts ...TaskGroupOption) *TaskGroup {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &TaskGroup{
+		client:       client,
+		pollInterval: defaultGroupPollInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		events:       make(chan GroupEvent, 64),
+		results:      make(map[string]*TaskGroupResult),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Events returns the merged event channel; it is closed when Wait returns
+func (g *TaskGroup) Events() <-chan GroupEvent {
+	return g.events
+}
+
+// Submit adds a task to the group. It returns immediately; the task starts once a concurrency slot is free.
+func (g *TaskGroup) Submit(params *types.TaskSendParams) error {
+	payload, err := validPayload(params)
+	if err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.waited {
+		return errors.New("task group is already being waited on")
+	}
+	if _, exists := g.results[params.ID]; exists {
+		return fmt.Errorf("task %s is already part of the group", params.ID)
+	}
+	result := &TaskGroupResult{TaskID: params.ID}
+	g.results[params.ID] = result
+
+	g.wg.Add(1)
+	go g.run(params.ID, payload, result)
+	return nil
+}
+
+// Cancel stops the group: queued tasks are not started and running tasks are canceled on the agent
+func (g *TaskGroup) Cancel() {
+	g.cancel()
+}
+
+// Wait blocks until every task settled and returns the results by task id
+func (g *TaskGroup) Wait() map[string]*TaskGroupResult {
+	g.lock.Lock()
+	g.waited = true
+	g.lock.Unlock()
+
+	g.wg.Wait()
+	close(g.events)
+	g.cancel()
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	results := make(map[string]*TaskGroupResult, len(g.results))
+	for id, result := range g.results {
+		results[id] = result
+	}
+	return results
+}
+
+// run executes one task of the group
+func (g *TaskGroup) run(taskID string, payload map[string]interface{}, result *TaskGroupResult) {
+	defer g.wg.Done()
+
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+			defer func() { <-g.sem }()
+		case <-g.ctx.Done():
+			g.setResult(result, nil, g.ctx.Err())
+			return
+		}
+	}
+
+	task, err := g.execute(taskID, payload)
+	if err == nil {
+		task, err = g.waitSettled(taskID, task)
+	}
+	if g.ctx.Err() != nil && (task == nil || !isSettled(task.Status.State)) {
+		// The group's context is done, so the cancel call gets one of its own
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(g.ctx), groupCancelTimeout)
+		_, cancelErr := g.client.CancelTask(ctx, map[string]interface{}{"id": taskID})
+		cancel()
+		if cancelErr != nil && err == nil {
+			err = cancelErr
+		}
+		if err == nil {
+			err = g.ctx.Err()
+		}
+	}
+	g.setResult(result, task, err)
+}
+
+// execute submits a task and returns the task state after submission
+func (g *TaskGroup) execute(taskID string, payload map[string]interface{}) (*types.Task, error) {
+	if !g.streaming {
+		response, err := g.client.SendTask(g.ctx, payload)
+		if err != nil {
+			return nil, err
+		}
+		return response.Result, nil
+	}
+
+	events, err := g.client.SendTaskStreamingEvents(g.ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	for event := range events {
+		select {
+		case g.events <- GroupEvent{TaskID: taskID, Event: event}:
+		case <-g.ctx.Done():
+			return nil, g.ctx.Err()
+		}
+		if event.IsError() && !event.IsDecodeError() {
+			return nil, fmt.Errorf("stream error %d: %s", event.Error.Code, event.Error.Message)
+		}
+	}
+	return nil, nil
+}
+
+// waitSettled polls the task until it is completed, canceled, failed or requires input
+func (g *TaskGroup) waitSettled(taskID string, task *types.Task) (*types.Task, error) {
+	for {
+		if task != nil && isSettled(task.Status.State) {
+			return task, nil
+		}
+		if task != nil {
+			select {
+			case <-g.ctx.Done():
+				return task, g.ctx.Err()
+			case <-time.After(g.pollInterval):
+			}
+		}
+
+		response, err := g.client.GetTask(g.ctx, map[string]interface{}{"id": taskID})
+		if err != nil {
+			return task, err
+		}
+		if response.Result == nil {
+			return task, fmt.Errorf("task %s not found", taskID)
+		}
+		task = response.Result
+	}
+}
+
+func (g *TaskGroup) setResult(result *TaskGroupResult, task *types.Task, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	result.Task = task
+	result.Err = err
+}
+
+// isSettled reports whether a task no longer progresses without outside action
+func isSettled(state types.TaskState) bool {
+	switch state {
+	case types.TaskCompleted, types.TaskCanceled, types.TaskFailed, types.TaskInputNeeded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskGroupCancelCancelsTasksOnTheAgent(t *testing.T) {
+	var lock sync.Mutex
+	var canceled []string
+	polled := make(chan struct{}, 1)
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     interface{}            `json:"id"`
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		state := "working"
+		switch request.Method {
+		case "get_task":
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+		case "cancel_task":
+			lock.Lock()
+			canceled = append(canceled, request.Params["id"].(string))
+			lock.Unlock()
+			state = "canceled"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  map[string]interface{}{"id": request.Params["id"], "status": map[string]interface{}{"state": state}},
+		})
+	}))
+	defer agent.Close()
+
+	c, err := NewA2AClient(nil, agent.URL)
+	if err != nil {
+		t.Fatalf("NewA2AClient: %v", err)
+	}
+	group := NewTaskGroup(context.Background(), c, WithGroupPollInterval(time.Millisecond))
+	err = group.Submit(&types.TaskSendParams{ID: "t1", Message: types.Message{Role: "user", Parts: types.Parts{types.TextPart{Type: "text", Text: "hi"}}}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	go func() {
+		for range group.Events() {
+		}
+	}()
+	<-polled
+	group.Cancel()
+	results := group.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(canceled) != 1 || canceled[0] != "t1" {
+		t.Fatalf("agent received cancel_task for %v, want t1", canceled)
+	}
+	if err := results["t1"].Err; !errors.Is(err, context.Canceled) {
+		t.Fatalf("result error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+)
+
+// WithTLSConfig sets the TLS settings of the client's connections, e.g. a minimum version or
+// the certificate presented to agents requiring mutual TLS. Egress rules with TLS settings
+// of their own take precedence for their destinations.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *A2AClient) {
+		c.tlsConfig = config
+	}
+}
+
+// WithRootCAs trusts the CAs in pool instead of the system roots for agent certificates,
+// e.g. for agents with certificates issued by a private CA
+func WithRootCAs(pool *x509.CertPool) ClientOption {
+	return func(c *A2AClient) {
+		c.rootCAs = pool
+	}
+}
+
+// WithClientCertificate presents the certificate and key in certFile and keyFile to agents
+// requiring mutual TLS
+func WithClientCertificate(certFile, keyFile string) ClientOption {
+	return func(c *A2AClient) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			c.optionErr = fmt.Errorf("failed to load client certificate: %w", err)
+			return
+		}
+		c.clientCerts = append(c.clientCerts, cert)
+	}
+}
+
+// configureTLS applies the client's TLS options to transport
+func (c *A2AClient) configureTLS(transport *http.Transport) {
+	if c.tlsConfig == nil && c.rootCAs == nil && len(c.clientCerts) == 0 {
+		return
+	}
+	config := &tls.Config{}
+	if c.tlsConfig != nil {
+		config = c.tlsConfig.Clone()
+	}
+	if c.rootCAs != nil {
+		config.RootCAs = c.rootCAs
+	}
+	config.Certificates = append(config.Certificates, c.clientCerts...)
+	transport.TLSClientConfig = config
+}
+
+// WithCardResolverTLSConfig sets the TLS settings used to fetch agent cards, e.g. the roots
+// trusted for the agent or a client certificate for mutual TLS
+func WithCardResolverTLSConfig(config *tls.Config) CardResolverOption {
+	return func(r *A2ACardResolver) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if current, ok := r.client.Transport.(*http.Transport); ok {
+			transport = current.Clone()
+		}
+		transport.TLSClientConfig = config.Clone()
+		r.client = &http.Client{Transport: transport}
+	}
+}
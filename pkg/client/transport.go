@@ -0,0 +1,137 @@
+package client
+
+import (
+	"a2a-go/pkg/utils"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDialTimeout    = 10 * time.Second
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// WithDialTimeout limits how long establishing a connection may take
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(c *A2AClient) {
+		c.dialTimeout = d
+	}
+}
+
+// WithResponseHeaderTimeout limits how long to wait for response headers after sending a request,
+// for both unary and streaming calls
+func WithResponseHeaderTimeout(d time.Duration) ClientOption {
+	return func(c *A2AClient) {
+		c.responseHeaderTimeout = d
+	}
+}
+
+// WithRequestTimeout limits the total duration of unary calls (default 30s); streams are not affected
+func WithRequestTimeout(d time.Duration) ClientOption {
+	return func(c *A2AClient) {
+		c.requestTimeout = d
+	}
+}
+
+// WithStreamIdleTimeout aborts SSE streams that produce no data for d
+func WithStreamIdleTimeout(d time.Duration) ClientOption {
+	return func(c *A2AClient) {
+		c.streamIdleTimeout = d
+	}
+}
+
+// WithEgressPolicy passes every request of the client through policy, which can block
+// destinations or require proxies and TLS settings per destination
+func WithEgressPolicy(policy utils.EgressPolicy) ClientOption {
+	return func(c *A2AClient) {
+		c.egress = policy
+	}
+}
+
+// WithTokens authorizes every request of the client, including the ones fetching files by
+// URI, with OAuth2 tokens for the destination's origin
+func WithTokens(tokens *utils.TokenManager) ClientOption {
+	return func(c *A2AClient) {
+		c.tokens = tokens
+	}
+}
+
+// buildHTTPClients creates the HTTP clients used for unary and streaming calls
+func (c *A2AClient) buildHTTPClients() {
+	dialer := &net.Dialer{
+		Timeout:   c.dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.ResponseHeaderTimeout = c.responseHeaderTimeout
+	c.configureTLS(transport)
+	for _, configure := range c.transportOptions {
+		configure(transport)
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if c.egress != nil {
+		roundTripper = utils.NewEgressTransport(c.egress, transport)
+	}
+	if c.tokens != nil {
+		roundTripper = utils.NewAuthTransport(c.tokens, roundTripper)
+	}
+
+	c.httpClient = &http.Client{
+		Transport: roundTripper,
+		Timeout:   c.requestTimeout,
+	}
+	c.streamClient = &http.Client{
+		Transport: roundTripper,
+		Timeout:   0, // Streams are bounded by the idle timeout instead
+	}
+}
+
+// idleTimeoutReader cancels a stream when no data was read for the idle timeout
+type idleTimeoutReader struct {
+	reader  io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+
+	lock    sync.Mutex
+	expired bool
+}
+
+// newIdleTimeoutReader wraps r so cancel is called after timeout without data; returns r if timeout is 0
+func newIdleTimeoutReader(r io.Reader, timeout time.Duration, cancel context.CancelFunc) (io.Reader, func() bool) {
+	if timeout <= 0 {
+		return r, func() bool { return false }
+	}
+	ir := &idleTimeoutReader{reader: r, timeout: timeout}
+	ir.timer = time.AfterFunc(timeout, func() {
+		ir.lock.Lock()
+		ir.expired = true
+		ir.lock.Unlock()
+		cancel()
+	})
+	return ir, ir.timedOut
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil {
+		r.timer.Stop()
+	}
+	return n, err
+}
+
+// timedOut reports whether the idle timeout fired
+func (r *idleTimeoutReader) timedOut() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.expired
+}
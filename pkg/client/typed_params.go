@@ -0,0 +1,15 @@
+package client
+
+//go:generate go run ../../internal/methodgen -side client -o methods_gen.go
+
+// The generated <Method>WithParams methods take typed params instead of payload maps. Params
+// are validated before anything is sent; invalid params fail with *types.ValidationError
+// errors, joined when there are several.
+
+// validPayload validates typed params and converts them into a payload map
+func validPayload(params interface{ Validate() error }) (map[string]interface{}, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return toPayload(params)
+}
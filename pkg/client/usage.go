@@ -0,0 +1,136 @@
+package client
+
+import (
+	"a2a-go/pkg/types"
+	"context"
+	"sync"
+)
+
+// UsageReport describes a change in the usage an agent reported for a task
+type UsageReport struct {
+	TaskID    string
+	SessionID string
+	Task      types.Usage // Cumulative usage of the task as last reported by the agent
+	Delta     types.Usage // Usage added since the previous report of the task
+	Session   types.Usage // Cumulative usage of all tasks of the session seen by this client
+}
+
+// UsageFunc receives usage reports; it is called from the goroutine that received the task or event
+type UsageFunc func(UsageReport)
+
+// WithUsageCallback registers a callback invoked whenever a task or streaming event carries
+// new usage metadata (see types.UsageMetadataKey)
+func WithUsageCallback(fn UsageFunc) ClientOption {
+	return func(c *A2AClient) {
+		c.onUsage = fn
+	}
+}
+
+// usageLedger aggregates the cumulative usage agents report per task into per-session totals
+type usageLedger struct {
+	lock     sync.Mutex
+	tasks    map[string]types.Usage
+	sessions map[string]types.Usage
+	total    types.Usage
+}
+
+// record stores the cumulative usage of a task and returns the resulting report,
+// or false if nothing changed since the last report
+func (l *usageLedger) record(taskID, sessionID string, usage types.Usage) (UsageReport, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.tasks == nil {
+		l.tasks = make(map[string]types.Usage)
+		l.sessions = make(map[string]types.Usage)
+	}
+	previous, seen := l.tasks[taskID]
+	if seen && previous == usage {
+		return UsageReport{}, false
+	}
+	delta := usage.Sub(previous)
+	l.tasks[taskID] = usage
+	l.sessions[sessionID] = l.sessions[sessionID].Add(delta)
+	l.total = l.total.Add(delta)
+	return UsageReport{
+		TaskID:    taskID,
+		SessionID: sessionID,
+		Task:      usage,
+		Delta:     delta,
+		Session:   l.sessions[sessionID],
+	}, true
+}
+
+// SessionUsage returns the aggregated usage of all tasks of a session received by this client
+func (c *A2AClient) SessionUsage(sessionID string) types.Usage {
+	c.usage.lock.Lock()
+	defer c.usage.lock.Unlock()
+	return c.usage.sessions[sessionID]
+}
+
+// TotalUsage returns the aggregated usage of all tasks received by this client
+func (c *A2AClient) TotalUsage() types.Usage {
+	c.usage.lock.Lock()
+	defer c.usage.lock.Unlock()
+	return c.usage.total
+}
+
+// recordUsage records the usage carried by metadata and notifies the usage callback
+func (c *A2AClient) recordUsage(taskID, sessionID string, metadata map[string]interface{}) {
+	usage, ok := types.UsageFromMetadata(metadata)
+	if !ok || taskID == "" {
+		return
+	}
+	report, changed := c.usage.record(taskID, sessionID, usage)
+	if changed && c.onUsage != nil {
+		c.onUsage(report)
+	}
+}
+
+// recordTaskUsage records the usage of a task received from the agent
+func (c *A2AClient) recordTaskUsage(task *types.Task) {
+	if task == nil {
+		return
+	}
+	sessionID := ""
+	if task.SessionID != nil {
+		sessionID = *task.SessionID
+	}
+	c.recordUsage(task.ID, sessionID, task.Metadata)
+}
+
+// observeStreamUsage relays a stream until ctx is canceled, recording the usage carried by its events
+func (c *A2AClient) observeStreamUsage(ctx context.Context, sessionID string, responses chan *types.SendTaskStreamingResponse) chan *types.SendTaskStreamingResponse {
+	relayed := make(chan *types.SendTaskStreamingResponse)
+	go func() {
+		defer close(relayed)
+		for response := range responses {
+			c.recordStreamUsage(sessionID, response)
+			select {
+			case relayed <- response:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return relayed
+}
+
+// recordStreamUsage records the usage of a streamed task, status or artifact event
+func (c *A2AClient) recordStreamUsage(sessionID string, response *types.SendTaskStreamingResponse) {
+	switch result := response.Result.(type) {
+	case *types.Task:
+		c.recordTaskUsage(result)
+	case *types.TaskStatusUpdateEvent:
+		c.recordUsage(result.ID, sessionID, result.Metadata)
+	case *types.TaskArtifactUpdateEvent:
+		c.recordUsage(result.ID, sessionID, result.Metadata)
+	case map[string]interface{}:
+		taskID, _ := result["id"].(string)
+		if id, ok := result["sessionId"].(string); ok {
+			sessionID = id
+		}
+		metadata, _ := result["metadata"].(map[string]interface{})
+		c.recordUsage(taskID, sessionID, metadata)
+	}
+}
@@ -0,0 +1,52 @@
+// Package cbor registers a CBOR codec; import it for its side effect
+// to let clients and servers negotiate application/cbor
+package cbor
+
+import (
+	"reflect"
+
+	"a2a-go/pkg/codec"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ContentType is the media type of the CBOR codec
+const ContentType = "application/cbor"
+
+// Codec encodes bodies as CBOR; struct fields use their json tags
+var Codec codec.Codec = newCBORCodec()
+
+func init() {
+	codec.Register(Codec)
+}
+
+type cborCodec struct {
+	enc cbor.EncMode
+	dec cbor.DecMode
+}
+
+func newCBORCodec() *cborCodec {
+	enc, err := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if err != nil {
+		panic("cbor: invalid encoding options: " + err.Error())
+	}
+	dec, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		panic("cbor: invalid decoding options: " + err.Error())
+	}
+	return &cborCodec{enc: enc, dec: dec}
+}
+
+func (c *cborCodec) ContentType() string {
+	return ContentType
+}
+
+func (c *cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return c.enc.Marshal(v)
+}
+
+func (c *cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.dec.Unmarshal(data, v)
+}
@@ -0,0 +1,108 @@
+// Package codec abstracts the wire encoding of JSON-RPC bodies. JSON is the spec-compliant
+// default; other codecs (see the msgpack and cbor subpackages) are negotiated through the
+// Content-Type and Accept headers. SSE streams always carry JSON.
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// JSONContentType is the content type of the default codec
+const JSONContentType = "application/json"
+
+// Codec encodes and decodes request and response bodies
+type Codec interface {
+	// ContentType is the media type identifying the codec in HTTP headers
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data; maps decoded into interface{} values must have string keys
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return JSONContentType
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// JSON is the default codec
+var JSON Codec = jsonCodec{}
+
+var (
+	registry     = map[string]Codec{JSONContentType: JSON}
+	registryLock sync.RWMutex
+)
+
+// Register makes a codec available for negotiation; codec packages register themselves when imported
+func Register(c Codec) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[c.ContentType()] = c
+}
+
+// Lookup returns the registered codec for a Content-Type header value
+func Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	c, ok := registry[mediaType]
+	return c, ok
+}
+
+// IsJSON reports whether c is the JSON codec
+func IsJSON(c Codec) bool {
+	return c == nil || c.ContentType() == JSONContentType
+}
+
+// Negotiate picks the first codec of an Accept header that is registered, or fallback
+func Negotiate(accept string, fallback Codec) Codec {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mediaType == "" || mediaType == "*/*" {
+			continue
+		}
+		if c, ok := Lookup(mediaType); ok {
+			return c
+		}
+	}
+	return fallback
+}
+
+// ToJSON transcodes a body encoded with c into JSON
+func ToJSON(c Codec, data []byte) ([]byte, error) {
+	if IsJSON(c) {
+		return data, nil
+	}
+	var v interface{}
+	if err := c.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode %s body: %w", c.ContentType(), err)
+	}
+	return json.Marshal(v)
+}
+
+// FromJSON transcodes a JSON body into the encoding of c
+func FromJSON(c Codec, data []byte) ([]byte, error) {
+	if IsJSON(c) {
+		return data, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON body: %w", err)
+	}
+	return c.Marshal(v)
+}
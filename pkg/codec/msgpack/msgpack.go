@@ -0,0 +1,44 @@
+// Package msgpack registers a MessagePack codec; import it for its side effect
+// to let clients and servers negotiate application/msgpack
+package msgpack
+
+import (
+	"bytes"
+
+	"a2a-go/pkg/codec"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the media type of the MessagePack codec
+const ContentType = "application/msgpack"
+
+// Codec encodes bodies as MessagePack, honoring json struct tags
+var Codec codec.Codec = msgpackCodec{}
+
+func init() {
+	codec.Register(Codec)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string {
+	return ContentType
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
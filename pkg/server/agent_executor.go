@@ -0,0 +1,330 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"a2a-go/pkg/types"
+)
+
+// RequestContext describes the message an AgentExecutor handles
+type RequestContext struct {
+	TaskID    string
+	SessionID string
+	Message   types.Message         // Message that started or continued the task
+	Task      *types.Task           // Snapshot of the task when execution started, including Message
+	Params    *types.TaskSendParams // Params of the request, e.g. for its metadata and accepted output modes
+	Resumed   bool                  // Set when the execution continues a task paused or yielded during an earlier one
+}
+
+// ErrTaskPaused is returned by an AgentExecutor that stopped because its task was paused.
+// The executor runs again with RequestContext.Resumed set once the task is resumed.
+var ErrTaskPaused = errors.New("task paused")
+
+// EventQueue publishes the progress of the task an AgentExecutor runs. Updates are stored on
+// the task and sent to its streams, pollers and push notification URL.
+type EventQueue interface {
+	// UpdateStatus moves the task to state with an optional agent message. A final state
+	// ends the task and input-required ends the execution until the next message; it fails
+	// once the task has ended, e.g. because it was canceled.
+	UpdateStatus(ctx context.Context, state types.TaskState, message *types.Message) error
+	// AddArtifact stores a complete artifact at the next free index
+	AddArtifact(ctx context.Context, artifact types.Artifact) error
+	// AwaitInput moves the task to input-required with prompt and returns the next message
+	// sent to the task
+	AwaitInput(ctx context.Context, prompt types.Message) (types.Message, error)
+	// Paused reports whether the task was paused through tasks/pause. The executor should
+	// stop at its next checkpoint: wait in AwaitResume, or save its progress and return
+	// ErrTaskPaused to run again once the task is resumed. Status updates fail meanwhile.
+	Paused(ctx context.Context) bool
+	// AwaitResume blocks while the task is paused, until it is resumed or ctx is done
+	AwaitResume(ctx context.Context) error
+	// ShouldYield reports whether the execution should make way for higher-priority tasks
+	// waiting on the worker pool, see WithPreemptAfter. The executor should save its progress
+	// and return ErrJobPaused to run again, with RequestContext.Resumed set, once it's their turn.
+	ShouldYield() bool
+}
+
+// AgentExecutor implements the business logic of an agent run by a DefaultRequestHandler
+type AgentExecutor interface {
+	// Execute handles a message sent to a task, publishing its progress to queue. When it
+	// returns without setting a final state or input-required, the task completes, or fails
+	// with the message of the returned error. ctx is canceled when the task is canceled.
+	Execute(ctx context.Context, reqCtx RequestContext, queue EventQueue) error
+	// Cancel stops the execution of a task being canceled, before it is marked canceled
+	Cancel(ctx context.Context, reqCtx RequestContext) error
+}
+
+// DefaultRequestHandler is a TaskManager running an AgentExecutor for every message sent to
+// it, so agents only implement their business logic. It stores the tasks, streams the events
+// the executor publishes, hands follow-up messages to executors awaiting input and saves the
+// push notification config sent with a task; notifications are delivered when it is
+// configured WithPushDelivery.
+type DefaultRequestHandler struct {
+	*InMemoryTaskManager
+	executor AgentExecutor
+}
+
+// NewDefaultRequestHandler creates a DefaultRequestHandler running executor
+func NewDefaultRequestHandler(executor AgentExecutor, opts ...TaskManagerOption) *DefaultRequestHandler {
+	return &DefaultRequestHandler{
+		InMemoryTaskManager: NewInMemoryTaskManager(opts...),
+		executor:            executor,
+	}
+}
+
+// OnSendTask runs the executor and returns the task once it has ended or awaits input. The
+// executor keeps running if the client disconnects.
+func (h *DefaultRequestHandler) OnSendTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskResponse, error) {
+	params, err := decodeSendParams(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	reqCtx, run, err := h.prepare(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	subscriber, err := h.setupSSEConsumer(waitCtx, reqCtx.TaskID, false)
+	if err != nil {
+		return nil, err
+	}
+	events := h.dequeueEventsForSSE(waitCtx, request.ID, reqCtx.TaskID, subscriber)
+	if run {
+		h.start(ctx, reqCtx)
+	}
+	if run || h.running(ctx, reqCtx.TaskID) {
+		// The stream ends with the final event, sent when the task ends or awaits input
+		for range events {
+		}
+	}
+
+	task, err := h.getTask(ctx, reqCtx.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SendTaskResponse{Result: h.appendTaskHistory(task, params.HistoryLength)}, nil
+}
+
+// OnSendTaskSubscribe runs the executor and returns the final status of the task, for
+// servers that don't stream through TaskStreamer
+func (h *DefaultRequestHandler) OnSendTaskSubscribe(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
+	response, err := h.OnSendTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	task := response.Result
+	return &types.SendTaskStreamingResponse{
+		ID: request.ID,
+		Result: &types.TaskStatusUpdateEvent{
+			ID:     task.ID,
+			Status: task.Status,
+			Final:  true,
+		},
+	}, nil
+}
+
+// OnSendTaskStream starts the executor and streams the task's events while it runs. The
+// executor keeps running if the client disconnects.
+func (h *DefaultRequestHandler) OnSendTaskStream(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error) {
+	params, err := decodeSendParams(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	reqCtx, run, err := h.prepare(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := h.setupSSEConsumer(ctx, reqCtx.TaskID, false)
+	if err != nil {
+		return nil, err
+	}
+	if !run && !h.running(ctx, reqCtx.TaskID) {
+		// Nothing will happen to the task; send its current status
+		h.removeSSEConsumer(subscriberKey(ctx, reqCtx.TaskID), subscriber)
+		task, err := h.getTask(ctx, reqCtx.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		responses := make(chan *types.SendTaskStreamingResponse, 1)
+		responses <- &types.SendTaskStreamingResponse{
+			ID:     request.ID,
+			Result: &types.TaskStatusUpdateEvent{ID: task.ID, Status: task.Status, Final: true},
+		}
+		close(responses)
+		return responses, nil
+	}
+	if run {
+		h.start(ctx, reqCtx)
+	}
+	return h.dequeueEventsForSSE(ctx, request.ID, reqCtx.TaskID, subscriber), nil
+}
+
+// running reports whether an executor is working on a task
+func (h *DefaultRequestHandler) running(ctx context.Context, taskID string) bool {
+	state := h.taskStatus(ctx, taskID).State
+	return state == types.TaskWorking || state == types.TaskSubmitted
+}
+
+// prepare stores the message sent with params and reports whether the executor should run
+// for it. It doesn't for messages handed to an executor awaiting input, or sent to tasks
+// that have ended.
+func (h *DefaultRequestHandler) prepare(ctx context.Context, params *types.TaskSendParams) (RequestContext, bool, error) {
+	task, resumed, err := h.ResumeWithInput(ctx, params)
+	if err != nil {
+		return RequestContext{}, false, err
+	}
+	if !resumed {
+		if task, err = h.upsertTask(ctx, params); err != nil {
+			return RequestContext{}, false, err
+		}
+	}
+	if params.PushNotification != nil {
+		if err := h.setPushNotificationInfo(ctx, task.ID, params.PushNotification); err != nil {
+			return RequestContext{}, false, err
+		}
+	}
+
+	snapshot, err := h.getTask(ctx, task.ID)
+	if err != nil {
+		return RequestContext{}, false, err
+	}
+	reqCtx := RequestContext{
+		TaskID:    snapshot.ID,
+		SessionID: params.SessionID,
+		Message:   params.Message,
+		Task:      snapshot,
+		Params:    params,
+	}
+	return reqCtx, !resumed && !isTerminalState(snapshot.Status.State), nil
+}
+
+// start runs the executor for a message in the background, on the worker pool if there is one.
+// The execution outlives ctx, the request's context.
+func (h *DefaultRequestHandler) start(ctx context.Context, reqCtx RequestContext) {
+	ctx = context.WithoutCancel(ctx)
+	if h.workerPool == nil {
+		go h.execute(ctx, reqCtx, nil)
+		return
+	}
+
+	fail := func(err error) {
+		h.setStatus(ctx, reqCtx.TaskID, types.TaskFailed, err.Error(), true)
+	}
+	err := h.workerPool.Submit(&Job{
+		TaskID:   reqCtx.TaskID,
+		Priority: PriorityFromMetadata(reqCtx.Params.Metadata),
+		Run: func(poolCtx context.Context, checkpoint *Checkpoint) error {
+			// Closing the pool cancels the execution like canceling the task
+			runCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(poolCtx, cancel)
+			defer stop()
+
+			if err := h.execute(runCtx, reqCtx, checkpoint); errors.Is(err, ErrJobPaused) {
+				// Requeued; the next run continues from the task's progress
+				reqCtx.Resumed = true
+				if task, err := h.getTask(ctx, reqCtx.TaskID); err == nil {
+					reqCtx.Task = task
+				}
+				return err
+			}
+			if poolCtx.Err() != nil && h.running(ctx, reqCtx.TaskID) {
+				fail(ErrWorkerPoolClosed)
+			}
+			return nil
+		},
+		Fail: fail,
+	})
+	if err != nil {
+		fail(err)
+	}
+}
+
+// execute runs the executor for a message until it returns and settles the task's state. It
+// returns ErrJobPaused if the executor yielded at checkpoint, the worker pool's, leaving the
+// task working.
+func (h *DefaultRequestHandler) execute(ctx context.Context, reqCtx RequestContext, checkpoint *Checkpoint) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	unregister := h.OnCancel(ctx, reqCtx.TaskID, func(ctx context.Context, task *types.Task) error {
+		defer cancel()
+		return h.executor.Cancel(ctx, reqCtx)
+	})
+	defer unregister()
+
+	queue := &taskEventQueue{tm: h.InMemoryTaskManager, taskID: reqCtx.TaskID, checkpoint: checkpoint}
+	if err := queue.UpdateStatus(runCtx, types.TaskWorking, nil); err != nil {
+		log.Printf("Failed to start task %s: %v", reqCtx.TaskID, err)
+		return nil
+	}
+	err := h.executor.Execute(runCtx, reqCtx, queue)
+
+	switch {
+	case runCtx.Err() != nil:
+		// Canceled
+	case errors.Is(err, ErrTaskPaused), h.IsPaused(ctx, reqCtx.TaskID):
+		h.resumeLater(ctx, reqCtx)
+	case errors.Is(err, ErrJobPaused) && checkpoint != nil && h.running(ctx, reqCtx.TaskID):
+		return ErrJobPaused
+	case !h.running(ctx, reqCtx.TaskID):
+		// Ended by the executor or waiting for the next message
+	case err != nil:
+		h.setStatus(ctx, reqCtx.TaskID, types.TaskFailed, err.Error(), true)
+	default:
+		h.setStatus(ctx, reqCtx.TaskID, types.TaskCompleted, "", true)
+	}
+	return nil
+}
+
+// resumeLater runs the executor again once a task its execution stopped for is resumed,
+// unless the executor registered a resume checkpoint of its own
+func (h *DefaultRequestHandler) resumeLater(ctx context.Context, reqCtx RequestContext) {
+	reqCtx.Resumed = true
+	state := h.setResumeCheckpointIfPaused(ctx, reqCtx.TaskID, func(ctx context.Context, task *types.Task) error {
+		reqCtx.Task = task
+		h.start(ctx, reqCtx)
+		return nil
+	}, false)
+	if state == types.TaskWorking {
+		// Resumed before the executor returned
+		h.start(ctx, reqCtx)
+	}
+}
+
+// taskEventQueue is the EventQueue of one task of an InMemoryTaskManager
+type taskEventQueue struct {
+	tm         *InMemoryTaskManager
+	taskID     string
+	checkpoint *Checkpoint // Of the worker pool job running the execution, if any
+}
+
+func (q *taskEventQueue) UpdateStatus(ctx context.Context, state types.TaskState, message *types.Message) error {
+	// Input-required ends the stream like a final state: the executor handles the next
+	// message in a new execution
+	final := isTerminalState(state) || state == types.TaskInputNeeded
+	return q.tm.publishStatus(ctx, q.taskID, state, message, final)
+}
+
+func (q *taskEventQueue) AddArtifact(ctx context.Context, artifact types.Artifact) error {
+	return q.tm.addArtifact(ctx, q.taskID, artifact)
+}
+
+func (q *taskEventQueue) AwaitInput(ctx context.Context, prompt types.Message) (types.Message, error) {
+	return q.tm.AwaitInput(ctx, q.taskID, prompt)
+}
+
+func (q *taskEventQueue) Paused(ctx context.Context) bool {
+	return q.tm.IsPaused(ctx, q.taskID)
+}
+
+func (q *taskEventQueue) AwaitResume(ctx context.Context) error {
+	return q.tm.AwaitResume(ctx, q.taskID)
+}
+
+func (q *taskEventQueue) ShouldYield() bool {
+	return q.checkpoint != nil && q.checkpoint.ShouldYield()
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// inputExecutor asks for input through the queue and records the messages it handles
+type inputExecutor struct {
+	await    bool // Wait for the input with AwaitInput instead of returning
+	messages chan types.Message
+}
+
+func (e *inputExecutor) Execute(ctx context.Context, reqCtx RequestContext, queue EventQueue) error {
+	e.messages <- reqCtx.Message
+	prompt := types.Message{Role: "agent", Parts: types.Parts{types.TextPart{Type: "text", Text: "which one?"}}}
+	if !e.await {
+		return queue.UpdateStatus(ctx, types.TaskInputNeeded, &prompt)
+	}
+	answer, err := queue.AwaitInput(ctx, prompt)
+	if err != nil {
+		return err
+	}
+	e.messages <- answer
+	return nil
+}
+
+func (e *inputExecutor) Cancel(ctx context.Context, reqCtx RequestContext) error {
+	return nil
+}
+
+func textMessage(text string) types.Message {
+	return types.Message{Role: "user", Parts: types.Parts{types.TextPart{Type: "text", Text: text}}}
+}
+
+// sendTask calls OnSendTask, failing the test if it doesn't return within a few seconds
+func sendTask(t *testing.T, h *DefaultRequestHandler, params *types.TaskSendParams) *types.Task {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.OnSendTask(ctx, &types.JSONRPCRequest{Params: params})
+	if err != nil {
+		t.Fatalf("OnSendTask: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("OnSendTask returned only after the timeout")
+	}
+	return response.Result
+}
+
+func TestDefaultRequestHandlerUpdateStatusInputRequired(t *testing.T) {
+	executor := &inputExecutor{messages: make(chan types.Message, 4)}
+	h := NewDefaultRequestHandler(executor)
+
+	task := sendTask(t, h, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("start")})
+	if task.Status.State != types.TaskInputNeeded {
+		t.Fatalf("state = %s, want %s", task.Status.State, types.TaskInputNeeded)
+	}
+
+	// The follow-up message starts a new execution
+	task = sendTask(t, h, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("the first")})
+	if task.Status.State != types.TaskInputNeeded {
+		t.Fatalf("state after follow-up = %s, want %s", task.Status.State, types.TaskInputNeeded)
+	}
+	if len(executor.messages) != 2 {
+		t.Fatalf("executor ran %d times, want 2", len(executor.messages))
+	}
+}
+
+func TestDefaultRequestHandlerAwaitInput(t *testing.T) {
+	executor := &inputExecutor{await: true, messages: make(chan types.Message, 4)}
+	h := NewDefaultRequestHandler(executor)
+
+	task := sendTask(t, h, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("start")})
+	if task.Status.State != types.TaskInputNeeded {
+		t.Fatalf("state = %s, want %s", task.Status.State, types.TaskInputNeeded)
+	}
+
+	task = sendTask(t, h, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("the first")})
+	if task.Status.State != types.TaskCompleted {
+		t.Fatalf("state after answer = %s, want %s", task.Status.State, types.TaskCompleted)
+	}
+	<-executor.messages
+	if answer := <-executor.messages; answer.Parts[0].(types.TextPart).Text != "the first" {
+		t.Fatalf("executor received %+v, want the answer", answer)
+	}
+}
+
+// pauseExecutor waits until its task is paused, then either awaits the resume or returns
+// ErrTaskPaused to run again
+type pauseExecutor struct {
+	await     bool
+	runs      chan RequestContext
+	updateErr chan error // Result of a status update while paused
+}
+
+func (e *pauseExecutor) Execute(ctx context.Context, reqCtx RequestContext, queue EventQueue) error {
+	e.runs <- reqCtx
+	if reqCtx.Resumed {
+		return nil
+	}
+	for !queue.Paused(ctx) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	e.updateErr <- queue.UpdateStatus(ctx, types.TaskWorking, nil)
+	if e.await {
+		return queue.AwaitResume(ctx)
+	}
+	return ErrTaskPaused
+}
+
+func (e *pauseExecutor) Cancel(ctx context.Context, reqCtx RequestContext) error {
+	return nil
+}
+
+func TestDefaultRequestHandlerPauseAndResume(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		await    bool
+		wantRuns int
+	}{
+		{"executor runs again", false, 2},
+		{"executor awaits the resume", true, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			executor := &pauseExecutor{await: test.await, runs: make(chan RequestContext, 4), updateErr: make(chan error, 1)}
+			h := NewDefaultRequestHandler(executor)
+
+			result := sendTaskAsync(h, &types.TaskSendParams{ID: "t1", Message: textMessage("go")})
+			<-executor.runs
+			if _, err := h.OnPauseTask(ctx, &types.JSONRPCRequest{Params: &types.TaskIdParams{ID: "t1"}}); err != nil {
+				t.Fatalf("OnPauseTask: %v", err)
+			}
+			if err := <-executor.updateErr; err == nil {
+				t.Fatal("status update of a paused task succeeded")
+			}
+			if state := h.taskStatus(ctx, "t1").State; state != types.TaskSuspended {
+				t.Fatalf("state = %s, want %s", state, types.TaskSuspended)
+			}
+			if _, err := h.OnResumeTask(ctx, &types.JSONRPCRequest{Params: &types.TaskIdParams{ID: "t1"}}); err != nil {
+				t.Fatalf("OnResumeTask: %v", err)
+			}
+
+			select {
+			case task := <-result:
+				if task == nil || task.Status.State != types.TaskCompleted {
+					t.Fatalf("task = %+v, want it completed", task)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("task didn't complete after resuming")
+			}
+			if runs := 1 + len(executor.runs); runs != test.wantRuns {
+				t.Fatalf("executor ran %d times, want %d", runs, test.wantRuns)
+			}
+			if test.wantRuns > 1 {
+				if rerun := <-executor.runs; !rerun.Resumed {
+					t.Fatal("second execution isn't marked resumed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"a2a-go/pkg/types"
+)
+
+// ErrDuplicateArtifactIndex is returned when an artifact reuses the index of a stored
+// artifact without setting append
+var ErrDuplicateArtifactIndex = &TaskError{Code: InvalidParamsErrorCode, Message: "Duplicate artifact index"}
+
+// duplicateArtifactIndex returns ErrDuplicateArtifactIndex for index of a task
+func duplicateArtifactIndex(taskID string, index int) error {
+	return &TaskError{
+		Code:    InvalidParamsErrorCode,
+		Message: "Duplicate artifact index",
+		Data:    &types.ArtifactErrorData{TaskID: taskID, Index: index},
+	}
+}
+
+// mergeArtifacts applies incoming artifacts to the stored ones following the Index
+// semantics of types.Artifact: appends extend the artifact at their index, other
+// artifacts must use a new index. The result is sorted by index; stored is not modified.
+func mergeArtifacts(taskID string, stored, incoming []types.Artifact) ([]types.Artifact, error) {
+	merged := append([]types.Artifact(nil), stored...)
+	positions := make(map[int]int, len(merged)+len(incoming))
+	for i, artifact := range merged {
+		positions[artifact.Index] = i
+	}
+
+	for _, artifact := range incoming {
+		i, exists := positions[artifact.Index]
+		switch {
+		case !exists:
+			positions[artifact.Index] = len(merged)
+			merged = append(merged, artifact)
+		case artifact.Append != nil && *artifact.Append:
+			existing := merged[i]
+			existing.Parts = append(append(types.Parts(nil), existing.Parts...), artifact.Parts...)
+			existing.LastChunk = artifact.LastChunk
+			if len(artifact.Metadata) > 0 {
+				metadata := make(map[string]interface{}, len(existing.Metadata)+len(artifact.Metadata))
+				for k, v := range existing.Metadata {
+					metadata[k] = v
+				}
+				for k, v := range artifact.Metadata {
+					metadata[k] = v
+				}
+				existing.Metadata = metadata
+			}
+			merged[i] = existing
+		default:
+			return nil, duplicateArtifactIndex(taskID, artifact.Index)
+		}
+	}
+
+	sortArtifacts(merged)
+	return merged, nil
+}
+
+// sortArtifacts orders artifacts by index, keeping the order of equal indices
+func sortArtifacts(artifacts []types.Artifact) {
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		return artifacts[i].Index < artifacts[j].Index
+	})
+}
+
+// NextArtifactIndex reserves the next free artifact index of a task. It is safe to call
+// from concurrent goroutines of a handler; each call returns a different index, above
+// every index stored on the task so far.
+func (tm *InMemoryTaskManager) NextArtifactIndex(ctx context.Context, taskID string) (int, error) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	task := store.tasks[taskID]
+	if task == nil {
+		return 0, taskNotFound(taskID)
+	}
+
+	next := store.artifactIndices[taskID]
+	for _, artifact := range task.Artifacts {
+		if artifact.Index >= next {
+			next = artifact.Index + 1
+		}
+	}
+	store.artifactIndices[taskID] = next + 1
+	return next, nil
+}
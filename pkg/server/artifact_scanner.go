@@ -0,0 +1,319 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// ScanDirection tells whether scanned content comes from a client or goes to one
+type ScanDirection string
+
+const (
+	// ScanIncoming is set for the file parts of messages sent by clients
+	ScanIncoming ScanDirection = "incoming"
+	// ScanOutgoing is set for the parts of artifacts produced by handlers
+	ScanOutgoing ScanDirection = "outgoing"
+)
+
+// ScanPolicy is what happens to content a scanner rejects
+type ScanPolicy int
+
+const (
+	// ScanFailTask rejects messages with flagged files, and fails tasks producing flagged artifacts
+	ScanFailTask ScanPolicy = iota
+	// ScanStripPart removes flagged parts and keeps the rest
+	ScanStripPart
+)
+
+// ScanTarget is a part handed to an ArtifactScanner
+type ScanTarget struct {
+	TaskID    string
+	Direction ScanDirection
+	Part      any               // The part itself
+	Content   types.PartContent // The decoded part
+	// Open returns the content of the part: the text of text parts, the bytes of file parts
+	// and the JSON of data parts
+	Open func() (io.ReadCloser, error)
+}
+
+// ScanFinding is the error scanners return for content they reject
+type ScanFinding struct {
+	Scanner string // E.g. "clamav"
+	Reason  string // E.g. the name of the signature that matched
+}
+
+func (f *ScanFinding) Error() string {
+	return fmt.Sprintf("%s: %s", f.Scanner, f.Reason)
+}
+
+// ArtifactScanner checks parts for malware, leaked secrets and the like. It returns a
+// *ScanFinding for rejected content; any other error also rejects the part, so content
+// isn't let through unscanned.
+type ArtifactScanner interface {
+	Scan(ctx context.Context, target *ScanTarget) error
+}
+
+// ArtifactScannerFunc adapts a function to the ArtifactScanner interface
+type ArtifactScannerFunc func(ctx context.Context, target *ScanTarget) error
+
+func (f ArtifactScannerFunc) Scan(ctx context.Context, target *ScanTarget) error {
+	return f(ctx, target)
+}
+
+// WithArtifactScanner scans the file parts of incoming messages and every part of outgoing
+// artifacts with scanner, handling rejected parts according to policy. Streamed artifacts
+// are scanned chunk by chunk before the chunks are sent, and again once complete.
+func WithArtifactScanner(scanner ArtifactScanner, policy ScanPolicy) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.scanner = scanner
+		tm.scanPolicy = policy
+	}
+}
+
+// scanPart scans one part, returning the reason it was rejected or nil
+func (tm *InMemoryTaskManager) scanPart(ctx context.Context, taskID string, direction ScanDirection, part any) error {
+	content, ok := types.DecodePart(part)
+	if !ok {
+		return nil
+	}
+	target := &ScanTarget{
+		TaskID:    taskID,
+		Direction: direction,
+		Part:      part,
+		Content:   content,
+		Open: func() (io.ReadCloser, error) {
+			switch {
+			case content.File != nil:
+				return tm.OpenFile(ctx, content.File)
+			case content.Data != nil:
+				return io.NopCloser(strings.NewReader(encodeData(content.Data))), nil
+			}
+			return io.NopCloser(strings.NewReader(content.Text)), nil
+		},
+	}
+	err := tm.scanner.Scan(ctx, target)
+	var finding *ScanFinding
+	if err != nil && !errors.As(err, &finding) {
+		log.Printf("Scanning a part of task %s failed: %v", taskID, err)
+	}
+	return err
+}
+
+// scanParts scans parts, returning the parts to keep. With ScanFailTask the first
+// rejection is returned as an error instead.
+func (tm *InMemoryTaskManager) scanParts(ctx context.Context, taskID string, direction ScanDirection, parts types.Parts, filesOnly bool) (types.Parts, error) {
+	kept := parts[:0:0]
+	for _, part := range parts {
+		if filesOnly {
+			if content, ok := types.DecodePart(part); !ok || content.File == nil {
+				kept = append(kept, part)
+				continue
+			}
+		}
+		if err := tm.scanPart(ctx, taskID, direction, part); err != nil {
+			if tm.scanPolicy == ScanFailTask {
+				return nil, err
+			}
+			log.Printf("Removed a part of task %s rejected by the scanner: %v", taskID, err)
+			continue
+		}
+		kept = append(kept, part)
+	}
+	return kept, nil
+}
+
+// scanMessage scans the file parts of a message sent by a client
+func (tm *InMemoryTaskManager) scanMessage(ctx context.Context, taskID string, message *types.Message) error {
+	if tm.scanner == nil {
+		return nil
+	}
+	parts, err := tm.scanParts(ctx, taskID, ScanIncoming, message.Parts, true)
+	if err != nil {
+		return &TaskError{
+			Code:    InvalidParamsErrorCode,
+			Message: fmt.Sprintf("Invalid params: a file was rejected: %v", err),
+			Data:    &types.TaskErrorData{TaskID: taskID},
+		}
+	}
+	message.Parts = parts
+	return nil
+}
+
+// scanArtifacts scans the artifacts produced for a task, dropping artifacts left without parts
+func (tm *InMemoryTaskManager) scanArtifacts(ctx context.Context, taskID string, artifacts []types.Artifact) ([]types.Artifact, error) {
+	if tm.scanner == nil || artifacts == nil {
+		return artifacts, nil
+	}
+	scanned := make([]types.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		parts, err := tm.scanParts(ctx, taskID, ScanOutgoing, artifact.Parts, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == 0 && len(artifact.Parts) > 0 {
+			continue
+		}
+		artifact.Parts = parts
+		scanned = append(scanned, artifact)
+	}
+	return scanned, nil
+}
+
+// rejectedStatus is the status of a task that produced an artifact the scanner rejected
+func (tm *InMemoryTaskManager) rejectedStatus(err error) types.TaskStatus {
+	return types.TaskStatus{
+		State: types.TaskFailed,
+		Message: &types.Message{
+			Role:  "agent",
+			Parts: []types.Part{types.TextPart{Type: "text", Text: fmt.Sprintf("An artifact was rejected: %v", err)}},
+		},
+		Timestamp: tm.clock.Now().Format(time.RFC3339),
+	}
+}
+
+// encodeData returns data parts as the JSON scanners read
+func encodeData(data map[string]interface{}) string {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprint(data)
+	}
+	return string(encoded)
+}
+
+// ClamAVScanner scans parts with a clamd daemon through its INSTREAM command
+type ClamAVScanner struct {
+	Network string // "tcp" or "unix"
+	Address string // E.g. "localhost:3310" or "/var/run/clamav/clamd.ctl"
+	Timeout time.Duration
+}
+
+// NewClamAVScanner creates a ClamAVScanner for the clamd listening on address, a unix
+// socket path if it starts with "/" and a TCP address otherwise
+func NewClamAVScanner(address string) *ClamAVScanner {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &ClamAVScanner{Network: network, Address: address, Timeout: time.Minute}
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, target *ScanTarget) error {
+	if target.Content.File == nil {
+		return nil
+	}
+	content, err := target.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer content.Close()
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return fmt.Errorf("clamd unavailable: %v", err)
+	}
+	defer conn.Close()
+	if s.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("clamd: %v", err)
+	}
+	chunk := make([]byte, 32<<10)
+	size := make([]byte, 4)
+	for {
+		n, err := content.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := conn.Write(append(size, chunk[:n]...)); werr != nil {
+				// clamd closes the connection once the stream exceeds its size limit
+				return fmt.Errorf("clamd: %v", werr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("clamd: %v", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("clamd: %v", err)
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return &ScanFinding{Scanner: "clamav", Reason: signature}
+	}
+	return fmt.Errorf("clamd: %s", reply)
+}
+
+// SecretPattern is a kind of secret SecretScanner looks for
+type SecretPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultSecretPatterns are the secrets SecretScanner looks for by default
+var DefaultSecretPatterns = []SecretPattern{
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY( BLOCK)?-----`)},
+	{Name: "AWS access key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{Name: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+}
+
+// SecretScanner rejects parts containing credentials, e.g. so agents don't leak the keys
+// of their environment in artifacts. Only the first MaxSize bytes of each part are read.
+type SecretScanner struct {
+	Patterns []SecretPattern
+	MaxSize  int64
+}
+
+// NewSecretScanner creates a SecretScanner looking for DefaultSecretPatterns in up to 10 MiB per part
+func NewSecretScanner() *SecretScanner {
+	return &SecretScanner{Patterns: DefaultSecretPatterns, MaxSize: 10 << 20}
+}
+
+func (s *SecretScanner) Scan(ctx context.Context, target *ScanTarget) error {
+	if target.Content.File != nil && target.Content.File.Bytes == nil {
+		// Files by URI are left to scanners fetching them
+		return nil
+	}
+	content, err := target.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open part: %v", err)
+	}
+	defer content.Close()
+	data, err := io.ReadAll(io.LimitReader(content, s.MaxSize))
+	if err != nil {
+		return fmt.Errorf("failed to read part: %v", err)
+	}
+	for _, pattern := range s.Patterns {
+		if pattern.Pattern.Match(data) {
+			return &ScanFinding{Scanner: "secrets", Reason: pattern.Name + " found"}
+		}
+	}
+	return nil
+}
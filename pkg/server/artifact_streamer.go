@@ -0,0 +1,269 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// ErrArtifactStreamerClosed is returned when writing to a closed ArtifactStreamer
+var ErrArtifactStreamerClosed = errors.New("artifact streamer is closed")
+
+// ArtifactEmitFunc delivers an artifact update event to subscribers
+type ArtifactEmitFunc func(event *types.TaskArtifactUpdateEvent) error
+
+// ArtifactFinalizeFunc receives the complete artifact once streaming finishes
+type ArtifactFinalizeFunc func(artifact types.Artifact) error
+
+// ArtifactStreamerOption configures an ArtifactStreamer
+type ArtifactStreamerOption func(*ArtifactStreamer)
+
+// WithArtifactName sets the name of the streamed artifact
+func WithArtifactName(name string) ArtifactStreamerOption {
+	return func(s *ArtifactStreamer) {
+		s.name = &name
+	}
+}
+
+// WithArtifactBatchSize emits a chunk once at least n bytes of text are pending
+func WithArtifactBatchSize(n int) ArtifactStreamerOption {
+	return func(s *ArtifactStreamer) {
+		s.batchSize = n
+	}
+}
+
+// WithArtifactFlushInterval emits pending text at least every d, even if the batch is not full
+func WithArtifactFlushInterval(d time.Duration) ArtifactStreamerOption {
+	return func(s *ArtifactStreamer) {
+		s.flushInterval = d
+	}
+}
+
+// WithArtifactFinalizer sets the function receiving the complete artifact on Close
+func WithArtifactFinalizer(finalize ArtifactFinalizeFunc) ArtifactStreamerOption {
+	return func(s *ArtifactStreamer) {
+		s.finalize = finalize
+	}
+}
+
+// ArtifactStreamer batches text deltas produced by a handler into TaskArtifactUpdateEvents
+// with consistent index, append and lastChunk flags, and finalizes the artifact on Close
+type ArtifactStreamer struct {
+	taskID        string
+	index         int
+	name          *string
+	batchSize     int
+	flushInterval time.Duration
+	emit          ArtifactEmitFunc
+	finalize      ArtifactFinalizeFunc
+
+	lock     sync.Mutex
+	emitLock sync.Mutex // Held while emitting, so chunks are emitted in order
+	pending  strings.Builder
+	full     strings.Builder
+	chunks   int
+	closed   bool
+	timer    *time.Timer
+	err      error
+}
+
+// NewArtifactStreamer creates an ArtifactStreamer for the artifact at index of a task
+func NewArtifactStreamer(taskID string, index int, emit ArtifactEmitFunc, opts ...ArtifactStreamerOption) *ArtifactStreamer {
+	s := &ArtifactStreamer{
+		taskID:    taskID,
+		index:     index,
+		batchSize: 1,
+		emit:      emit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// StreamArtifact creates an ArtifactStreamer whose chunks go to the task's SSE subscribers
+// and whose complete artifact is stored on the task when closed. Chunks and the complete
+// artifact go through the same scan, content negotiation and index checks as other artifacts.
+func (tm *InMemoryTaskManager) StreamArtifact(ctx context.Context, taskID string, index int, opts ...ArtifactStreamerOption) *ArtifactStreamer {
+	emit := func(event *types.TaskArtifactUpdateEvent) error {
+		return tm.publishArtifactChunk(ctx, taskID, event.Artifact)
+	}
+	finalize := func(artifact types.Artifact) error {
+		_, err := tm.updateStore(ctx, taskID, tm.taskStatus(ctx, taskID), []types.Artifact{artifact})
+		return err
+	}
+	return NewArtifactStreamer(taskID, index, emit, append([]ArtifactStreamerOption{WithArtifactFinalizer(finalize)}, opts...)...)
+}
+
+// Write appends a text delta; it implements io.Writer
+func (s *ArtifactStreamer) Write(p []byte) (int, error) {
+	if err := s.WriteText(string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteText appends a text delta, emitting a chunk when the batch is full
+func (s *ArtifactStreamer) WriteText(delta string) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return ErrArtifactStreamerClosed
+	}
+	if s.err != nil {
+		s.lock.Unlock()
+		return s.err
+	}
+
+	s.pending.WriteString(delta)
+	s.full.WriteString(delta)
+	if s.pending.Len() >= s.batchSize {
+		return s.flushAndUnlock(false)
+	}
+	if s.flushInterval > 0 && s.timer == nil {
+		s.timer = time.AfterFunc(s.flushInterval, s.flushFromTimer)
+	}
+	s.lock.Unlock()
+	return nil
+}
+
+// Flush emits any pending text immediately
+func (s *ArtifactStreamer) Flush() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return ErrArtifactStreamerClosed
+	}
+	if s.pending.Len() == 0 {
+		s.lock.Unlock()
+		return nil
+	}
+	return s.flushAndUnlock(false)
+}
+
+// Close emits the last chunk and hands the complete artifact to the finalizer
+func (s *ArtifactStreamer) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	artifact := types.Artifact{
+		Name:  s.name,
+		Parts: []types.Part{types.TextPart{Type: "text", Text: s.full.String()}},
+		Index: s.index,
+	}
+	if err := s.flushAndUnlock(true); err != nil {
+		return err
+	}
+
+	if s.finalize == nil {
+		return nil
+	}
+	return s.finalize(artifact)
+}
+
+func (s *ArtifactStreamer) flushFromTimer() {
+	s.lock.Lock()
+	s.timer = nil
+	if s.closed || s.pending.Len() == 0 {
+		s.lock.Unlock()
+		return
+	}
+	s.flushAndUnlock(false)
+}
+
+// flushAndUnlock takes the pending text as one chunk, unlocks s.lock, which the caller must
+// hold, and emits the chunk. Chunks are emitted in order without holding s.lock, so emit may
+// take other locks, e.g. the task manager's. The first emit error fails later writes.
+func (s *ArtifactStreamer) flushAndUnlock(last bool) error {
+	event := s.takeChunkLocked(last)
+	s.emitLock.Lock()
+	s.lock.Unlock()
+	err := s.emit(event)
+	s.emitLock.Unlock()
+
+	if err != nil {
+		s.lock.Lock()
+		if s.err == nil {
+			s.err = err
+		}
+		s.lock.Unlock()
+	}
+	return err
+}
+
+// takeChunkLocked returns the pending text as the next chunk. The caller must hold s.lock.
+func (s *ArtifactStreamer) takeChunkLocked(last bool) *types.TaskArtifactUpdateEvent {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+
+	appendChunk := s.chunks > 0
+	artifact := types.Artifact{
+		Name:   s.name,
+		Parts:  []types.Part{types.TextPart{Type: "text", Text: s.pending.String()}},
+		Index:  s.index,
+		Append: &appendChunk,
+	}
+	if last {
+		lastChunk := true
+		artifact.LastChunk = &lastChunk
+	}
+	s.pending.Reset()
+	s.chunks++
+
+	return &types.TaskArtifactUpdateEvent{
+		ID:       s.taskID,
+		Artifact: artifact,
+	}
+}
+
+// publishArtifactChunk sends a streamed chunk to the task's subscribers after the scan,
+// content negotiation and index checks updateStore applies to stored artifacts. A chunk the
+// scanner rejects fails the task.
+func (tm *InMemoryTaskManager) publishArtifactChunk(ctx context.Context, taskID string, chunk types.Artifact) error {
+	scanned, err := tm.scanArtifacts(ctx, taskID, []types.Artifact{chunk})
+	if err != nil {
+		task, storeErr := tm.updateStore(ctx, taskID, tm.rejectedStatus(err), nil)
+		if storeErr != nil {
+			return storeErr
+		}
+		tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{ID: taskID, Status: task.Status, Final: true})
+		return err
+	}
+	if len(scanned) == 0 {
+		// Every part was stripped
+		return nil
+	}
+
+	tm.lock.Lock()
+	store := tm.store(ctx)
+	task := store.tasks[taskID]
+	if task == nil {
+		tm.lock.Unlock()
+		return taskNotFound(taskID)
+	}
+	chunks, err := tm.negotiateOutput(store.acceptedOutputModes[taskID], nil, scanned)
+	if err == nil && (chunk.Append == nil || !*chunk.Append) {
+		// The first chunk takes its index like a new artifact
+		_, err = mergeArtifacts(taskID, task.Artifacts, chunks)
+	}
+	tm.lock.Unlock()
+	if err != nil {
+		var unsupported *ContentTypeNotSupportedError
+		if errors.As(err, &unsupported) {
+			unsupported.TaskID = taskID
+		}
+		return err
+	}
+
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskArtifactUpdateEvent{ID: taskID, Artifact: chunks[0]})
+	return nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestStreamArtifactStoresCompleteArtifact(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager()
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	streamer := tm.StreamArtifact(ctx, "t1", 0)
+	for _, delta := range []string{"hello ", "world"} {
+		if err := streamer.WriteText(delta); err != nil {
+			t.Fatalf("WriteText: %v", err)
+		}
+	}
+	if err := streamer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	task, err := tm.getTask(ctx, "t1")
+	if err != nil {
+		t.Fatalf("getTask: %v", err)
+	}
+	if len(task.Artifacts) != 1 || task.Artifacts[0].Parts[0].(types.TextPart).Text != "hello world" {
+		t.Fatalf("artifacts = %+v, want the complete text once", task.Artifacts)
+	}
+}
+
+func TestStreamArtifactChecksChunks(t *testing.T) {
+	rejectSecrets := ArtifactScannerFunc(func(ctx context.Context, target *ScanTarget) error {
+		if strings.Contains(target.Content.Text, "secret") {
+			return &ScanFinding{Scanner: "test", Reason: "secret"}
+		}
+		return nil
+	})
+
+	for _, test := range []struct {
+		name      string
+		opts      []TaskManagerOption
+		params    types.TaskSendParams
+		delta     string
+		indexUsed bool // An artifact already holds the streamer's index
+		wantErr   func(error) bool
+		wantState types.TaskState
+	}{
+		{
+			name:      "index taken",
+			params:    types.TaskSendParams{ID: "t1", Message: textMessage("hi")},
+			delta:     "text",
+			indexUsed: true,
+			wantErr: func(err error) bool {
+				var taskErr *TaskError
+				return errors.As(err, &taskErr) && taskErr.Code == InvalidParamsErrorCode
+			},
+			wantState: types.TaskSubmitted,
+		},
+		{
+			name:   "output mode not accepted",
+			params: types.TaskSendParams{ID: "t1", Message: textMessage("hi"), AcceptedOutputModes: []string{"application/json"}},
+			delta:  "text",
+			wantErr: func(err error) bool {
+				var unsupported *ContentTypeNotSupportedError
+				return errors.As(err, &unsupported)
+			},
+			wantState: types.TaskSubmitted,
+		},
+		{
+			name:   "rejected by scanner",
+			opts:   []TaskManagerOption{WithArtifactScanner(rejectSecrets, ScanFailTask)},
+			params: types.TaskSendParams{ID: "t1", Message: textMessage("hi")},
+			delta:  "the secret",
+			wantErr: func(err error) bool {
+				var finding *ScanFinding
+				return errors.As(err, &finding)
+			},
+			wantState: types.TaskFailed,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			tm := NewInMemoryTaskManager(test.opts...)
+			if _, err := tm.upsertTask(ctx, &test.params); err != nil {
+				t.Fatalf("upsertTask: %v", err)
+			}
+			index := 1
+			if test.indexUsed {
+				if err := tm.addArtifact(ctx, "t1", types.Artifact{Parts: types.Parts{types.TextPart{Type: "text", Text: "stored"}}}); err != nil {
+					t.Fatalf("addArtifact: %v", err)
+				}
+				index = 0
+			}
+			streamer := tm.StreamArtifact(ctx, "t1", index)
+			if err := streamer.WriteText(test.delta); !test.wantErr(err) {
+				t.Fatalf("WriteText = %v, want the chunk rejected", err)
+			}
+			if err := streamer.WriteText("more"); err == nil {
+				t.Fatal("WriteText after a rejected chunk succeeded")
+			}
+			if state := tm.taskStatus(ctx, "t1").State; state != test.wantState {
+				t.Fatalf("task state = %s, want %s", state, test.wantState)
+			}
+		})
+	}
+}
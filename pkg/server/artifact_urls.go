@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// defaultArtifactURLTTL is how long signed artifact URLs stay valid by default
+const defaultArtifactURLTTL = 15 * time.Minute
+
+// artifactsPath is the path of the signed artifact endpoint, relative to the path prefix
+const artifactsPath = "/artifacts/"
+
+// ArtifactProvider is implemented by task managers that can serve single artifacts of a task
+type ArtifactProvider interface {
+	GetArtifact(ctx context.Context, taskID string, index int) (*types.Artifact, error)
+}
+
+// WithSignedArtifactURLs enables the /artifacts/{taskId}/{index} endpoint. Requests must carry
+// a signature created by SignArtifactURL with key; URLs expire after ttl (15m when zero).
+func WithSignedArtifactURLs(key []byte, ttl time.Duration) ServerOption {
+	return func(s *A2AServer) {
+		if ttl <= 0 {
+			ttl = defaultArtifactURLTTL
+		}
+		s.artifactKey = key
+		s.artifactURLTTL = ttl
+	}
+}
+
+// GetArtifact returns the artifact with index of a task belonging to the tenant in ctx
+func (tm *InMemoryTaskManager) GetArtifact(ctx context.Context, taskID string, index int) (*types.Artifact, error) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		return nil, taskNotFound(taskID)
+	}
+	for i := range task.Artifacts {
+		if task.Artifacts[i].Index == index {
+			artifact := task.Artifacts[i]
+			return &artifact, nil
+		}
+	}
+	return nil, errors.New("artifact not found")
+}
+
+// SignArtifactURL returns a time-limited URL for fetching an artifact of a task belonging to the tenant in ctx.
+// The URL is absolute when the agent card URL is, so it can be handed to push notification receivers.
+func (s *A2AServer) SignArtifactURL(ctx context.Context, taskID string, index int) (string, error) {
+	if len(s.artifactKey) == 0 {
+		return "", errors.New("signed artifact URLs are not enabled")
+	}
+
+	tenant := TenantFromContext(ctx)
+	expires := s.clock.Now().Add(s.artifactURLTTL).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	if tenant != DefaultTenant {
+		query.Set("tenant", tenant)
+	}
+	query.Set("sig", s.artifactSignature(tenant, taskID, index, expires))
+
+	u := url.URL{
+		Path:     s.path(artifactsPath + url.PathEscape(taskID) + "/" + strconv.Itoa(index)),
+		RawQuery: query.Encode(),
+	}
+	if cardURL, err := url.Parse(s.agentCard.URL); err == nil && cardURL.Host != "" {
+		u.Scheme = cardURL.Scheme
+		u.Host = cardURL.Host
+	}
+	return u.String(), nil
+}
+
+// artifactSignature computes the HMAC binding a URL to tenant, task, artifact and expiry.
+// The strings are length-prefixed, so no tenant and task id pair signs the same as another.
+func (s *A2AServer) artifactSignature(tenant, taskID string, index int, expires int64) string {
+	mac := hmac.New(sha256.New, s.artifactKey)
+	for _, field := range []string{tenant, taskID} {
+		binary.Write(mac, binary.BigEndian, uint64(len(field)))
+		io.WriteString(mac, field)
+	}
+	binary.Write(mac, binary.BigEndian, int64(index))
+	binary.Write(mac, binary.BigEndian, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// serveArtifact handles signed artifact downloads
+func (s *A2AServer) serveArtifact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	taskID := r.PathValue("taskId")
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		http.Error(w, "Invalid artifact index", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+	tenant := query.Get("tenant")
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	expected := s.artifactSignature(tenant, taskID, index, expires)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+	if s.clock.Now().Unix() > expires {
+		http.Error(w, "URL expired", http.StatusForbidden)
+		return
+	}
+
+	provider, ok := s.taskManager.(ArtifactProvider)
+	if !ok {
+		http.Error(w, "Artifact retrieval not supported", http.StatusNotImplemented)
+		return
+	}
+	artifact, err := provider.GetArtifact(WithTenant(r.Context(), tenant), taskID, index)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	contentType, body, err := artifactContent(artifact)
+	if err != nil {
+		http.Error(w, "Failed to encode artifact", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("Cache-Control", "private, no-store")
+	// The content type comes from the artifact; browsers must not guess a more dangerous one,
+	// nor run scripts of an artifact in the agent's origin
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	if !inlineContentTypes[mediaType(contentType)] {
+		w.Header().Set("Content-Disposition", "attachment")
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to write artifact: %v", err)
+	}
+}
+
+// inlineContentTypes are the artifact content types browsers may display rather than
+// download, none of which can run scripts
+var inlineContentTypes = map[string]bool{
+	"text/plain":       true,
+	"application/json": true,
+	"image/png":        true,
+	"image/jpeg":       true,
+	"image/gif":        true,
+	"image/webp":       true,
+}
+
+// mediaType returns the lowercase media type of a Content-Type value without its parameters
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// artifactContent returns the raw bytes of a single-part text or file artifact,
+// and the artifact as JSON otherwise
+func artifactContent(artifact *types.Artifact) (string, []byte, error) {
+	if len(artifact.Parts) == 1 {
+		var part struct {
+			Type string             `json:"type"`
+			Text string             `json:"text"`
+			File *types.FileContent `json:"file"`
+		}
+		data, err := json.Marshal(artifact.Parts[0])
+		if err != nil {
+			return "", nil, err
+		}
+		if err := json.Unmarshal(data, &part); err != nil {
+			return "", nil, err
+		}
+
+		switch part.Type {
+		case "text":
+			return "text/plain; charset=utf-8", []byte(part.Text), nil
+		case "file":
+			if part.File != nil && part.File.Bytes != nil {
+				raw, err := base64.StdEncoding.DecodeString(*part.File.Bytes)
+				if err != nil {
+					return "", nil, err
+				}
+				contentType := "application/octet-stream"
+				if part.File.MimeType != nil {
+					contentType = *part.File.MimeType
+				}
+				return contentType, raw, nil
+			}
+		}
+	}
+
+	body, err := json.Marshal(artifact)
+	if err != nil {
+		return "", nil, err
+	}
+	return "application/json", body, nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+func TestArtifactSignatureSeparatesFields(t *testing.T) {
+	s := &A2AServer{artifactKey: []byte("secret")}
+	// Joined with a separator, both pairs would read "a\nb\nc"
+	if s.artifactSignature("a\nb", "c", 0, 1) == s.artifactSignature("a", "b\nc", 0, 1) {
+		t.Fatal("different tenant and task id pairs have the same signature")
+	}
+}
+
+func TestServeSignedArtifact(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	tm := NewInMemoryTaskManager(WithClock(clock))
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	artifact := types.Artifact{Index: 0, Parts: types.Parts{types.TextPart{Type: "text", Text: "<script>alert(1)</script>"}}}
+	if err := tm.addArtifact(ctx, "t1", artifact); err != nil {
+		t.Fatalf("addArtifact: %v", err)
+	}
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, tm,
+		WithSignedArtifactURLs([]byte("secret"), time.Minute), WithServerClock(clock))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	handler := s.Handler()
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	signed, err := s.SignArtifactURL(ctx, "t1", 0)
+	if err != nil {
+		t.Fatalf("SignArtifactURL: %v", err)
+	}
+	w := get(signed)
+	if w.Code != http.StatusOK || w.Body.String() != "<script>alert(1)</script>" {
+		t.Fatalf("response = %d %q, want the artifact's text", w.Code, w.Body.String())
+	}
+	if sniff := w.Header().Get("X-Content-Type-Options"); sniff != "nosniff" {
+		t.Fatalf("X-Content-Type-Options = %q, want nosniff", sniff)
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); csp != "sandbox" {
+		t.Fatalf("Content-Security-Policy = %q, want sandbox", csp)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); disposition != "" {
+		t.Fatalf("Content-Disposition of a text artifact = %q, want it shown inline", disposition)
+	}
+
+	if w := get(strings.Replace(signed, "/t1/0", "/t1/1", 1)); w.Code != http.StatusForbidden {
+		t.Fatalf("URL signed for another artifact: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	clock.Advance(2 * time.Minute)
+	if w := get(signed); w.Code != http.StatusForbidden {
+		t.Fatalf("expired URL: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestServeArtifactDownloadsActiveContent(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager()
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, tm, WithSignedArtifactURLs([]byte("secret"), time.Minute))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	handler := s.Handler()
+
+	for i, test := range []struct {
+		mimeType       string
+		wantAttachment bool
+	}{
+		{"text/html", true},
+		{"image/svg+xml", true},
+		{"Text/HTML; charset=utf-8", true},
+		{"application/javascript", true},
+		{"image/png", false},
+		{"application/json", false},
+	} {
+		t.Run(test.mimeType, func(t *testing.T) {
+			mimeType, data := test.mimeType, base64.StdEncoding.EncodeToString([]byte("<svg onload=alert(1)>"))
+			artifact := types.Artifact{Index: i, Parts: types.Parts{types.FilePart{
+				Type: "file",
+				File: types.FileContent{MimeType: &mimeType, Bytes: &data},
+			}}}
+			if err := tm.addArtifact(ctx, "t1", artifact); err != nil {
+				t.Fatalf("addArtifact: %v", err)
+			}
+			signed, err := s.SignArtifactURL(ctx, "t1", i)
+			if err != nil {
+				t.Fatalf("SignArtifactURL: %v", err)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, signed, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if attachment := w.Header().Get("Content-Disposition") == "attachment"; attachment != test.wantAttachment {
+				t.Fatalf("Content-Disposition = %q, want attachment %v", w.Header().Get("Content-Disposition"), test.wantAttachment)
+			}
+			if csp := w.Header().Get("Content-Security-Policy"); csp != "sandbox" {
+				t.Fatalf("Content-Security-Policy = %q, want sandbox", csp)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// ErrPermissionDenied matches every error returned for denied requests through errors.Is
+var ErrPermissionDenied = &TaskError{Code: PermissionDeniedErrorCode, Message: "Permission denied"}
+
+// EventsMethod is the method name authorizers see for requests to the events endpoint
+const EventsMethod = "events"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string                 `json:"subject"`
+	Roles   []string               `json:"roles,omitempty"`
+	Claims  map[string]interface{} `json:"claims,omitempty"` // E.g. the claims of the caller's token
+}
+
+// HasRole reports whether the principal has role
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PrincipalResolver returns the principal of a request, typically from values authentication
+// middleware placed in r.Context(). A nil principal means an anonymous caller.
+type PrincipalResolver func(r *http.Request) (*Principal, error)
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, or nil if none
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// AuthorizationRequest describes the action an Authorizer decides on
+type AuthorizationRequest struct {
+	Principal *Principal  `json:"principal,omitempty"`
+	Tenant    string      `json:"tenant,omitempty"`
+	Method    string      `json:"method"`
+	TaskID    string      `json:"taskId,omitempty"`
+	SessionID string      `json:"sessionId,omitempty"` // The session of the task, or of the params for a new task
+	Params    interface{} `json:"params,omitempty"`
+}
+
+// Authorizer decides whether a request may proceed. It returns nil to allow it; any other
+// error denies it and is sent to the client as a permission denied error.
+type Authorizer interface {
+	Authorize(ctx context.Context, request *AuthorizationRequest) error
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(ctx context.Context, request *AuthorizationRequest) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, request *AuthorizationRequest) error {
+	return f(ctx, request)
+}
+
+// WithAuthorizer consults authorizer before every JSON-RPC method and events subscription,
+// with the principal resolver returns for the request. resolver may be nil if authentication
+// middleware puts the principal in the request context with WithPrincipal.
+func WithAuthorizer(authorizer Authorizer, resolver PrincipalResolver) ServerOption {
+	return func(s *A2AServer) {
+		s.authorizer = authorizer
+		s.principalResolver = resolver
+	}
+}
+
+// taskSessionLookup is implemented by task managers that can tell the session of a task
+type taskSessionLookup interface {
+	taskSession(ctx context.Context, taskID string) (string, bool)
+}
+
+// taskSession returns the session of a task belonging to the tenant in ctx, and false if
+// there is no such task
+func (tm *InMemoryTaskManager) taskSession(ctx context.Context, taskID string) (string, bool) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		return "", false
+	}
+	if task.SessionID == nil {
+		return "", true
+	}
+	return *task.SessionID, true
+}
+
+// sessionParamMethods are the methods whose sessionId param names the session they act on.
+// Other methods may carry one too, e.g. the destination of tasks/transfer.
+var sessionParamMethods = map[string]bool{
+	"send_task":                   true,
+	types.SendTaskStreamingMethod: true,
+	types.ListTasksMethod:         true,
+	types.CloseSessionMethod:      true,
+}
+
+// authorize resolves the principal of r and asks the authorizer whether it may call method.
+// It returns ctx carrying the principal.
+func (s *A2AServer) authorize(ctx context.Context, r *http.Request, method string, params interface{}, sessionID string) (context.Context, error) {
+	if s.authorizer == nil {
+		return ctx, nil
+	}
+	if s.principalResolver != nil {
+		resolved, err := s.principalResolver(r.WithContext(ctx))
+		if err != nil {
+			return ctx, permissionDenied(err)
+		}
+		ctx = WithPrincipal(ctx, resolved)
+	}
+	return ctx, s.authorizeRequest(ctx, method, params, sessionID)
+}
+
+// authorizeRequest asks the authorizer whether the principal in ctx may call method with params
+func (s *A2AServer) authorizeRequest(ctx context.Context, method string, params interface{}, sessionID string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	request := &AuthorizationRequest{
+		Principal: PrincipalFromContext(ctx),
+		Tenant:    TenantFromContext(ctx),
+		Method:    method,
+		TaskID:    requestTaskID(params),
+		SessionID: sessionID,
+		Params:    params,
+	}
+	// The session of an existing task is its own, whatever session the caller claims
+	known := false
+	if lookup, ok := s.taskManager.(taskSessionLookup); ok && request.TaskID != "" {
+		if sessionID, found := lookup.taskSession(ctx, request.TaskID); found {
+			request.SessionID, known = sessionID, true
+		}
+	}
+	if !known && request.SessionID == "" && sessionParamMethods[method] {
+		request.SessionID = requestSessionID(params)
+	}
+	if err := s.authorizer.Authorize(ctx, request); err != nil {
+		return permissionDenied(err)
+	}
+	return nil
+}
+
+// permissionDenied wraps an authorizer error into ErrPermissionDenied
+func permissionDenied(err error) error {
+	if errors.Is(err, ErrPermissionDenied) {
+		return err
+	}
+	return &TaskError{
+		Code:    PermissionDeniedErrorCode,
+		Message: fmt.Sprintf("Permission denied: %v", err),
+	}
+}
+
+// requestSessionID returns the session id of request params, whether decoded or typed
+func requestSessionID(params interface{}) string {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		id, _ := p["sessionId"].(string)
+		return id
+	case *types.TaskSendParams:
+		return p.SessionID
+	case *types.TaskListParams:
+		return p.SessionID
+	case *types.SessionCloseParams:
+		return p.SessionID
+	}
+	return ""
+}
+
+// AuthorizationRule matches requests and allows or denies them. Empty lists match anything.
+type AuthorizationRule struct {
+	Allow     bool
+	Methods   []string // Methods, or prefixes ending in "*" such as "tasks/*"
+	Subjects  []string // Principal subjects
+	Roles     []string // Any of these principal roles
+	Tenants   []string
+	Condition func(ctx context.Context, request *AuthorizationRequest) bool // Further condition, if set
+}
+
+// matches reports whether the rule applies to request
+func (rule *AuthorizationRule) matches(ctx context.Context, request *AuthorizationRequest) bool {
+	if len(rule.Methods) > 0 && !matchesAny(rule.Methods, request.Method) {
+		return false
+	}
+	if len(rule.Tenants) > 0 && !matchesAny(rule.Tenants, request.Tenant) {
+		return false
+	}
+	if len(rule.Subjects) > 0 && (request.Principal == nil || !matchesAny(rule.Subjects, request.Principal.Subject)) {
+		return false
+	}
+	if len(rule.Roles) > 0 {
+		found := false
+		for _, role := range rule.Roles {
+			if request.Principal.HasRole(role) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return rule.Condition == nil || rule.Condition(ctx, request)
+}
+
+// matchesAny reports whether value equals one of patterns or has the prefix of one ending in "*"
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == value || pattern == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleAuthorizer applies the first matching rule, or denies requests no rule matches unless
+// DefaultAllow is set
+type RuleAuthorizer struct {
+	Rules        []AuthorizationRule
+	DefaultAllow bool
+}
+
+// NewRuleAuthorizer creates a RuleAuthorizer denying requests no rule matches
+func NewRuleAuthorizer(rules ...AuthorizationRule) *RuleAuthorizer {
+	return &RuleAuthorizer{Rules: rules}
+}
+
+func (a *RuleAuthorizer) Authorize(ctx context.Context, request *AuthorizationRequest) error {
+	for i := range a.Rules {
+		rule := &a.Rules[i]
+		if !rule.matches(ctx, request) {
+			continue
+		}
+		if rule.Allow {
+			return nil
+		}
+		return fmt.Errorf("%s denied by rule %d", request.Method, i)
+	}
+	if a.DefaultAllow {
+		return nil
+	}
+	return fmt.Errorf("%s is not allowed", request.Method)
+}
+
+// OPAAuthorizer asks an Open Policy Agent server for decisions through its data API, with
+// the AuthorizationRequest as input. The policy document must be a boolean, or an object
+// with a boolean "allow" and an optional "reason".
+type OPAAuthorizer struct {
+	URL    string // Document URL, e.g. http://localhost:8181/v1/data/a2a/allow
+	Client *http.Client
+}
+
+// NewOPAAuthorizer creates an OPAAuthorizer querying the document at url
+func NewOPAAuthorizer(url string) *OPAAuthorizer {
+	return &OPAAuthorizer{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (a *OPAAuthorizer) Authorize(ctx context.Context, request *AuthorizationRequest) error {
+	body, err := json.Marshal(map[string]interface{}{"input": request})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("policy decision unavailable: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("policy decision unavailable: status %d", resp.StatusCode)
+	}
+
+	var decision struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return fmt.Errorf("invalid policy decision: %v", err)
+	}
+	var allowed bool
+	if err := json.Unmarshal(decision.Result, &allowed); err == nil {
+		if allowed {
+			return nil
+		}
+		return fmt.Errorf("%s denied by policy", request.Method)
+	}
+	var result struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(decision.Result, &result); err != nil {
+		// Undefined documents have no result
+		return fmt.Errorf("%s denied by policy: no decision", request.Method)
+	}
+	if result.Allow {
+		return nil
+	}
+	if result.Reason != "" {
+		return errors.New(result.Reason)
+	}
+	return fmt.Errorf("%s denied by policy", request.Method)
+}
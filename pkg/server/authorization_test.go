@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestAuthorizeUsesTaskSession(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager()
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "victim", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	var seen []*AuthorizationRequest
+	authorizer := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error {
+		seen = append(seen, request)
+		return nil
+	})
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, tm, WithAuthorizer(authorizer, nil))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	handler := s.Handler()
+
+	for _, test := range []struct {
+		name, body, wantSession string
+	}{
+		{"task scoped method", `{"jsonrpc":"2.0","id":1,"method":"get_task","params":{"id":"t1","sessionId":"mine"}}`, "victim"},
+		{"message to an existing task", `{"jsonrpc":"2.0","id":2,"method":"send_task","params":{"id":"t1","sessionId":"mine","message":{"role":"user","parts":[]}}}`, "victim"},
+		{"message creating a task", `{"jsonrpc":"2.0","id":3,"method":"send_task","params":{"id":"t2","sessionId":"mine","message":{"role":"user","parts":[]}}}`, "mine"},
+		{"unknown task", `{"jsonrpc":"2.0","id":4,"method":"get_task","params":{"id":"t3","sessionId":"mine"}}`, ""},
+		{"session method", `{"jsonrpc":"2.0","id":5,"method":"tasks/list","params":{"sessionId":"mine"}}`, "mine"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			seen = nil
+			postRPC(t, handler, test.body)
+			if len(seen) != 1 {
+				t.Fatalf("authorizer called %d times, want once", len(seen))
+			}
+			if seen[0].SessionID != test.wantSession {
+				t.Fatalf("session = %q, want %q", seen[0].SessionID, test.wantSession)
+			}
+		})
+	}
+}
+
+func TestRuleAuthorizer(t *testing.T) {
+	admin := &Principal{Subject: "alice", Roles: []string{"admin"}}
+	user := &Principal{Subject: "bob", Roles: []string{"user"}}
+	authorizer := NewRuleAuthorizer(
+		AuthorizationRule{Allow: false, Methods: []string{"tasks/batchCancel"}, Roles: []string{"user"}},
+		AuthorizationRule{Allow: true, Roles: []string{"admin"}},
+		AuthorizationRule{Allow: true, Methods: []string{"get_task", "tasks/*"}, Subjects: []string{"bob"}, Tenants: []string{"acme"}},
+		AuthorizationRule{Allow: true, Methods: []string{"send_task"}, Condition: func(ctx context.Context, request *AuthorizationRequest) bool {
+			return request.SessionID == "open"
+		}},
+	)
+
+	for _, test := range []struct {
+		name    string
+		request AuthorizationRequest
+		allowed bool
+	}{
+		{"role", AuthorizationRequest{Principal: admin, Method: "cancel_task"}, true},
+		{"method and subject", AuthorizationRequest{Principal: user, Tenant: "acme", Method: "get_task"}, true},
+		{"method prefix", AuthorizationRequest{Principal: user, Tenant: "acme", Method: "tasks/list"}, true},
+		{"earlier deny rule", AuthorizationRequest{Principal: user, Tenant: "acme", Method: "tasks/batchCancel"}, false},
+		{"other tenant", AuthorizationRequest{Principal: user, Tenant: "other", Method: "get_task"}, false},
+		{"anonymous caller", AuthorizationRequest{Tenant: "acme", Method: "get_task"}, false},
+		{"condition met", AuthorizationRequest{Method: "send_task", SessionID: "open"}, true},
+		{"condition not met", AuthorizationRequest{Method: "send_task", SessionID: "closed"}, false},
+		{"no rule matches", AuthorizationRequest{Principal: user, Method: "cancel_task"}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := authorizer.Authorize(context.Background(), &test.request)
+			if (err == nil) != test.allowed {
+				t.Fatalf("Authorize = %v, want allowed %v", err, test.allowed)
+			}
+		})
+	}
+
+	authorizer.DefaultAllow = true
+	if err := authorizer.Authorize(context.Background(), &AuthorizationRequest{Principal: user, Method: "cancel_task"}); err != nil {
+		t.Fatalf("Authorize with DefaultAllow = %v, want allowed", err)
+	}
+}
+
+func TestOPAAuthorizer(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		status     int
+		response   string
+		allowed    bool
+		wantReason string
+	}{
+		{"boolean allow", http.StatusOK, `{"result":true}`, true, ""},
+		{"boolean deny", http.StatusOK, `{"result":false}`, false, "denied by policy"},
+		{"object allow", http.StatusOK, `{"result":{"allow":true}}`, true, ""},
+		{"object deny with reason", http.StatusOK, `{"result":{"allow":false,"reason":"outside business hours"}}`, false, "outside business hours"},
+		{"undefined document", http.StatusOK, `{}`, false, "no decision"},
+		{"server error", http.StatusInternalServerError, `{}`, false, "unavailable"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var input struct {
+				Input AuthorizationRequest `json:"input"`
+			}
+			policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+					t.Errorf("decoding input: %v", err)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.response))
+			}))
+			defer policy.Close()
+
+			request := &AuthorizationRequest{
+				Principal: &Principal{Subject: "alice"},
+				Method:    "get_task",
+				TaskID:    "t1",
+				SessionID: "s1",
+			}
+			err := NewOPAAuthorizer(policy.URL).Authorize(context.Background(), request)
+			if (err == nil) != test.allowed {
+				t.Fatalf("Authorize = %v, want allowed %v", err, test.allowed)
+			}
+			if err != nil && !strings.Contains(err.Error(), test.wantReason) {
+				t.Fatalf("Authorize = %v, want it to mention %q", err, test.wantReason)
+			}
+			if input.Input.Method != "get_task" || input.Input.TaskID != "t1" || input.Input.SessionID != "s1" ||
+				input.Input.Principal == nil || input.Input.Principal.Subject != "alice" {
+				t.Fatalf("policy input = %+v, want the authorization request", input.Input)
+			}
+		})
+	}
+}
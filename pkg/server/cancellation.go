@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// CancelCallback releases the external resources of a task being canceled, e.g. a headless browser.
+// It receives a snapshot of the task before it is marked canceled.
+type CancelCallback func(ctx context.Context, task *types.Task) error
+
+// cancelRegistration is one registered CancelCallback; its address identifies it for unregistering
+type cancelRegistration struct {
+	callback CancelCallback
+}
+
+// OnCancel registers a callback invoked when the task is canceled, before it is marked canceled.
+// Callbacks run in reverse registration order. The returned func unregisters the callback,
+// e.g. once the resource it cleans up has been released normally.
+func (tm *InMemoryTaskManager) OnCancel(ctx context.Context, taskID string, callback CancelCallback) func() {
+	key := subscriberKey(ctx, taskID)
+	registration := &cancelRegistration{callback: callback}
+
+	tm.lock.Lock()
+	if tm.cancelCallbacks == nil {
+		tm.cancelCallbacks = make(map[taskKey][]*cancelRegistration)
+	}
+	tm.cancelCallbacks[key] = append(tm.cancelCallbacks[key], registration)
+	tm.lock.Unlock()
+
+	return func() {
+		tm.lock.Lock()
+		defer tm.lock.Unlock()
+
+		registrations := tm.cancelCallbacks[key]
+		for i, r := range registrations {
+			if r == registration {
+				tm.cancelCallbacks[key] = append(registrations[:i], registrations[i+1:]...)
+				break
+			}
+		}
+		if len(tm.cancelCallbacks[key]) == 0 {
+			delete(tm.cancelCallbacks, key)
+		}
+	}
+}
+
+// cancelTask runs the task's cancel callbacks and marks it canceled
+func (tm *InMemoryTaskManager) cancelTask(ctx context.Context, taskID string) (*types.Task, error) {
+	key := subscriberKey(ctx, taskID)
+
+	tm.lock.Lock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		tm.lock.Unlock()
+		return nil, taskNotFound(taskID)
+	}
+	if isTerminalState(task.Status.State) {
+		tm.lock.Unlock()
+		return nil, &TaskError{
+			Code:    TaskNotCancelableErrorCode,
+			Message: fmt.Sprintf("Task is %s and cannot be canceled", task.Status.State),
+			Data:    &types.TaskErrorData{TaskID: taskID, State: task.Status.State},
+		}
+	}
+	registrations := tm.cancelCallbacks[key]
+	delete(tm.cancelCallbacks, key)
+	snapshot := *task
+	tm.lock.Unlock()
+
+	for i := len(registrations) - 1; i >= 0; i-- {
+		if err := registrations[i].callback(ctx, &snapshot); err != nil {
+			log.Printf("Cancel callback of task %s failed: %v", taskID, err)
+		}
+	}
+
+	tm.lock.Lock()
+	if isTerminalState(task.Status.State) {
+		// The handler finished while the callbacks ran
+		snapshot = *task
+		tm.lock.Unlock()
+		return &snapshot, nil
+	}
+	status := types.TaskStatus{
+		State:     types.TaskCanceled,
+		Timestamp: tm.clock.Now().Format(time.RFC3339),
+	}
+	if err := tm.journal(ctx, TaskLogEntry{TaskID: taskID, Version: task.Version + 1, Kind: TaskLogStatus, Status: &status}); err != nil {
+		tm.lock.Unlock()
+		return nil, err
+	}
+	task.Status = status
+	task.Version++
+	snapshot = *task
+	scratch := tm.finishTask(ctx, taskID)
+	tm.lock.Unlock()
+	scratch.remove()
+
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: snapshot.Status,
+		Final:  true,
+	})
+	return &snapshot, nil
+}
+
+// finishTask releases what a task only needs while it runs once it reached a terminal state
+// or is evicted: its cancel callbacks, input waiter, heartbeat timer, progress func and
+// scratch space. It returns the scratch space for the caller to remove after releasing
+// tm.lock, which it must hold.
+func (tm *InMemoryTaskManager) finishTask(ctx context.Context, taskID string) *ScratchSpace {
+	key := subscriberKey(ctx, taskID)
+	delete(tm.cancelCallbacks, key)
+	delete(tm.inputWaiters, key)
+
+	tm.heartbeatLock.Lock()
+	if timer := tm.heartbeatTimers[key]; timer != nil {
+		timer.Stop()
+		delete(tm.heartbeatTimers, key)
+	}
+	delete(tm.progressFuncs, key)
+	tm.heartbeatLock.Unlock()
+
+	return tm.takeScratchSpace(ctx, taskID)
+}
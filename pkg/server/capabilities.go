@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"a2a-go/pkg/types"
+)
+
+// legacyProtocolVersion is the protocol version served when the agent card declares none
+const legacyProtocolVersion = "0.1.0"
+
+// LimitsReporter is implemented by task managers reporting the limits they enforce through
+// agent/capabilities
+type LimitsReporter interface {
+	AgentLimits(ctx context.Context) types.AgentLimits
+}
+
+// AgentLimits reports the largest page of tasks/list
+func (tm *InMemoryTaskManager) AgentLimits(ctx context.Context) types.AgentLimits {
+	return types.AgentLimits{MaxListTasks: maxTaskListLimit}
+}
+
+// agentCapabilities answers agent/capabilities with the methods, capabilities and limits the
+// server has right now, picking the protocol version shared with the client
+func (s *A2AServer) agentCapabilities(ctx context.Context, params *types.AgentCapabilitiesParams) *types.AgentCapabilitiesResponse {
+	result := &types.AgentCapabilitiesResult{
+		ProtocolVersions: []string{legacyProtocolVersion},
+		Methods:          supportedMethods(s.taskManager),
+	}
+	if s.agentCard != nil {
+		if s.agentCard.ProtocolVersion != "" {
+			result.ProtocolVersions = []string{s.agentCard.ProtocolVersion}
+		}
+		result.Capabilities = s.agentCard.Capabilities
+	}
+	result.ProtocolVersion = types.NegotiateProtocolVersion(result.ProtocolVersions, params.ProtocolVersions)
+
+	s.methodsLock.RLock()
+	custom := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		custom = append(custom, name)
+	}
+	s.methodsLock.RUnlock()
+	sort.Strings(custom)
+	result.Methods = append(result.Methods, custom...)
+
+	if reporter, ok := s.taskManager.(LimitsReporter); ok {
+		result.Limits = reporter.AgentLimits(ctx)
+	}
+	result.Limits.MaxBatchTasks = types.MaxBatchTasks
+	if s.rateLimiter != nil {
+		result.Limits.RequestsPerSecond = s.rateLimiter.rate
+		result.Limits.RequestBurst = int(s.rateLimiter.burst)
+	}
+	return &types.AgentCapabilitiesResponse{Result: result}
+}
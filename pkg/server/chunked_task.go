@@ -0,0 +1,83 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"a2a-go/pkg/types"
+)
+
+// WithChunkedTaskResponses streams get_task responses of tasks with artifacts one artifact
+// at a time using chunked transfer encoding, instead of encoding the whole task in memory
+func WithChunkedTaskResponses() ServerOption {
+	return func(s *A2AServer) {
+		s.chunkedTasks = true
+	}
+}
+
+// writeTaskChunked writes a get_task response, flushing after every artifact
+func (s *A2AServer) writeTaskChunked(w http.ResponseWriter, id interface{}, response *types.GetTaskResponse) {
+	task := *response.Result
+	artifacts := task.Artifacts
+	task.Artifacts = nil
+
+	head, err := json.Marshal(struct {
+		JSONRPC string      `json:"jsonrpc"`
+		ID      interface{} `json:"id"`
+		Result  *types.Task `json:"result"`
+	}{"2.0", id, &task})
+	if err != nil {
+		http.Error(w, "Failed to encode task", http.StatusInternalServerError)
+		return
+	}
+	// Reopen the result object to append the artifacts array
+	head = bytes.TrimSuffix(head, []byte("}}"))
+	if !bytes.HasSuffix(head, []byte("{")) {
+		head = append(head, ',')
+	}
+	head = append(head, `"artifacts":[`...)
+
+	w.Header().Set("Content-Type", "application/json")
+	flusher, _ := w.(http.Flusher)
+	if _, err := w.Write(head); err != nil {
+		return
+	}
+
+	for i := range artifacts {
+		data, err := json.Marshal(&artifacts[i])
+		if err != nil {
+			// Headers are sent; the truncated body makes the client fail to decode
+			log.Printf("Failed to marshal artifact %d of task %s: %v", artifacts[i].Index, task.ID, err)
+			return
+		}
+		if i > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	tail := []byte("]}")
+	if response.NotModified {
+		tail = append(tail, `,"notModified":true`...)
+	}
+	if len(response.Events) > 0 {
+		events, err := json.Marshal(response.Events)
+		if err != nil {
+			log.Printf("Failed to marshal recorded events of task %s: %v", task.ID, err)
+			return
+		}
+		tail = append(tail, `,"events":`...)
+		tail = append(tail, events...)
+	}
+	tail = append(tail, "}\n"...)
+	if _, err := w.Write(tail); err != nil {
+		log.Printf("Failed to write task response: %v", err)
+	}
+}
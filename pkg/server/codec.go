@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"a2a-go/pkg/codec"
+)
+
+// WithCodecs accepts requests and sends responses in the given codecs in addition to JSON
+// and the codecs registered with codec.Register. Handlers keep working with JSON; bodies
+// are transcoded at the edge.
+func WithCodecs(codecs ...codec.Codec) ServerOption {
+	return func(s *A2AServer) {
+		if s.codecs == nil {
+			s.codecs = make(map[string]codec.Codec)
+		}
+		for _, c := range codecs {
+			s.codecs[c.ContentType()] = c
+		}
+	}
+}
+
+// lookupCodec returns the codec for a Content-Type or Accept media type
+func (s *A2AServer) lookupCodec(mediaType string) (codec.Codec, bool) {
+	mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+	if c, ok := s.codecs[mediaType]; ok {
+		return c, true
+	}
+	return codec.Lookup(mediaType)
+}
+
+// negotiateCodec returns the codec of the request body and the one the client accepts for the response
+func (s *A2AServer) negotiateCodec(r *http.Request) (codec.Codec, codec.Codec) {
+	requestCodec, ok := s.lookupCodec(r.Header.Get("Content-Type"))
+	if !ok {
+		// Unlabeled or unknown bodies are parsed as JSON, as they always were
+		requestCodec = codec.JSON
+	}
+	responseCodec := requestCodec
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		if c, ok := s.lookupCodec(part); ok {
+			responseCodec = c
+			break
+		}
+	}
+	return requestCodec, responseCodec
+}
+
+// withCodec transcodes a non-JSON request body to JSON and wraps w so JSON responses are
+// sent in the negotiated codec. The returned func must be called once the response is written.
+func (s *A2AServer) withCodec(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func(), error) {
+	requestCodec, responseCodec := s.negotiateCodec(r)
+
+	if !codec.IsJSON(requestCodec) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return w, r, func() {}, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body, err = codec.ToJSON(requestCodec, body)
+		if err != nil {
+			return w, r, func() {}, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.Header.Set("Content-Type", codec.JSONContentType)
+	}
+
+	if codec.IsJSON(responseCodec) {
+		return w, r, func() {}, nil
+	}
+	cw := &codecResponseWriter{ResponseWriter: w, codec: responseCodec, status: http.StatusOK}
+	return cw, r, cw.finish, nil
+}
+
+// codecResponseWriter buffers JSON responses and re-encodes them with codec.
+// Other content, such as SSE streams, is passed through unchanged.
+type codecResponseWriter struct {
+	http.ResponseWriter
+	codec       codec.Codec
+	status      int
+	wroteHeader bool
+	passthrough bool
+	buffer      bytes.Buffer
+}
+
+func (c *codecResponseWriter) WriteHeader(status int) {
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.status = status
+	if !strings.HasPrefix(c.Header().Get("Content-Type"), codec.JSONContentType) {
+		c.passthrough = true
+		c.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (c *codecResponseWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.passthrough {
+		return c.ResponseWriter.Write(p)
+	}
+	return c.buffer.Write(p)
+}
+
+// Unwrap gives http.ResponseController access to the connection
+func (c *codecResponseWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *codecResponseWriter) Flush() {
+	if !c.passthrough {
+		return
+	}
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish encodes the buffered JSON response with the codec and writes it
+func (c *codecResponseWriter) finish() {
+	if c.passthrough || !c.wroteHeader {
+		return
+	}
+	body, err := codec.FromJSON(c.codec, c.buffer.Bytes())
+	if err != nil {
+		log.Printf("Failed to encode %s response: %v", c.codec.ContentType(), err)
+		c.ResponseWriter.WriteHeader(c.status)
+		c.ResponseWriter.Write(c.buffer.Bytes())
+		return
+	}
+	c.Header().Set("Content-Type", c.codec.ContentType())
+	c.Header().Del("Content-Length")
+	c.ResponseWriter.WriteHeader(c.status)
+	if _, err := c.ResponseWriter.Write(body); err != nil {
+		log.Printf("Failed to write %s response: %v", c.codec.ContentType(), err)
+	}
+}
@@ -0,0 +1,392 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// CommandProtocol is how a CommandAgent exchanges tasks with its command
+type CommandProtocol int
+
+const (
+	// CommandLines writes the text of the task message to stdin and streams stdout as the
+	// text of the task's artifact
+	CommandLines CommandProtocol = iota
+	// CommandJSON writes the task as a CommandInput JSON line to stdin and reads CommandOutput
+	// JSON lines from stdout
+	CommandJSON
+)
+
+const (
+	// maxCommandLine is the longest stdout line a command may write
+	maxCommandLine = 1 << 20
+	// maxCommandStderr is how much of the end of stderr is kept for the message of failed tasks
+	maxCommandStderr = 4 << 10
+)
+
+// ScratchDirEnv is the environment variable giving commands the scratch space of their task,
+// when the task manager was configured WithScratchSpace
+const ScratchDirEnv = "A2A_SCRATCH_DIR"
+
+// CommandInput is the stdin line of commands speaking CommandJSON
+type CommandInput struct {
+	ID        string                 `json:"id"`
+	SessionID string                 `json:"sessionId"`
+	Text      string                 `json:"text"` // Text of the message parts
+	Message   types.Message          `json:"message"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CommandOutput is a stdout line of commands speaking CommandJSON. Each line sets one field.
+type CommandOutput struct {
+	Text     string          `json:"text,omitempty"`     // Appended to the streamed text artifact
+	Status   types.TaskState `json:"status,omitempty"`   // Interim state, e.g. "working", with Message
+	Message  string          `json:"message,omitempty"`  // Message of Status
+	Artifact *types.Artifact `json:"artifact,omitempty"` // A complete artifact; its index is assigned
+	Error    string          `json:"error,omitempty"`    // Fails the task once the command exits
+	Progress *float64        `json:"progress,omitempty"` // Completion percentage, with Message as step description
+}
+
+// CommandAgentOption configures a CommandAgent
+type CommandAgentOption func(*CommandAgent)
+
+// WithCommandProtocol sets the protocol of the command, CommandLines by default
+func WithCommandProtocol(protocol CommandProtocol) CommandAgentOption {
+	return func(a *CommandAgent) {
+		a.protocol = protocol
+	}
+}
+
+// WithCommandDir runs the command in dir, instead of the scratch space of its task or the
+// working directory of the agent
+func WithCommandDir(dir string) CommandAgentOption {
+	return func(a *CommandAgent) {
+		a.dir = dir
+	}
+}
+
+// WithCommandEnv adds "KEY=value" entries to the environment of the command
+func WithCommandEnv(env ...string) CommandAgentOption {
+	return func(a *CommandAgent) {
+		a.env = append(a.env, env...)
+	}
+}
+
+// WithCommandTimeout kills commands running longer than timeout, failing their task
+func WithCommandTimeout(timeout time.Duration) CommandAgentOption {
+	return func(a *CommandAgent) {
+		a.timeout = timeout
+	}
+}
+
+// WithCommandTaskManager configures the task manager storing the tasks of the agent
+func WithCommandTaskManager(opts ...TaskManagerOption) CommandAgentOption {
+	return func(a *CommandAgent) {
+		a.tmOptions = append(a.tmOptions, opts...)
+	}
+}
+
+// CommandAgent is a task manager running a local command for every task sent to it, so
+// existing command line tools can serve as agents. Each task starts a new process; its
+// output is streamed to subscribers as it is written, and the process is killed when the
+// task is canceled.
+type CommandAgent struct {
+	*InMemoryTaskManager
+	name      string
+	args      []string
+	protocol  CommandProtocol
+	dir       string
+	env       []string
+	timeout   time.Duration
+	tmOptions []TaskManagerOption
+
+	// argsFor returns the arguments of a task's process, args if nil, and a function
+	// releasing what the process leaves behind when it is killed
+	argsFor func(ctx context.Context, taskID string) (args []string, stop func())
+}
+
+// NewCommandAgent creates a CommandAgent running name with args
+func NewCommandAgent(name string, args []string, opts ...CommandAgentOption) *CommandAgent {
+	a := &CommandAgent{name: name, args: args}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.InMemoryTaskManager = NewInMemoryTaskManager(a.tmOptions...)
+	return a
+}
+
+// OnSendTask runs the command and returns the task once it exits. The command keeps running
+// if the client disconnects.
+func (a *CommandAgent) OnSendTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskResponse, error) {
+	params, err := decodeSendParams(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	task, err := a.upsertTask(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	a.run(context.WithoutCancel(ctx), task.ID, params)
+	task, err = a.getTask(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.SendTaskResponse{Result: a.appendTaskHistory(task, params.HistoryLength)}, nil
+}
+
+// OnSendTaskSubscribe runs the command and returns the final status of the task, for
+// servers that don't stream through TaskStreamer
+func (a *CommandAgent) OnSendTaskSubscribe(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
+	response, err := a.OnSendTask(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	task := response.Result
+	return &types.SendTaskStreamingResponse{
+		ID: request.ID,
+		Result: &types.TaskStatusUpdateEvent{
+			ID:     task.ID,
+			Status: task.Status,
+			Final:  true,
+		},
+	}, nil
+}
+
+// OnSendTaskStream starts the command and streams the task's events while it runs. The
+// command keeps running if the client disconnects.
+func (a *CommandAgent) OnSendTaskStream(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error) {
+	params, err := decodeSendParams(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	task, err := a.upsertTask(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := a.setupSSEConsumer(ctx, task.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	go a.run(context.WithoutCancel(ctx), task.ID, params)
+	return a.dequeueEventsForSSE(ctx, request.ID, task.ID, subscriber), nil
+}
+
+// run runs the command for a task until it exits and stores the outcome on the task
+func (a *CommandAgent) run(ctx context.Context, taskID string, params *types.TaskSendParams) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if a.timeout > 0 {
+		runCtx, cancel = context.WithTimeout(runCtx, a.timeout)
+		defer cancel()
+	}
+	unregister := a.OnCancel(ctx, taskID, func(ctx context.Context, task *types.Task) error {
+		cancel()
+		return nil
+	})
+	defer unregister()
+
+	a.setStatus(ctx, taskID, types.TaskWorking, "", false)
+
+	stdin, err := a.commandInput(taskID, params)
+	if err != nil {
+		a.setStatus(ctx, taskID, types.TaskFailed, err.Error(), true)
+		return
+	}
+	dir, env := a.dir, a.env
+	var scratch *ScratchSpace
+	if a.scratchRoot != "" {
+		if scratch, err = a.ScratchSpace(ctx, taskID); err != nil {
+			a.setStatus(ctx, taskID, types.TaskFailed, err.Error(), true)
+			return
+		}
+		env = append(env[:len(env):len(env)], ScratchDirEnv+"="+scratch.Dir())
+		if dir == "" {
+			dir = scratch.Dir()
+		}
+	}
+	args, stop := a.args, func() {}
+	if a.argsFor != nil {
+		args, stop = a.argsFor(ctx, taskID)
+	}
+	cmd := exec.CommandContext(runCtx, a.name, args...)
+	cmd.Cancel = func() error {
+		stop()
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = strings.NewReader(stdin)
+	stderr := &tailBuffer{limit: maxCommandStderr}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		a.setStatus(ctx, taskID, types.TaskFailed, fmt.Sprintf("failed to start %s: %v", a.name, err), true)
+		return
+	}
+
+	var streamer *ArtifactStreamer
+	text := func(delta string) {
+		if streamer == nil {
+			index, err := a.NextArtifactIndex(ctx, taskID)
+			if err != nil {
+				return
+			}
+			streamer = a.StreamArtifact(ctx, taskID, index)
+		}
+		if err := streamer.WriteText(delta); err != nil {
+			log.Printf("Failed to stream output of task %s: %v", taskID, err)
+		}
+	}
+	failure := a.readOutput(ctx, taskID, stdout, text, a.ProgressReporter(ctx, taskID))
+	if streamer != nil {
+		if err := streamer.Close(); err != nil {
+			log.Printf("Failed to store output of task %s: %v", taskID, err)
+		}
+	}
+
+	err = cmd.Wait()
+	var quotaErr error
+	if scratch != nil {
+		quotaErr = scratch.CheckQuota()
+	}
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		a.setStatus(ctx, taskID, types.TaskFailed, fmt.Sprintf("%s timed out after %s", a.name, a.timeout), true)
+	case runCtx.Err() != nil:
+		// Canceled; the task is marked canceled by cancelTask
+	case err != nil:
+		message := fmt.Sprintf("%s failed: %v", a.name, err)
+		if tail := strings.TrimSpace(stderr.String()); tail != "" {
+			message += ": " + tail
+		}
+		a.setStatus(ctx, taskID, types.TaskFailed, message, true)
+	case failure != "":
+		a.setStatus(ctx, taskID, types.TaskFailed, failure, true)
+	case quotaErr != nil:
+		a.setStatus(ctx, taskID, types.TaskFailed, fmt.Sprintf("%s used too much disk: %v", a.name, quotaErr), true)
+	default:
+		a.setStatus(ctx, taskID, types.TaskCompleted, "", true)
+	}
+}
+
+// commandInput returns what is written to the stdin of the command
+func (a *CommandAgent) commandInput(taskID string, params *types.TaskSendParams) (string, error) {
+	text := types.PartsText(params.Message.Parts)
+	if a.protocol != CommandJSON {
+		return text, nil
+	}
+	data, err := json.Marshal(&CommandInput{
+		ID:        taskID,
+		SessionID: params.SessionID,
+		Text:      text,
+		Message:   params.Message,
+		Metadata:  params.Metadata,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode command input: %v", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// readOutput handles the stdout of the command until it is closed, passing text to text.
+// It returns the error the command reported, if any.
+func (a *CommandAgent) readOutput(ctx context.Context, taskID string, stdout io.Reader, text func(string), progress *ProgressReporter) string {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64<<10), maxCommandLine)
+	var failure string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if a.protocol != CommandJSON {
+			text(line + "\n")
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var output CommandOutput
+		if err := json.Unmarshal([]byte(line), &output); err != nil {
+			log.Printf("Ignoring invalid output line of task %s: %v", taskID, err)
+			continue
+		}
+		switch {
+		case output.Text != "":
+			text(output.Text)
+		case output.Progress != nil:
+			if err := progress.SetProgress(*output.Progress, output.Message); err != nil {
+				log.Printf("Failed to report progress of task %s: %v", taskID, err)
+			}
+		case output.Status != "" && !isTerminalState(output.Status):
+			a.setStatus(ctx, taskID, output.Status, output.Message, false)
+		case output.Artifact != nil:
+			if err := a.addArtifact(ctx, taskID, *output.Artifact); err != nil {
+				log.Printf("Failed to store artifact of task %s: %v", taskID, err)
+			}
+		case output.Error != "":
+			failure = output.Error
+		}
+	}
+	if err := scanner.Err(); err != nil && failure == "" {
+		failure = fmt.Sprintf("failed to read output of %s: %v", a.name, err)
+		// Let the command exit instead of blocking on a full pipe
+		io.Copy(io.Discard, stdout)
+	}
+	return failure
+}
+
+// decodeSendParams returns the send params of a request, decoding them if they are still JSON
+func decodeSendParams(params interface{}) (*types.TaskSendParams, error) {
+	if p, ok := params.(*types.TaskSendParams); ok {
+		return p, nil
+	}
+	var decoded types.TaskSendParams
+	data, err := json.Marshal(params)
+	if err == nil {
+		err = json.Unmarshal(data, &decoded)
+	}
+	if err != nil {
+		return nil, invalidParams("%v", err)
+	}
+	return &decoded, nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	lock  sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = append(b.data[:0:0], b.data[len(b.data)-b.limit:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return string(b.data)
+}
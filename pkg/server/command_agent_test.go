@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// waitForPID waits until the command wrote its process id to path
+func waitForPID(t *testing.T, path string) int {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		data, _ := os.ReadFile(path)
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return pid
+		}
+	}
+	t.Fatal("the command didn't start")
+	return 0
+}
+
+func TestCommandAgentKillsCanceledCommands(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		script      string
+		timeout     time.Duration
+		cancel      bool
+		wantState   types.TaskState
+		wantMessage string
+	}{
+		{"completed", `echo $$ > "$PID_FILE"`, 0, false, types.TaskCompleted, ""},
+		{"failed", `echo $$ > "$PID_FILE"; echo oops >&2; exit 3`, 0, false, types.TaskFailed, "oops"},
+		{"canceled", `echo $$ > "$PID_FILE"; exec sleep 30`, 0, true, types.TaskCanceled, ""},
+		{"timed out", `echo $$ > "$PID_FILE"; exec sleep 30`, 200 * time.Millisecond, false, types.TaskFailed, "timed out"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pidFile := filepath.Join(t.TempDir(), "pid")
+			agent := NewCommandAgent("sh", []string{"-c", test.script},
+				WithCommandEnv("PID_FILE="+pidFile), WithCommandTimeout(test.timeout))
+			ctx := context.Background()
+
+			result := make(chan *types.Task, 1)
+			go func() {
+				response, err := agent.OnSendTask(ctx, &types.JSONRPCRequest{Params: &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}})
+				if err != nil {
+					t.Errorf("OnSendTask: %v", err)
+					result <- nil
+					return
+				}
+				result <- response.Result
+			}()
+			pid := waitForPID(t, pidFile)
+			if test.cancel {
+				if _, err := agent.OnCancelTask(ctx, &types.JSONRPCRequest{Params: &types.TaskIdParams{ID: "t1"}}); err != nil {
+					t.Fatalf("OnCancelTask: %v", err)
+				}
+			}
+
+			var task *types.Task
+			select {
+			case task = <-result:
+			case <-time.After(10 * time.Second):
+				t.Fatal("OnSendTask didn't return once the command was stopped")
+			}
+			if task == nil {
+				return
+			}
+			if task.Status.State != test.wantState {
+				t.Fatalf("task state = %s, want %s", task.Status.State, test.wantState)
+			}
+			if test.wantMessage != "" {
+				if task.Status.Message == nil || !strings.Contains(types.PartsText(task.Status.Message.Parts), test.wantMessage) {
+					t.Fatalf("task status message = %+v, want it to mention %q", task.Status.Message, test.wantMessage)
+				}
+			}
+			if err := syscall.Kill(pid, 0); err != syscall.ESRCH {
+				t.Fatalf("signaling the command's process = %v, want it gone", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// MimeTypeMetadataKey is the part metadata key used to declare a TextPart's content type (e.g. "text/markdown")
+const MimeTypeMetadataKey = types.MimeTypeMetadataKey
+
+// ContentConverter converts a message or artifact part into another output mode
+type ContentConverter func(part interface{}) (interface{}, error)
+
+// ContentTypeNotSupportedError is returned when handler output cannot be delivered in any accepted output mode
+type ContentTypeNotSupportedError struct {
+	TaskID   string
+	Mode     string
+	Accepted []string
+}
+
+func (e *ContentTypeNotSupportedError) Error() string {
+	return fmt.Sprintf("content type %q not supported, accepted output modes: %s", e.Mode, strings.Join(e.Accepted, ", "))
+}
+
+// JSONRPCError converts the error into its JSON-RPC representation
+func (e *ContentTypeNotSupportedError) JSONRPCError() *types.JSONRPCError {
+	rpcErr := types.ContentTypeNotSupportedError(e.TaskID, []string{e.Mode}, e.Accepted)
+	rpcErr.Message = e.Error()
+	return rpcErr
+}
+
+// WithContentConverter registers a converter used when handler output in mode from is not accepted but mode to is
+func WithContentConverter(from, to string, converter ContentConverter) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		if tm.contentConverters == nil {
+			tm.contentConverters = make(map[string]map[string]ContentConverter)
+		}
+		if tm.contentConverters[from] == nil {
+			tm.contentConverters[from] = make(map[string]ContentConverter)
+		}
+		tm.contentConverters[from][to] = converter
+	}
+}
+
+// PartMode returns the output mode of a message or artifact part
+func PartMode(part interface{}) string {
+	return types.PartMode(part)
+}
+
+// negotiateParts checks every part against the accepted output modes, converting parts when a converter is registered
+func (tm *InMemoryTaskManager) negotiateParts(accepted []string, parts types.Parts) (types.Parts, error) {
+	if len(accepted) == 0 {
+		return parts, nil
+	}
+
+	negotiated := make(types.Parts, 0, len(parts))
+	for _, part := range parts {
+		mode := PartMode(part)
+		if mode == "" || AreModalitiesCompatible(modeAliases(mode), accepted) {
+			negotiated = append(negotiated, part)
+			continue
+		}
+
+		converted, err := tm.convertPart(mode, accepted, part)
+		if err != nil {
+			return nil, err
+		}
+		convertedPart, ok := types.AsPart(converted)
+		if !ok {
+			return nil, fmt.Errorf("converter for %s returned %T, not a part", mode, converted)
+		}
+		negotiated = append(negotiated, convertedPart)
+	}
+	return negotiated, nil
+}
+
+// convertPart applies the first registered converter from mode to an accepted mode
+func (tm *InMemoryTaskManager) convertPart(mode string, accepted []string, part interface{}) (interface{}, error) {
+	for _, target := range accepted {
+		if converter := tm.contentConverters[mode][target]; converter != nil {
+			return converter(part)
+		}
+	}
+	return nil, &ContentTypeNotSupportedError{Mode: mode, Accepted: accepted}
+}
+
+// negotiateOutput validates a status message and artifacts against the task's accepted output modes
+func (tm *InMemoryTaskManager) negotiateOutput(accepted []string, status *types.TaskStatus, artifacts []types.Artifact) ([]types.Artifact, error) {
+	if len(accepted) == 0 {
+		return artifacts, nil
+	}
+
+	if status != nil && status.Message != nil {
+		parts, err := tm.negotiateParts(accepted, status.Message.Parts)
+		if err != nil {
+			return nil, err
+		}
+		message := *status.Message
+		message.Parts = parts
+		status.Message = &message
+	}
+
+	if artifacts == nil {
+		return nil, nil
+	}
+
+	negotiated := make([]types.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		parts, err := tm.negotiateParts(accepted, artifact.Parts)
+		if err != nil {
+			return nil, err
+		}
+		artifact.Parts = parts
+		negotiated = append(negotiated, artifact)
+	}
+	return negotiated, nil
+}
+
+// modeAliases returns the modes a part satisfies; plain text satisfies both "text" and "text/plain"
+func modeAliases(mode string) []string {
+	switch mode {
+	case "text", "text/plain":
+		return []string{"text", "text/plain"}
+	}
+	return []string{mode}
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	markdownEmphasis = regexp.MustCompile("(\\*\\*|__|\\*|`{1,3})")
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+)
+
+// MarkdownToTextConverter converts a text/markdown TextPart into a plain TextPart
+func MarkdownToTextConverter(part interface{}) (interface{}, error) {
+	var text string
+	var metadata map[string]interface{}
+	switch p := part.(type) {
+	case types.TextPart:
+		text, metadata = p.Text, p.Metadata
+	case *types.TextPart:
+		text, metadata = p.Text, p.Metadata
+	case map[string]interface{}:
+		text, _ = p["text"].(string)
+		metadata, _ = p["metadata"].(map[string]interface{})
+	default:
+		return nil, fmt.Errorf("cannot convert %T from markdown", part)
+	}
+
+	text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+
+	plainMetadata := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		if k != MimeTypeMetadataKey {
+			plainMetadata[k] = v
+		}
+	}
+	if len(plainMetadata) == 0 {
+		plainMetadata = nil
+	}
+
+	return types.TextPart{
+		Type:     "text",
+		Text:     text,
+		Metadata: plainMetadata,
+	}, nil
+}
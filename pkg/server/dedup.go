@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// CallerFunc identifies the caller of a request for deduplication purposes
+type CallerFunc func(r *http.Request) string
+
+// RemoteAddrCaller identifies callers by tenant and remote IP address
+func RemoteAddrCaller(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return TenantFromContext(r.Context()) + "|" + host
+}
+
+// WithRequestDeduplication returns the original response for requests repeating the
+// JSON-RPC id and params of a request from the same caller within window. Streaming methods
+// are never deduplicated. caller defaults to RemoteAddrCaller.
+func WithRequestDeduplication(window time.Duration, caller CallerFunc) ServerOption {
+	return func(s *A2AServer) {
+		if caller == nil {
+			caller = RemoteAddrCaller
+		}
+		s.dedup = &replayCache{
+			window:  window,
+			caller:  caller,
+			entries: make(map[string]*replayEntry),
+		}
+	}
+}
+
+// nonReplayableMethods lists methods whose responses are streams, or long polls returning
+// whatever arrived meanwhile, and cannot be replayed
+var nonReplayableMethods = map[string]bool{
+	types.SendTaskStreamingMethod: true,
+	types.ResubscribeMethod:       true,
+	types.ReplayTaskMethod:        true,
+	types.PollTaskEventsMethod:    true,
+}
+
+// replayEntry holds a recorded response; done is closed once it is complete
+type replayEntry struct {
+	done    chan struct{}
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// replayCache stores recent responses keyed by caller, method, JSON-RPC id and params
+type replayCache struct {
+	window    time.Duration
+	caller    CallerFunc
+	clock     utils.Clock
+	lock      sync.Mutex
+	entries   map[string]*replayEntry
+	nextSweep time.Time // When expired entries are dropped next
+}
+
+// key builds the cache key of a request, or "" if the request cannot be deduplicated.
+// The params are part of the key so a reused id with different params isn't answered
+// with the response to another request.
+func (c *replayCache) key(r *http.Request, method string, id interface{}, params interface{}) string {
+	if id == nil || nonReplayableMethods[method] {
+		return ""
+	}
+	idJSON, err := json.Marshal(id)
+	if err != nil {
+		return ""
+	}
+	// Params are still decoded generically here, and maps encode with sorted keys
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s|%s|%s|%x", c.caller(r), method, idJSON, sha256.Sum256(paramsJSON))
+}
+
+// begin returns the entry of a duplicate request, or registers a new in-flight entry
+func (c *replayCache) begin(key string) (*replayEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock.Now()
+	if !now.Before(c.nextSweep) {
+		// Sweeping once per window keeps expired entries for at most two windows
+		for k, entry := range c.entries {
+			if entry.expired(now) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.window)
+	}
+
+	if entry, ok := c.entries[key]; ok && !entry.expired(now) {
+		return entry, true
+	}
+	entry := &replayEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, false
+}
+
+// expired reports whether a completed entry is past its window; in-flight entries never expire
+func (entry *replayEntry) expired(now time.Time) bool {
+	return !entry.expires.IsZero() && now.After(entry.expires)
+}
+
+// finish stores the recorded response and releases waiting duplicates
+func (c *replayCache) finish(entry *replayEntry, recorder *responseRecorder) {
+	c.lock.Lock()
+	entry.status = recorder.status
+	entry.header = recorder.Header().Clone()
+	entry.body = recorder.body.Bytes()
+	entry.expires = c.clock.Now().Add(c.window)
+	c.lock.Unlock()
+	close(entry.done)
+}
+
+// replay writes a recorded response, waiting for it if the original is still in flight
+func (entry *replayEntry) replay(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-entry.done:
+	case <-r.Context().Done():
+		return
+	}
+
+	for k, v := range entry.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("X-A2A-Replayed", "true")
+	w.WriteHeader(entry.status)
+	if _, err := w.Write(entry.body); err != nil {
+		log.Printf("Failed to replay response: %v", err)
+	}
+}
+
+// responseRecorder forwards a response to the client while keeping a copy
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write keeps the whole response even if sending it fails, so the retry of a client that
+// lost the connection gets all of it
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// postRPC sends a JSON-RPC request body to the server's handler and returns the response
+func postRPC(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRequestDeduplication(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, NewInMemoryTaskManager(),
+		WithRequestDeduplication(time.Minute, nil), WithServerClock(clock))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	handler := s.Handler()
+	replayed := func(body string) bool {
+		t.Helper()
+		return postRPC(t, handler, body).Header().Get("X-A2A-Replayed") == "true"
+	}
+
+	getTask := `{"jsonrpc":"2.0","id":1,"method":"get_task","params":{"id":"t1"}}`
+	if replayed(getTask) {
+		t.Fatal("first request was replayed")
+	}
+	if !replayed(getTask) {
+		t.Fatal("repeated request was not replayed")
+	}
+	if replayed(`{"jsonrpc":"2.0","id":1,"method":"get_task","params":{"id":"t2"}}`) {
+		t.Fatal("request reusing an id with other params was replayed")
+	}
+	if replayed(`{"jsonrpc":"2.0","id":2,"method":"get_task","params":{"id":"t1"}}`) {
+		t.Fatal("request with another id was replayed")
+	}
+
+	poll := `{"jsonrpc":"2.0","id":3,"method":"tasks/events","params":{"id":"t1"}}`
+	postRPC(t, handler, poll)
+	if replayed(poll) {
+		t.Fatal("long poll was replayed")
+	}
+
+	clock.Advance(2 * time.Minute)
+	if replayed(getTask) {
+		t.Fatal("request was replayed after the window")
+	}
+}
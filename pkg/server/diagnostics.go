@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// LingeringSubscription describes SSE consumers still registered for a task that finished or no longer exists
+type LingeringSubscription struct {
+	Tenant      string
+	TaskID      string
+	State       types.TaskState // Empty if the task no longer exists
+	Subscribers int
+	OldestAge   time.Duration
+}
+
+// QueueDepth is the fill level of a multiplexed subscriber's event buffer
+type QueueDepth struct {
+	Tenant    string
+	SessionID string
+	Len       int
+	Cap       int
+}
+
+// Diagnostics is a snapshot of the subscriber bookkeeping of an InMemoryTaskManager
+type Diagnostics struct {
+	// OpenConsumers is the number of SSE consumer channels that have not been released
+	OpenConsumers int
+	// TrackedTasks is the number of tasks with an entry in the SSE subscriber registry
+	TrackedTasks int
+	// Lingering lists tasks in a terminal state, or deleted, that still have SSE consumers
+	Lingering []LingeringSubscription
+	// Multiplexed lists the buffer depths of multiplexed event subscribers
+	Multiplexed []QueueDepth
+}
+
+// HasLeaks reports whether the snapshot shows subscribers that outlived their task
+func (d Diagnostics) HasLeaks() bool {
+	return len(d.Lingering) > 0
+}
+
+// Diagnostics reports lingering SSE subscribers, open consumer channels and event queue depths
+func (tm *InMemoryTaskManager) Diagnostics() Diagnostics {
+	var diag Diagnostics
+	entries := tm.taskSSESubscribers.stats()
+	diag.TrackedTasks = len(entries)
+	for _, e := range entries {
+		diag.OpenConsumers += e.subscribers
+	}
+
+	tm.subscriberLock.Lock()
+	for sub := range tm.eventSubscriptions {
+		diag.Multiplexed = append(diag.Multiplexed, QueueDepth{
+			Tenant:    sub.tenant,
+			SessionID: sub.sessionID,
+			Len:       len(sub.events),
+			Cap:       cap(sub.events),
+		})
+	}
+	tm.subscriberLock.Unlock()
+
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	now := tm.clock.Now()
+	for _, e := range entries {
+		if e.subscribers == 0 {
+			continue
+		}
+		var state types.TaskState
+		if store := tm.tenants[e.key.tenant]; store != nil {
+			if task := store.tasks[e.key.taskID]; task != nil {
+				state = task.Status.State
+			}
+		}
+		if state != "" && !isTerminalState(state) {
+			continue
+		}
+		diag.Lingering = append(diag.Lingering, LingeringSubscription{
+			Tenant:      e.key.tenant,
+			TaskID:      e.key.taskID,
+			State:       state,
+			Subscribers: e.subscribers,
+			OldestAge:   now.Sub(e.oldest),
+		})
+	}
+	return diag
+}
+
+// LogDiagnostics logs a Diagnostics snapshot every interval while it shows leaks or
+// nearly full multiplexed queues. It blocks until ctx is canceled.
+func (tm *InMemoryTaskManager) LogDiagnostics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		diag := tm.Diagnostics()
+		for _, l := range diag.Lingering {
+			state := string(l.State)
+			if state == "" {
+				state = "deleted"
+			}
+			log.Printf("Diagnostics: task %s (tenant %q, %s) still has %d SSE subscribers, oldest %s",
+				l.TaskID, l.Tenant, state, l.Subscribers, l.OldestAge.Round(time.Second))
+		}
+		for _, q := range diag.Multiplexed {
+			if q.Len*4 >= q.Cap*3 {
+				log.Printf("Diagnostics: multiplexed subscriber (tenant %q, session %q) queue at %d/%d",
+					q.Tenant, q.SessionID, q.Len, q.Cap)
+			}
+		}
+	}
+}
+
+// isTerminalState reports whether a task in state can no longer change
+func isTerminalState(state types.TaskState) bool {
+	switch state {
+	case types.TaskCompleted, types.TaskCanceled, types.TaskFailed:
+		return true
+	}
+	return false
+}
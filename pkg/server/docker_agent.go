@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DockerConfig configures the containers of a DockerAgent
+type DockerConfig struct {
+	Image     string        // Image the instructions run in
+	Shell     string        // Shell running the instructions, "sh" if empty
+	Memory    string        // Memory limit, e.g. "512m"; unlimited if empty
+	CPUs      float64       // CPU limit; unlimited if 0
+	PidsLimit int           // Process limit; unlimited if 0
+	Network   string        // Network of the container, "none" if empty
+	ReadOnly  bool          // Mount the root filesystem read-only
+	Timeout   time.Duration // Containers running longer are removed, failing the task
+	Env       []string      // "KEY=value" entries set in the container
+	WorkDir   string        // Working directory in the container
+	Docker    string        // Docker CLI, "docker" if empty
+}
+
+// containerNameChars matches the characters docker doesn't allow in container names
+var containerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// DockerAgent is a sample task manager for code execution agents. It runs the text of each
+// task message as a shell script in a new container, which is removed when the script
+// exits, times out or the task is canceled. Stdout and stderr are streamed as the text of
+// the task's artifact.
+type DockerAgent struct {
+	*CommandAgent
+	config DockerConfig
+}
+
+// NewDockerAgent creates a DockerAgent running tasks in containers configured by config
+func NewDockerAgent(config DockerConfig, opts ...TaskManagerOption) (*DockerAgent, error) {
+	if config.Image == "" {
+		return nil, fmt.Errorf("docker image is not defined")
+	}
+	if config.Shell == "" {
+		config.Shell = "sh"
+	}
+	if config.Network == "" {
+		config.Network = "none"
+	}
+	if config.Docker == "" {
+		config.Docker = "docker"
+	}
+
+	a := &DockerAgent{config: config}
+	a.CommandAgent = NewCommandAgent(config.Docker, nil,
+		WithCommandTimeout(config.Timeout),
+		WithCommandTaskManager(opts...),
+	)
+	a.CommandAgent.argsFor = a.runArgs
+	return a, nil
+}
+
+// containerName returns a new name for a container of a task of tenant. The readable prefix
+// of the task id may be shared by other tasks; the hash of tenant and task id and the random
+// suffix make the name unique to one execution.
+func containerName(tenant, taskID string) string {
+	prefix := containerNameChars.ReplaceAllString(taskID, "_")
+	if len(prefix) > 32 {
+		prefix = prefix[:32]
+	}
+	sum := sha256.Sum256([]byte(tenant + "\x00" + taskID))
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return "a2a-" + prefix + "-" + hex.EncodeToString(sum[:8]) + "-" + hex.EncodeToString(suffix)
+}
+
+// runArgs returns the docker arguments running the script of a task, read from stdin, and
+// the function removing its container
+func (a *DockerAgent) runArgs(ctx context.Context, taskID string) ([]string, func()) {
+	c := a.config
+	name := containerName(TenantFromContext(ctx), taskID)
+	args := []string{
+		"run", "--rm", "-i",
+		"--name", name,
+		"--network", c.Network,
+		"--label", "a2a.task=" + taskID,
+		"--label", "a2a.tenant=" + TenantFromContext(ctx),
+	}
+	if c.Memory != "" {
+		args = append(args, "--memory", c.Memory)
+	}
+	if c.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(c.CPUs, 'f', -1, 64))
+	}
+	if c.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(c.PidsLimit))
+	}
+	if c.ReadOnly {
+		args = append(args, "--read-only")
+	}
+	for _, env := range c.Env {
+		args = append(args, "-e", env)
+	}
+	if c.WorkDir != "" {
+		args = append(args, "-w", c.WorkDir)
+	}
+	// Stderr joins stdout so both are streamed in order
+	args = append(args, c.Image, c.Shell, "-c", "exec 2>&1; exec "+c.Shell+" -s")
+	return args, func() { a.removeContainer(name) }
+}
+
+// removeContainer removes a container of a task, which outlives the docker CLI when it is killed
+func (a *DockerAgent) removeContainer(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, a.config.Docker, "rm", "-f", name).CombinedOutput(); err != nil {
+		log.Printf("Failed to remove container %s: %v: %s", name, err, output)
+	}
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// validContainerName matches the container names docker accepts
+var validContainerName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func TestContainerNameIsUnique(t *testing.T) {
+	long := strings.Repeat("x", 64)
+	for _, test := range []struct {
+		name           string
+		tenantA, taskA string
+		tenantB, taskB string
+	}{
+		{"ids sanitized alike", "", "a/b", "", "a_b"},
+		{"same id in two tenants", "acme", "t1", "globex", "t1"},
+		{"ids sharing a long prefix", "", long + "1", "", long + "2"},
+		{"same task twice", "acme", "t1", "acme", "t1"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := containerName(test.tenantA, test.taskA), containerName(test.tenantB, test.taskB)
+			if a == b {
+				t.Fatalf("both containers are named %s", a)
+			}
+			for _, name := range []string{a, b} {
+				if !validContainerName.MatchString(name) || len(name) > 64 {
+					t.Fatalf("container name %q isn't a valid docker name", name)
+				}
+			}
+		})
+	}
+}
+
+func TestDockerAgentRemovesItsOwnContainer(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "docker.log")
+	docker := filepath.Join(t.TempDir(), "docker")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\n"
+	if err := os.WriteFile(docker, []byte(script), 0o755); err != nil {
+		t.Fatalf("writing fake docker: %v", err)
+	}
+	agent, err := NewDockerAgent(DockerConfig{Image: "alpine", Docker: docker})
+	if err != nil {
+		t.Fatalf("NewDockerAgent: %v", err)
+	}
+
+	ctx := WithTenant(context.Background(), "acme")
+	args, stop := agent.runArgs(ctx, "t1")
+	stop()
+
+	var name string
+	for i, arg := range args {
+		if arg == "--name" {
+			name = args[i+1]
+		}
+	}
+	removed, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("reading docker log: %v", err)
+	}
+	if got := strings.TrimSpace(string(removed)); got != "rm -f "+name {
+		t.Fatalf("docker ran %q, want it to remove %s", got, name)
+	}
+}
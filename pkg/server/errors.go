@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"a2a-go/pkg/types"
+)
+
+// A2A JSON-RPC error codes returned for TaskManager errors
+const (
+	TaskNotFoundErrorCode                 = types.TaskNotFoundErrorCode
+	TaskNotCancelableErrorCode            = types.TaskNotCancelableErrorCode
+	PushNotificationNotSupportedErrorCode = types.PushNotificationNotSupportedErrorCode
+	UnsupportedOperationErrorCode         = types.UnsupportedOperationErrorCode
+	ContentTypeNotSupportedErrorCode      = types.ContentTypeNotSupportedErrorCode
+	InvalidAgentResponseErrorCode         = types.InvalidAgentResponseErrorCode
+	InvalidParamsErrorCode                = types.InvalidParamsErrorCode
+	InternalErrorCode                     = types.InternalErrorCode
+	TimeoutErrorCode                      = types.TimeoutErrorCode
+	ThrottledErrorCode                    = types.ThrottledErrorCode
+	PermissionDeniedErrorCode             = types.PermissionDeniedErrorCode
+)
+
+// TaskError is an error with a JSON-RPC error code, returned by TaskManager methods.
+// Errors compare equal with errors.Is when their codes match. Data is one of the error data
+// types of the types package for the A2A error codes.
+type TaskError struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *TaskError) Error() string {
+	return e.Message
+}
+
+// Is matches any TaskError with the same code, so errors.Is(err, ErrTaskNotFound) works for every task id
+func (e *TaskError) Is(target error) bool {
+	t, ok := target.(*TaskError)
+	return ok && t.Code == e.Code
+}
+
+// JSONRPCError converts the error into its JSON-RPC representation
+func (e *TaskError) JSONRPCError() *types.JSONRPCError {
+	return &types.JSONRPCError{
+		Code:    e.Code,
+		Message: e.Message,
+		Data:    e.Data,
+	}
+}
+
+// Sentinel errors for use with errors.Is
+var (
+	ErrTaskNotFound         = &TaskError{Code: TaskNotFoundErrorCode, Message: "Task not found"}
+	ErrTaskNotCancelable    = &TaskError{Code: TaskNotCancelableErrorCode, Message: "Task cannot be canceled"}
+	ErrUnsupportedOperation = &TaskError{Code: UnsupportedOperationErrorCode, Message: "This operation is not supported"}
+	ErrInvalidParams        = &TaskError{Code: InvalidParamsErrorCode, Message: "Invalid params"}
+
+	ErrPushNotificationNotSupported = &TaskError{Code: PushNotificationNotSupportedErrorCode, Message: "Push notifications are not supported"}
+	ErrInvalidAgentResponse         = &TaskError{Code: InvalidAgentResponseErrorCode, Message: "Invalid agent response"}
+)
+
+// taskNotFound returns ErrTaskNotFound for taskID
+func taskNotFound(taskID string) error {
+	return &TaskError{
+		Code:    TaskNotFoundErrorCode,
+		Message: "Task not found",
+		Data:    &types.TaskErrorData{TaskID: taskID},
+	}
+}
+
+// invalidTaskState returns ErrUnsupportedOperation for a task in the wrong state
+func invalidTaskState(taskID string, state types.TaskState, format string, args ...interface{}) error {
+	return &TaskError{
+		Code:    UnsupportedOperationErrorCode,
+		Message: fmt.Sprintf(format, args...),
+		Data:    &types.TaskErrorData{TaskID: taskID, State: state},
+	}
+}
+
+// invalidParams returns ErrInvalidParams with a description of the problem
+func invalidParams(format string, args ...interface{}) error {
+	return &TaskError{
+		Code:    InvalidParamsErrorCode,
+		Message: fmt.Sprintf("Invalid params: "+format, args...),
+	}
+}
+
+// jsonRPCErrorer is implemented by errors that carry their own JSON-RPC representation
+type jsonRPCErrorer interface {
+	JSONRPCError() *types.JSONRPCError
+}
+
+// toJSONRPCError maps an error returned by a TaskManager to the JSON-RPC error sent to the client
+func toJSONRPCError(ctx context.Context, err error) *types.JSONRPCError {
+	var withCode jsonRPCErrorer
+	if errors.As(err, &withCode) {
+		return withCode.JSONRPCError()
+	}
+	var rpcErr *types.JSONRPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	if timedOut(ctx) && errors.Is(err, context.DeadlineExceeded) {
+		return timeoutError(ctx)
+	}
+	return &types.JSONRPCError{
+		Code:    InternalErrorCode,
+		Message: err.Error(),
+	}
+}
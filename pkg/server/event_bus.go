@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"a2a-go/pkg/types"
+)
+
+// EventBus carries task events between the replicas of a server sharing a TaskStore, so a
+// stream can be served by any replica regardless of which one runs the task
+type EventBus interface {
+	// Publish sends message to every subscriber, including the publisher's own
+	Publish(ctx context.Context, message []byte) error
+	// Subscribe calls receive with every published message until ctx is canceled
+	Subscribe(ctx context.Context, receive func(message []byte)) error
+}
+
+// WithEventBus publishes the events of tasks to bus; ConsumeEvents delivers the events
+// other replicas publish. Use it with a shared TaskStore so every replica sees the tasks.
+func WithEventBus(bus EventBus) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.eventBus = bus
+	}
+}
+
+// busMessage is a task event as published on the event bus
+type busMessage struct {
+	Origin string          `json:"origin"` // Replica that published it
+	Tenant string          `json:"tenant"`
+	TaskID string          `json:"taskId"`
+	Kind   string          `json:"kind"`
+	Event  json.RawMessage `json:"event"`
+}
+
+const (
+	busStatusEvent   = "status"
+	busArtifactEvent = "artifact"
+	busErrorEvent    = "error"
+)
+
+// publishEvent sends an event of a task belonging to the tenant in ctx to the other replicas
+func (tm *InMemoryTaskManager) publishEvent(ctx context.Context, taskID string, event interface{}) {
+	if tm.eventBus == nil {
+		return
+	}
+	var kind string
+	switch event.(type) {
+	case *types.TaskStatusUpdateEvent:
+		kind = busStatusEvent
+	case *types.TaskArtifactUpdateEvent:
+		kind = busArtifactEvent
+	case *types.JSONRPCError:
+		kind = busErrorEvent
+	default:
+		return
+	}
+	data, err := json.Marshal(event)
+	if err == nil {
+		data, err = json.Marshal(busMessage{
+			Origin: tm.replicaID,
+			Tenant: TenantFromContext(ctx),
+			TaskID: taskID,
+			Kind:   kind,
+			Event:  data,
+		})
+	}
+	if err == nil {
+		err = tm.eventBus.Publish(ctx, data)
+	}
+	if err != nil {
+		log.Printf("Failed to publish event of task %s: %v", taskID, err)
+	}
+}
+
+// ConsumeEvents delivers the events other replicas publish on the event bus to the streams,
+// pollers and multiplexed subscribers of this replica, refreshing the task from the
+// TaskStore first. It blocks until ctx is canceled.
+func (tm *InMemoryTaskManager) ConsumeEvents(ctx context.Context) error {
+	if tm.eventBus == nil {
+		return errors.New("no event bus is configured")
+	}
+	return tm.eventBus.Subscribe(ctx, func(data []byte) {
+		var message busMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			log.Printf("Dropping undecodable event bus message: %v", err)
+			return
+		}
+		if message.Origin == tm.replicaID {
+			return
+		}
+		event, err := decodeBusEvent(message)
+		if err != nil {
+			log.Printf("Dropping event of task %s: %v", message.TaskID, err)
+			return
+		}
+		tm.deliverRemoteEvent(WithTenant(ctx, message.Tenant), message.TaskID, event)
+	})
+}
+
+// decodeBusEvent decodes the event of a bus message into its type
+func decodeBusEvent(message busMessage) (interface{}, error) {
+	var event interface{}
+	switch message.Kind {
+	case busStatusEvent:
+		event = &types.TaskStatusUpdateEvent{}
+	case busArtifactEvent:
+		event = &types.TaskArtifactUpdateEvent{}
+	case busErrorEvent:
+		event = &types.JSONRPCError{}
+	default:
+		return nil, fmt.Errorf("unknown event kind %q", message.Kind)
+	}
+	if err := json.Unmarshal(message.Event, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// deliverRemoteEvent hands an event published by another replica to local consumers. The
+// publishing replica already recorded it and sent push notifications.
+func (tm *InMemoryTaskManager) deliverRemoteEvent(ctx context.Context, taskID string, event interface{}) {
+	if tm.taskStore != nil {
+		task, err := tm.taskStore.Get(ctx, taskID)
+		if err != nil {
+			log.Printf("Failed to refresh task %s: %v", taskID, err)
+		} else if task != nil {
+			tm.refreshTask(ctx, task)
+		}
+	}
+
+	seq := 0
+	switch e := event.(type) {
+	case *types.TaskStatusUpdateEvent:
+		seq = e.Seq
+	case *types.TaskArtifactUpdateEvent:
+		seq = e.Seq
+	}
+	if seq > 0 {
+		tm.lock.Lock()
+		store := tm.store(ctx)
+		if seq > store.eventSeqs[taskID] {
+			store.eventSeqs[taskID] = seq
+			tm.bufferPollEvent(ctx, taskID, seq, event)
+		}
+		tm.lock.Unlock()
+	}
+
+	tm.publishMultiplexed(ctx, taskID, event)
+	tm.fanOutSSE(ctx, taskID, event)
+}
+
+// refreshTask replaces the local copy of a task with the one read from the TaskStore
+func (tm *InMemoryTaskManager) refreshTask(ctx context.Context, task *types.Task) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	sessionID := ""
+	if task.SessionID != nil {
+		sessionID = *task.SessionID
+	}
+	if current := store.tasks[task.ID]; current == nil {
+		store.sessions[sessionID] = append(store.sessions[sessionID], task.ID)
+	} else if current.SessionID != nil && *current.SessionID != sessionID {
+		removeSessionTask(store, *current.SessionID, task.ID)
+		store.sessions[sessionID] = append(store.sessions[sessionID], task.ID)
+	}
+	store.tasks[task.ID] = task
+	tm.indexMetadata(store, task)
+}
+
+// InMemoryEventBus delivers messages between task managers of the same process, e.g. in tests
+type InMemoryEventBus struct {
+	lock        sync.Mutex
+	subscribers map[*func([]byte)]struct{}
+}
+
+// NewInMemoryEventBus creates an InMemoryEventBus without subscribers
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{subscribers: make(map[*func([]byte)]struct{})}
+}
+
+func (b *InMemoryEventBus) Publish(ctx context.Context, message []byte) error {
+	b.lock.Lock()
+	receivers := make([]func([]byte), 0, len(b.subscribers))
+	for receive := range b.subscribers {
+		receivers = append(receivers, *receive)
+	}
+	b.lock.Unlock()
+
+	for _, receive := range receivers {
+		receive(message)
+	}
+	return nil
+}
+
+func (b *InMemoryEventBus) Subscribe(ctx context.Context, receive func(message []byte)) error {
+	b.lock.Lock()
+	b.subscribers[&receive] = struct{}{}
+	b.lock.Unlock()
+
+	<-ctx.Done()
+
+	b.lock.Lock()
+	delete(b.subscribers, &receive)
+	b.lock.Unlock()
+	return ctx.Err()
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisEventBus carries task events between replicas over Redis pub/sub
+type RedisEventBus struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisEventBus creates a RedisEventBus publishing on channel
+func NewRedisEventBus(client redis.UniversalClient, channel string) *RedisEventBus {
+	if channel == "" {
+		channel = "a2a:events"
+	}
+	return &RedisEventBus{client: client, channel: channel}
+}
+
+func (b *RedisEventBus) Publish(ctx context.Context, message []byte) error {
+	return b.client.Publish(ctx, b.channel, message).Err()
+}
+
+func (b *RedisEventBus) Subscribe(ctx context.Context, receive func(message []byte)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed so connection errors are returned
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			receive([]byte(message.Payload))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+
+	"a2a-go/pkg/types"
+)
+
+// sequenceEvent numbers a status or artifact event with the next event cursor of its task,
+// buffering it for pollers, and returns the cursor. Streams, polls and push notifications
+// carry the same cursor so clients can deliver each event once.
+func (tm *InMemoryTaskManager) sequenceEvent(ctx context.Context, taskID string, event interface{}) int {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	seq := store.eventSeqs[taskID] + 1
+	switch e := event.(type) {
+	case *types.TaskStatusUpdateEvent:
+		e.Seq = seq
+	case *types.TaskArtifactUpdateEvent:
+		e.Seq = seq
+	default:
+		return 0
+	}
+	store.eventSeqs[taskID] = seq
+	tm.bufferPollEvent(ctx, taskID, seq, event)
+	return seq
+}
+
+// EventCursor returns the cursor of the last event of a task belonging to the tenant in ctx,
+// 0 if it had none
+func (tm *InMemoryTaskManager) EventCursor(ctx context.Context, taskID string) int {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	return tm.store(ctx).eventSeqs[taskID]
+}
+
+// EventsSince returns the events of a task after cursor that are still buffered for long
+// polling, e.g. for a resubscribe handler to replay what the client missed, and whether
+// events after the cursor are missing from the buffer
+func (tm *InMemoryTaskManager) EventsSince(ctx context.Context, taskID string, cursor int) ([]interface{}, bool) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	latest := tm.store(ctx).eventSeqs[taskID]
+	buffer := tm.pollBuffers[subscriberKey(ctx, taskID)]
+	if buffer == nil {
+		return nil, latest > cursor
+	}
+	var events []interface{}
+	next := cursor + 1
+	missing := false
+	for _, record := range buffer.events {
+		if record.Seq <= cursor {
+			continue
+		}
+		if record.Seq > next {
+			missing = true
+		}
+		events = append(events, record.Event)
+		next = record.Seq + 1
+	}
+	return events, missing || next <= latest
+}
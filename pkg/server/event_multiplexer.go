@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"a2a-go/pkg/types"
+)
+
+// multiplexBufferSize is the number of events buffered per multiplexed subscriber
+const multiplexBufferSize = 64
+
+// EventFilter selects the task events delivered to a multiplexed subscriber
+type EventFilter struct {
+	SessionID string
+	// Owned limits the events to the tasks Principal created, or anonymous callers if nil
+	Owned     bool
+	Principal *Principal
+}
+
+// EventSubscriber is implemented by task managers that can deliver events of many tasks over one subscription
+type EventSubscriber interface {
+	SubscribeEvents(ctx context.Context, filter EventFilter) (<-chan interface{}, func())
+}
+
+// eventSubscription is a multiplexed subscriber registered with the InMemoryTaskManager
+type eventSubscription struct {
+	tenant    string
+	sessionID string
+	owned     bool
+	owner     string // Subject of the principal whose tasks are delivered, if owned
+	events    chan interface{}
+	closeOnce sync.Once
+}
+
+// SubscribeEvents registers a subscriber for the events of every task of the tenant in ctx
+// matching filter. The returned func unsubscribes and closes the channel.
+func (tm *InMemoryTaskManager) SubscribeEvents(ctx context.Context, filter EventFilter) (<-chan interface{}, func()) {
+	sub := &eventSubscription{
+		tenant:    TenantFromContext(ctx),
+		sessionID: filter.SessionID,
+		owned:     filter.Owned,
+		events:    make(chan interface{}, multiplexBufferSize),
+	}
+	if filter.Principal != nil {
+		sub.owner = filter.Principal.Subject
+	}
+
+	tm.subscriberLock.Lock()
+	if tm.eventSubscriptions == nil {
+		tm.eventSubscriptions = make(map[*eventSubscription]struct{})
+	}
+	tm.eventSubscriptions[sub] = struct{}{}
+	tm.subscriberLock.Unlock()
+
+	unsubscribe := func() {
+		tm.subscriberLock.Lock()
+		delete(tm.eventSubscriptions, sub)
+		tm.subscriberLock.Unlock()
+		sub.closeOnce.Do(func() { close(sub.events) })
+	}
+	return sub.events, unsubscribe
+}
+
+// publishMultiplexed delivers a task event to matching multiplexed subscribers without blocking
+func (tm *InMemoryTaskManager) publishMultiplexed(ctx context.Context, taskID string, event interface{}) {
+	tm.subscriberLock.Lock()
+	subscribed := len(tm.eventSubscriptions) > 0
+	tm.subscriberLock.Unlock()
+	if !subscribed {
+		return
+	}
+
+	tenant := TenantFromContext(ctx)
+	sessionID, owner := tm.taskSessionAndOwner(ctx, taskID)
+
+	tm.subscriberLock.Lock()
+	defer tm.subscriberLock.Unlock()
+
+	for sub := range tm.eventSubscriptions {
+		if sub.tenant != tenant || (sub.sessionID != "" && sub.sessionID != sessionID) || (sub.owned && sub.owner != owner) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Dropping event for task %s: multiplexed subscriber is not keeping up", taskID)
+		}
+	}
+}
+
+// taskSessionAndOwner returns the session of a task and the subject of the principal that
+// created it, or "" if unknown
+func (tm *InMemoryTaskManager) taskSessionAndOwner(ctx context.Context, taskID string) (string, string) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	task := store.tasks[taskID]
+	if task == nil || task.SessionID == nil {
+		return "", store.owners[taskID]
+	}
+	return *task.SessionID, store.owners[taskID]
+}
+
+// WithEventsEndpoint serves a multiplexed SSE stream of task events at path. Callers receive
+// the events of the tasks they created, per the principal of WithAuthorizer or WithPrincipal.
+// The task manager must implement EventSubscriber.
+func WithEventsEndpoint(path string) ServerOption {
+	return func(s *A2AServer) {
+		s.eventsEndpoint = path
+	}
+}
+
+// streamEvents handles multiplexed SSE subscriptions to the events of the tasks the caller
+// created, optionally filtered by the sessionId query parameter
+func (s *A2AServer) streamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	subscriber, ok := s.taskManager.(EventSubscriber)
+	if !ok {
+		http.Error(w, "Event subscriptions not supported", http.StatusNotImplemented)
+		return
+	}
+
+	ctx := r.Context()
+	if s.tenantResolver != nil {
+		tenant, err := s.tenantResolver(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		ctx = WithTenant(ctx, tenant)
+	}
+
+	sessionID := r.URL.Query().Get("sessionId")
+	ctx, err := s.authorize(ctx, r, EventsMethod, nil, sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := subscriber.SubscribeEvents(ctx, EventFilter{
+		SessionID: sessionID,
+		Owned:     true,
+		Principal: PrincipalFromContext(ctx),
+	})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w, flusher, closeStream := s.compressStream(withAcceptEncoding(ctx, r), w, flusher)
+	defer closeStream()
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	writer := s.newEventWriter(w)
+	defer writer.stop()
+
+	s.streams.started.Add(1)
+	for {
+		select {
+		case <-writer.due():
+			if err := writer.flush(); err != nil {
+				s.countWriteError(err)
+				return
+			}
+		case <-ctx.Done():
+			s.streams.clientDisconnected.Add(1)
+			return
+		case <-s.shutdown:
+			s.writeShutdownEvent(w, flusher, nil, "")
+			return
+		case event, ok := <-events:
+			if !ok {
+				if err := writer.flush(); err != nil {
+					s.countWriteError(err)
+					return
+				}
+				s.streams.completed.Add(1)
+				return
+			}
+			data, err := json.Marshal(&types.SendTaskStreamingResponse{Result: event})
+			if err != nil {
+				log.Printf("Failed to marshal multiplexed event: %v", err)
+				continue
+			}
+			// Cursors number the events of each task, so they can't identify events across tasks
+			if err := writer.write("", data); err != nil {
+				s.countWriteError(err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+// createTask creates a task on behalf of principal and publishes a status event for it
+func createTask(t *testing.T, tm *InMemoryTaskManager, principal *Principal, taskID, sessionID string) {
+	t.Helper()
+	ctx := WithPrincipal(context.Background(), principal)
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: taskID, SessionID: sessionID, Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	if err := tm.publishStatus(ctx, taskID, types.TaskWorking, nil, false); err != nil {
+		t.Fatalf("publishStatus: %v", err)
+	}
+}
+
+// receivedTasks drains the events buffered for a subscriber and returns their task ids
+func receivedTasks(events <-chan interface{}) []string {
+	var taskIDs []string
+	for {
+		select {
+		case event := <-events:
+			taskIDs = append(taskIDs, event.(*types.TaskStatusUpdateEvent).ID)
+		default:
+			return taskIDs
+		}
+	}
+}
+
+func TestSubscribeEventsFilters(t *testing.T) {
+	ctx := context.Background()
+	alice, bob := &Principal{Subject: "alice"}, &Principal{Subject: "bob"}
+	tm := NewInMemoryTaskManager()
+
+	all, unsubscribeAll := tm.SubscribeEvents(ctx, EventFilter{})
+	defer unsubscribeAll()
+	owned, unsubscribeOwned := tm.SubscribeEvents(ctx, EventFilter{Owned: true, Principal: alice})
+	defer unsubscribeOwned()
+	anonymous, unsubscribeAnonymous := tm.SubscribeEvents(ctx, EventFilter{Owned: true})
+	defer unsubscribeAnonymous()
+	session, unsubscribeSession := tm.SubscribeEvents(ctx, EventFilter{SessionID: "s2", Owned: true, Principal: alice})
+	defer unsubscribeSession()
+
+	createTask(t, tm, alice, "a1", "s1")
+	createTask(t, tm, bob, "b1", "s1")
+	createTask(t, tm, nil, "n1", "s1")
+	createTask(t, tm, alice, "a2", "s2")
+
+	for _, test := range []struct {
+		name   string
+		events <-chan interface{}
+		want   string
+	}{
+		{"unfiltered", all, "a1 b1 n1 a2"},
+		{"owned by alice", owned, "a1 a2"},
+		{"owned by anonymous callers", anonymous, "n1"},
+		{"owned by alice in a session", session, "a2"},
+	} {
+		if got := strings.Join(receivedTasks(test.events), " "); got != test.want {
+			t.Errorf("%s: received events of %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEventsEndpointDeliversOwnTasks(t *testing.T) {
+	tm := NewInMemoryTaskManager()
+	allowAll := AuthorizerFunc(func(ctx context.Context, request *AuthorizationRequest) error { return nil })
+	resolver := func(r *http.Request) (*Principal, error) {
+		return &Principal{Subject: r.Header.Get("X-User")}, nil
+	}
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, tm,
+		WithEventsEndpoint("/events"), WithAuthorizer(allowAll, resolver))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	request.Header.Set("X-User", "alice")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer response.Body.Close()
+
+	createTask(t, tm, &Principal{Subject: "bob"}, "bob-task", "")
+	createTask(t, tm, &Principal{Subject: "alice"}, "alice-task", "")
+
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		if !strings.Contains(line, "alice-task") {
+			t.Fatalf("first event = %s, want the event of alice's task", line)
+		}
+		return
+	}
+	t.Fatalf("stream ended without events: %v", scanner.Err())
+}
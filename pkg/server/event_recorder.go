@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// RecordedEvent is a task event captured for later replay
+type RecordedEvent struct {
+	Seq       int
+	Timestamp time.Time
+	Event     interface{}
+}
+
+// EventReplayer is implemented by task managers that can replay a task's recorded event stream
+type EventReplayer interface {
+	OnReplayTask(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error)
+}
+
+// RecordScope selects which tasks are recorded or audited
+type RecordScope int
+
+const (
+	// RecordAllTasks records every task
+	RecordAllTasks RecordScope = iota
+	// RecordFlaggedTasks records only tasks sent with the types.RecordStreamMetadataKey metadata flag set to true
+	RecordFlaggedTasks
+)
+
+// defaultRecordedEventLimit is how many events are kept per task by default
+const defaultRecordedEventLimit = 1000
+
+// RecordedEventStore is implemented by task stores that keep the recorded event streams of
+// tasks as well, so they can still be replayed after a restart
+type RecordedEventStore interface {
+	// AppendEvent adds event to the end of the recorded stream of a task, dropping its oldest
+	// events beyond limit
+	AppendEvent(ctx context.Context, taskID string, event RecordedEvent, limit int) error
+	// Events returns the recorded stream of a task in order
+	Events(ctx context.Context, taskID string) ([]RecordedEvent, error)
+}
+
+// AuditSink receives a copy of every streamed event of the audited tasks
+type AuditSink func(ctx context.Context, taskID string, event interface{})
+
+// WithEventRecording records every status and artifact event per task so it can be replayed
+func WithEventRecording() TaskManagerOption {
+	return WithEventRecordingScope(RecordAllTasks)
+}
+
+// WithEventRecordingScope records the status and artifact events of the tasks in scope
+func WithEventRecordingScope(scope RecordScope) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.eventLogs = make(map[taskKey][]RecordedEvent)
+		tm.recordScope = scope
+		if tm.recordLimit == 0 {
+			tm.recordLimit = defaultRecordedEventLimit
+		}
+	}
+}
+
+// WithEventRecordingLimit keeps at most limit recorded events per task, dropping the oldest,
+// 1000 by default
+func WithEventRecordingLimit(limit int) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.recordLimit = limit
+	}
+}
+
+// WithAuditSink tees the streamed events of the tasks in scope to sink.
+// The sink is called synchronously and should hand slow work off to another goroutine.
+func WithAuditSink(sink AuditSink, scope RecordScope) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.auditSink = sink
+		tm.auditScope = scope
+	}
+}
+
+// recordEvent appends an event to the task's event log, writing it through to the TaskStore
+// if it keeps recorded events, and tees it to the audit sink when enabled
+func (tm *InMemoryTaskManager) recordEvent(ctx context.Context, taskID string, event interface{}) {
+	if tm.eventLogs == nil && tm.auditSink == nil {
+		return
+	}
+
+	tm.lock.Lock()
+	flagged := streamRecordingRequested(tm.store(ctx).tasks[taskID])
+	if tm.eventLogs != nil && (tm.recordScope == RecordAllTasks || flagged) {
+		key := subscriberKey(ctx, taskID)
+		events := tm.eventLogs[key]
+		recorded := RecordedEvent{Seq: 1, Timestamp: tm.clock.Now(), Event: event}
+		if len(events) > 0 {
+			recorded.Seq = events[len(events)-1].Seq + 1
+		}
+		tm.eventLogs[key] = appendRecordedEvent(events, recorded, tm.recordLimit)
+		if store, ok := tm.taskStore.(RecordedEventStore); ok {
+			if err := store.AppendEvent(ctx, taskID, recorded, tm.recordLimit); err != nil {
+				log.Printf("Failed to persist recorded event of task %s: %v", taskID, err)
+			}
+		}
+	}
+	tm.lock.Unlock()
+
+	if tm.auditSink != nil && (tm.auditScope == RecordAllTasks || flagged) {
+		tm.auditSink(ctx, taskID, event)
+	}
+}
+
+// appendRecordedEvent appends event to events, dropping the oldest ones beyond limit
+func appendRecordedEvent(events []RecordedEvent, event RecordedEvent, limit int) []RecordedEvent {
+	events = append(events, event)
+	if limit > 0 && len(events) > limit {
+		// Copy so the dropped events don't stay reachable from the backing array
+		events = append([]RecordedEvent(nil), events[len(events)-limit:]...)
+	}
+	return events
+}
+
+// streamRecordingRequested reports whether a task was sent with the record stream metadata flag
+func streamRecordingRequested(task *types.Task) bool {
+	if task == nil {
+		return false
+	}
+	flag, _ := task.Metadata[types.RecordStreamMetadataKey].(bool)
+	return flag
+}
+
+// streamEventRecords returns the recorded events of a task in their wire form
+func (tm *InMemoryTaskManager) streamEventRecords(ctx context.Context, taskID string) []types.StreamEventRecord {
+	events := tm.TaskEvents(ctx, taskID)
+	records := make([]types.StreamEventRecord, 0, len(events))
+	for _, recorded := range events {
+		records = append(records, types.StreamEventRecord{
+			Seq:       recorded.Seq,
+			Timestamp: recorded.Timestamp.Format(time.RFC3339Nano),
+			Event:     recorded.Event,
+		})
+	}
+	return records
+}
+
+// TaskEvents returns the recorded event stream of a task, read from the TaskStore if it keeps
+// recorded events and none are in memory, e.g. after a restart
+func (tm *InMemoryTaskManager) TaskEvents(ctx context.Context, taskID string) []RecordedEvent {
+	tm.lock.Lock()
+	events := append([]RecordedEvent(nil), tm.eventLogs[subscriberKey(ctx, taskID)]...)
+	tm.lock.Unlock()
+
+	if store, ok := tm.taskStore.(RecordedEventStore); ok && len(events) == 0 {
+		stored, err := store.Events(ctx, taskID)
+		if err != nil {
+			log.Printf("Failed to read recorded events of task %s: %v", taskID, err)
+		}
+		return stored
+	}
+	return events
+}
+
+// ReplayTask delivers a task's recorded events to sink. speed 1 replays with the original
+// timing, 2 twice as fast and so on; speed 0 replays without delays.
+func (tm *InMemoryTaskManager) ReplayTask(ctx context.Context, taskID string, speed float64, sink func(event interface{}) error) error {
+	if tm.eventLogs == nil {
+		return errors.New("event recording is not enabled")
+	}
+
+	events := tm.TaskEvents(ctx, taskID)
+	if len(events) == 0 {
+		return taskNotFound(taskID)
+	}
+
+	for i, recorded := range events {
+		if i > 0 && speed > 0 {
+			delay := time.Duration(float64(recorded.Timestamp.Sub(events[i-1].Timestamp)) / speed)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		if err := sink(recorded.Event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnReplayTask handles requests to replay a task's recorded events as an SSE stream
+func (tm *InMemoryTaskManager) OnReplayTask(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error) {
+	replayParams := request.Params.(*types.TaskReplayParams)
+
+	if len(tm.TaskEvents(ctx, replayParams.ID)) == 0 {
+		return nil, taskNotFound(replayParams.ID)
+	}
+
+	responseChan := make(chan *types.SendTaskStreamingResponse)
+	go func() {
+		defer close(responseChan)
+		err := tm.ReplayTask(ctx, replayParams.ID, replayParams.Speed, func(event interface{}) error {
+			select {
+			case responseChan <- &types.SendTaskStreamingResponse{ID: request.ID, Result: event}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			responseChan <- &types.SendTaskStreamingResponse{
+				ID:    request.ID,
+				Error: types.InternalError(err.Error()),
+			}
+		}
+	}()
+	return responseChan, nil
+}
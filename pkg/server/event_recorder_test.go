@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+// recordedSeqs returns the sequence numbers of a task's recorded events
+func recordedSeqs(tm *InMemoryTaskManager, ctx context.Context, taskID string) []int {
+	var seqs []int
+	for _, recorded := range tm.TaskEvents(ctx, taskID) {
+		seqs = append(seqs, recorded.Seq)
+	}
+	return seqs
+}
+
+func TestRecordedEventsAreCappedAndPersisted(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryTaskStore()
+	tm := NewInMemoryTaskManager(WithEventRecording(), WithEventRecordingLimit(2), WithTaskStore(store))
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := tm.publishStatus(ctx, "t1", types.TaskWorking, nil, false); err != nil {
+			t.Fatalf("publishStatus: %v", err)
+		}
+	}
+	if seqs := recordedSeqs(tm, ctx, "t1"); len(seqs) != 2 || seqs[0] != 2 || seqs[1] != 3 {
+		t.Fatalf("recorded seqs = %v, want [2 3]", seqs)
+	}
+
+	// Another task manager reads the events back from the store
+	restarted := NewInMemoryTaskManager(WithEventRecording(), WithTaskStore(store))
+	if _, err := restarted.LoadTasks(ctx); err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	if seqs := recordedSeqs(restarted, ctx, "t1"); len(seqs) != 2 || seqs[0] != 2 || seqs[1] != 3 {
+		t.Fatalf("recorded seqs after restart = %v, want [2 3]", seqs)
+	}
+}
+
+func TestRecordedEventsAreFreedOnEviction(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager(WithEventRecording())
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	if err := tm.publishStatus(ctx, "t1", types.TaskCompleted, nil, true); err != nil {
+		t.Fatalf("publishStatus: %v", err)
+	}
+	if len(tm.TaskEvents(ctx, "t1")) != 1 {
+		t.Fatal("the task's status event wasn't recorded")
+	}
+	tm.evictSession(ctx, "s1")
+
+	tm.lock.Lock()
+	logs := len(tm.eventLogs)
+	tm.lock.Unlock()
+	if logs != 0 {
+		t.Fatalf("%d event logs kept after eviction, want none", logs)
+	}
+}
+
+func TestReplayUnknownTask(t *testing.T) {
+	tm := NewInMemoryTaskManager(WithEventRecording())
+	_, err := tm.OnReplayTask(context.Background(), &types.JSONRPCRequest{Params: &types.TaskReplayParams{ID: "missing"}})
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Code != TaskNotFoundErrorCode {
+		t.Fatalf("OnReplayTask = %v, want task not found", err)
+	}
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// TaskLogKind identifies the change a TaskLogEntry records
+type TaskLogKind string
+
+const (
+	TaskLogCreated  TaskLogKind = "created"  // Task is the new task
+	TaskLogMessage  TaskLogKind = "message"  // Message was appended to the history
+	TaskLogStatus   TaskLogKind = "status"   // Status replaced the status, its message joined the history and Artifacts were merged
+	TaskLogArtifact TaskLogKind = "artifact" // Artifacts[0] replaced the artifact with its index
+	TaskLogHistory  TaskLogKind = "history"  // History replaced the first Replaced messages of the history
+	TaskLogTransfer TaskLogKind = "transfer" // The task moved to SessionID, recording Transfer in its metadata
+	TaskLogMetadata TaskLogKind = "metadata" // Key was set to Value, or removed if Value is nil
+)
+
+// TaskLogEntry is one change in the event log of a task. Applying the entries of a task
+// in order reproduces the task exactly.
+type TaskLogEntry struct {
+	Seq       uint64 // Position in the task's log, starting at 1
+	Timestamp time.Time
+	TaskID    string
+	Version   uint64 // Task version after the change
+	Kind      TaskLogKind
+
+	Task                *types.Task
+	Message             *types.Message
+	Status              *types.TaskStatus
+	Artifacts           []types.Artifact
+	AcceptedOutputModes []string
+	History             []types.Message
+	Replaced            int
+	SessionID           string
+	Transfer            *types.TaskTransferRecord
+	Key                 string
+	Value               interface{}
+}
+
+// TaskEventStore persists the ordered event logs of tasks, the source of truth in event
+// sourcing mode
+type TaskEventStore interface {
+	// Append adds entry to the end of its task's log and returns its sequence number
+	Append(ctx context.Context, tenant string, entry TaskLogEntry) (uint64, error)
+	// Entries returns the log of a task in order
+	Entries(ctx context.Context, tenant, taskID string) ([]TaskLogEntry, error)
+	// TaskIDs returns the ids of the tasks of a tenant with a log, in creation order
+	TaskIDs(ctx context.Context, tenant string) ([]string, error)
+}
+
+// WithEventSourcing appends every change to a task to store before applying it, so tasks can
+// be materialized from their log at any version and restored with RestoreTasks
+func WithEventSourcing(store TaskEventStore) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.eventStore = store
+	}
+}
+
+// journal appends an entry to the log of its task when event sourcing is enabled and writes
+// it through to the TaskStore if one is set. Callers journal before changing the task, holding
+// tm.lock, so a failed append leaves it untouched.
+func (tm *InMemoryTaskManager) journal(ctx context.Context, entry TaskLogEntry) error {
+	if tm.eventStore != nil {
+		entry.Timestamp = tm.clock.Now()
+		if _, err := tm.eventStore.Append(ctx, TenantFromContext(ctx), entry); err != nil {
+			return fmt.Errorf("failed to append to task log: %w", err)
+		}
+	}
+	return tm.persist(ctx, entry)
+}
+
+// TaskLog returns the event log of a task belonging to the tenant in ctx
+func (tm *InMemoryTaskManager) TaskLog(ctx context.Context, taskID string) ([]TaskLogEntry, error) {
+	if tm.eventStore == nil {
+		return nil, errors.New("event sourcing is not enabled")
+	}
+	return tm.eventStore.Entries(ctx, TenantFromContext(ctx), taskID)
+}
+
+// MaterializeTask builds a task belonging to the tenant in ctx from its log, as it was at
+// version, or at its latest version if version is 0
+func (tm *InMemoryTaskManager) MaterializeTask(ctx context.Context, taskID string, version uint64) (*types.Task, error) {
+	entries, err := tm.TaskLog(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, taskNotFound(taskID)
+	}
+	return materializeTask(entries, version)
+}
+
+// RestoreTasks replaces the tasks of the tenant in ctx with the ones materialized from the
+// event store, e.g. after a restart, and returns how many were restored
+func (tm *InMemoryTaskManager) RestoreTasks(ctx context.Context) (int, error) {
+	if tm.eventStore == nil {
+		return 0, errors.New("event sourcing is not enabled")
+	}
+	tenant := TenantFromContext(ctx)
+	taskIDs, err := tm.eventStore.TaskIDs(ctx, tenant)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := newTenantStore()
+	for _, taskID := range taskIDs {
+		entries, err := tm.eventStore.Entries(ctx, tenant, taskID)
+		if err != nil {
+			return 0, err
+		}
+		task, err := materializeTask(entries, 0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to materialize task %s: %w", taskID, err)
+		}
+		restored.tasks[taskID] = task
+		if task.SessionID != nil {
+			restored.sessions[*task.SessionID] = append(restored.sessions[*task.SessionID], taskID)
+		}
+		for _, entry := range entries {
+			if entry.AcceptedOutputModes != nil {
+				restored.acceptedOutputModes[taskID] = entry.AcceptedOutputModes
+			}
+		}
+		tm.indexMetadata(restored, task)
+	}
+
+	tm.lock.Lock()
+	tm.tenants[tenant] = restored
+	tm.lock.Unlock()
+	return len(taskIDs), nil
+}
+
+// materializeTask applies entries in order up to version, or all of them if version is 0
+func materializeTask(entries []TaskLogEntry, version uint64) (*types.Task, error) {
+	var task *types.Task
+	for _, entry := range entries {
+		if version > 0 && entry.Version > version {
+			break
+		}
+		next, err := applyTaskLogEntry(task, entry)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", entry.Seq, err)
+		}
+		task = next
+	}
+	if task == nil {
+		return nil, errors.New("log does not start with the task's creation")
+	}
+	if version > 0 && task.Version != version {
+		return nil, fmt.Errorf("version %d not found in log", version)
+	}
+	return task, nil
+}
+
+// applyTaskLogEntry returns task with entry applied; task is not modified
+func applyTaskLogEntry(task *types.Task, entry TaskLogEntry) (*types.Task, error) {
+	if entry.Kind == TaskLogCreated {
+		if entry.Task == nil {
+			return nil, errors.New("created entry without task")
+		}
+		created := *entry.Task
+		created.History = append([]types.Message(nil), created.History...)
+		created.Metadata = copyMetadata(created.Metadata)
+		return &created, nil
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%s entry before the task was created", entry.Kind)
+	}
+
+	next := *task
+	switch entry.Kind {
+	case TaskLogMessage:
+		next.History = append(append([]types.Message(nil), task.History...), *entry.Message)
+	case TaskLogStatus:
+		next.Status = *entry.Status
+		if entry.Status.Message != nil {
+			next.History = append(append([]types.Message(nil), task.History...), *entry.Status.Message)
+		}
+		if entry.Artifacts != nil {
+			merged, err := mergeArtifacts(task.ID, task.Artifacts, entry.Artifacts)
+			if err != nil {
+				return nil, err
+			}
+			next.Artifacts = merged
+		}
+	case TaskLogArtifact:
+		next.Artifacts = replaceArtifact(task.Artifacts, entry.Artifacts[0])
+	case TaskLogHistory:
+		if entry.Replaced > len(task.History) {
+			return nil, fmt.Errorf("history entry replaces %d of %d messages", entry.Replaced, len(task.History))
+		}
+		next.History = append(append([]types.Message(nil), entry.History...), task.History[entry.Replaced:]...)
+	case TaskLogTransfer:
+		sessionID := entry.SessionID
+		next.SessionID = &sessionID
+		next.Transfers = append(append([]types.TaskTransferRecord(nil), task.Transfers...), *entry.Transfer)
+	case TaskLogMetadata:
+		next.Metadata = copyMetadata(task.Metadata)
+		if entry.Value == nil {
+			delete(next.Metadata, entry.Key)
+		} else {
+			if next.Metadata == nil {
+				next.Metadata = make(map[string]interface{})
+			}
+			next.Metadata[entry.Key] = entry.Value
+		}
+	default:
+		return nil, fmt.Errorf("unknown entry kind %q", entry.Kind)
+	}
+	next.Version = entry.Version
+	return &next, nil
+}
+
+// replaceArtifact returns artifacts with artifact replacing the one with its index, or added
+func replaceArtifact(artifacts []types.Artifact, artifact types.Artifact) []types.Artifact {
+	replaced := append([]types.Artifact(nil), artifacts...)
+	for i := range replaced {
+		if replaced[i].Index == artifact.Index {
+			replaced[i] = artifact
+			return replaced
+		}
+	}
+	replaced = append(replaced, artifact)
+	sortArtifacts(replaced)
+	return replaced
+}
+
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
+
+// InMemoryTaskEventStore keeps task event logs in memory
+type InMemoryTaskEventStore struct {
+	lock    sync.Mutex
+	tenants map[string]*eventStoreTenant
+}
+
+type eventStoreTenant struct {
+	logs  map[string][]TaskLogEntry
+	order []string
+}
+
+// NewInMemoryTaskEventStore creates an empty InMemoryTaskEventStore
+func NewInMemoryTaskEventStore() *InMemoryTaskEventStore {
+	return &InMemoryTaskEventStore{tenants: make(map[string]*eventStoreTenant)}
+}
+
+func (s *InMemoryTaskEventStore) Append(ctx context.Context, tenant string, entry TaskLogEntry) (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	logs := s.tenants[tenant]
+	if logs == nil {
+		logs = &eventStoreTenant{logs: make(map[string][]TaskLogEntry)}
+		s.tenants[tenant] = logs
+	}
+	if _, exists := logs.logs[entry.TaskID]; !exists {
+		logs.order = append(logs.order, entry.TaskID)
+	}
+	entry.Seq = uint64(len(logs.logs[entry.TaskID]) + 1)
+	if entry.Task != nil {
+		task := *entry.Task
+		task.Metadata = copyMetadata(task.Metadata)
+		entry.Task = &task
+	}
+	logs.logs[entry.TaskID] = append(logs.logs[entry.TaskID], entry)
+	return entry.Seq, nil
+}
+
+func (s *InMemoryTaskEventStore) Entries(ctx context.Context, tenant, taskID string) ([]TaskLogEntry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	logs := s.tenants[tenant]
+	if logs == nil {
+		return nil, nil
+	}
+	return append([]TaskLogEntry(nil), logs.logs[taskID]...), nil
+}
+
+func (s *InMemoryTaskEventStore) TaskIDs(ctx context.Context, tenant string) ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	logs := s.tenants[tenant]
+	if logs == nil {
+		return nil, nil
+	}
+	return append([]string(nil), logs.order...), nil
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"a2a-go/pkg/types"
+)
+
+// Extension is a protocol extension supported by the server. It is declared in the agent
+// card and activated for requests listing its URI in types.ExtensionsHeader.
+type Extension struct {
+	types.AgentExtension
+
+	// Activate runs before the method handler of requests activating the extension. It may
+	// return a context carrying extension state, or an error rejecting the request.
+	Activate func(ctx context.Context, request *types.JSONRPCRequest) (context.Context, error)
+}
+
+// WithExtension registers an extension. NewA2AServer fails if the URI is empty or already
+// registered.
+func WithExtension(extension Extension) ServerOption {
+	return func(s *A2AServer) {
+		if err := s.RegisterExtension(extension); err != nil && s.optionErr == nil {
+			s.optionErr = err
+		}
+	}
+}
+
+// RegisterExtension adds an extension to the server and its agent card
+func (s *A2AServer) RegisterExtension(extension Extension) error {
+	if extension.URI == "" {
+		return errors.New("extension URI is required")
+	}
+
+	s.extensionsLock.Lock()
+	defer s.extensionsLock.Unlock()
+
+	for _, registered := range s.extensions {
+		if registered.URI == extension.URI {
+			return fmt.Errorf("extension %s is already registered", extension.URI)
+		}
+	}
+	s.extensions = append(s.extensions, extension)
+	return nil
+}
+
+type extensionsKey struct{}
+
+// ActiveExtensions returns the URIs of the extensions activated for the request in ctx
+func ActiveExtensions(ctx context.Context) []string {
+	uris, _ := ctx.Value(extensionsKey{}).([]string)
+	return uris
+}
+
+// ExtensionActive reports whether the request in ctx activated the extension uri
+func ExtensionActive(ctx context.Context, uri string) bool {
+	return slices.Contains(ActiveExtensions(ctx), uri)
+}
+
+// activateExtensions activates the registered extensions a request asks for, in the order
+// asked, and reports them in the response header. Unknown extensions are ignored; a
+// required extension that isn't asked for rejects the request.
+func (s *A2AServer) activateExtensions(ctx context.Context, w http.ResponseWriter, r *http.Request, request *types.JSONRPCRequest) (context.Context, error) {
+	s.extensionsLock.RLock()
+	extensions := s.extensions
+	s.extensionsLock.RUnlock()
+	if len(extensions) == 0 {
+		return ctx, nil
+	}
+
+	requested := types.ParseExtensionsHeader(r.Header.Values(types.ExtensionsHeader))
+	for _, extension := range extensions {
+		if extension.Required && !slices.Contains(requested, extension.URI) {
+			return ctx, types.InvalidRequestError(fmt.Sprintf("extension %s is required", extension.URI))
+		}
+	}
+
+	var active []string
+	for _, uri := range requested {
+		i := slices.IndexFunc(extensions, func(extension Extension) bool { return extension.URI == uri })
+		if i < 0 {
+			continue
+		}
+		if activate := extensions[i].Activate; activate != nil {
+			var err error
+			if ctx, err = activate(ctx, request); err != nil {
+				return ctx, err
+			}
+		}
+		active = append(active, uri)
+	}
+	if len(active) == 0 {
+		return ctx, nil
+	}
+	w.Header().Set(types.ExtensionsHeader, types.FormatExtensionsHeader(active))
+	return context.WithValue(ctx, extensionsKey{}, active), nil
+}
+
+// cardExtensions adds the registered extensions to those declared by the agent card
+func (s *A2AServer) cardExtensions(card *types.AgentCard) {
+	s.extensionsLock.RLock()
+	defer s.extensionsLock.RUnlock()
+	if len(s.extensions) == 0 {
+		return
+	}
+	declared := slices.Clone(card.Capabilities.Extensions)
+	for _, extension := range s.extensions {
+		if card.Extension(extension.URI) == nil {
+			declared = append(declared, extension.AgentExtension)
+		}
+	}
+	card.Capabilities.Extensions = declared
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestExtensionsLeaveCallerCardUntouched(t *testing.T) {
+	card := &types.AgentCard{Name: "test", Capabilities: types.AgentCapabilities{
+		Extensions: []types.AgentExtension{{URI: "https://example.com/declared"}},
+	}}
+	s, err := NewA2AServer("localhost", 0, "/", card, NewInMemoryTaskManager(),
+		WithExtension(Extension{AgentExtension: types.AgentExtension{URI: "https://example.com/registered"}}))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	// Later changes to the caller's card don't reach the served one either
+	card.Capabilities.Extensions[0].URI = "https://example.com/changed"
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/agent.json", nil))
+	var served types.AgentCard
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("decoding card: %v", err)
+	}
+	if served.Extension("https://example.com/declared") == nil || served.Extension("https://example.com/registered") == nil {
+		t.Fatalf("served extensions = %+v, want the declared and the registered one", served.Capabilities.Extensions)
+	}
+	if len(card.Capabilities.Extensions) != 1 {
+		t.Fatalf("caller's card extensions = %+v, want only its own", card.Capabilities.Extensions)
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+	"io"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// WithFileResolvers sets the registry OpenFile resolves file part URIs with. By default
+// http, https and data URIs are resolved, with http requests restricted by
+// utils.DefaultURLPolicy since the URIs come from clients.
+func WithFileResolvers(resolvers *utils.FileResolvers) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.fileResolvers = resolvers
+	}
+}
+
+// OpenFile returns the content of a file part received from a client, whether inline or by URI
+func (tm *InMemoryTaskManager) OpenFile(ctx context.Context, file *types.FileContent) (io.ReadCloser, error) {
+	return tm.fileResolvers.Open(ctx, file)
+}
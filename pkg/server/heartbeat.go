@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// ProgressFunc returns the completion percentage of a task, 0 to 100, and whether it is known
+type ProgressFunc func() (percent float64, ok bool)
+
+// WithHeartbeat sends a "working" status event for tasks that have been working for
+// interval without a status update, so clients can tell slow tasks from stuck ones.
+// Heartbeats carry types.HeartbeatMetadataKey, and the task's progress under
+// types.ProgressMetadataKey if its handler set a ProgressFunc. They are not stored on the
+// task nor sent as push notifications. Clocks set WithClock that implement utils.TimerClock
+// time the heartbeats as well.
+func WithHeartbeat(interval time.Duration) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.heartbeatInterval = interval
+	}
+}
+
+// SetProgressFunc sets the function heartbeats of a task read its progress from
+func (tm *InMemoryTaskManager) SetProgressFunc(ctx context.Context, taskID string, progress ProgressFunc) {
+	tm.heartbeatLock.Lock()
+	defer tm.heartbeatLock.Unlock()
+	if tm.progressFuncs == nil {
+		tm.progressFuncs = make(map[taskKey]ProgressFunc)
+	}
+	tm.progressFuncs[subscriberKey(ctx, taskID)] = progress
+}
+
+// trackHeartbeat restarts the heartbeat timer of a task on every status event while it is
+// working, and stops it once the task is in another state
+func (tm *InMemoryTaskManager) trackHeartbeat(ctx context.Context, taskID string, event interface{}) {
+	status, ok := event.(*types.TaskStatusUpdateEvent)
+	if !ok || tm.heartbeatInterval <= 0 {
+		return
+	}
+	key := subscriberKey(ctx, taskID)
+
+	tm.heartbeatLock.Lock()
+	defer tm.heartbeatLock.Unlock()
+	if timer := tm.heartbeatTimers[key]; timer != nil {
+		timer.Stop()
+		delete(tm.heartbeatTimers, key)
+	}
+	if isTerminalState(status.Status.State) {
+		delete(tm.progressFuncs, key)
+		return
+	}
+	if status.Final || status.Status.State != types.TaskWorking {
+		return
+	}
+	if tm.heartbeatTimers == nil {
+		tm.heartbeatTimers = make(map[taskKey]utils.Timer)
+	}
+	ctx = context.WithoutCancel(ctx)
+	tm.heartbeatTimers[key] = utils.AfterFunc(tm.clock, tm.heartbeatInterval, func() {
+		tm.sendHeartbeat(ctx, taskID)
+	})
+}
+
+// sendHeartbeat sends a heartbeat for a task that is still working
+func (tm *InMemoryTaskManager) sendHeartbeat(ctx context.Context, taskID string) {
+	tm.lock.Lock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil || task.Status.State != types.TaskWorking {
+		tm.lock.Unlock()
+		return
+	}
+	status := task.Status
+	tm.lock.Unlock()
+
+	status.Timestamp = tm.clock.Now().Format(time.RFC3339)
+	metadata := map[string]interface{}{types.HeartbeatMetadataKey: true}
+	tm.heartbeatLock.Lock()
+	progress := tm.progressFuncs[subscriberKey(ctx, taskID)]
+	tm.heartbeatLock.Unlock()
+	if progress != nil {
+		if percent, ok := progress(); ok {
+			metadata[types.ProgressMetadataKey] = percent
+		}
+	}
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{
+		ID:       taskID,
+		Status:   status,
+		Metadata: metadata,
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// heartbeats returns the heartbeats recorded for a task
+func heartbeats(tm *InMemoryTaskManager, ctx context.Context, taskID string) []*types.TaskStatusUpdateEvent {
+	var sent []*types.TaskStatusUpdateEvent
+	for _, recorded := range tm.TaskEvents(ctx, taskID) {
+		if status, ok := recorded.Event.(*types.TaskStatusUpdateEvent); ok && status.IsHeartbeat() {
+			sent = append(sent, status)
+		}
+	}
+	return sent
+}
+
+func TestHeartbeatsFollowTheClock(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	tm := NewInMemoryTaskManager(WithClock(clock), WithHeartbeat(time.Minute), WithEventRecording())
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	tm.SetProgressFunc(ctx, "t1", func() (float64, bool) { return 50, true })
+
+	// The steps run in order, each one after the clock advanced by advance
+	for _, step := range []struct {
+		name     string
+		state    types.TaskState // Status published before the clock advances, if any
+		advance  time.Duration
+		wantSent int
+		wantLast string // Timestamp of the last heartbeat
+	}{
+		{"working", types.TaskWorking, 59 * time.Second, 0, ""},
+		{"interval passed", "", time.Second, 1, "2025-01-01T00:01:00Z"},
+		{"every interval", "", 2*time.Minute + 30*time.Second, 3, "2025-01-01T00:03:00Z"},
+		{"status update restarts the interval", types.TaskWorking, 59 * time.Second, 3, "2025-01-01T00:03:00Z"},
+		{"interval after the update", "", time.Second, 4, "2025-01-01T00:04:30Z"},
+		{"completed", types.TaskCompleted, time.Hour, 4, "2025-01-01T00:04:30Z"},
+	} {
+		t.Run(step.name, func(t *testing.T) {
+			if step.state != "" {
+				if err := tm.publishStatus(ctx, "t1", step.state, nil, isTerminalState(step.state)); err != nil {
+					t.Fatalf("publishStatus: %v", err)
+				}
+			}
+			clock.Advance(step.advance)
+
+			sent := heartbeats(tm, ctx, "t1")
+			if len(sent) != step.wantSent {
+				t.Fatalf("%d heartbeats sent, want %d", len(sent), step.wantSent)
+			}
+			if len(sent) == 0 {
+				return
+			}
+			last := sent[len(sent)-1]
+			if last.Status.Timestamp != step.wantLast || last.Metadata[types.ProgressMetadataKey] != 50.0 {
+				t.Fatalf("last heartbeat at %s with metadata %v, want it at %s with progress 50", last.Status.Timestamp, last.Metadata, step.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// defaultCompactionTimeout bounds a single run of the HistoryCompactor
+const defaultCompactionTimeout = time.Minute
+
+// HistoryCompactor shrinks a task's history once it grows past the configured threshold.
+// Compact receives the current history and returns the messages that replace it.
+type HistoryCompactor interface {
+	Compact(ctx context.Context, taskID string, history []types.Message) ([]types.Message, error)
+}
+
+// KeepLastCompactor keeps only the most recent N messages
+type KeepLastCompactor struct {
+	N int
+}
+
+// Compact drops all but the last N messages
+func (c KeepLastCompactor) Compact(ctx context.Context, taskID string, history []types.Message) ([]types.Message, error) {
+	if c.N <= 0 {
+		return []types.Message{}, nil
+	}
+	if len(history) <= c.N {
+		return history, nil
+	}
+	return append([]types.Message{}, history[len(history)-c.N:]...), nil
+}
+
+// SummarizeFunc condenses messages into a single summary message, e.g. by calling an LLM
+type SummarizeFunc func(ctx context.Context, messages []types.Message) (types.Message, error)
+
+// SummarizingCompactor replaces older messages with a summary message and keeps the last Keep messages verbatim
+type SummarizingCompactor struct {
+	Summarize SummarizeFunc
+	Keep      int
+}
+
+// Compact summarizes everything but the last Keep messages
+func (c SummarizingCompactor) Compact(ctx context.Context, taskID string, history []types.Message) ([]types.Message, error) {
+	if c.Summarize == nil {
+		return nil, errors.New("summarizing compactor has no summarize function")
+	}
+	keep := c.Keep
+	if keep < 0 {
+		keep = 0
+	}
+	if len(history) <= keep {
+		return history, nil
+	}
+
+	cut := len(history) - keep
+	summary, err := c.Summarize(ctx, history[:cut])
+	if err != nil {
+		return nil, err
+	}
+
+	compacted := make([]types.Message, 0, keep+1)
+	compacted = append(compacted, summary)
+	return append(compacted, history[cut:]...), nil
+}
+
+// compactHistory starts the configured HistoryCompactor in the background when a task's
+// history exceeds the threshold, one compaction per task at a time, so requests don't wait
+// for it. The compactor runs without holding the lock and is canceled after compactTimeout;
+// messages appended meanwhile are preserved. The result is dropped if the task was replaced
+// meanwhile, e.g. evicted and created again or updated by another replica.
+func (tm *InMemoryTaskManager) compactHistory(ctx context.Context, taskID string) {
+	if tm.historyCompactor == nil || tm.historyThreshold <= 0 {
+		return
+	}
+
+	key := subscriberKey(ctx, taskID)
+	tm.lock.Lock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil || len(task.History) <= tm.historyThreshold || tm.compacting[key] {
+		tm.lock.Unlock()
+		return
+	}
+	tm.compacting[key] = true
+	history := append([]types.Message{}, task.History...)
+	tm.lock.Unlock()
+
+	go func() {
+		defer func() {
+			tm.lock.Lock()
+			delete(tm.compacting, key)
+			tm.lock.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tm.compactTimeout)
+		defer cancel()
+		tm.applyCompaction(ctx, task, history)
+	}()
+}
+
+// applyCompaction replaces history, the start of task's history, with the compactor's result
+func (tm *InMemoryTaskManager) applyCompaction(ctx context.Context, task *types.Task, history []types.Message) {
+	compacted, err := tm.historyCompactor.Compact(ctx, task.ID, history)
+	if err != nil {
+		log.Printf("Failed to compact history of task %s: %v", task.ID, err)
+		return
+	}
+
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	if tm.store(ctx).tasks[task.ID] != task {
+		return
+	}
+	if err := tm.journal(ctx, TaskLogEntry{
+		TaskID:   task.ID,
+		Version:  task.Version + 1,
+		Kind:     TaskLogHistory,
+		History:  compacted,
+		Replaced: len(history),
+	}); err != nil {
+		log.Printf("Failed to compact history of task %s: %v", task.ID, err)
+		return
+	}
+	task.History = append(compacted, task.History[len(history):]...)
+	task.Version++
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// blockingCompactor keeps the last message once released, reporting each compaction it starts
+type blockingCompactor struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingCompactor) Compact(ctx context.Context, taskID string, history []types.Message) ([]types.Message, error) {
+	c.entered <- struct{}{}
+	<-c.release
+	return KeepLastCompactor{N: 1}.Compact(ctx, taskID, history)
+}
+
+// historyTexts returns the texts of a task's messages
+func historyTexts(tm *InMemoryTaskManager, ctx context.Context, taskID string) []string {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	var texts []string
+	for _, message := range tm.store(ctx).tasks[taskID].History {
+		texts = append(texts, message.Parts[0].(types.TextPart).Text)
+	}
+	return texts
+}
+
+// waitCompacted waits until no compaction is running
+func waitCompacted(t *testing.T, tm *InMemoryTaskManager) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tm.lock.Lock()
+		running := len(tm.compacting)
+		tm.lock.Unlock()
+		if running == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("compaction didn't finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startCompaction sends messages to a task until its history passes the compactor's threshold
+// of 2 and returns once the compactor is running, checking the request didn't wait for it
+func startCompaction(t *testing.T, ctx context.Context, compactor *blockingCompactor) *InMemoryTaskManager {
+	t.Helper()
+	tm := NewInMemoryTaskManager(WithHistoryCompactor(2, compactor))
+	for _, text := range []string{"m1", "m2", "m3"} {
+		if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage(text)}); err != nil {
+			t.Fatalf("upsertTask: %v", err)
+		}
+	}
+	<-compactor.entered
+	return tm
+}
+
+func TestCompactHistoryKeepsMessagesAppendedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	compactor := &blockingCompactor{entered: make(chan struct{}, 4), release: make(chan struct{})}
+	tm := startCompaction(t, ctx, compactor)
+
+	// The message past the threshold doesn't start a second compaction of the same history
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage("m4")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	if len(compactor.entered) != 0 {
+		t.Fatal("a second compaction started while the first was running")
+	}
+	close(compactor.release)
+	waitCompacted(t, tm)
+
+	if texts := historyTexts(tm, ctx, "t1"); len(texts) != 2 || texts[0] != "m3" || texts[1] != "m4" {
+		t.Fatalf("history = %v, want [m3 m4]", texts)
+	}
+}
+
+func TestCompactHistoryDropsResultForReplacedTask(t *testing.T) {
+	ctx := context.Background()
+	compactor := &blockingCompactor{entered: make(chan struct{}, 4), release: make(chan struct{})}
+	tm := startCompaction(t, ctx, compactor)
+
+	// Another replica replaces the task with a longer history of its own
+	replaced := &types.Task{ID: "t1", Version: 10}
+	for _, text := range []string{"r1", "r2", "r3", "r4"} {
+		replaced.History = append(replaced.History, textMessage(text))
+	}
+	tm.lock.Lock()
+	tm.store(ctx).tasks["t1"] = replaced
+	tm.lock.Unlock()
+	close(compactor.release)
+	waitCompacted(t, tm)
+
+	if texts := historyTexts(tm, ctx, "t1"); len(texts) != 4 || texts[0] != "r1" {
+		t.Fatalf("history = %v, want the replaced task's history untouched", texts)
+	}
+}
+
+// stuckCompactor runs until its context is done
+type stuckCompactor struct{}
+
+func (stuckCompactor) Compact(ctx context.Context, taskID string, history []types.Message) ([]types.Message, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCompactHistoryTimesOut(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager(WithHistoryCompactor(1, stuckCompactor{}))
+	tm.compactTimeout = 10 * time.Millisecond
+	for _, text := range []string{"m1", "m2"} {
+		if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", Message: textMessage(text)}); err != nil {
+			t.Fatalf("upsertTask: %v", err)
+		}
+	}
+	// The stuck compactor is canceled well before waitCompacted gives up
+	waitCompacted(t, tm)
+
+	if texts := historyTexts(tm, ctx, "t1"); len(texts) != 2 {
+		t.Fatalf("history = %v, want it untouched", texts)
+	}
+}
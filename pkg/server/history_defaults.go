@@ -0,0 +1,50 @@
+package server
+
+import "a2a-go/pkg/types"
+
+const (
+	// HistoryNone omits the history when a request does not set historyLength
+	HistoryNone = 0
+	// HistoryAll includes the full history when a request does not set historyLength
+	HistoryAll = -1
+)
+
+// WithDefaultHistoryLength sets how much history is returned when a request does not set
+// historyLength: HistoryNone, HistoryAll or the last n messages. Without this option the
+// full history is returned if the agent card declares stateTransitionHistory, and none otherwise.
+func WithDefaultHistoryLength(n int) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.defaultHistory = n
+		tm.defaultHistorySet = true
+	}
+}
+
+// agentCardAware is implemented by task managers that adapt their defaults to the agent card they serve
+type agentCardAware interface {
+	applyAgentCard(card *types.AgentCard)
+}
+
+// applyAgentCard derives the default history length from the card's stateTransitionHistory
+// capability, and keeps the card for the metadata schemas of its skills
+func (tm *InMemoryTaskManager) applyAgentCard(card *types.AgentCard) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	tm.agentCard = card
+	if !tm.defaultHistorySet && card.Capabilities.StateTransitionHistory {
+		tm.defaultHistory = HistoryAll
+	}
+}
+
+// historyLength resolves the number of messages to return; negative means all.
+// Explicit negative lengths keep returning no history as before.
+func (tm *InMemoryTaskManager) historyLength(requested *int) int {
+	if requested != nil {
+		if *requested < 0 {
+			return HistoryNone
+		}
+		return *requested
+	}
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	return tm.defaultHistory
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// SetInputRequired moves a task to input-required with prompt as the status message and returns
+// a channel that receives the follow-up message once ResumeWithInput is called for the task
+func (tm *InMemoryTaskManager) SetInputRequired(ctx context.Context, taskID string, prompt types.Message) (<-chan types.Message, error) {
+	return tm.setInputRequired(ctx, taskID, prompt)
+}
+
+// setInputRequired implements SetInputRequired, returning the waiter so it can be unregistered
+func (tm *InMemoryTaskManager) setInputRequired(ctx context.Context, taskID string, prompt types.Message) (chan types.Message, error) {
+	key := subscriberKey(ctx, taskID)
+	waiter := make(chan types.Message, 1)
+
+	// Register before publishing the state so a fast follow-up cannot miss the waiter
+	tm.lock.Lock()
+	if tm.inputWaiters == nil {
+		tm.inputWaiters = make(map[taskKey]chan types.Message)
+	}
+	tm.inputWaiters[key] = waiter
+	tm.lock.Unlock()
+
+	status := types.TaskStatus{
+		State:     types.TaskInputNeeded,
+		Message:   &prompt,
+		Timestamp: tm.clock.Now().Format(time.RFC3339),
+	}
+	task, err := tm.updateStore(ctx, taskID, status, nil)
+	if err != nil {
+		tm.removeInputWaiter(key, waiter)
+		return nil, err
+	}
+
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: task.Status,
+		Final:  true,
+	})
+	return waiter, nil
+}
+
+// AwaitInput moves a task to input-required with prompt and blocks until the follow-up
+// message arrives through ResumeWithInput or ctx is done
+func (tm *InMemoryTaskManager) AwaitInput(ctx context.Context, taskID string, prompt types.Message) (types.Message, error) {
+	waiter, err := tm.setInputRequired(ctx, taskID, prompt)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	select {
+	case message := <-waiter:
+		return message, nil
+	case <-ctx.Done():
+		tm.removeInputWaiter(subscriberKey(ctx, taskID), waiter)
+		return types.Message{}, ctx.Err()
+	}
+}
+
+// ResumeWithInput is called by OnSendTask implementations for every incoming message.
+// If a handler is waiting for input on the task, the message is appended to the history,
+// the task moves back to working and the message is handed to the waiting handler;
+// resumed reports whether that happened. Otherwise nothing is changed and the caller
+// should start its handler as usual.
+func (tm *InMemoryTaskManager) ResumeWithInput(ctx context.Context, params *types.TaskSendParams) (task *types.Task, resumed bool, err error) {
+	key := subscriberKey(ctx, params.ID)
+
+	tm.lock.Lock()
+	waiter := tm.inputWaiters[key]
+	current := tm.store(ctx).tasks[params.ID]
+	if waiter == nil || current == nil || current.Status.State != types.TaskInputNeeded || taskCollision(current, params.SessionID) != "" {
+		tm.lock.Unlock()
+		return nil, false, nil
+	}
+	delete(tm.inputWaiters, key)
+	tm.lock.Unlock()
+
+	if _, err := tm.upsertTask(ctx, params); err != nil {
+		return nil, false, err
+	}
+	task, err = tm.transitionTask(ctx, params.ID, types.TaskInputNeeded, types.TaskWorking)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to resume task: %w", err)
+	}
+
+	waiter <- params.Message
+	return task, true, nil
+}
+
+// removeInputWaiter unregisters waiter if it is still the task's current waiter
+func (tm *InMemoryTaskManager) removeInputWaiter(key taskKey, waiter chan types.Message) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	if tm.inputWaiters[key] == waiter {
+		delete(tm.inputWaiters, key)
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// WithListenNetwork selects the network passed to net.Listen: "tcp" (default, dual-stack
+// when the host is "::" or empty), "tcp4" for IPv4 only or "tcp6" for IPv6 only
+func WithListenNetwork(network string) ServerOption {
+	return func(s *A2AServer) {
+		s.network = network
+	}
+}
+
+// Addr returns the host:port address the server listens on, bracketing IPv6 literals
+func (s *A2AServer) Addr() string {
+	host := strings.TrimSuffix(strings.TrimPrefix(s.host, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(s.port))
+}
+
+// listen opens the server's listener on the configured network
+func (s *A2AServer) listen() (net.Listener, error) {
+	network := s.network
+	if network == "" {
+		network = "tcp"
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("unsupported listen network %q", network)
+	}
+	return net.Listen(network, s.Addr())
+}
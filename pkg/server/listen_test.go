@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func newListenServer(t *testing.T, host string, port int, opts ...ServerOption) *A2AServer {
+	t.Helper()
+	s, err := NewA2AServer(host, port, "/", &types.AgentCard{Name: "test"}, NewInMemoryTaskManager(), opts...)
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	return s
+}
+
+func TestServerAddr(t *testing.T) {
+	for _, tt := range []struct {
+		host string
+		want string
+	}{
+		{"localhost", "localhost:8080"},
+		{"127.0.0.1", "127.0.0.1:8080"},
+		{"", ":8080"},
+		{"::", "[::]:8080"},
+		{"::1", "[::1]:8080"},
+		{"[::1]", "[::1]:8080"},
+		{"fe80::1%eth0", "[fe80::1%eth0]:8080"},
+	} {
+		if got := newListenServer(t, tt.host, 8080).Addr(); got != tt.want {
+			t.Errorf("Addr() with host %q = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestServerListen(t *testing.T) {
+	listener, err := newListenServer(t, "127.0.0.1", 0).listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	if addr := listener.Addr().(*net.TCPAddr); !addr.IP.Equal(net.IPv4(127, 0, 0, 1)) || addr.Port == 0 {
+		t.Errorf("listening on %s, want 127.0.0.1 with a free port", addr)
+	}
+}
+
+func TestServerListenIPv6(t *testing.T) {
+	probe, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	probe.Close()
+
+	listener, err := newListenServer(t, "::1", 0, WithListenNetwork("tcp6")).listen()
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	if addr := listener.Addr().(*net.TCPAddr); !addr.IP.Equal(net.IPv6loopback) {
+		t.Errorf("listening on %s, want ::1", addr)
+	}
+}
+
+func TestServerListenNetwork(t *testing.T) {
+	if _, err := newListenServer(t, "127.0.0.1", 0, WithListenNetwork("tcp6")).listen(); err == nil {
+		t.Error("listening on an IPv4 address with tcp6 succeeded")
+	}
+	if _, err := newListenServer(t, "127.0.0.1", 0, WithListenNetwork("udp")).listen(); err == nil {
+		t.Error("listening on udp succeeded")
+	}
+
+	listener, err := newListenServer(t, "127.0.0.1", 0, WithListenNetwork("tcp4")).listen()
+	if err != nil {
+		t.Fatalf("listen on tcp4: %v", err)
+	}
+	listener.Close()
+}
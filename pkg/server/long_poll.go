@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+const (
+	// defaultPollBufferSize is how many events are buffered per task for long polling by default
+	defaultPollBufferSize = 256
+	// pollBufferRetention is how long the events of a finished task stay available to pollers
+	pollBufferRetention = 5 * time.Minute
+	// maxPollWait caps how long a tasks/events request blocks
+	maxPollWait = 60 * time.Second
+)
+
+// EventPoller is implemented by task managers serving task events by long polling
+type EventPoller interface {
+	OnPollTaskEvents(ctx context.Context, request *types.JSONRPCRequest) (*types.PollTaskEventsResponse, error)
+}
+
+// WithLongPolling buffers the last bufferSize status and artifact events of every task, 256
+// if bufferSize is 0, so clients can fetch them with tasks/events when SSE is unavailable
+func WithLongPolling(bufferSize int) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		if bufferSize <= 0 {
+			bufferSize = defaultPollBufferSize
+		}
+		tm.pollBufferSize = bufferSize
+		tm.pollBuffers = make(map[taskKey]*pollBuffer)
+	}
+}
+
+// pollBuffer holds the recent events of a task for pollers
+type pollBuffer struct {
+	events   []types.StreamEventRecord
+	lastSeq  int
+	finished time.Time     // When the final event was buffered
+	wake     chan struct{} // Closed and replaced whenever an event is buffered
+}
+
+// bufferPollEvent adds the event with cursor seq to the task's poll buffer and wakes its
+// pollers. The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) bufferPollEvent(ctx context.Context, taskID string, seq int, event interface{}) {
+	if tm.pollBuffers == nil {
+		return
+	}
+	now := tm.clock.Now()
+	key := subscriberKey(ctx, taskID)
+
+	for k, buffer := range tm.pollBuffers {
+		if !buffer.finished.IsZero() && now.Sub(buffer.finished) > pollBufferRetention && k != key {
+			delete(tm.pollBuffers, k)
+		}
+	}
+
+	buffer := tm.pollBufferLocked(key)
+	buffer.lastSeq = seq
+	buffer.events = append(buffer.events, types.StreamEventRecord{
+		Seq:       seq,
+		Timestamp: now.Format(time.RFC3339Nano),
+		Event:     event,
+	})
+	if len(buffer.events) > tm.pollBufferSize {
+		buffer.events = append(buffer.events[:0:0], buffer.events[len(buffer.events)-tm.pollBufferSize:]...)
+	}
+	if status, ok := event.(*types.TaskStatusUpdateEvent); ok && status.Final {
+		buffer.finished = now
+	}
+	close(buffer.wake)
+	buffer.wake = make(chan struct{})
+}
+
+// pollBufferLocked returns the poll buffer of key, creating it if needed. The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) pollBufferLocked(key taskKey) *pollBuffer {
+	buffer := tm.pollBuffers[key]
+	if buffer == nil {
+		buffer = &pollBuffer{wake: make(chan struct{})}
+		tm.pollBuffers[key] = buffer
+	}
+	return buffer
+}
+
+// PollTaskEvents returns the buffered events of a task belonging to the tenant in ctx after
+// cursor, waiting up to wait for one if there are none
+func (tm *InMemoryTaskManager) PollTaskEvents(ctx context.Context, taskID string, cursor int, wait time.Duration) (*types.TaskEvents, error) {
+	if tm.pollBuffers == nil {
+		return nil, errors.New("long polling is not enabled")
+	}
+	if !tm.taskExists(ctx, taskID) {
+		return nil, taskNotFound(taskID)
+	}
+	if cursor < 0 {
+		return nil, invalidParams("cursor must not be negative")
+	}
+	wait = min(max(wait, 0), maxPollWait)
+	key := subscriberKey(ctx, taskID)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		tm.lock.Lock()
+		latest := tm.store(ctx).eventSeqs[taskID]
+		buffer := tm.pollBufferLocked(key)
+		result := &types.TaskEvents{Events: []types.StreamEventRecord{}, Cursor: cursor}
+		from := cursor
+		if cursor > latest {
+			// The cursor is from before the agent restarted
+			result.Truncated = true
+			from = 0
+		}
+		for _, record := range buffer.events {
+			if record.Seq > from {
+				result.Events = append(result.Events, record)
+			}
+		}
+		switch {
+		case len(result.Events) > 0:
+			result.Truncated = result.Truncated || result.Events[0].Seq > from+1
+			result.Cursor = result.Events[len(result.Events)-1].Seq
+		case latest > from || result.Truncated:
+			// The events after the cursor are no longer buffered
+			result.Truncated = true
+			result.Cursor = latest
+		}
+		result.Final = !buffer.finished.IsZero() && result.Cursor == buffer.lastSeq
+		wake := buffer.wake
+		tm.lock.Unlock()
+
+		if len(result.Events) > 0 || result.Final || result.Truncated {
+			return result, nil
+		}
+		select {
+		case <-wake:
+		case <-timer.C:
+			return result, nil
+		case <-ctx.Done():
+			return result, nil
+		}
+	}
+}
+
+// OnPollTaskEvents handles tasks/events requests
+func (tm *InMemoryTaskManager) OnPollTaskEvents(ctx context.Context, request *types.JSONRPCRequest) (*types.PollTaskEventsResponse, error) {
+	params := request.Params.(*types.TaskEventsParams)
+	events, err := tm.PollTaskEvents(ctx, params.ID, params.Cursor, time.Duration(params.Wait)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &types.PollTaskEventsResponse{Result: events}, nil
+}
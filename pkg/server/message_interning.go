@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"unique"
+	"unsafe"
+
+	"a2a-go/pkg/types"
+)
+
+// WithMessageInterning stores identical text, file contents and URIs of message and artifact
+// parts only once across all tasks, e.g. when many sessions share long system prompts or attachments
+func WithMessageInterning() TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.intern = true
+	}
+}
+
+// internMessage shares the part contents of a message with identical ones stored before
+func (tm *InMemoryTaskManager) internMessage(message types.Message) types.Message {
+	if tm.intern {
+		internParts(message.Parts)
+	}
+	return message
+}
+
+// internArtifacts shares the part contents of artifacts with identical ones stored before
+func (tm *InMemoryTaskManager) internArtifacts(artifacts []types.Artifact) {
+	if !tm.intern {
+		return
+	}
+	for i := range artifacts {
+		internParts(artifacts[i].Parts)
+	}
+}
+
+// internParts replaces part strings in place with their canonical copies
+func internParts(parts []types.Part) {
+	for i, part := range parts {
+		switch p := part.(type) {
+		case types.TextPart:
+			p.Text = internString(p.Text)
+			parts[i] = p
+		case *types.TextPart:
+			p.Text = internString(p.Text)
+		case types.FilePart:
+			internFileContent(&p.File)
+			parts[i] = p
+		case *types.FilePart:
+			internFileContent(&p.File)
+		}
+	}
+}
+
+func internFileContent(file *types.FileContent) {
+	for _, s := range []*string{file.Bytes, file.URI, file.MimeType} {
+		if s != nil {
+			*s = internString(*s)
+		}
+	}
+}
+
+func internString(s string) string {
+	if s == "" {
+		return s
+	}
+	return unique.Make(s).Value()
+}
+
+// MemoryUsage is the estimated payload size of tasks in bytes.
+// Strings shared between tasks are counted once in UniqueBytes.
+type MemoryUsage struct {
+	Tasks         int
+	Messages      int
+	HistoryBytes  int64
+	ArtifactBytes int64
+	MetadataBytes int64
+	UniqueBytes   int64
+}
+
+// TotalBytes returns the logical size, counting shared contents once per use
+func (u MemoryUsage) TotalBytes() int64 {
+	return u.HistoryBytes + u.ArtifactBytes + u.MetadataBytes
+}
+
+// MemoryReport attributes the memory of an InMemoryTaskManager to tenants
+type MemoryReport struct {
+	Tenants map[string]MemoryUsage
+	Total   MemoryUsage
+}
+
+// TaskMemoryUsage estimates the payload size of one task of the tenant in ctx
+func (tm *InMemoryTaskManager) TaskMemoryUsage(ctx context.Context, taskID string) (MemoryUsage, bool) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		return MemoryUsage{}, false
+	}
+	z := newSizer()
+	return z.task(task), true
+}
+
+// MemoryUsage estimates the payload size of all tasks, per tenant
+func (tm *InMemoryTaskManager) MemoryUsage() MemoryReport {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	report := MemoryReport{Tenants: make(map[string]MemoryUsage, len(tm.tenants))}
+	total := newSizer()
+	for tenant, store := range tm.tenants {
+		z := newSizer()
+		var usage MemoryUsage
+		for _, task := range store.tasks {
+			usage.add(z.task(task))
+			total.task(task)
+		}
+		report.Tenants[tenant] = usage
+		report.Total.add(usage)
+	}
+	report.Total.UniqueBytes = total.unique
+	return report
+}
+
+func (u *MemoryUsage) add(o MemoryUsage) {
+	u.Tasks += o.Tasks
+	u.Messages += o.Messages
+	u.HistoryBytes += o.HistoryBytes
+	u.ArtifactBytes += o.ArtifactBytes
+	u.MetadataBytes += o.MetadataBytes
+	u.UniqueBytes += o.UniqueBytes
+}
+
+// wordSize approximates the size of scalar values and headers
+const wordSize = 8
+
+// sizer estimates payload sizes, recognizing strings shared through interning by their data pointer
+type sizer struct {
+	seen   map[*byte]struct{}
+	total  int64
+	unique int64
+}
+
+func newSizer() *sizer {
+	return &sizer{seen: make(map[*byte]struct{})}
+}
+
+// task returns the usage of one task; UniqueBytes only counts strings not seen by this sizer before
+func (z *sizer) task(task *types.Task) MemoryUsage {
+	usage := MemoryUsage{Tasks: 1, Messages: len(task.History)}
+	unique := z.unique
+
+	usage.HistoryBytes = z.measure(func() {
+		for _, message := range task.History {
+			z.str(message.Role)
+			z.parts(message.Parts)
+			z.value(message.Metadata)
+		}
+	})
+	usage.ArtifactBytes = z.measure(func() {
+		for _, artifact := range task.Artifacts {
+			z.strPtr(artifact.Name)
+			z.strPtr(artifact.Description)
+			z.parts(artifact.Parts)
+			z.value(artifact.Metadata)
+		}
+	})
+	usage.MetadataBytes = z.measure(func() {
+		z.str(task.ID)
+		z.strPtr(task.SessionID)
+		z.value(task.Metadata)
+	})
+
+	usage.UniqueBytes = z.unique - unique
+	return usage
+}
+
+func (z *sizer) measure(f func()) int64 {
+	start := z.total
+	f()
+	return z.total - start
+}
+
+func (z *sizer) str(s string) {
+	z.total += int64(len(s))
+	if len(s) == 0 {
+		return
+	}
+	p := unsafe.StringData(s)
+	if _, ok := z.seen[p]; !ok {
+		z.seen[p] = struct{}{}
+		z.unique += int64(len(s))
+	}
+}
+
+func (z *sizer) strPtr(s *string) {
+	if s != nil {
+		z.str(*s)
+	}
+}
+
+func (z *sizer) parts(parts []types.Part) {
+	for _, part := range parts {
+		switch p := part.(type) {
+		case types.TextPart:
+			z.str(p.Type)
+			z.str(p.Text)
+			z.value(p.Metadata)
+		case *types.TextPart:
+			z.parts([]types.Part{*p})
+		case types.FilePart:
+			z.str(p.Type)
+			z.strPtr(p.File.Name)
+			z.strPtr(p.File.MimeType)
+			z.strPtr(p.File.Bytes)
+			z.strPtr(p.File.URI)
+			z.value(p.Metadata)
+		case *types.FilePart:
+			z.parts([]types.Part{*p})
+		case types.DataPart:
+			z.str(p.Type)
+			z.value(p.Data)
+			z.value(p.Metadata)
+		case *types.DataPart:
+			z.parts([]types.Part{*p})
+		default:
+			z.value(part)
+		}
+	}
+}
+
+func (z *sizer) value(v interface{}) {
+	switch x := v.(type) {
+	case nil:
+	case string:
+		z.str(x)
+	case map[string]interface{}:
+		for key, value := range x {
+			z.str(key)
+			z.value(value)
+		}
+	case []interface{}:
+		for _, value := range x {
+			z.value(value)
+		}
+	case bool, float64, int, int64, uint64:
+		z.total += wordSize
+		z.unique += wordSize
+	default:
+		// Unknown types are approximated by their JSON encoding
+		data, _ := json.Marshal(x)
+		z.total += int64(len(data))
+		z.unique += int64(len(data))
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// validateMetadata checks the metadata of a new task against the metadata schema of the
+// skill it is sent to. Tasks naming a skill the card doesn't have are rejected once any
+// skill declares a schema. The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) validateMetadata(params *types.TaskSendParams) error {
+	if tm.agentCard == nil || !hasMetadataSchemas(tm.agentCard) {
+		return nil
+	}
+	skill := tm.agentCard.MetadataSkill(params.Metadata)
+	if id := params.RequestedSkill(); id != "" {
+		skill = tm.agentCard.Skill(id)
+		if skill == nil {
+			return invalidParams("unknown skill %q", id)
+		}
+	}
+	if skill == nil {
+		return nil
+	}
+
+	err := types.ValidateMetadata(skill, params.Metadata)
+	if err == nil {
+		return nil
+	}
+	data := &types.MetadataErrorData{SkillID: skill.ID}
+	var messages []string
+	for _, e := range unjoin(err) {
+		var violation *types.ValidationError
+		if errors.As(e, &violation) {
+			data.Violations = append(data.Violations, *violation)
+		}
+		messages = append(messages, e.Error())
+	}
+	return &TaskError{
+		Code:    InvalidParamsErrorCode,
+		Message: fmt.Sprintf("Invalid params: metadata does not match the schema of skill %s: %s", skill.ID, strings.Join(messages, "; ")),
+		Data:    data,
+	}
+}
+
+// hasMetadataSchemas reports whether any skill of the card declares a metadata schema
+func hasMetadataSchemas(card *types.AgentCard) bool {
+	for _, skill := range card.Skills {
+		if skill.MetadataSchema != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// unjoin returns the errors joined into err with errors.Join
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"a2a-go/pkg/types"
+)
+
+// MethodHandler handles a custom JSON-RPC method. The result is sent as the response result,
+// except for a chan *types.SendTaskStreamingResponse, which is streamed as SSE.
+// Returning a *types.JSONRPCError or *TaskError selects the error code sent to the client.
+type MethodHandler func(ctx context.Context, request *types.JSONRPCRequest) (interface{}, error)
+
+// WithMethod registers a custom JSON-RPC method, e.g. "x-myorg/embeddings".
+// NewA2AServer fails if name is empty, built in or already registered.
+func WithMethod(name string, handler MethodHandler) ServerOption {
+	return func(s *A2AServer) {
+		if err := s.RegisterMethod(name, handler); err != nil && s.optionErr == nil {
+			s.optionErr = err
+		}
+	}
+}
+
+// RegisterMethod adds a custom JSON-RPC method to the server
+func (s *A2AServer) RegisterMethod(name string, handler MethodHandler) error {
+	if name == "" || handler == nil {
+		return errors.New("method name and handler are required")
+	}
+	if builtinMethods[name] {
+		return fmt.Errorf("method %s is built in", name)
+	}
+
+	s.methodsLock.Lock()
+	defer s.methodsLock.Unlock()
+
+	if s.methods == nil {
+		s.methods = make(map[string]MethodHandler)
+	}
+	if _, exists := s.methods[name]; exists {
+		return fmt.Errorf("method %s is already registered", name)
+	}
+	s.methods[name] = handler
+	return nil
+}
+
+// customMethod returns the handler registered for name
+func (s *A2AServer) customMethod(name string) (MethodHandler, bool) {
+	s.methodsLock.RLock()
+	defer s.methodsLock.RUnlock()
+	handler, ok := s.methods[name]
+	return handler, ok
+}
+
+// TypedMethod adapts a handler taking decoded params of type P into a MethodHandler.
+// Params that cannot be decoded into P are rejected with -32602.
+func TypedMethod[P any, R any](handler func(ctx context.Context, params *P) (R, error)) MethodHandler {
+	return func(ctx context.Context, request *types.JSONRPCRequest) (interface{}, error) {
+		var params P
+		if request.Params != nil {
+			data, err := json.Marshal(request.Params)
+			if err == nil {
+				err = json.Unmarshal(data, &params)
+			}
+			if err != nil {
+				return nil, types.InvalidParamsError(err.Error())
+			}
+		}
+		return handler(ctx, &params)
+	}
+}
+
+// callCustomMethod runs a registered handler and converts its result into a response for createResponse
+func (s *A2AServer) callCustomMethod(ctx context.Context, handler MethodHandler, request *types.JSONRPCRequest) (interface{}, *types.JSONRPCError) {
+	result, err := handler(ctx, request)
+	if err != nil {
+		return nil, toJSONRPCError(ctx, err)
+	}
+
+	switch result.(type) {
+	case *types.JSONRPCResponse, chan *types.SendTaskStreamingResponse:
+		return result, nil
+	}
+	return &types.JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      request.ID,
+		Result:  result,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestInvalidServerOptionsFailNewA2AServer(t *testing.T) {
+	handler := func(ctx context.Context, request *types.JSONRPCRequest) (interface{}, error) { return nil, nil }
+	extension := Extension{AgentExtension: types.AgentExtension{URI: "https://example.com/ext"}}
+
+	for _, test := range []struct {
+		name    string
+		opts    []ServerOption
+		wantErr string
+	}{
+		{"method without name", []ServerOption{WithMethod("", handler)}, "required"},
+		{"built in method", []ServerOption{WithMethod("get_task", handler)}, "built in"},
+		{"method registered twice", []ServerOption{WithMethod("x-test/echo", handler), WithMethod("x-test/echo", handler)}, "already registered"},
+		{"extension without URI", []ServerOption{WithExtension(Extension{})}, "required"},
+		{"extension registered twice", []ServerOption{WithExtension(extension), WithExtension(extension)}, "already registered"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, NewInMemoryTaskManager(), test.opts...)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("NewA2AServer = %v, want an error mentioning %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by methodgen from internal/methodgen/methods.go. DO NOT EDIT.
+
+package server
+
+import (
+	"context"
+
+	"a2a-go/pkg/types"
+)
+
+// builtinMethods are the methods handled by processRequest itself; they cannot be registered
+var builtinMethods = map[string]bool{
+	"get_task":                            true,
+	"send_task":                           true,
+	types.SendTaskStreamingMethod:         true,
+	"cancel_task":                         true,
+	"set_task_push_notification":          true,
+	"get_task_push_notification":          true,
+	types.ResubscribeMethod:               true,
+	"tasks/pause":                         true,
+	"tasks/resume":                        true,
+	"tasks/transfer":                      true,
+	types.ReplayTaskMethod:                true,
+	"tasks/pushNotificationConfig/status": true,
+	types.ListTasksMethod:                 true,
+	types.PollTaskEventsMethod:            true,
+	types.BatchGetTasksMethod:             true,
+	types.BatchCancelTasksMethod:          true,
+	types.CloseSessionMethod:              true,
+	types.AgentCapabilitiesMethod:         true,
+}
+
+// methodParams creates the typed params of each built-in method. Method handlers can
+// assert request.Params to the type of their method.
+var methodParams = map[string]func() interface{}{
+	"get_task":                            func() interface{} { return &types.TaskQueryParams{} },
+	"send_task":                           func() interface{} { return &types.TaskSendParams{} },
+	types.SendTaskStreamingMethod:         func() interface{} { return &types.TaskSendParams{} },
+	"cancel_task":                         func() interface{} { return &types.TaskIdParams{} },
+	"set_task_push_notification":          func() interface{} { return &types.TaskPushNotificationConfig{} },
+	"get_task_push_notification":          func() interface{} { return &types.TaskIdParams{} },
+	types.ResubscribeMethod:               func() interface{} { return &types.TaskQueryParams{} },
+	"tasks/pause":                         func() interface{} { return &types.TaskIdParams{} },
+	"tasks/resume":                        func() interface{} { return &types.TaskIdParams{} },
+	"tasks/transfer":                      func() interface{} { return &types.TaskTransferParams{} },
+	types.ReplayTaskMethod:                func() interface{} { return &types.TaskReplayParams{} },
+	"tasks/pushNotificationConfig/status": func() interface{} { return &types.TaskIdParams{} },
+	types.ListTasksMethod:                 func() interface{} { return &types.TaskListParams{} },
+	types.PollTaskEventsMethod:            func() interface{} { return &types.TaskEventsParams{} },
+	types.BatchGetTasksMethod:             func() interface{} { return &types.TaskBatchParams{} },
+	types.BatchCancelTasksMethod:          func() interface{} { return &types.TaskBatchParams{} },
+	types.CloseSessionMethod:              func() interface{} { return &types.SessionCloseParams{} },
+	types.AgentCapabilitiesMethod:         func() interface{} { return &types.AgentCapabilitiesParams{} },
+}
+
+// supportedMethods returns the built-in methods served with tm, leaving out the methods of
+// optional interfaces it doesn't implement
+func supportedMethods(tm TaskManager) []string {
+	methods := make([]string, 0, len(builtinMethods))
+	methods = append(methods, "get_task")
+	methods = append(methods, "send_task")
+	methods = append(methods, types.SendTaskStreamingMethod)
+	methods = append(methods, "cancel_task")
+	methods = append(methods, "set_task_push_notification")
+	methods = append(methods, "get_task_push_notification")
+	methods = append(methods, types.ResubscribeMethod)
+	methods = append(methods, "tasks/pause")
+	methods = append(methods, "tasks/resume")
+	methods = append(methods, "tasks/transfer")
+	if _, ok := tm.(EventReplayer); ok {
+		methods = append(methods, types.ReplayTaskMethod)
+	}
+	if _, ok := tm.(PushDeliveryReporter); ok {
+		methods = append(methods, "tasks/pushNotificationConfig/status")
+	}
+	if _, ok := tm.(TaskLister); ok {
+		methods = append(methods, types.ListTasksMethod)
+	}
+	if _, ok := tm.(EventPoller); ok {
+		methods = append(methods, types.PollTaskEventsMethod)
+	}
+	methods = append(methods, types.BatchGetTasksMethod)
+	methods = append(methods, types.BatchCancelTasksMethod)
+	if _, ok := tm.(SessionCloser); ok {
+		methods = append(methods, types.CloseSessionMethod)
+	}
+	methods = append(methods, types.AgentCapabilitiesMethod)
+	return methods
+}
+
+// dispatchMethod calls the handler of a built-in method processRequest doesn't dispatch
+// itself. It reports false for other methods and for methods of optional interfaces tm
+// doesn't implement.
+func dispatchMethod(ctx context.Context, tm TaskManager, request *types.JSONRPCRequest) (result interface{}, ok bool, err error) {
+	switch request.Method {
+	case "send_task":
+		result, err := tm.OnSendTask(ctx, request)
+		return result, true, err
+	case "cancel_task":
+		result, err := tm.OnCancelTask(ctx, request)
+		return result, true, err
+	case "set_task_push_notification":
+		result, err := tm.OnSetTaskPushNotification(ctx, request)
+		return result, true, err
+	case "get_task_push_notification":
+		result, err := tm.OnGetTaskPushNotification(ctx, request)
+		return result, true, err
+	case "tasks/pause":
+		result, err := tm.OnPauseTask(ctx, request)
+		return result, true, err
+	case "tasks/resume":
+		result, err := tm.OnResumeTask(ctx, request)
+		return result, true, err
+	case "tasks/transfer":
+		result, err := tm.OnTransferTask(ctx, request)
+		return result, true, err
+	case types.ReplayTaskMethod:
+		handler, ok := tm.(EventReplayer)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.OnReplayTask(ctx, request)
+		return result, true, err
+	case "tasks/pushNotificationConfig/status":
+		handler, ok := tm.(PushDeliveryReporter)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.OnGetPushNotificationStatus(ctx, request)
+		return result, true, err
+	case types.ListTasksMethod:
+		handler, ok := tm.(TaskLister)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.OnListTasks(ctx, request)
+		return result, true, err
+	case types.PollTaskEventsMethod:
+		handler, ok := tm.(EventPoller)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.OnPollTaskEvents(ctx, request)
+		return result, true, err
+	case types.CloseSessionMethod:
+		handler, ok := tm.(SessionCloser)
+		if !ok {
+			return nil, false, nil
+		}
+		result, err := handler.OnCloseSession(ctx, request)
+		return result, true, err
+	}
+	return nil, false, nil
+}
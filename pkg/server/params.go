@@ -0,0 +1,94 @@
+package server
+
+//go:generate go run ../../internal/methodgen -side server -o methods_gen.go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// decodeParams replaces the decoded JSON params of a built-in method's request with its
+// typed params and validates them. Params that don't fit are rejected with -32602, listing
+// the offending fields in types.ParamsErrorData. Requests for other methods are left as is.
+func decodeParams(request *types.JSONRPCRequest) error {
+	newParams, ok := methodParams[request.Method]
+	if !ok {
+		return nil
+	}
+	params := newParams()
+	if request.Params != nil && reflect.TypeOf(request.Params) == reflect.TypeOf(params) {
+		params = request.Params
+	} else if err := unmarshalParams(request.Params, params); err != nil {
+		return paramsError(request.Method, err)
+	}
+
+	if validator, ok := params.(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			return paramsError(request.Method, err)
+		}
+	}
+	request.Params = params
+	return nil
+}
+
+// unmarshalParams decodes params received as JSON into v. Missing params decode into the
+// zero value, for validation to report the required fields.
+func unmarshalParams(params interface{}, v interface{}) error {
+	if params == nil {
+		return nil
+	}
+	if _, ok := params.(map[string]interface{}); !ok {
+		return &types.ValidationError{Field: "params", Message: "must be an object"}
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &types.ValidationError{Field: "params." + typeErr.Field, Message: "must be " + jsonKind(typeErr.Type)}
+	}
+	return err
+}
+
+// jsonKind names the JSON value expected for a Go type in error messages
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// paramsError converts decoding and validation errors into an invalid params error
+func paramsError(method string, err error) error {
+	data := &types.ParamsErrorData{Method: method}
+	var messages []string
+	for _, e := range unjoin(err) {
+		var violation *types.ValidationError
+		if errors.As(e, &violation) {
+			data.Violations = append(data.Violations, *violation)
+		}
+		messages = append(messages, e.Error())
+	}
+	return &TaskError{
+		Code:    InvalidParamsErrorCode,
+		Message: fmt.Sprintf("Invalid params: %s", strings.Join(messages, "; ")),
+		Data:    data,
+	}
+}
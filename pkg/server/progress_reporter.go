@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// ProgressReporter lets a handler report how far its task got. Every report is stored in
+// the task metadata, so it is part of get_task results and push notifications, and is sent
+// to subscribers as a status event. Heartbeats carry the last reported percentage.
+type ProgressReporter struct {
+	tm     *InMemoryTaskManager
+	ctx    context.Context
+	taskID string
+
+	lock    sync.Mutex
+	percent float64
+	note    string
+	set     bool
+}
+
+// ProgressReporter returns a reporter for a task belonging to the tenant in ctx
+func (tm *InMemoryTaskManager) ProgressReporter(ctx context.Context, taskID string) *ProgressReporter {
+	r := &ProgressReporter{tm: tm, ctx: context.WithoutCancel(ctx), taskID: taskID}
+	tm.SetProgressFunc(ctx, taskID, func() (float64, bool) {
+		percent, _, ok := r.Progress()
+		return percent, ok
+	})
+	return r
+}
+
+// SetProgress reports the completion percentage of the task, clamped to 0 to 100, and a
+// description of the current step, which may be empty
+func (r *ProgressReporter) SetProgress(percent float64, note string) error {
+	if math.IsNaN(percent) {
+		percent = 0
+	}
+	percent = min(max(percent, 0), 100)
+	r.lock.Lock()
+	r.percent, r.note, r.set = percent, note, true
+	r.lock.Unlock()
+
+	if err := r.tm.SetTaskMetadata(r.ctx, r.taskID, types.ProgressMetadataKey, percent); err != nil {
+		return err
+	}
+	var noteValue interface{}
+	if note != "" {
+		noteValue = note
+	}
+	if err := r.tm.SetTaskMetadata(r.ctx, r.taskID, types.ProgressNoteMetadataKey, noteValue); err != nil {
+		return err
+	}
+
+	r.tm.lock.Lock()
+	task := r.tm.store(r.ctx).tasks[r.taskID]
+	if task == nil {
+		r.tm.lock.Unlock()
+		return taskNotFound(r.taskID)
+	}
+	status := task.Status
+	r.tm.lock.Unlock()
+	if isTerminalState(status.State) {
+		return nil
+	}
+
+	status.Timestamp = r.tm.clock.Now().Format(time.RFC3339)
+	metadata := map[string]interface{}{types.ProgressMetadataKey: percent}
+	if note != "" {
+		metadata[types.ProgressNoteMetadataKey] = note
+	}
+	r.tm.enqueueEventsForSSE(r.ctx, r.taskID, &types.TaskStatusUpdateEvent{
+		ID:       r.taskID,
+		Status:   status,
+		Metadata: metadata,
+	})
+	return nil
+}
+
+// Progress returns the last reported percentage and note, and whether any was reported
+func (r *ProgressReporter) Progress() (float64, string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.percent, r.note, r.set
+}
+
+type progressContextKey struct{}
+
+// WithProgressReporter returns a copy of ctx carrying reporter, for passing it to the
+// functions a handler calls
+func WithProgressReporter(ctx context.Context, reporter *ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, reporter)
+}
+
+// ProgressFromContext returns the reporter carried by ctx, or nil if none
+func ProgressFromContext(ctx context.Context) *ProgressReporter {
+	reporter, _ := ctx.Value(progressContextKey{}).(*ProgressReporter)
+	return reporter
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// WithPathPrefix mounts all server endpoints under prefix (e.g. "/agents/reimbursement")
+func WithPathPrefix(prefix string) ServerOption {
+	return func(s *A2AServer) {
+		s.pathPrefix = "/" + strings.Trim(prefix, "/")
+		if s.pathPrefix == "/" {
+			s.pathPrefix = ""
+		}
+	}
+}
+
+// WithTrustForwardedHeaders makes the server honor X-Forwarded-Proto and X-Forwarded-Host
+// (or the standard Forwarded header) when generating absolute URLs. Only enable it behind
+// a reverse proxy that sets these headers.
+func WithTrustForwardedHeaders() ServerOption {
+	return func(s *A2AServer) {
+		s.trustForwarded = true
+	}
+}
+
+// path returns p mounted under the configured path prefix
+func (s *A2AServer) path(p string) string {
+	return s.pathPrefix + p
+}
+
+// externalBase returns the scheme and host clients use to reach the server
+func (s *A2AServer) externalBase(r *http.Request) (string, string) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if s.trustForwarded {
+		if forwardedProto, forwardedHost := parseForwarded(r.Header.Get("Forwarded")); forwardedProto != "" || forwardedHost != "" {
+			if forwardedProto != "" {
+				scheme = forwardedProto
+			}
+			if forwardedHost != "" {
+				host = forwardedHost
+			}
+		} else {
+			if proto := firstHeaderValue(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+				scheme = proto
+			}
+			if forwardedHost := firstHeaderValue(r.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+				host = forwardedHost
+			}
+		}
+	}
+	return scheme, host
+}
+
+// ExternalURL builds the absolute URL of a server path as seen by the client of r
+func (s *A2AServer) ExternalURL(r *http.Request, p string) string {
+	scheme, host := s.externalBase(r)
+	u := url.URL{Scheme: scheme, Host: host, Path: s.path(p)}
+	return u.String()
+}
+
+// publicAgentCard returns the agent card with its URL adjusted for the client of r
+func (s *A2AServer) publicAgentCard(r *http.Request) *types.AgentCard {
+	card := *s.agentCard
+	s.cardExtensions(&card)
+	if card.JWKSURL == nil && s.jwksURL != "" {
+		card.JWKSURL = &s.jwksURL
+	}
+
+	cardURL, err := url.Parse(card.URL)
+	if err != nil {
+		return &card
+	}
+	if cardURL.Host != "" && !s.trustForwarded {
+		return &card
+	}
+
+	scheme, host := s.externalBase(r)
+	cardURL.Scheme = scheme
+	cardURL.Host = host
+	if cardURL.Path == "" || cardURL.Path == "/" {
+		cardURL.Path = s.path(s.endpoint)
+	}
+	card.URL = cardURL.String()
+	return &card
+}
+
+// firstHeaderValue returns the first entry of a comma separated header value
+func firstHeaderValue(value string) string {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseForwarded extracts proto and host from the first element of an RFC 7239 Forwarded header
+func parseForwarded(value string) (string, string) {
+	var proto, host string
+	for _, pair := range strings.Split(firstHeaderValue(value), ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		val = strings.Trim(val, `"`)
+		switch strings.ToLower(key) {
+		case "proto":
+			proto = val
+		case "host":
+			host = val
+		}
+	}
+	return proto, host
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestAgentCardForwardedHeaders(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		opts     []ServerOption
+		wantURL  string
+		wantVary string
+	}{
+		{"untrusted", nil, "http://agent.internal/", ""},
+		{"trusted", []ServerOption{WithTrustForwardedHeaders()}, "https://agent.example.com/", "Forwarded, X-Forwarded-Host, X-Forwarded-Proto"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, NewInMemoryTaskManager(), test.opts...)
+			if err != nil {
+				t.Fatalf("NewA2AServer: %v", err)
+			}
+			handler := s.Handler()
+
+			r := httptest.NewRequest(http.MethodGet, "http://agent.internal/.well-known/agent.json", nil)
+			r.Header.Set("X-Forwarded-Proto", "https")
+			r.Header.Set("X-Forwarded-Host", "agent.example.com")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			var card types.AgentCard
+			if err := json.Unmarshal(w.Body.Bytes(), &card); err != nil {
+				t.Fatalf("decoding card: %v", err)
+			}
+			if card.URL != test.wantURL {
+				t.Fatalf("card URL = %q, want %q", card.URL, test.wantURL)
+			}
+			if vary := w.Header().Get("Vary"); vary != test.wantVary {
+				t.Fatalf("Vary = %q, want %q", vary, test.wantVary)
+			}
+
+			// Revalidations keep the header so caches store the variants apart
+			r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusNotModified {
+				t.Fatalf("revalidation status = %d, want %d", w.Code, http.StatusNotModified)
+			}
+			if vary := w.Header().Get("Vary"); vary != test.wantVary {
+				t.Fatalf("Vary on revalidation = %q, want %q", vary, test.wantVary)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// PushConfigStore persists push notification configs per task, scoped to the tenant in ctx
+type PushConfigStore interface {
+	SetPushConfig(ctx context.Context, taskID string, config *types.PushNotificationConfig) error
+	// GetPushConfig returns nil without error if the task has no config
+	GetPushConfig(ctx context.Context, taskID string) (*types.PushNotificationConfig, error)
+	DeletePushConfig(ctx context.Context, taskID string) error
+}
+
+// WithPushConfigStore sets where push notification configs are persisted (in memory by default)
+func WithPushConfigStore(store PushConfigStore) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.pushConfigs = store
+	}
+}
+
+// WithPushURLPolicy sets the policy push notification URLs must satisfy to be accepted,
+// utils.DefaultURLPolicy by default. A nil policy accepts any URL. In development, pass
+// utils.DefaultURLPolicy().AllowLoopback() to reach receivers on the same machine.
+func WithPushURLPolicy(policy *utils.URLPolicy) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.pushURLPolicy = policy
+	}
+}
+
+// InMemoryPushConfigStore keeps push notification configs in a map
+type InMemoryPushConfigStore struct {
+	lock    sync.RWMutex
+	configs map[taskKey]*types.PushNotificationConfig
+}
+
+// NewInMemoryPushConfigStore creates an empty InMemoryPushConfigStore
+func NewInMemoryPushConfigStore() *InMemoryPushConfigStore {
+	return &InMemoryPushConfigStore{
+		configs: make(map[taskKey]*types.PushNotificationConfig),
+	}
+}
+
+func (s *InMemoryPushConfigStore) SetPushConfig(ctx context.Context, taskID string, config *types.PushNotificationConfig) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.configs[subscriberKey(ctx, taskID)] = config
+	return nil
+}
+
+func (s *InMemoryPushConfigStore) GetPushConfig(ctx context.Context, taskID string) (*types.PushNotificationConfig, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.configs[subscriberKey(ctx, taskID)], nil
+}
+
+func (s *InMemoryPushConfigStore) DeletePushConfig(ctx context.Context, taskID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.configs, subscriberKey(ctx, taskID))
+	return nil
+}
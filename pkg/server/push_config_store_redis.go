@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"a2a-go/pkg/types"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPushConfigStore persists push notification configs as Redis strings
+type RedisPushConfigStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisPushConfigStore creates a RedisPushConfigStore storing keys under prefix
+func NewRedisPushConfigStore(client redis.UniversalClient, prefix string) *RedisPushConfigStore {
+	if prefix == "" {
+		prefix = "a2a"
+	}
+	return &RedisPushConfigStore{client: client, prefix: prefix}
+}
+
+func (s *RedisPushConfigStore) key(ctx context.Context, taskID string) string {
+	return fmt.Sprintf("%s:push:%s:%s", s.prefix, TenantFromContext(ctx), taskID)
+}
+
+func (s *RedisPushConfigStore) SetPushConfig(ctx context.Context, taskID string, config *types.PushNotificationConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(ctx, taskID), data, 0).Err()
+}
+
+func (s *RedisPushConfigStore) GetPushConfig(ctx context.Context, taskID string) (*types.PushNotificationConfig, error) {
+	data, err := s.client.Get(ctx, s.key(ctx, taskID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config types.PushNotificationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode push config: %w", err)
+	}
+	return &config, nil
+}
+
+func (s *RedisPushConfigStore) DeletePushConfig(ctx context.Context, taskID string) error {
+	return s.client.Del(ctx, s.key(ctx, taskID)).Err()
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// SQLDialect selects the placeholder and upsert syntax of a SQL store
+type SQLDialect int
+
+const (
+	// SQLDialectSQLite uses ? placeholders
+	SQLDialectSQLite SQLDialect = iota
+	// SQLDialectPostgres uses $n placeholders
+	SQLDialectPostgres
+)
+
+var sqlTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// rebind rewrites ? placeholders for the dialect
+func (d SQLDialect) rebind(query string) string {
+	if d != SQLDialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SQLPushConfigStore persists push notification configs in a SQL table using database/sql.
+// The caller provides the *sql.DB and driver.
+type SQLPushConfigStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	table   string
+}
+
+// NewSQLPushConfigStore creates a SQLPushConfigStore using table, creating it if missing
+func NewSQLPushConfigStore(ctx context.Context, db *sql.DB, dialect SQLDialect, table string) (*SQLPushConfigStore, error) {
+	if table == "" {
+		table = "a2a_push_configs"
+	}
+	if !sqlTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	s := &SQLPushConfigStore{db: db, dialect: dialect, table: table}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	tenant TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	config TEXT NOT NULL,
+	PRIMARY KEY (tenant, task_id)
+)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push config table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLPushConfigStore) SetPushConfig(ctx context.Context, taskID string, config *types.PushNotificationConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	query := s.dialect.rebind(fmt.Sprintf(
+		`INSERT INTO %s (tenant, task_id, config) VALUES (?, ?, ?)
+ON CONFLICT (tenant, task_id) DO UPDATE SET config = excluded.config`, s.table))
+	_, err = s.db.ExecContext(ctx, query, TenantFromContext(ctx), taskID, string(data))
+	return err
+}
+
+func (s *SQLPushConfigStore) GetPushConfig(ctx context.Context, taskID string) (*types.PushNotificationConfig, error) {
+	query := s.dialect.rebind(fmt.Sprintf(`SELECT config FROM %s WHERE tenant = ? AND task_id = ?`, s.table))
+
+	var data string
+	err := s.db.QueryRowContext(ctx, query, TenantFromContext(ctx), taskID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config types.PushNotificationConfig
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return nil, fmt.Errorf("failed to decode push config: %w", err)
+	}
+	return &config, nil
+}
+
+func (s *SQLPushConfigStore) DeletePushConfig(ctx context.Context, taskID string) error {
+	query := s.dialect.rebind(fmt.Sprintf(`DELETE FROM %s WHERE tenant = ? AND task_id = ?`, s.table))
+	_, err := s.db.ExecContext(ctx, query, TenantFromContext(ctx), taskID)
+	return err
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// Default retry schedule of push notification deliveries
+const (
+	defaultDeliveryAttempts   = 5
+	defaultDeliveryBackoff    = time.Second
+	defaultDeliveryMaxBackoff = time.Minute
+)
+
+// PushDeliveryReporter is implemented by task managers that deliver push notifications
+// themselves and keep delivery receipts
+type PushDeliveryReporter interface {
+	OnGetPushNotificationStatus(ctx context.Context, request *types.JSONRPCRequest) (*types.GetPushNotificationStatusResponse, error)
+	PushDeliveryStats() PushDeliveryStats
+}
+
+// PushDeliveryStats counts push notification deliveries across all tasks
+type PushDeliveryStats struct {
+	Attempts  uint64
+	Delivered uint64
+	Failed    uint64 // Notifications given up on after the last retry
+	Retrying  int    // Notifications currently waiting for a retry
+}
+
+// PushDeliveryOption configures a PushDeliverer
+type PushDeliveryOption func(*PushDeliverer)
+
+// WithDeliveryRetries sets how often a notification is attempted and the backoff between
+// attempts, doubled after each retry up to maxBackoff
+func WithDeliveryRetries(attempts int, backoff, maxBackoff time.Duration) PushDeliveryOption {
+	return func(d *PushDeliverer) {
+		d.attempts = attempts
+		d.backoff = backoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithDeliveryClock sets the clock used for delivery receipt timestamps
+func WithDeliveryClock(clock utils.Clock) PushDeliveryOption {
+	return func(d *PushDeliverer) {
+		d.clock = clock
+	}
+}
+
+// PushDeliverer sends signed push notifications with retries and records a delivery
+// receipt per task and URL
+type PushDeliverer struct {
+	auth       *utils.PushNotificationSenderAuth
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	clock      utils.Clock
+
+	lock     sync.Mutex
+	receipts map[taskKey]map[string]*types.PushDeliveryStatus
+	stats    PushDeliveryStats
+}
+
+// NewPushDeliverer creates a PushDeliverer signing notifications with auth
+func NewPushDeliverer(auth *utils.PushNotificationSenderAuth, opts ...PushDeliveryOption) *PushDeliverer {
+	d := &PushDeliverer{
+		auth:       auth,
+		attempts:   defaultDeliveryAttempts,
+		backoff:    defaultDeliveryBackoff,
+		maxBackoff: defaultDeliveryMaxBackoff,
+		clock:      utils.DefaultClock(),
+		receipts:   make(map[taskKey]map[string]*types.PushDeliveryStatus),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// WithPushDelivery makes the task manager push the task to its configured URL on every
+// status update, and serve delivery receipts via tasks/pushNotificationConfig/status
+func WithPushDelivery(deliverer *PushDeliverer) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.pushDeliverer = deliverer
+	}
+}
+
+// Deliver sends payload to url in the background, retrying failed attempts
+func (d *PushDeliverer) Deliver(ctx context.Context, taskID, url string, payload map[string]interface{}) {
+	key := subscriberKey(ctx, taskID)
+	// Retries must resend the same sequence number
+	payload = d.auth.SequenceNotification(payload)
+	go d.run(key, url, payload)
+}
+
+func (d *PushDeliverer) run(key taskKey, url string, payload map[string]interface{}) {
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		statusCode, err := d.auth.PostPushNotification(url, payload)
+		if err == nil && (statusCode < 200 || statusCode > 299) {
+			err = fmt.Errorf("receiver returned status %d", statusCode)
+		}
+		retry := err != nil && attempt < d.attempts && retryableDelivery(statusCode)
+		d.record(key, url, statusCode, err, retry, backoff)
+		if !retry {
+			if err != nil {
+				log.Printf("Giving up push notification of task %s to %s after %d attempts: %v", key.taskID, url, attempt, err)
+			}
+			return
+		}
+
+		time.Sleep(backoff)
+		d.lock.Lock()
+		d.stats.Retrying--
+		d.lock.Unlock()
+		if backoff *= 2; d.maxBackoff > 0 && backoff > d.maxBackoff {
+			backoff = d.maxBackoff
+		}
+	}
+}
+
+// retryableDelivery reports whether a failed attempt may succeed when repeated
+func retryableDelivery(statusCode int) bool {
+	switch {
+	case statusCode == 0: // No response
+		return true
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return statusCode >= 500
+	}
+}
+
+// record updates the receipt of key and url after an attempt
+func (d *PushDeliverer) record(key taskKey, url string, statusCode int, err error, retry bool, backoff time.Duration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	urls := d.receipts[key]
+	if urls == nil {
+		urls = make(map[string]*types.PushDeliveryStatus)
+		d.receipts[key] = urls
+	}
+	receipt := urls[url]
+	if receipt == nil {
+		receipt = &types.PushDeliveryStatus{TaskID: key.taskID, URL: url}
+		urls[url] = receipt
+	}
+
+	now := d.clock.Now()
+	d.stats.Attempts++
+	receipt.Attempts++
+	receipt.LastStatusCode = statusCode
+	receipt.LastAttemptAt = now.Format(time.RFC3339)
+	receipt.NextRetryAt = ""
+	receipt.LastError = ""
+	switch {
+	case err == nil:
+		d.stats.Delivered++
+		receipt.Delivered++
+		receipt.LastDeliveredAt = receipt.LastAttemptAt
+	case retry:
+		d.stats.Retrying++
+		receipt.LastError = err.Error()
+		receipt.NextRetryAt = now.Add(backoff).Format(time.RFC3339)
+	default:
+		d.stats.Failed++
+		receipt.Failed++
+		receipt.LastError = err.Error()
+	}
+}
+
+// Status returns the delivery receipts of the task in the tenant of ctx
+func (d *PushDeliverer) Status(ctx context.Context, taskID string) []types.PushDeliveryStatus {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	receipts := make([]types.PushDeliveryStatus, 0, len(d.receipts[subscriberKey(ctx, taskID)]))
+	for _, receipt := range d.receipts[subscriberKey(ctx, taskID)] {
+		receipts = append(receipts, *receipt)
+	}
+	return receipts
+}
+
+// forget drops the delivery receipts of the task in the tenant of ctx, e.g. once it is evicted
+func (d *PushDeliverer) forget(ctx context.Context, taskID string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.receipts, subscriberKey(ctx, taskID))
+}
+
+// Stats returns the delivery counters across all tasks
+func (d *PushDeliverer) Stats() PushDeliveryStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.stats
+}
+
+// pushTaskUpdate delivers the current task to its push notification URL, if it has one
+func (tm *InMemoryTaskManager) pushTaskUpdate(ctx context.Context, taskID string, seq int) {
+	config, err := tm.pushConfigs.GetPushConfig(ctx, taskID)
+	if err != nil {
+		log.Printf("Failed to load push notification config of task %s: %v", taskID, err)
+		return
+	}
+	if config == nil {
+		return
+	}
+
+	tm.lock.Lock()
+	data, err := json.Marshal(tm.store(ctx).tasks[taskID])
+	tm.lock.Unlock()
+	if err != nil {
+		log.Printf("Failed to encode push notification of task %s: %v", taskID, err)
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil || payload == nil {
+		return
+	}
+	if seq > 0 {
+		payload[utils.NotificationEventSeqKey] = seq
+	}
+	tm.pushDeliverer.Deliver(ctx, taskID, config.URL, payload)
+}
+
+// OnGetPushNotificationStatus returns the push notification delivery receipts of a task
+func (tm *InMemoryTaskManager) OnGetPushNotificationStatus(ctx context.Context, request *types.JSONRPCRequest) (*types.GetPushNotificationStatusResponse, error) {
+	taskParams := request.Params.(*types.TaskIdParams)
+
+	tm.lock.Lock()
+	_, exists := tm.store(ctx).tasks[taskParams.ID]
+	tm.lock.Unlock()
+	if !exists {
+		return nil, taskNotFound(taskParams.ID)
+	}
+	if tm.pushDeliverer == nil {
+		return nil, &TaskError{
+			Code:    PushNotificationNotSupportedErrorCode,
+			Message: "Push notifications are not delivered by this server",
+		}
+	}
+	return &types.GetPushNotificationStatusResponse{
+		Result: tm.pushDeliverer.Status(ctx, taskParams.ID),
+	}, nil
+}
+
+// PushDeliveryStats returns the push notification delivery counters, zero without WithPushDelivery
+func (tm *InMemoryTaskManager) PushDeliveryStats() PushDeliveryStats {
+	if tm.pushDeliverer == nil {
+		return PushDeliveryStats{}
+	}
+	return tm.pushDeliverer.Stats()
+}
+
+// PushDeliveryStats returns the push notification delivery counters of the task manager,
+// if it delivers notifications
+func (s *A2AServer) PushDeliveryStats() (PushDeliveryStats, bool) {
+	reporter, ok := s.taskManager.(PushDeliveryReporter)
+	if !ok {
+		return PushDeliveryStats{}, false
+	}
+	return reporter.PushDeliveryStats(), true
+}
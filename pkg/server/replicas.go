@@ -0,0 +1,289 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// defaultResubscribeTokenTTL is how long resubscribe tokens stay valid by default
+const defaultResubscribeTokenTTL = time.Hour
+
+// forwardedByHeader marks requests forwarded between replicas, which are always served locally
+const forwardedByHeader = "A2A-Forwarded-By"
+
+// ReplicaResolver returns the internal base URL of a replica, the URL its JSON-RPC endpoint
+// is reachable at from the other replicas
+type ReplicaResolver func(replicaID string) (baseURL string, ok bool)
+
+// StaticReplicas resolves replicas from a fixed map of replica ids to base URLs
+func StaticReplicas(urls map[string]string) ReplicaResolver {
+	return func(replicaID string) (string, bool) {
+		baseURL, ok := urls[replicaID]
+		return baseURL, ok
+	}
+}
+
+// TaskLocator returns the replica streaming a task, for resubscribe requests without a
+// token, e.g. by looking up an ownership record in a shared store
+type TaskLocator func(ctx context.Context, taskID string) (replicaID string, ok bool)
+
+// ReplicaOption configures cross-replica resubscription
+type ReplicaOption func(*replicaRouting)
+
+// WithTaskLocator sets the fallback used to find a task's replica when a request has no valid token
+func WithTaskLocator(locate TaskLocator) ReplicaOption {
+	return func(r *replicaRouting) {
+		r.locate = locate
+	}
+}
+
+// WithForwardingClient sets the HTTP client resubscribe requests are forwarded with
+func WithForwardingClient(client *http.Client) ReplicaOption {
+	return func(r *replicaRouting) {
+		r.client = client
+	}
+}
+
+// WithResubscribeTokenTTL sets how long resubscribe tokens stay valid, 1h by default
+func WithResubscribeTokenTTL(ttl time.Duration) ReplicaOption {
+	return func(r *replicaRouting) {
+		r.ttl = ttl
+	}
+}
+
+// replicaRouting routes resubscribe requests to the replica streaming the task
+type replicaRouting struct {
+	id      string
+	key     []byte
+	resolve ReplicaResolver
+	locate  TaskLocator
+	client  *http.Client
+	ttl     time.Duration
+}
+
+// WithReplica runs the server as replica id behind a load balancer without sticky sessions.
+// Stream responses carry a resubscribe token signed with key, shared by all replicas; a
+// resubscribe request presenting it to another replica is forwarded to the one streaming
+// the task, whose internal URL replicas resolves.
+func WithReplica(id string, key []byte, replicas ReplicaResolver, opts ...ReplicaOption) ServerOption {
+	return func(s *A2AServer) {
+		r := &replicaRouting{
+			id:      id,
+			key:     key,
+			resolve: replicas,
+			client:  &http.Client{},
+			ttl:     defaultResubscribeTokenTTL,
+		}
+		for _, opt := range opts {
+			opt(r)
+		}
+		s.replicas = r
+	}
+}
+
+// resubscribeClaims is the signed content of a resubscribe token
+type resubscribeClaims struct {
+	Replica string `json:"r"`
+	Tenant  string `json:"t"`
+	TaskID  string `json:"i"`
+	Expires int64  `json:"e"`
+}
+
+// ResubscribeToken returns a token routing resubscribe requests for a task belonging to the
+// tenant in ctx to this replica
+func (s *A2AServer) ResubscribeToken(ctx context.Context, taskID string) (string, error) {
+	if s.replicas == nil {
+		return "", errors.New("replica routing is not enabled")
+	}
+	payload, err := json.Marshal(resubscribeClaims{
+		Replica: s.replicas.id,
+		Tenant:  TenantFromContext(ctx),
+		TaskID:  taskID,
+		Expires: s.clock.Now().Add(s.replicas.ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.replicas.sign(encoded), nil
+}
+
+// sign computes the HMAC of an encoded token payload
+func (r *replicaRouting) sign(encoded string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature and expiry of a token and returns its claims
+func (r *replicaRouting) verify(token string, now time.Time) (*resubscribeClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(r.sign(encoded))) {
+		return nil, errors.New("invalid resubscribe token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("invalid resubscribe token")
+	}
+	var claims resubscribeClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("invalid resubscribe token")
+	}
+	if now.Unix() > claims.Expires {
+		return nil, errors.New("resubscribe token expired")
+	}
+	return &claims, nil
+}
+
+// setResubscribeToken adds the resubscribe token of the requested task to a stream response
+func (s *A2AServer) setResubscribeToken(ctx context.Context, w http.ResponseWriter, request *types.JSONRPCRequest) {
+	if s.replicas == nil {
+		return
+	}
+	taskID := requestTaskID(request.Params)
+	if taskID == "" {
+		return
+	}
+	token, err := s.ResubscribeToken(ctx, taskID)
+	if err != nil {
+		log.Printf("Failed to create resubscribe token for task %s: %v", taskID, err)
+		return
+	}
+	w.Header().Set(types.ResubscribeTokenHeader, token)
+}
+
+// requestTaskID returns the task id of request params, whether decoded or typed
+func requestTaskID(params interface{}) string {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		if id, ok := p["id"].(string); ok {
+			return id
+		}
+		if message, ok := p["message"].(map[string]interface{}); ok {
+			id, _ := message["taskId"].(string)
+			return id
+		}
+	case *types.TaskIdParams:
+		return p.ID
+	case *types.TaskQueryParams:
+		return p.ID
+	case *types.TaskSendParams:
+		return p.ID
+	case *types.TaskReplayParams:
+		return p.ID
+	case *types.TaskTransferParams:
+		return p.ID
+	case *types.TaskPushNotificationConfig:
+		return p.ID
+	case *types.TaskEventsParams:
+		return p.ID
+	}
+	return ""
+}
+
+// forwardResubscribe forwards a resubscribe request for a task streamed by another replica
+// and relays its response; it reports whether the request was handled
+func (s *A2AServer) forwardResubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, request *types.JSONRPCRequest) bool {
+	routing := s.replicas
+	if routing == nil || r.Header.Get(forwardedByHeader) != "" {
+		return false
+	}
+	taskID := requestTaskID(request.Params)
+	if taskID == "" {
+		return false
+	}
+
+	replicaID := ""
+	if token := r.Header.Get(types.ResubscribeTokenHeader); token != "" {
+		claims, err := routing.verify(token, s.clock.Now())
+		if err != nil {
+			log.Printf("Ignoring resubscribe token for task %s: %v", taskID, err)
+		} else if claims.TaskID == taskID && claims.Tenant == TenantFromContext(ctx) {
+			replicaID = claims.Replica
+		}
+	}
+	if replicaID == "" && routing.locate != nil {
+		replicaID, _ = routing.locate(ctx, taskID)
+	}
+	if replicaID == "" || replicaID == routing.id {
+		return false
+	}
+	baseURL, ok := routing.resolve(replicaID)
+	if !ok {
+		log.Printf("Unknown replica %s for task %s, serving resubscribe locally", replicaID, taskID)
+		return false
+	}
+
+	if err := s.forward(ctx, w, r, request, baseURL); err != nil {
+		log.Printf("Failed to forward resubscribe for task %s to replica %s: %v", taskID, replicaID, err)
+		s.handleError(w, request.ID, types.InternalError(fmt.Sprintf("Replica streaming the task is unavailable: %v", err)))
+	}
+	return true
+}
+
+// forward sends request to the replica at baseURL as JSON and streams its response to w
+func (s *A2AServer) forward(ctx context.Context, w http.ResponseWriter, r *http.Request, request *types.JSONRPCRequest, baseURL string) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"Authorization", "Accept-Encoding", types.ResubscribeTokenHeader} {
+		if value := r.Header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream, application/json")
+	req.Header.Set(forwardedByHeader, s.replicas.id)
+
+	resp, err := s.replicas.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, name := range []string{"Content-Type", "Content-Encoding", "Cache-Control", types.ResubscribeTokenHeader} {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	flusher, _ := w.(http.Flusher)
+	buffer := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buffer)
+		if n > 0 {
+			if _, writeErr := w.Write(buffer[:n]); writeErr != nil {
+				return nil // The client went away
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			log.Printf("Forwarded stream from %s ended: %v", baseURL, err)
+			return nil
+		}
+	}
+}
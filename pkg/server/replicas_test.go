@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// newReplica creates the server of replica id sharing key with the other replicas
+func newReplica(t *testing.T, id string, key []byte, clock utils.Clock, replicas ReplicaResolver) *A2AServer {
+	t.Helper()
+	s, err := NewA2AServer("localhost", 0, "/", &types.AgentCard{Name: "test"}, NewInMemoryTaskManager(),
+		WithReplica(id, key, replicas, WithResubscribeTokenTTL(time.Minute)), WithServerClock(clock))
+	if err != nil {
+		t.Fatalf("NewA2AServer: %v", err)
+	}
+	return s
+}
+
+func TestResubscribeTokenVerify(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := newReplica(t, "a", []byte("secret"), clock, StaticReplicas(nil))
+	token, err := s.ResubscribeToken(WithTenant(context.Background(), "acme"), "t1")
+	if err != nil {
+		t.Fatalf("ResubscribeToken: %v", err)
+	}
+	encoded, signature, _ := strings.Cut(token, ".")
+	other := &replicaRouting{key: []byte("other")}
+
+	for _, test := range []struct {
+		name    string
+		routing *replicaRouting
+		token   string
+		after   time.Duration
+		wantErr string
+	}{
+		{"valid", s.replicas, token, 0, ""},
+		{"valid until expiry", s.replicas, token, time.Minute, ""},
+		{"expired", s.replicas, token, time.Minute + time.Second, "expired"},
+		{"other key", other, token, 0, "invalid"},
+		{"tampered payload", s.replicas, encoded + "x." + signature, 0, "invalid"},
+		{"tampered signature", s.replicas, encoded + "." + signature[1:], 0, "invalid"},
+		{"no signature", s.replicas, encoded, 0, "invalid"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := test.routing.verify(test.token, clock.Now().Add(test.after))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("verify = %v, want an error mentioning %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if claims.Replica != "a" || claims.Tenant != "acme" || claims.TaskID != "t1" {
+				t.Fatalf("claims = %+v, want replica a, tenant acme and task t1", claims)
+			}
+		})
+	}
+}
+
+func TestForwardResubscribeBindsTokenToTenantAndTask(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	key := []byte("secret")
+	forwarded := 0
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded++
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer b.Close()
+
+	a := newReplica(t, "a", key, clock, StaticReplicas(map[string]string{"b": b.URL}))
+	token, err := newReplica(t, "b", key, clock, nil).ResubscribeToken(WithTenant(context.Background(), "acme"), "t1")
+	if err != nil {
+		t.Fatalf("ResubscribeToken: %v", err)
+	}
+
+	for _, test := range []struct {
+		name          string
+		tenant        string
+		taskID        string
+		wantForwarded bool
+	}{
+		{"same tenant and task", "acme", "t1", true},
+		{"other task", "acme", "t2", false},
+		{"other tenant", "other", "t1", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			forwarded = 0
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(types.ResubscribeTokenHeader, token)
+			request := &types.JSONRPCRequest{ID: 1, Method: types.ResubscribeMethod, Params: &types.TaskIdParams{ID: test.taskID}}
+			handled := a.forwardResubscribe(WithTenant(context.Background(), test.tenant), httptest.NewRecorder(), r, request)
+			if handled != test.wantForwarded || (forwarded == 1) != test.wantForwarded {
+				t.Fatalf("handled %v with %d forwarded requests, want forwarded %v", handled, forwarded, test.wantForwarded)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// TimeoutMsKey is the params or params.metadata field carrying a request's timeout in milliseconds
+const TimeoutMsKey = "timeoutMs"
+
+// requestTimeout returns the timeout requested in params.timeoutMs or params.metadata.timeoutMs
+func requestTimeout(params interface{}) (time.Duration, bool) {
+	fields, ok := params.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	value, ok := fields[TimeoutMsKey]
+	if !ok {
+		metadata, _ := fields["metadata"].(map[string]interface{})
+		value, ok = metadata[TimeoutMsKey]
+	}
+	if !ok {
+		return 0, false
+	}
+	ms, ok := value.(float64)
+	if !ok || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
+
+type requestTimeoutKey struct{}
+
+// withRequestDeadline derives a context that expires after the request's timeoutMs, if any
+func withRequestDeadline(ctx context.Context, request *types.JSONRPCRequest) (context.Context, context.CancelFunc) {
+	timeout, ok := requestTimeout(request.Params)
+	if !ok {
+		return ctx, func() {}
+	}
+	ctx = context.WithValue(ctx, requestTimeoutKey{}, timeout)
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timedOut reports whether ctx ended because its request deadline passed
+func timedOut(ctx context.Context) bool {
+	_, hasTimeout := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	return hasTimeout && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// timeoutError is the error returned for requests exceeding their timeoutMs
+func timeoutError(ctx context.Context) *types.JSONRPCError {
+	timeout, _ := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	return &types.JSONRPCError{
+		Code:    TimeoutErrorCode,
+		Message: fmt.Sprintf("Request timed out after %s", timeout),
+		Data:    map[string]interface{}{TimeoutMsKey: timeout.Milliseconds()},
+	}
+}
+
+// writeStreamTimeout ends an SSE stream whose request deadline passed with a timeout error event
+func writeStreamTimeout(ctx context.Context, w http.ResponseWriter, flusher http.Flusher) {
+	data, err := json.Marshal(&types.SendTaskStreamingResponse{Error: timeoutError(ctx)})
+	if err != nil {
+		log.Printf("Failed to marshal timeout event: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	flusher.Flush()
+}
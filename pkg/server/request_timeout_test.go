@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestRequestDeadline(t *testing.T) {
+	for _, params := range []interface{}{
+		map[string]interface{}{"id": "t1", TimeoutMsKey: float64(1)},
+		map[string]interface{}{"id": "t1", "metadata": map[string]interface{}{TimeoutMsKey: float64(1)}},
+	} {
+		ctx, cancel := withRequestDeadline(context.Background(), &types.JSONRPCRequest{Params: params})
+		<-ctx.Done()
+		if !timedOut(ctx) {
+			t.Fatalf("request with params %v did not time out", params)
+		}
+		if err := timeoutError(ctx); err.Code != types.TimeoutErrorCode {
+			t.Fatalf("timeout error code = %d, want %d", err.Code, types.TimeoutErrorCode)
+		}
+		cancel()
+	}
+
+	ctx, cancel := withRequestDeadline(context.Background(), &types.JSONRPCRequest{Params: map[string]interface{}{"id": "t1"}})
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("request without timeoutMs got a deadline")
+	}
+	canceled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	if timedOut(canceled) {
+		t.Fatal("canceled request counted as timed out")
+	}
+}
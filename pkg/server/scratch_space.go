@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrScratchQuotaExceeded is returned by writes that would take a scratch space over its quota
+var ErrScratchQuotaExceeded = errors.New("scratch space quota exceeded")
+
+// WithScratchSpace gives every task a temporary directory under root, the system temp
+// directory if empty, created on first use and removed once the task reaches a terminal
+// state. quota limits the bytes stored in each directory, 0 for no limit. Files meant to
+// outlive the task must be turned into artifacts before it completes.
+func WithScratchSpace(root string, quota int64) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		if root == "" {
+			root = os.TempDir()
+		}
+		tm.scratchRoot = root
+		tm.scratchQuota = quota
+	}
+}
+
+// ScratchSpace is the temporary directory of a task
+type ScratchSpace struct {
+	dir   string
+	quota int64
+
+	lock    sync.Mutex
+	used    int64 // Bytes written through Create and measured by Usage
+	removed bool
+}
+
+// ScratchSpace returns the scratch space of a task belonging to the tenant in ctx, creating
+// its directory if needed
+func (tm *InMemoryTaskManager) ScratchSpace(ctx context.Context, taskID string) (*ScratchSpace, error) {
+	if tm.scratchRoot == "" {
+		return nil, errors.New("scratch spaces are not enabled")
+	}
+	if space, err := tm.registerScratchSpace(ctx, taskID, nil); space != nil || err != nil {
+		return space, err
+	}
+
+	if err := os.MkdirAll(tm.scratchRoot, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create scratch root: %v", err)
+	}
+	dir, err := os.MkdirTemp(tm.scratchRoot, "a2a-task-"+containerNameChars.ReplaceAllString(taskID, "_")+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch space: %v", err)
+	}
+	space := &ScratchSpace{dir: dir, quota: tm.scratchQuota}
+	registered, err := tm.registerScratchSpace(ctx, taskID, space)
+	if registered != space {
+		// The task ended or another caller created its space meanwhile
+		space.remove()
+	}
+	return registered, err
+}
+
+// registerScratchSpace returns the scratch space of a task, registering space if it has none
+// yet; space may be nil to only look it up. It fails once the task has ended. Registration
+// happens under tm.lock so it can't miss the task reaching a terminal state.
+func (tm *InMemoryTaskManager) registerScratchSpace(ctx context.Context, taskID string, space *ScratchSpace) (*ScratchSpace, error) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		return nil, taskNotFound(taskID)
+	}
+	if isTerminalState(task.Status.State) {
+		return nil, invalidTaskState(taskID, task.Status.State, "Task is %s, its scratch space was removed", task.Status.State)
+	}
+
+	key := subscriberKey(ctx, taskID)
+	tm.scratchLock.Lock()
+	defer tm.scratchLock.Unlock()
+	if registered := tm.scratchSpaces[key]; registered != nil || space == nil {
+		return registered, nil
+	}
+	if tm.scratchSpaces == nil {
+		tm.scratchSpaces = make(map[taskKey]*ScratchSpace)
+	}
+	tm.scratchSpaces[key] = space
+	return space, nil
+}
+
+// takeScratchSpace unregisters the scratch space of a task, returning it for removal
+func (tm *InMemoryTaskManager) takeScratchSpace(ctx context.Context, taskID string) *ScratchSpace {
+	key := subscriberKey(ctx, taskID)
+	tm.scratchLock.Lock()
+	defer tm.scratchLock.Unlock()
+	space := tm.scratchSpaces[key]
+	delete(tm.scratchSpaces, key)
+	return space
+}
+
+// Dir returns the directory of the scratch space
+func (s *ScratchSpace) Dir() string {
+	return s.dir
+}
+
+// Path returns the path of name within the scratch space, rejecting names leaving it
+func (s *ScratchSpace) Path(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid scratch file name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Create creates or truncates a file in the scratch space, creating its parent directories.
+// Writes to the file count against the quota.
+func (s *ScratchSpace) Create(name string) (*ScratchFile, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path); err == nil {
+		s.release(info.Size())
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ScratchFile{File: file, space: s}, nil
+}
+
+// Usage measures the bytes stored in the scratch space, including files written without
+// Create, e.g. by child processes
+func (s *ScratchSpace) Usage() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.lock.Lock()
+	s.used = size
+	s.lock.Unlock()
+	return size, nil
+}
+
+// CheckQuota returns ErrScratchQuotaExceeded if the scratch space holds more than its quota
+func (s *ScratchSpace) CheckQuota() error {
+	if s.quota <= 0 {
+		return nil
+	}
+	used, err := s.Usage()
+	if err != nil {
+		return err
+	}
+	if used > s.quota {
+		return fmt.Errorf("%w: %d of %d bytes used", ErrScratchQuotaExceeded, used, s.quota)
+	}
+	return nil
+}
+
+// reserve accounts for n more bytes, failing if they don't fit in the quota
+func (s *ScratchSpace) reserve(n int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.removed {
+		return os.ErrClosed
+	}
+	if s.quota > 0 && s.used+n > s.quota {
+		return fmt.Errorf("%w: %d of %d bytes used", ErrScratchQuotaExceeded, s.used, s.quota)
+	}
+	s.used += n
+	return nil
+}
+
+func (s *ScratchSpace) release(n int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.used = max(s.used-n, 0)
+}
+
+// remove deletes the directory of the scratch space
+func (s *ScratchSpace) remove() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	s.removed = true
+	s.lock.Unlock()
+	if err := os.RemoveAll(s.dir); err != nil {
+		log.Printf("Failed to remove scratch space %s: %v", s.dir, err)
+	}
+}
+
+// ScratchFile is a file of a scratch space whose writes count against its quota
+type ScratchFile struct {
+	*os.File
+	space *ScratchSpace
+}
+
+// Write writes p unless that would exceed the quota, in which case nothing is written
+func (f *ScratchFile) Write(p []byte) (int, error) {
+	if err := f.space.reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := f.File.Write(p)
+	if n < len(p) {
+		f.space.release(int64(len(p) - n))
+	}
+	return n, err
+}
+
+// WriteString writes s unless that would exceed the quota
+func (f *ScratchFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// ReadFrom copies r into the file, failing once the quota is reached
+func (f *ScratchFile) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
+
+type scratchContextKey struct{}
+
+// ContextWithScratchSpace returns a copy of ctx carrying space, for passing it to the
+// functions a handler calls
+func ContextWithScratchSpace(ctx context.Context, space *ScratchSpace) context.Context {
+	return context.WithValue(ctx, scratchContextKey{}, space)
+}
+
+// ScratchSpaceFromContext returns the scratch space carried by ctx, or nil if none
+func ScratchSpaceFromContext(ctx context.Context) *ScratchSpace {
+	space, _ := ctx.Value(scratchContextKey{}).(*ScratchSpace)
+	return space
+}
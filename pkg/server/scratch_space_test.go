@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+// scratchTask creates a task with a scratch space holding a file and returns the space
+func scratchTask(t *testing.T, tm *InMemoryTaskManager, taskID, sessionID string) *ScratchSpace {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: taskID, SessionID: sessionID, Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	space, err := tm.ScratchSpace(ctx, taskID)
+	if err != nil {
+		t.Fatalf("ScratchSpace: %v", err)
+	}
+	file, err := space.Create("work/data.txt")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	file.WriteString("scratch")
+	file.Close()
+	return space
+}
+
+func assertRemoved(t *testing.T, space *ScratchSpace) {
+	t.Helper()
+	if _, err := os.Stat(space.Dir()); !os.IsNotExist(err) {
+		t.Fatalf("scratch space %s still exists: %v", space.Dir(), err)
+	}
+}
+
+func TestScratchSpaceRemovedWhenTaskEnds(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		end  func(tm *InMemoryTaskManager, taskID string) error
+	}{
+		{"completed", func(tm *InMemoryTaskManager, taskID string) error {
+			return tm.publishStatus(ctx, taskID, types.TaskCompleted, nil, true)
+		}},
+		{"canceled", func(tm *InMemoryTaskManager, taskID string) error {
+			_, err := tm.cancelTask(ctx, taskID)
+			return err
+		}},
+		{"session closed", func(tm *InMemoryTaskManager, taskID string) error {
+			_, err := tm.CloseSession(ctx, "s1", "")
+			return err
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tm := NewInMemoryTaskManager(WithScratchSpace(t.TempDir(), 0))
+			space := scratchTask(t, tm, "t1", "s1")
+			if err := test.end(tm, "t1"); err != nil {
+				t.Fatalf("ending task: %v", err)
+			}
+			assertRemoved(t, space)
+			if _, err := tm.ScratchSpace(ctx, "t1"); err == nil {
+				t.Fatalf("ScratchSpace of an ended task succeeded")
+			}
+		})
+	}
+}
+
+func TestScratchSpaceReused(t *testing.T) {
+	tm := NewInMemoryTaskManager(WithScratchSpace(t.TempDir(), 0))
+	space := scratchTask(t, tm, "t1", "s1")
+	again, err := tm.ScratchSpace(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("ScratchSpace: %v", err)
+	}
+	if again != space {
+		t.Fatalf("ScratchSpace created a second space %s next to %s", again.Dir(), space.Dir())
+	}
+}
@@ -1,25 +1,130 @@
 package server
 
 import (
+	"a2a-go/pkg/codec"
 	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
 )
 
+// defaultAgentCardMaxAge is how long clients may cache the agent card by default
+const defaultAgentCardMaxAge = 5 * time.Minute
+
 // A2AServer represents an A2A server that handles JSON-RPC requests
 type A2AServer struct {
-	host         string
-	port         int
-	endpoint     string
-	taskManager  TaskManager
-	agentCard    *types.AgentCard
-	server       *http.Server
+	host        string
+	port        int
+	endpoint    string
+	taskManager TaskManager
+	agentCard   *types.AgentCard // Copy of the card passed to NewA2AServer
+	server      *http.Server
+	optionErr   error // First error of a ServerOption, returned by NewA2AServer
+
+	tenantResolver TenantResolver
+	eventsEndpoint string
+
+	agentCardMaxAge time.Duration
+	jwksURL         string
+	pathPrefix      string
+	trustForwarded  bool
+	network         string
+	dedup           *replayCache
+	rateLimiter     *rateLimiter
+
+	artifactKey    []byte
+	artifactURLTTL time.Duration
+
+	streams streamCounters
+
+	methods     map[string]MethodHandler
+	methodsLock sync.RWMutex
+
+	extensions     []Extension
+	extensionsLock sync.RWMutex
+
+	chunkedTasks bool
+
+	clock utils.Clock
+
+	streamCompression      bool
+	streamCompressionLevel int
+	streamWriteTimeout     time.Duration
+	streamBatchInterval    time.Duration
+	streamBatchEvents      int
+
+	codecs map[string]codec.Codec
+
+	serviceNotify bool
+	serverLock    sync.Mutex
+	watchdogStop  chan struct{}
+
+	shutdown           chan struct{}
+	shutdownOnce       sync.Once
+	shutdownRetryAfter time.Duration
+
+	replicas *replicaRouting
+
+	authorizer        Authorizer
+	principalResolver PrincipalResolver
+
+	certFile  string
+	keyFile   string
+	tlsConfig *tls.Config
+	clientCAs *x509.CertPool
+	autocert  *autocert.Manager
+}
+
+// ServerOption configures optional A2AServer behavior
+type ServerOption func(*A2AServer)
+
+// WithTenantResolver sets the resolver used to scope each request to a tenant
+func WithTenantResolver(resolver TenantResolver) ServerOption {
+	return func(s *A2AServer) {
+		s.tenantResolver = resolver
+	}
+}
+
+// WithAgentCardMaxAge sets the Cache-Control max-age advertised for the agent card
+func WithAgentCardMaxAge(maxAge time.Duration) ServerOption {
+	return func(s *A2AServer) {
+		s.agentCardMaxAge = maxAge
+	}
+}
+
+// WithJWKSURL advertises the JWKS holding the push notification signing keys in the agent
+// card, for keys hosted elsewhere than /.well-known/jwks.json. Relative URLs are resolved by
+// clients against the agent URL.
+func WithJWKSURL(jwksURL string) ServerOption {
+	return func(s *A2AServer) {
+		s.jwksURL = jwksURL
+	}
+}
+
+// WithServerClock sets the clock used for artifact URL expiry and request deduplication
+func WithServerClock(clock utils.Clock) ServerOption {
+	return func(s *A2AServer) {
+		s.clock = clock
+	}
 }
 
 // NewA2AServer creates a new A2AServer instance
-func NewA2AServer(host string, port int, endpoint string, agentCard *types.AgentCard, taskManager TaskManager) (*A2AServer, error) {
+func NewA2AServer(host string, port int, endpoint string, agentCard *types.AgentCard, taskManager TaskManager, opts ...ServerOption) (*A2AServer, error) {
 	if agentCard == nil {
 		return nil, fmt.Errorf("agent_card is not defined")
 	}
@@ -28,42 +133,132 @@ func NewA2AServer(host string, port int, endpoint string, agentCard *types.Agent
 		return nil, fmt.Errorf("task_manager is not defined")
 	}
 
-	return &A2AServer{
-		host:        host,
-		port:        port,
-		endpoint:    endpoint,
-		agentCard:   agentCard,
-		taskManager: taskManager,
-	}, nil
+	// Copy the card so neither the server nor the caller sees changes the other makes
+	card := *agentCard
+	card.Capabilities.Extensions = slices.Clone(agentCard.Capabilities.Extensions)
+	agentCard = &card
+
+	s := &A2AServer{
+		host:               host,
+		port:               port,
+		endpoint:           endpoint,
+		agentCard:          agentCard,
+		taskManager:        taskManager,
+		agentCardMaxAge:    defaultAgentCardMaxAge,
+		clock:              utils.DefaultClock(),
+		shutdown:           make(chan struct{}),
+		shutdownRetryAfter: defaultShutdownRetryAfter,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.optionErr != nil {
+		return nil, s.optionErr
+	}
+	if s.dedup != nil {
+		s.dedup.clock = s.clock
+	}
+	if s.rateLimiter != nil {
+		s.rateLimiter.clock = s.clock
+	}
+	if aware, ok := taskManager.(agentCardAware); ok {
+		aware.applyAgentCard(agentCard)
+	}
+	return s, nil
+}
+
+// Handler returns the HTTP handler serving all A2A endpoints, for mounting in an existing server
+func (s *A2AServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.path(s.endpoint), s.processRequest)
+	mux.HandleFunc(s.path("/.well-known/agent.json"), s.getAgentCard)
+	if s.eventsEndpoint != "" {
+		mux.HandleFunc(s.path(s.eventsEndpoint), s.streamEvents)
+	}
+	if len(s.artifactKey) > 0 {
+		mux.HandleFunc(s.path(artifactsPath+"{taskId}/{index}"), s.serveArtifact)
+	}
+	return mux
 }
 
 // Start starts the A2A server
 func (s *A2AServer) Start() error {
-	mux := http.NewServeMux()
-	mux.HandleFunc(s.endpoint, s.processRequest)
-	mux.HandleFunc("/.well-known/agent.json", s.getAgentCard)
+	server := &http.Server{
+		Addr:      s.Addr(),
+		Handler:   s.Handler(),
+		TLSConfig: s.serverTLSConfig(),
+	}
+	s.serverLock.Lock()
+	s.server = server
+	s.serverLock.Unlock()
 
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
-		Handler: mux,
+	listener, err := s.listen()
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Starting server on %s:%d", s.host, s.port)
-	return s.server.ListenAndServe()
+	if server.TLSConfig != nil {
+		log.Printf("Starting server on %s with TLS", listener.Addr())
+		s.notifyReady(listener)
+		return server.ServeTLS(listener, s.certFile, s.keyFile)
+	}
+	log.Printf("Starting server on %s", listener.Addr())
+	s.notifyReady(listener)
+	return server.Serve(listener)
 }
 
 // getAgentCard handles requests for the agent card
 func (s *A2AServer) getAgentCard(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(s.agentCard); err != nil {
+	body, err := json.Marshal(s.publicAgentCard(r))
+	if err != nil {
 		http.Error(w, "Failed to encode agent card", http.StatusInternalServerError)
 		return
 	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.agentCardMaxAge.Seconds())))
+	if s.trustForwarded {
+		// The card's URL depends on the proxy's headers, so caches must not share it across them
+		w.Header().Set("Vary", "Forwarded, X-Forwarded-Host, X-Forwarded-Proto")
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Failed to write agent card: %v", err)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
 
 // processRequest handles JSON-RPC requests
@@ -73,68 +268,161 @@ func (s *A2AServer) processRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w, r, finish, codecErr := s.withCodec(w, r)
+	defer finish()
+	if codecErr != nil {
+		s.handleError(w, nil, types.ParseError(codecErr.Error()))
+		return
+	}
+
 	var jsonRPCRequest types.JSONRPCRequest
 	if err := json.NewDecoder(r.Body).Decode(&jsonRPCRequest); err != nil {
-		s.handleError(w, &types.JSONRPCError{
-			Code:    -32700,
-			Message: "Parse error",
-		})
+		s.handleError(w, nil, types.ParseError(err.Error()))
+		return
+	}
+	if jsonRPCRequest.JSONRPC != "2.0" || jsonRPCRequest.Method == "" {
+		s.handleError(w, jsonRPCRequest.ID, types.InvalidRequestError(`jsonrpc must be "2.0" and method is required`))
+		return
+	}
+
+	ctx := r.Context()
+	if s.tenantResolver != nil {
+		tenant, err := s.tenantResolver(r)
+		if err != nil {
+			s.handleError(w, jsonRPCRequest.ID, types.InvalidRequestError(err.Error()))
+			return
+		}
+		ctx = WithTenant(ctx, tenant)
+	}
+
+	if s.rateLimiter != nil {
+		if wait, ok := s.rateLimiter.allow(r.WithContext(ctx)); !ok {
+			s.handleError(w, jsonRPCRequest.ID, toJSONRPCError(ctx, Throttled(wait, "Rate limit exceeded")))
+			return
+		}
+	}
+
+	ctx, err := s.authorize(ctx, r, jsonRPCRequest.Method, jsonRPCRequest.Params, "")
+	if err != nil {
+		s.handleError(w, jsonRPCRequest.ID, toJSONRPCError(ctx, err))
+		return
+	}
+
+	ctx, err = s.activateExtensions(ctx, w, r, &jsonRPCRequest)
+	if err != nil {
+		s.handleError(w, jsonRPCRequest.ID, toJSONRPCError(ctx, err))
+		return
+	}
+
+	if s.dedup != nil {
+		if key := s.dedup.key(r.WithContext(ctx), jsonRPCRequest.Method, jsonRPCRequest.ID, jsonRPCRequest.Params); key != "" {
+			entry, duplicate := s.dedup.begin(key)
+			if duplicate {
+				entry.replay(w, r)
+				return
+			}
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer s.dedup.finish(entry, recorder)
+			w = recorder
+		}
+	}
+
+	ctx, cancel := withRequestDeadline(ctx, &jsonRPCRequest)
+	defer cancel()
+	ctx = withAcceptEncoding(ctx, r)
+
+	if err := decodeParams(&jsonRPCRequest); err != nil {
+		s.handleError(w, jsonRPCRequest.ID, toJSONRPCError(ctx, err))
 		return
 	}
 
 	var result interface{}
-	var err error
 
 	switch jsonRPCRequest.Method {
 	case "get_task":
-		result = s.taskManager.OnGetTask(&jsonRPCRequest)
-	case "send_task":
-		result = s.taskManager.OnSendTask(&jsonRPCRequest)
+		var response *types.GetTaskResponse
+		response, err = s.taskManager.OnGetTask(ctx, &jsonRPCRequest)
+		if err == nil && s.chunkedTasks && response != nil && response.Result != nil && len(response.Result.Artifacts) > 0 {
+			s.writeTaskChunked(w, jsonRPCRequest.ID, response)
+			return
+		}
+		result = response
 	case "send_task_streaming":
-		result, err = s.taskManager.OnSendTaskSubscribe(&jsonRPCRequest)
-	case "cancel_task":
-		result = s.taskManager.OnCancelTask(&jsonRPCRequest)
-	case "set_task_push_notification":
-		result = s.taskManager.OnSetTaskPushNotification(&jsonRPCRequest)
-	case "get_task_push_notification":
-		result = s.taskManager.OnGetTaskPushNotification(&jsonRPCRequest)
+		s.setResubscribeToken(ctx, w, &jsonRPCRequest)
+		if streamer, ok := s.taskManager.(TaskStreamer); ok {
+			result, err = streamer.OnSendTaskStream(ctx, &jsonRPCRequest)
+		} else {
+			result, err = s.taskManager.OnSendTaskSubscribe(ctx, &jsonRPCRequest)
+		}
 	case "resubscribe_to_task":
-		result, err = s.taskManager.OnResubscribeToTask(&jsonRPCRequest)
+		if s.forwardResubscribe(ctx, w, r, &jsonRPCRequest) {
+			return
+		}
+		s.setResubscribeToken(ctx, w, &jsonRPCRequest)
+		result, err = s.taskManager.OnResubscribeToTask(ctx, &jsonRPCRequest)
+	case types.BatchGetTasksMethod, types.BatchCancelTasksMethod:
+		result = s.batchTasks(ctx, &jsonRPCRequest)
+	case types.AgentCapabilitiesMethod:
+		result = s.agentCapabilities(ctx, jsonRPCRequest.Params.(*types.AgentCapabilitiesParams))
 	default:
-		s.handleError(w, &types.JSONRPCError{
-			Code:    -32601,
-			Message: "Method not found",
-		})
-		return
+		var dispatched bool
+		if result, dispatched, err = dispatchMethod(ctx, s.taskManager, &jsonRPCRequest); dispatched {
+			break
+		}
+		handler, ok := s.customMethod(jsonRPCRequest.Method)
+		if !ok {
+			s.handleError(w, jsonRPCRequest.ID, types.MethodNotFoundError(jsonRPCRequest.Method))
+			return
+		}
+		var rpcErr *types.JSONRPCError
+		result, rpcErr = s.callCustomMethod(ctx, handler, &jsonRPCRequest)
+		if rpcErr != nil {
+			s.handleError(w, jsonRPCRequest.ID, rpcErr)
+			return
+		}
+	}
+
+	if timedOut(ctx) {
+		if _, streaming := result.(chan *types.SendTaskStreamingResponse); !streaming {
+			s.handleError(w, jsonRPCRequest.ID, timeoutError(ctx))
+			return
+		}
 	}
 
 	if err != nil {
-		s.handleError(w, &types.JSONRPCError{
-			Code:    -32603,
-			Message: err.Error(),
-		})
+		s.handleError(w, jsonRPCRequest.ID, toJSONRPCError(ctx, err))
+		return
+	}
+	if isNil(result) {
+		s.handleError(w, jsonRPCRequest.ID, types.InternalError(fmt.Sprintf("%s returned no result", jsonRPCRequest.Method)))
 		return
 	}
 
-	s.createResponse(w, result)
+	s.createResponse(ctx, w, jsonRPCRequest.ID, result)
 }
 
 // handleError handles error responses
-func (s *A2AServer) handleError(w http.ResponseWriter, error *types.JSONRPCError) {
+func (s *A2AServer) handleError(w http.ResponseWriter, id interface{}, error *types.JSONRPCError) {
 	response := &types.JSONRPCResponse{
 		JSONRPC: "2.0",
+		ID:      id,
 		Error:   error,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
+	status := setRetryAfter(w, error)
+	if error.Code == PermissionDeniedErrorCode {
+		status = http.StatusForbidden
+	}
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Printf("Failed to encode error response: %v", err)
 	}
 }
 
-// createResponse creates the appropriate response based on the result type
-func (s *A2AServer) createResponse(w http.ResponseWriter, result interface{}) {
+// createResponse creates the appropriate response based on the result type.
+// Streams end early when ctx, the request context, is canceled because the client went away.
+func (s *A2AServer) createResponse(ctx context.Context, w http.ResponseWriter, id interface{}, result interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch v := result.(type) {
@@ -152,19 +440,111 @@ func (s *A2AServer) createResponse(w http.ResponseWriter, result interface{}) {
 			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
 			return
 		}
+		w, flusher, closeStream := s.compressStream(ctx, w, flusher)
+		defer closeStream()
+		events := s.newEventWriter(w)
+		defer events.stop()
+
+		s.streams.started.Add(1)
+		var taskID string
+		for {
+			var response *types.SendTaskStreamingResponse
+			var ok bool
+			select {
+			case response, ok = <-v:
+			case <-events.due():
+				if err := events.flush(); err != nil {
+					s.countWriteError(err)
+					return
+				}
+				continue
+			case <-s.shutdown:
+				s.writeShutdownEvent(w, flusher, id, taskID)
+				return
+			case <-ctx.Done():
+				if timedOut(ctx) {
+					writeStreamTimeout(ctx, w, flusher)
+					s.streams.completed.Add(1)
+					return
+				}
+				s.streams.clientDisconnected.Add(1)
+				return
+			}
+			if !ok {
+				if err := events.flush(); err != nil {
+					s.countWriteError(err)
+					return
+				}
+				s.streams.completed.Add(1)
+				return
+			}
+			if eventTaskID := streamTaskID(response); eventTaskID != "" {
+				taskID = eventTaskID
+			}
 
-		for response := range v {
 			data, err := json.Marshal(response)
 			if err != nil {
 				log.Printf("Failed to marshal streaming response: %v", err)
 				continue
 			}
 
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			flusher.Flush()
+			if err := events.write(streamEventID(response), data); err != nil {
+				// The producer notices the disconnect through the canceled request context
+				s.countWriteError(err)
+				return
+			}
 		}
 	default:
-		log.Printf("Unexpected result type: %T", result)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		response, err := envelope(id, result)
+		if err != nil {
+			log.Printf("Failed to encode %T response: %v", result, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if _, err := w.Write(response); err != nil {
+			log.Printf("Failed to write JSON-RPC response: %v", err)
+		}
+	}
+}
+
+// isNil reports whether a method result is nil, including nil responses and channels
+func isNil(result interface{}) bool {
+	if result == nil {
+		return true
 	}
-}
\ No newline at end of file
+	v := reflect.ValueOf(result)
+	return (v.Kind() == reflect.Pointer || v.Kind() == reflect.Chan) && v.IsNil()
+}
+
+// envelope encodes a typed TaskManager response (e.g. *types.GetTaskResponse) as a JSON-RPC
+// response, adding the jsonrpc version and request id to its fields
+func envelope(id interface{}, result interface{}) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) != "null" {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+	}
+
+	idData, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	fields["jsonrpc"] = json.RawMessage(`"2.0"`)
+	fields["id"] = idData
+	if _, ok := fields["result"]; !ok {
+		if _, ok := fields["error"]; !ok {
+			fields["result"] = json.RawMessage("null")
+		}
+	}
+
+	response, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return append(response, '\n'), nil
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// serviceStopTimeout bounds the graceful shutdown requested by a service manager
+const serviceStopTimeout = 30 * time.Second
+
+// WithServiceNotify reports readiness, shutdown and watchdog keep-alives to systemd
+// through the sd_notify protocol. It has no effect unless NOTIFY_SOCKET is set, i.e.
+// when the agent runs as a Type=notify service.
+func WithServiceNotify() ServerOption {
+	return func(s *A2AServer) {
+		s.serviceNotify = true
+	}
+}
+
+// sdNotify sends state to the service manager socket in NOTIFY_SOCKET; it returns false
+// without error when the process is not supervised
+func sdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if socket[0] == '@' {
+		// Abstract socket namespace
+		addr.Name = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify service manager: %w", err)
+	}
+	return true, nil
+}
+
+// watchdogInterval returns how often keep-alives must be sent, or 0 if the watchdog is disabled
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// notifyReady tells the service manager the server accepts connections and starts the watchdog
+func (s *A2AServer) notifyReady(listener net.Listener) {
+	if !s.serviceNotify {
+		return
+	}
+	sent, err := sdNotify(fmt.Sprintf("READY=1\nSTATUS=Listening on %s\nMAINPID=%d", listener.Addr(), os.Getpid()))
+	if err != nil {
+		log.Printf("sd_notify: %v", err)
+		return
+	}
+	if !sent {
+		return
+	}
+
+	interval := watchdogInterval()
+	if interval <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	s.serverLock.Lock()
+	s.watchdogStop = stop
+	s.serverLock.Unlock()
+	go func() {
+		// Keep-alives are sent at half the interval, as recommended by sd_watchdog_enabled(3)
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := sdNotify("WATCHDOG=1"); err != nil {
+					log.Printf("sd_notify watchdog: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown gracefully stops a server started with Start, waiting for in-flight
+// requests until ctx is done. Active SSE streams end with a final event telling clients
+// how to resume, and supervising service managers are told the server is stopping.
+func (s *A2AServer) Shutdown(ctx context.Context) error {
+	s.serverLock.Lock()
+	server := s.server
+	stop := s.watchdogStop
+	s.watchdogStop = nil
+	s.serverLock.Unlock()
+
+	if s.serviceNotify {
+		if _, err := sdNotify("STOPPING=1"); err != nil {
+			log.Printf("sd_notify: %v", err)
+		}
+	}
+	if stop != nil {
+		close(stop)
+	}
+	s.beginShutdown()
+	if server == nil {
+		return nil
+	}
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package server
+
+// RunService runs the server as a Windows service when started by the service control
+// manager; on other platforms it behaves like Start
+func (s *A2AServer) RunService(name string) error {
+	return s.Start()
+}
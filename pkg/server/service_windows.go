@@ -0,0 +1,68 @@
+//go:build windows
+
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// RunService runs the server as the Windows service name when started by the service
+// control manager, answering stop and shutdown requests with a graceful Shutdown.
+// Otherwise it behaves like Start.
+func (s *A2AServer) RunService(name string) error {
+	inService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !inService {
+		return s.Start()
+	}
+	return svc.Run(name, &windowsService{server: s})
+}
+
+// windowsService adapts an A2AServer to svc.Handler
+type windowsService struct {
+	server *A2AServer
+}
+
+func (w *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- w.server.Start()
+	}()
+
+	accepts := svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+	for {
+		select {
+		case err := <-served:
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Service %s stopped: %v", args, err)
+				return false, 1
+			}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				ctx, cancel := context.WithTimeout(context.Background(), serviceStopTimeout)
+				err := w.server.Shutdown(ctx)
+				cancel()
+				if err != nil {
+					log.Printf("Error stopping service: %v", err)
+					return false, 1
+				}
+				return false, 0
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// SessionCloser is implemented by task managers that can end sessions
+type SessionCloser interface {
+	OnCloseSession(ctx context.Context, request *types.JSONRPCRequest) (*types.CloseSessionResponse, error)
+}
+
+// SessionCloseHook is called once a session is closed and its outstanding tasks are canceled
+type SessionCloseHook func(ctx context.Context, session *types.ClosedSession)
+
+// WithSessionRetention evicts the tasks of closed sessions from memory retention after the
+// close, along with their event logs, poll buffers, push notification configs and delivery
+// receipts; zero evicts them right away. Due sessions are evicted the next time a session is
+// closed or a task receives a message. Without it the tasks of closed sessions are kept.
+// A TaskStore keeps its copies.
+func WithSessionRetention(retention time.Duration) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.sessionRetention = retention
+		tm.sessionRetentionSet = true
+	}
+}
+
+// WithSessionCloseHook calls hook whenever a session is closed, e.g. to release the state an
+// agent keeps per conversation
+func WithSessionCloseHook(hook SessionCloseHook) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.sessionCloseHook = hook
+	}
+}
+
+// CloseSession ends a session of the tenant in ctx: it cancels the session's outstanding
+// tasks, rejects further messages to it, ends the multiplexed event streams filtered on it
+// and schedules its eviction per WithSessionRetention. Closing it again returns the same record.
+func (tm *InMemoryTaskManager) CloseSession(ctx context.Context, sessionID, reason string) (*types.ClosedSession, error) {
+	tm.lock.Lock()
+	store := tm.store(ctx)
+	if closed := store.closedSessions[sessionID]; closed != nil {
+		snapshot := *closed
+		tm.lock.Unlock()
+		return &snapshot, nil
+	}
+	if len(store.sessions[sessionID]) == 0 {
+		tm.lock.Unlock()
+		return nil, invalidParams("session %s not found", sessionID)
+	}
+
+	now := tm.clock.Now()
+	closed := &types.ClosedSession{
+		SessionID: sessionID,
+		Reason:    reason,
+		ClosedAt:  now.Format(time.RFC3339),
+	}
+	if tm.sessionRetentionSet {
+		evictAt := now.Add(tm.sessionRetention)
+		closed.EvictAt = evictAt.Format(time.RFC3339)
+		store.sessionEvictions[sessionID] = evictAt
+		if tm.nextSessionEviction.IsZero() || evictAt.Before(tm.nextSessionEviction) {
+			tm.nextSessionEviction = evictAt
+		}
+	}
+	store.closedSessions[sessionID] = closed
+	var outstanding []string
+	for _, taskID := range store.sessions[sessionID] {
+		if task := store.tasks[taskID]; task != nil && !isTerminalState(task.Status.State) {
+			outstanding = append(outstanding, taskID)
+		}
+	}
+	tm.lock.Unlock()
+
+	var canceled []string
+	for _, taskID := range outstanding {
+		task, err := tm.cancelTask(ctx, taskID)
+		var taskErr *TaskError
+		switch {
+		case errors.As(err, &taskErr) && taskErr.Code == TaskNotCancelableErrorCode:
+			// It ended on its own meanwhile
+		case err != nil:
+			log.Printf("Failed to cancel task %s of closed session %s: %v", taskID, sessionID, err)
+		case task.Status.State == types.TaskCanceled:
+			canceled = append(canceled, taskID)
+		}
+	}
+
+	tm.lock.Lock()
+	closed.Canceled = canceled
+	snapshot := *closed
+	tm.lock.Unlock()
+
+	tm.closeSessionSubscriptions(ctx, sessionID)
+	if tm.sessionCloseHook != nil {
+		tm.sessionCloseHook(ctx, &snapshot)
+	}
+	tm.evictClosedSessions(ctx)
+	return &snapshot, nil
+}
+
+// OnCloseSession handles sessions/close requests
+func (tm *InMemoryTaskManager) OnCloseSession(ctx context.Context, request *types.JSONRPCRequest) (*types.CloseSessionResponse, error) {
+	params := request.Params.(*types.SessionCloseParams)
+	closed, err := tm.CloseSession(ctx, params.SessionID, params.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CloseSessionResponse{Result: closed}, nil
+}
+
+// sessionClosedError rejects a message to a closed session, or to a task of one.
+// The caller must hold tm.lock.
+func sessionClosedError(store *tenantStore, sessionIDs ...string) error {
+	for _, sessionID := range sessionIDs {
+		if store.closedSessions[sessionID] != nil {
+			return invalidParams("session %s is closed", sessionID)
+		}
+	}
+	return nil
+}
+
+// closeSessionSubscriptions ends the multiplexed subscriptions filtered on a closed session
+// once they received the events buffered for them
+func (tm *InMemoryTaskManager) closeSessionSubscriptions(ctx context.Context, sessionID string) {
+	tenant := TenantFromContext(ctx)
+
+	tm.subscriberLock.Lock()
+	defer tm.subscriberLock.Unlock()
+
+	for sub := range tm.eventSubscriptions {
+		if sub.tenant == tenant && sub.sessionID == sessionID {
+			delete(tm.eventSubscriptions, sub)
+			sub.closeOnce.Do(func() { close(sub.events) })
+		}
+	}
+}
+
+// evictClosedSessions evicts the closed sessions of every tenant whose retention has passed.
+// It is cheap until the earliest pending eviction is due.
+func (tm *InMemoryTaskManager) evictClosedSessions(ctx context.Context) {
+	now := tm.clock.Now()
+	tm.lock.Lock()
+	if tm.nextSessionEviction.IsZero() || now.Before(tm.nextSessionEviction) {
+		tm.lock.Unlock()
+		return
+	}
+	type closedSession struct{ tenant, sessionID string }
+	var due []closedSession
+	var next time.Time
+	for tenant, store := range tm.tenants {
+		for sessionID, evictAt := range store.sessionEvictions {
+			if now.Before(evictAt) {
+				if next.IsZero() || evictAt.Before(next) {
+					next = evictAt
+				}
+				continue
+			}
+			due = append(due, closedSession{tenant, sessionID})
+			delete(store.sessionEvictions, sessionID)
+		}
+	}
+	tm.nextSessionEviction = next
+	tm.lock.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, session := range due {
+		tm.evictSession(WithTenant(ctx, session.tenant), session.sessionID)
+	}
+}
+
+// evictSession drops the tasks of a closed session and the state kept for them from memory
+func (tm *InMemoryTaskManager) evictSession(ctx context.Context, sessionID string) {
+	var scratches []*ScratchSpace
+	tm.lock.Lock()
+	store := tm.store(ctx)
+	taskIDs := store.sessions[sessionID]
+	delete(store.sessions, sessionID)
+	delete(store.closedSessions, sessionID)
+	for _, taskID := range taskIDs {
+		key := subscriberKey(ctx, taskID)
+		scratches = append(scratches, tm.finishTask(ctx, taskID))
+		// Indexing a task without metadata removes it from the index
+		tm.indexMetadata(store, &types.Task{ID: taskID})
+		delete(store.tasks, taskID)
+		delete(store.acceptedOutputModes, taskID)
+		delete(store.artifactIndices, taskID)
+		delete(store.eventSeqs, taskID)
+		delete(store.owners, taskID)
+		delete(tm.eventLogs, key)
+		delete(tm.pollBuffers, key)
+		delete(tm.resumeFuncs, key)
+	}
+	tm.lock.Unlock()
+
+	for i, taskID := range taskIDs {
+		scratches[i].remove()
+		tm.taskSSESubscribers.drop(subscriberKey(ctx, taskID))
+		if tm.pushDeliverer != nil {
+			tm.pushDeliverer.forget(ctx, taskID)
+		}
+		if err := tm.pushConfigs.DeletePushConfig(ctx, taskID); err != nil {
+			log.Printf("Failed to delete push notification config of task %s: %v", taskID, err)
+		}
+	}
+}
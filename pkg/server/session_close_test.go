@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+func TestCloseSessionEvictsAfterRetention(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	tm := NewInMemoryTaskManager(WithClock(clock), WithSessionRetention(time.Hour), WithHeartbeat(time.Hour))
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	tm.SetProgressFunc(ctx, "t1", func() (float64, bool) { return 50, true })
+	if _, err := tm.setInputRequired(ctx, "t1", textMessage("which one?")); err != nil {
+		t.Fatalf("setInputRequired: %v", err)
+	}
+
+	closed, err := tm.CloseSession(ctx, "s1", "done")
+	if err != nil {
+		t.Fatalf("CloseSession: %v", err)
+	}
+	if len(closed.Canceled) != 1 || closed.EvictAt != "2025-01-01T01:00:00Z" {
+		t.Fatalf("closed session = %+v, want t1 canceled and eviction after an hour", closed)
+	}
+	key := subscriberKey(ctx, "t1")
+	if tm.inputWaiters[key] != nil || tm.progressFuncs[key] != nil || tm.heartbeatTimers[key] != nil {
+		t.Fatal("canceled task still holds its input waiter, progress func or heartbeat timer")
+	}
+	if len(tm.ListSessionTasks(ctx, "s1")) != 1 {
+		t.Fatal("closed session was evicted before its retention passed")
+	}
+
+	// The next message evicts the session once its retention passed
+	clock.Advance(time.Hour)
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t2", SessionID: "s2", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	tm.lock.Lock()
+	store := tm.store(ctx)
+	_, task := store.tasks["t1"]
+	_, session := store.closedSessions["s1"]
+	tm.lock.Unlock()
+	if task || session {
+		t.Fatalf("session was not evicted after its retention: task kept %v, closed session kept %v", task, session)
+	}
+
+	// Closing it again finds nothing to close
+	if _, err := tm.CloseSession(ctx, "s1", ""); err == nil {
+		t.Fatal("closing an evicted session succeeded")
+	}
+}
+
+func TestCloseSessionWithoutRetentionEvictsRightAway(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager(WithSessionRetention(0))
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	if _, err := tm.CloseSession(ctx, "s1", ""); err != nil {
+		t.Fatalf("CloseSession: %v", err)
+	}
+	if tasks := tm.ListSessionTasks(ctx, "s1"); len(tasks) != 0 {
+		t.Fatalf("session still has %d tasks", len(tasks))
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// defaultShutdownRetryAfter is the resume delay suggested to clients of streams closed on shutdown
+const defaultShutdownRetryAfter = time.Second
+
+// WithShutdownRetryAfter sets how long clients are told to wait before resubscribing to
+// streams closed by Shutdown, e.g. while a load balancer moves them to another replica
+func WithShutdownRetryAfter(retryAfter time.Duration) ServerOption {
+	return func(s *A2AServer) {
+		s.shutdownRetryAfter = retryAfter
+	}
+}
+
+// beginShutdown ends active SSE streams; each gets a final event with a resume hint
+func (s *A2AServer) beginShutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+// streamTaskID returns the task a streamed event belongs to, or ""
+func streamTaskID(response *types.SendTaskStreamingResponse) string {
+	switch event := response.Result.(type) {
+	case *types.TaskStatusUpdateEvent:
+		return event.ID
+	case *types.TaskArtifactUpdateEvent:
+		return event.ID
+	case types.TaskStatusUpdateEvent:
+		return event.ID
+	case types.TaskArtifactUpdateEvent:
+		return event.ID
+	}
+	return ""
+}
+
+// writeShutdownEvent sends the final event of a stream closed by Shutdown.
+// Task streams are told to resubscribe; multiplexed streams just reconnect.
+func (s *A2AServer) writeShutdownEvent(w http.ResponseWriter, flusher http.Flusher, id interface{}, taskID string) {
+	hint := types.ResumeHint{TaskID: taskID, RetryAfter: s.shutdownRetryAfter}
+	if taskID != "" {
+		hint.Method = types.ResubscribeMethod
+	}
+	data, err := json.Marshal(&types.SendTaskStreamingResponse{ID: id, Error: types.ShutdownError(hint)})
+	if err != nil {
+		log.Printf("Failed to marshal shutdown event: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	flusher.Flush()
+	s.streams.shutDown.Add(1)
+}
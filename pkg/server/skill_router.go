@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"a2a-go/pkg/types"
+)
+
+// SkillRouter is a TaskManager hosting several skills in one agent, each implemented by its
+// own TaskManager. Tasks are sent to the skill named by the skillId param or the skillId
+// metadata key, or else to the default skill; every later request for a task goes to the
+// skill that created it.
+type SkillRouter struct {
+	lock         sync.RWMutex
+	skills       map[string]TaskManager
+	order        []string // Skill ids in registration order
+	defaultSkill string
+	owners       map[taskKey]string // Skill of every task sent through the router
+}
+
+// SkillRouterOption configures a SkillRouter
+type SkillRouterOption func(*SkillRouter)
+
+// WithDefaultSkill sends tasks that don't name a skill to skillID. Without it they go to
+// the only registered skill, and are rejected if there are several.
+func WithDefaultSkill(skillID string) SkillRouterOption {
+	return func(r *SkillRouter) {
+		r.defaultSkill = skillID
+	}
+}
+
+// NewSkillRouter creates a SkillRouter without skills
+func NewSkillRouter(opts ...SkillRouterOption) *SkillRouter {
+	r := &SkillRouter{
+		skills: make(map[string]TaskManager),
+		owners: make(map[taskKey]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Handle registers the task manager implementing skillID, replacing any previous one
+func (r *SkillRouter) Handle(skillID string, handler TaskManager) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.skills[skillID]; !ok {
+		r.order = append(r.order, skillID)
+	}
+	r.skills[skillID] = handler
+}
+
+// Skill returns the task manager of skillID, or nil if none is registered
+func (r *SkillRouter) Skill(skillID string) TaskManager {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.skills[skillID]
+}
+
+// applyAgentCard passes the card on to the skills adapting to it, and warns about skills of
+// the card without a handler
+func (r *SkillRouter) applyAgentCard(card *types.AgentCard) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, id := range r.order {
+		if aware, ok := r.skills[id].(agentCardAware); ok {
+			aware.applyAgentCard(card)
+		}
+	}
+	for _, skill := range card.Skills {
+		if r.skills[skill.ID] == nil {
+			log.Printf("Skill %s of the agent card has no handler", skill.ID)
+		}
+	}
+}
+
+// sendTarget returns the skill a task sent with params goes to
+func (r *SkillRouter) sendTarget(ctx context.Context, params interface{}) (string, TaskManager, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	skillID := requestSkillID(params)
+	if skillID == "" {
+		if owner, ok := r.owners[subscriberKey(ctx, requestTaskID(params))]; ok {
+			skillID = owner
+		}
+	}
+	switch {
+	case skillID != "":
+	case r.defaultSkill != "":
+		skillID = r.defaultSkill
+	case len(r.order) == 1:
+		skillID = r.order[0]
+	default:
+		return "", nil, invalidParams("skillId is required, the agent has several skills")
+	}
+
+	handler := r.skills[skillID]
+	if handler == nil {
+		return "", nil, invalidParams("unknown skill %q", skillID)
+	}
+	return skillID, handler, nil
+}
+
+// setOwner records the skill of a task
+func (r *SkillRouter) setOwner(ctx context.Context, taskID, skillID string) {
+	if taskID == "" {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.owners[subscriberKey(ctx, taskID)] = skillID
+}
+
+// route calls call with the skill owning the task of request. Tasks the router hasn't seen,
+// e.g. after a restart, are looked for in every skill in registration order.
+func (r *SkillRouter) route(ctx context.Context, request *types.JSONRPCRequest, call func(TaskManager) error) error {
+	taskID := requestTaskID(request.Params)
+	key := subscriberKey(ctx, taskID)
+
+	r.lock.RLock()
+	owner, known := r.owners[key]
+	handler := r.skills[owner]
+	order := append([]string(nil), r.order...)
+	r.lock.RUnlock()
+
+	if known && handler != nil {
+		return call(handler)
+	}
+	for _, skillID := range order {
+		handler := r.Skill(skillID)
+		if handler == nil {
+			continue
+		}
+		err := call(handler)
+		if errors.Is(err, ErrTaskNotFound) {
+			continue
+		}
+		r.setOwner(ctx, taskID, skillID)
+		return err
+	}
+	return taskNotFound(taskID)
+}
+
+// OnSendTask sends the task to its skill
+func (r *SkillRouter) OnSendTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskResponse, error) {
+	skillID, handler, err := r.sendTarget(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	r.setOwner(ctx, requestTaskID(request.Params), skillID)
+	response, err := handler.OnSendTask(ctx, request)
+	if response != nil && response.Result != nil {
+		r.setOwner(ctx, response.Result.ID, skillID)
+	}
+	return response, err
+}
+
+// OnSendTaskSubscribe sends the task to its skill
+func (r *SkillRouter) OnSendTaskSubscribe(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
+	skillID, handler, err := r.sendTarget(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	r.setOwner(ctx, requestTaskID(request.Params), skillID)
+	response, err := handler.OnSendTaskSubscribe(ctx, request)
+	if response != nil {
+		r.setOwner(ctx, eventTaskID(response.Result), skillID)
+	}
+	return response, err
+}
+
+// OnSendTaskStream streams the task from its skill. The response of skills that don't
+// implement TaskStreamer is streamed as the only event.
+func (r *SkillRouter) OnSendTaskStream(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error) {
+	skillID, handler, err := r.sendTarget(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	r.setOwner(ctx, requestTaskID(request.Params), skillID)
+	if streamer, ok := handler.(TaskStreamer); ok {
+		return streamer.OnSendTaskStream(ctx, request)
+	}
+	response, err := handler.OnSendTaskSubscribe(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	responseChan := make(chan *types.SendTaskStreamingResponse, 1)
+	if response != nil {
+		r.setOwner(ctx, eventTaskID(response.Result), skillID)
+		responseChan <- response
+	}
+	close(responseChan)
+	return responseChan, nil
+}
+
+// OnGetTask gets the task from its skill
+func (r *SkillRouter) OnGetTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.GetTaskResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnGetTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnCancelTask cancels the task in its skill
+func (r *SkillRouter) OnCancelTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.CancelTaskResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnCancelTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnSetTaskPushNotification sets the push notification config of the task in its skill
+func (r *SkillRouter) OnSetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (response *types.SetTaskPushNotificationResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnSetTaskPushNotification(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnGetTaskPushNotification gets the push notification config of the task from its skill
+func (r *SkillRouter) OnGetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (response *types.GetTaskPushNotificationResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnGetTaskPushNotification(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnResubscribeToTask resubscribes to the task in its skill
+func (r *SkillRouter) OnResubscribeToTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.SendTaskStreamingResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnResubscribeToTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnPauseTask pauses the task in its skill
+func (r *SkillRouter) OnPauseTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.PauseTaskResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnPauseTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnResumeTask resumes the task in its skill
+func (r *SkillRouter) OnResumeTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.ResumeTaskResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnResumeTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnTransferTask transfers the task in its skill
+func (r *SkillRouter) OnTransferTask(ctx context.Context, request *types.JSONRPCRequest) (response *types.TransferTaskResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		response, err = handler.OnTransferTask(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// OnPollTaskEvents polls the events of a task from its skill, if that skill supports long polling
+func (r *SkillRouter) OnPollTaskEvents(ctx context.Context, request *types.JSONRPCRequest) (response *types.PollTaskEventsResponse, err error) {
+	err = r.route(ctx, request, func(handler TaskManager) (err error) {
+		poller, ok := handler.(EventPoller)
+		if !ok {
+			return &TaskError{Code: UnsupportedOperationErrorCode, Message: "The skill of the task does not support long polling"}
+		}
+		response, err = poller.OnPollTaskEvents(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// requestSkillID returns the skill named by send params, whether decoded or typed
+func requestSkillID(params interface{}) string {
+	switch p := params.(type) {
+	case map[string]interface{}:
+		if id, ok := p["skillId"].(string); ok && id != "" {
+			return id
+		}
+		metadata, _ := p["metadata"].(map[string]interface{})
+		id, _ := metadata[types.SkillIDMetadataKey].(string)
+		return id
+	case *types.TaskSendParams:
+		return p.RequestedSkill()
+	}
+	return ""
+}
+
+// eventTaskID returns the task id of a streaming event
+func eventTaskID(event interface{}) string {
+	switch e := event.(type) {
+	case *types.TaskStatusUpdateEvent:
+		return e.ID
+	case *types.TaskArtifactUpdateEvent:
+		return e.ID
+	case *types.Task:
+		return e.ID
+	}
+	return ""
+}
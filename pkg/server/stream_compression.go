@@ -0,0 +1,128 @@
+package server
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithStreamCompression compresses SSE streams with gzip or deflate when the client
+// advertises support for it in Accept-Encoding. level is a compress/flate level;
+// flate.DefaultCompression is used when it is out of range.
+func WithStreamCompression(level int) ServerOption {
+	return func(s *A2AServer) {
+		if level < flate.HuffmanOnly || level > flate.BestCompression {
+			level = flate.DefaultCompression
+		}
+		s.streamCompression = true
+		s.streamCompressionLevel = level
+	}
+}
+
+type acceptEncodingKey struct{}
+
+// withAcceptEncoding stores the request's Accept-Encoding header for createResponse
+func withAcceptEncoding(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, acceptEncodingKey{}, r.Header.Get("Accept-Encoding"))
+}
+
+// negotiateStreamEncoding picks gzip or deflate from an Accept-Encoding header, or "" for identity
+func negotiateStreamEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "deflate" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Prefer gzip on ties; it is the most widely supported
+		if q > bestQ || (q == bestQ && name == "gzip") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter is implemented by gzip.Writer and zlib.Writer
+type compressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressedStream compresses an SSE stream, flushing the compressor on every event
+type compressedStream struct {
+	http.ResponseWriter
+	writer  compressWriter
+	flusher http.Flusher
+}
+
+func (c *compressedStream) Write(p []byte) (int, error) {
+	return c.writer.Write(p)
+}
+
+// Unwrap gives http.ResponseController access to the connection
+func (c *compressedStream) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *compressedStream) Flush() {
+	if err := c.writer.Flush(); err != nil {
+		log.Printf("Failed to flush compressed stream: %v", err)
+	}
+	c.flusher.Flush()
+}
+
+// close writes the compression trailer
+func (c *compressedStream) close() {
+	if err := c.writer.Close(); err != nil {
+		log.Printf("Failed to close compressed stream: %v", err)
+		return
+	}
+	c.flusher.Flush()
+}
+
+// compressStream wraps w in a compressor negotiated from the request in ctx. It must be
+// called before the response headers are written; the returned func ends the stream.
+func (s *A2AServer) compressStream(ctx context.Context, w http.ResponseWriter, flusher http.Flusher) (http.ResponseWriter, http.Flusher, func()) {
+	noop := func() {}
+	if !s.streamCompression {
+		return w, flusher, noop
+	}
+	acceptEncoding, _ := ctx.Value(acceptEncodingKey{}).(string)
+	encoding := negotiateStreamEncoding(acceptEncoding)
+
+	var writer compressWriter
+	var err error
+	switch encoding {
+	case "gzip":
+		writer, err = gzip.NewWriterLevel(w, s.streamCompressionLevel)
+	case "deflate":
+		writer, err = zlib.NewWriterLevel(w, s.streamCompressionLevel)
+	default:
+		return w, flusher, noop
+	}
+	if err != nil {
+		log.Printf("Failed to create %s writer: %v", encoding, err)
+		return w, flusher, noop
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Del("Content-Length")
+	stream := &compressedStream{ResponseWriter: w, writer: writer, flusher: flusher}
+	return stream, stream, stream.close
+}
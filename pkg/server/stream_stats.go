@@ -0,0 +1,32 @@
+package server
+
+import "sync/atomic"
+
+// StreamStats counts SSE streams served by an A2AServer
+type StreamStats struct {
+	Started            uint64
+	Completed          uint64
+	ClientDisconnected uint64 // Streams abandoned by the client before the final event
+	ShutDown           uint64 // Streams closed with a resume hint because the server shut down
+	WriteTimeouts      uint64 // Disconnected streams whose client stopped reading, see WithStreamWriteTimeout
+}
+
+// streamCounters holds the live counters behind StreamStats
+type streamCounters struct {
+	started            atomic.Uint64
+	completed          atomic.Uint64
+	clientDisconnected atomic.Uint64
+	shutDown           atomic.Uint64
+	writeTimeouts      atomic.Uint64
+}
+
+// StreamStats returns the SSE stream counters of the server
+func (s *A2AServer) StreamStats() StreamStats {
+	return StreamStats{
+		Started:            s.streams.started.Load(),
+		Completed:          s.streams.completed.Load(),
+		ClientDisconnected: s.streams.clientDisconnected.Load(),
+		ShutDown:           s.streams.shutDown.Load(),
+		WriteTimeouts:      s.streams.writeTimeouts.Load(),
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// WithStreamWriteTimeout gives every SSE event d to reach the client's socket. A stream
+// whose client stopped reading, e.g. behind a dead connection, is closed once a write
+// misses its deadline instead of blocking its producer until TCP gives up.
+func WithStreamWriteTimeout(d time.Duration) ServerOption {
+	return func(s *A2AServer) {
+		s.streamWriteTimeout = d
+	}
+}
+
+// WithStreamBatching flushes SSE events in batches: once maxEvents are pending, or interval
+// after the first pending event, whichever comes first. This saves syscalls on high
+// frequency streams such as token by token output, delaying events by up to interval.
+// Either limit may be 0 to only use the other; both 0 flushes every event.
+func WithStreamBatching(interval time.Duration, maxEvents int) ServerOption {
+	return func(s *A2AServer) {
+		s.streamBatchInterval = interval
+		s.streamBatchEvents = maxEvents
+	}
+}
+
+// eventWriter writes the events of an SSE stream, applying the write deadline and
+// batching flushes
+type eventWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	timeout    time.Duration
+
+	interval  time.Duration
+	maxEvents int
+	pending   int
+	timer     *time.Timer
+}
+
+func (s *A2AServer) newEventWriter(w http.ResponseWriter) *eventWriter {
+	return &eventWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		timeout:    s.streamWriteTimeout,
+		interval:   s.streamBatchInterval,
+		maxEvents:  s.streamBatchEvents,
+	}
+}
+
+// batching reports whether flushes are batched
+func (e *eventWriter) batching() bool {
+	return e.interval > 0 || e.maxEvents > 1
+}
+
+// write writes an event with an optional SSE id, flushing it now or with its batch
+func (e *eventWriter) write(id string, data []byte) error {
+	err := e.withDeadline(func() error {
+		if id != "" {
+			if _, err := fmt.Fprintf(e.w, "id: %s\n", id); err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(e.w, "data: %s\n\n", data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	e.pending++
+	if !e.batching() || (e.maxEvents > 0 && e.pending >= e.maxEvents) {
+		return e.flush()
+	}
+	if e.pending == 1 && e.interval > 0 {
+		if e.timer == nil {
+			e.timer = time.NewTimer(e.interval)
+		} else {
+			e.timer.Reset(e.interval)
+		}
+	}
+	return nil
+}
+
+// flush sends the pending events to the client
+func (e *eventWriter) flush() error {
+	if e.pending == 0 {
+		return nil
+	}
+	e.pending = 0
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	// Unlike Flusher, the controller reports write errors
+	return e.withDeadline(e.controller.Flush)
+}
+
+// due fires when the pending batch must be flushed; it is nil while nothing is pending
+func (e *eventWriter) due() <-chan time.Time {
+	if e.pending == 0 || e.timer == nil {
+		return nil
+	}
+	return e.timer.C
+}
+
+// stop releases the batch timer
+func (e *eventWriter) stop() {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
+
+// withDeadline runs a write under the write deadline. The deadline is cleared afterwards,
+// so it can't expire while the stream is idle and fail later writes, such as the shutdown
+// event or the end of the response written by net/http.
+func (e *eventWriter) withDeadline(write func() error) error {
+	if e.timeout <= 0 {
+		return write()
+	}
+	// Deadlines are best effort: writers without a connection don't support them
+	_ = e.controller.SetWriteDeadline(time.Now().Add(e.timeout))
+	err := write()
+	if err == nil {
+		_ = e.controller.SetWriteDeadline(time.Time{})
+	}
+	return err
+}
+
+// streamEventID returns the SSE id of a task stream event: its event cursor, which clients
+// send back as Last-Event-ID when resubscribing
+func streamEventID(response *types.SendTaskStreamingResponse) string {
+	var seq int
+	switch event := response.Result.(type) {
+	case *types.TaskStatusUpdateEvent:
+		seq = event.Seq
+	case *types.TaskArtifactUpdateEvent:
+		seq = event.Seq
+	case types.TaskStatusUpdateEvent:
+		seq = event.Seq
+	case types.TaskArtifactUpdateEvent:
+		seq = event.Seq
+	}
+	if seq == 0 {
+		return ""
+	}
+	return strconv.Itoa(seq)
+}
+
+// countWriteError counts a stream ended by a failed write
+func (s *A2AServer) countWriteError(err error) {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		s.streams.writeTimeouts.Add(1)
+	}
+	s.streams.clientDisconnected.Add(1)
+}
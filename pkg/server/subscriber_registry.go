@@ -0,0 +1,115 @@
+package server
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// subscriberShards is the number of independently locked shards of a subscriberRegistry
+const subscriberShards = 64
+
+// subscriberRegistry tracks the SSE subscribers of each task. Entries are sharded by
+// task key so streams of different tasks don't contend on one lock, and each task's
+// subscribers are kept in a set so unsubscribing is O(1).
+type subscriberRegistry struct {
+	seed   maphash.Seed
+	shards [subscriberShards]subscriberShard
+}
+
+type subscriberShard struct {
+	lock  sync.Mutex
+	tasks map[taskKey]map[*sseSubscriber]struct{}
+}
+
+func newSubscriberRegistry() *subscriberRegistry {
+	r := &subscriberRegistry{seed: maphash.MakeSeed()}
+	for i := range r.shards {
+		r.shards[i].tasks = make(map[taskKey]map[*sseSubscriber]struct{})
+	}
+	return r
+}
+
+func (r *subscriberRegistry) shard(key taskKey) *subscriberShard {
+	var h maphash.Hash
+	h.SetSeed(r.seed)
+	h.WriteString(key.tenant)
+	h.WriteByte(0)
+	h.WriteString(key.taskID)
+	return &r.shards[h.Sum64()%subscriberShards]
+}
+
+// add registers subscriber for key
+func (r *subscriberRegistry) add(key taskKey, subscriber *sseSubscriber) {
+	shard := r.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	subscribers := shard.tasks[key]
+	if subscribers == nil {
+		subscribers = make(map[*sseSubscriber]struct{})
+		shard.tasks[key] = subscribers
+	}
+	subscribers[subscriber] = struct{}{}
+}
+
+// remove unregisters subscriber, and stops tracking the task once its last subscriber left
+func (r *subscriberRegistry) remove(key taskKey, subscriber *sseSubscriber) {
+	shard := r.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	subscribers := shard.tasks[key]
+	delete(subscribers, subscriber)
+	if len(subscribers) == 0 {
+		delete(shard.tasks, key)
+	}
+}
+
+// drop stops tracking a task, e.g. once it is evicted; its subscribers get no more events
+func (r *subscriberRegistry) drop(key taskKey) {
+	shard := r.shard(key)
+	shard.lock.Lock()
+	delete(shard.tasks, key)
+	shard.lock.Unlock()
+}
+
+// snapshot returns the current subscribers of key
+func (r *subscriberRegistry) snapshot(key taskKey) []*sseSubscriber {
+	shard := r.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	subscribers := make([]*sseSubscriber, 0, len(shard.tasks[key]))
+	for subscriber := range shard.tasks[key] {
+		subscribers = append(subscribers, subscriber)
+	}
+	return subscribers
+}
+
+// subscriberStats summarizes the subscribers of one tracked task
+type subscriberStats struct {
+	key         taskKey
+	subscribers int
+	oldest      time.Time
+}
+
+// stats returns one entry per tracked task, locking one shard at a time
+func (r *subscriberRegistry) stats() []subscriberStats {
+	var stats []subscriberStats
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.lock.Lock()
+		for key, subscribers := range shard.tasks {
+			s := subscriberStats{key: key, subscribers: len(subscribers)}
+			for sub := range subscribers {
+				if s.oldest.IsZero() || sub.created.Before(s.oldest) {
+					s.oldest = sub.created
+				}
+			}
+			stats = append(stats, s)
+		}
+		shard.lock.Unlock()
+	}
+	return stats
+}
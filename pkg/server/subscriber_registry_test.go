@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSubscriberRegistryRemove(t *testing.T) {
+	r := newSubscriberRegistry()
+	key := taskKey{taskID: "t1"}
+	first, second := &sseSubscriber{}, &sseSubscriber{}
+
+	r.add(key, first)
+	r.add(key, second)
+	r.remove(key, first)
+	if subscribers := r.snapshot(key); len(subscribers) != 1 || subscribers[0] != second {
+		t.Fatalf("subscribers after removing one = %v, want the other", subscribers)
+	}
+
+	r.remove(key, second)
+	if stats := r.stats(); len(stats) != 0 {
+		t.Fatalf("registry still tracks %d tasks after their last subscriber left", len(stats))
+	}
+
+	// A task is tracked again once it is resubscribed to
+	r.add(key, first)
+	if subscribers := r.snapshot(key); len(subscribers) != 1 {
+		t.Fatalf("subscribers after resubscribing = %d, want 1", len(subscribers))
+	}
+}
+
+// BenchmarkFanOut subscribes many concurrent subscribers spread over a few tasks, publishes
+// to every task and unsubscribes them again, the churn of SSE streams the registry sees
+func BenchmarkFanOut(b *testing.B) {
+	for _, tasks := range []int{1, 100} {
+		b.Run(fmt.Sprintf("tasks=%d", tasks), func(b *testing.B) {
+			const subscriberCount, events = 10000, 10
+			keys := make([]taskKey, tasks)
+			for i := range keys {
+				keys[i] = taskKey{taskID: fmt.Sprintf("task-%d", i)}
+			}
+			subscribers := make([]*sseSubscriber, subscriberCount)
+			for i := range subscribers {
+				subscribers[i] = &sseSubscriber{events: make(chan interface{}, events)}
+			}
+
+			concurrently := func(n int, fn func(i int)) {
+				var wg sync.WaitGroup
+				for i := 0; i < n; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						fn(i)
+					}(i)
+				}
+				wg.Wait()
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				r := newSubscriberRegistry()
+				concurrently(subscriberCount, func(i int) {
+					r.add(keys[i%tasks], subscribers[i])
+				})
+				concurrently(tasks, func(i int) {
+					for e := 0; e < events; e++ {
+						for _, subscriber := range r.snapshot(keys[i]) {
+							subscriber.events <- e
+						}
+					}
+				})
+				concurrently(subscriberCount, func(i int) {
+					for len(subscribers[i].events) > 0 {
+						<-subscribers[i].events
+					}
+					r.remove(keys[i%tasks], subscribers[i])
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"a2a-go/pkg/types"
+)
+
+// batchTasks answers tasks/batchGet and tasks/batchCancel by calling OnGetTask or OnCancelTask
+// for every id. Each task is authorized as its own get_task or cancel_task request, and a
+// failure for one id is reported in its result without failing the others.
+func (s *A2AServer) batchTasks(ctx context.Context, request *types.JSONRPCRequest) interface{} {
+	params := request.Params.(*types.TaskBatchParams)
+	batch := &types.TaskBatch{Results: make([]types.TaskBatchResult, 0, len(params.IDs))}
+	for _, id := range params.IDs {
+		task, err := s.batchTask(ctx, request, params, id)
+		result := types.TaskBatchResult{ID: id, Task: task}
+		if err != nil {
+			result.Error = toJSONRPCError(ctx, err)
+		}
+		batch.Results = append(batch.Results, result)
+	}
+
+	if request.Method == types.BatchCancelTasksMethod {
+		return &types.BatchCancelTasksResponse{Result: batch}
+	}
+	return &types.BatchGetTasksResponse{Result: batch}
+}
+
+// batchTask gets or cancels the task id of a batch request
+func (s *A2AServer) batchTask(ctx context.Context, request *types.JSONRPCRequest, params *types.TaskBatchParams, id string) (*types.Task, error) {
+	single := &types.JSONRPCRequest{JSONRPC: request.JSONRPC, ID: request.ID}
+	if request.Method == types.BatchCancelTasksMethod {
+		single.Method = "cancel_task"
+		single.Params = &types.TaskIdParams{ID: id}
+	} else {
+		single.Method = "get_task"
+		single.Params = &types.TaskQueryParams{TaskIdParams: types.TaskIdParams{ID: id}, HistoryLength: params.HistoryLength}
+	}
+	if err := s.authorizeRequest(ctx, single.Method, single.Params, ""); err != nil {
+		return nil, err
+	}
+
+	var task *types.Task
+	var err error
+	if single.Method == "cancel_task" {
+		var response *types.CancelTaskResponse
+		if response, err = s.taskManager.OnCancelTask(ctx, single); response != nil {
+			task = response.Result
+		}
+	} else {
+		var response *types.GetTaskResponse
+		if response, err = s.taskManager.OnGetTask(ctx, single); response != nil {
+			task = response.Result
+		}
+	}
+	if err == nil && task == nil {
+		err = types.InternalError(fmt.Sprintf("%s returned no task", single.Method))
+	}
+	return task, err
+}
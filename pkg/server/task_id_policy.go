@@ -0,0 +1,59 @@
+package server
+
+import (
+	"fmt"
+
+	"a2a-go/pkg/types"
+)
+
+// DuplicateTaskPolicy decides what happens when a client reuses a task id for what looks like a new
+// conversation: the id belongs to a task of another session, or to a task that already finished
+type DuplicateTaskPolicy int
+
+const (
+	// DuplicateContinue appends the message to the existing task (the historic behavior)
+	DuplicateContinue DuplicateTaskPolicy = iota
+	// DuplicateReject fails the request with a DuplicateTaskIDError
+	DuplicateReject
+	// DuplicateFork creates a new task with a generated id; the original id is kept in the
+	// new task's metadata under types.ForkedFromMetadataKey
+	DuplicateFork
+)
+
+// DuplicateTaskIDError is returned when DuplicateReject refuses a reused task id
+type DuplicateTaskIDError struct {
+	TaskID string
+	Reason string
+}
+
+func (e *DuplicateTaskIDError) Error() string {
+	return fmt.Sprintf("task id %s is already in use: %s", e.TaskID, e.Reason)
+}
+
+// JSONRPCError converts the error into its JSON-RPC representation
+func (e *DuplicateTaskIDError) JSONRPCError() *types.JSONRPCError {
+	return &types.JSONRPCError{
+		Code:    UnsupportedOperationErrorCode,
+		Message: e.Error(),
+		Data:    &types.TaskErrorData{TaskID: e.TaskID},
+	}
+}
+
+// WithDuplicateTaskPolicy sets how reused task ids are handled (DuplicateContinue by default)
+func WithDuplicateTaskPolicy(policy DuplicateTaskPolicy) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.duplicatePolicy = policy
+	}
+}
+
+// taskCollision returns why sending to an existing task looks like a new conversation, or "" if it does not.
+// sessionID is the session supplied by the client, empty if none was.
+func taskCollision(task *types.Task, sessionID string) string {
+	if sessionID != "" && task.SessionID != nil && *task.SessionID != sessionID {
+		return fmt.Sprintf("it belongs to session %s", *task.SessionID)
+	}
+	if isTerminalState(task.Status.State) {
+		return fmt.Sprintf("the task is already %s", task.Status.State)
+	}
+	return ""
+}
@@ -1,303 +1,987 @@
 package server
 
 import (
+	"context"
 	"errors"
+	"log"
 	"sync"
 	"time"
 
 	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
 )
 
-// TaskManager defines the interface for task management operations
+// TaskManager defines the interface for task management operations.
+// Methods return a *TaskError (or another error implementing JSONRPCError) to choose the error code sent to the client.
+// The server decodes and validates request.Params into the typed params of the method, e.g.
+// *types.TaskQueryParams for OnGetTask, before calling it.
 type TaskManager interface {
-	OnGetTask(request *types.JSONRPCRequest) *types.GetTaskResponse
-	OnCancelTask(request *types.JSONRPCRequest) *types.CancelTaskResponse
-	OnSendTask(request *types.JSONRPCRequest) *types.SendTaskResponse
-	OnSendTaskSubscribe(request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error)
-	OnSetTaskPushNotification(request *types.JSONRPCRequest) *types.SetTaskPushNotificationResponse
-	OnGetTaskPushNotification(request *types.JSONRPCRequest) *types.GetTaskPushNotificationResponse
-	OnResubscribeToTask(request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error)
+	OnGetTask(ctx context.Context, request *types.JSONRPCRequest) (*types.GetTaskResponse, error)
+	OnCancelTask(ctx context.Context, request *types.JSONRPCRequest) (*types.CancelTaskResponse, error)
+	OnSendTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskResponse, error)
+	OnSendTaskSubscribe(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error)
+	OnSetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (*types.SetTaskPushNotificationResponse, error)
+	OnGetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (*types.GetTaskPushNotificationResponse, error)
+	OnResubscribeToTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error)
+	OnPauseTask(ctx context.Context, request *types.JSONRPCRequest) (*types.PauseTaskResponse, error)
+	OnResumeTask(ctx context.Context, request *types.JSONRPCRequest) (*types.ResumeTaskResponse, error)
+	OnTransferTask(ctx context.Context, request *types.JSONRPCRequest) (*types.TransferTaskResponse, error)
+}
+
+// TaskStreamer is implemented by task managers streaming the events of send_task_streaming
+// requests as they happen; it is used instead of OnSendTaskSubscribe
+type TaskStreamer interface {
+	OnSendTaskStream(ctx context.Context, request *types.JSONRPCRequest) (chan *types.SendTaskStreamingResponse, error)
+}
+
+// ResumeFunc continues a suspended task's handler from its last checkpoint
+type ResumeFunc func(ctx context.Context, task *types.Task) error
+
+// tenantStore holds the tasks and sessions of a single tenant
+type tenantStore struct {
+	tasks               map[string]*types.Task
+	sessions            map[string][]string
+	acceptedOutputModes map[string][]string
+	artifactIndices     map[string]int // Next artifact index to hand out per task
+	metadataIndex       metadataIndex
+	eventSeqs           map[string]int // Event cursor of the last event per task
+	closedSessions      map[string]*types.ClosedSession
+	sessionEvictions    map[string]time.Time // When closed sessions are evicted, see WithSessionRetention
+	owners              map[string]string    // Subject of the principal that created each task, "" if anonymous
+}
+
+// newTenantStore creates an empty tenantStore
+func newTenantStore() *tenantStore {
+	return &tenantStore{
+		tasks:               make(map[string]*types.Task),
+		sessions:            make(map[string][]string),
+		acceptedOutputModes: make(map[string][]string),
+		artifactIndices:     make(map[string]int),
+		metadataIndex:       newMetadataIndex(),
+		eventSeqs:           make(map[string]int),
+		closedSessions:      make(map[string]*types.ClosedSession),
+		sessionEvictions:    make(map[string]time.Time),
+		owners:              make(map[string]string),
+	}
 }
 
 // InMemoryTaskManager implements TaskManager with in-memory storage
 type InMemoryTaskManager struct {
-	tasks                  map[string]*types.Task
-	pushNotificationInfos  map[string]*types.PushNotificationConfig
-	lock                  sync.Mutex
-	taskSSESubscribers    map[string][]chan interface{}
-	subscriberLock        sync.Mutex
+	tenants            map[string]*tenantStore
+	lock               sync.Mutex
+	taskSSESubscribers *subscriberRegistry
+	subscriberLock     sync.Mutex // Guards eventSubscriptions
+	resumeFuncs        map[taskKey]ResumeFunc
+	cancelCallbacks    map[taskKey][]*cancelRegistration
+	inputWaiters       map[taskKey]chan types.Message
+	duplicatePolicy    DuplicateTaskPolicy
+	defaultHistory     int
+	defaultHistorySet  bool
+	agentCard          *types.AgentCard // The card served, for the metadata schemas of its skills
+	eventSubscriptions map[*eventSubscription]struct{}
+	eventLogs          map[taskKey][]RecordedEvent
+	recordScope        RecordScope
+	recordLimit        int // Recorded events kept per task
+	auditSink          AuditSink
+	auditScope         RecordScope
+
+	historyCompactor HistoryCompactor
+	historyThreshold int
+	compacting       map[taskKey]bool // Tasks whose history is being compacted
+	compactTimeout   time.Duration
+
+	contentConverters   map[string]map[string]ContentConverter
+	idGenerator         utils.IDGenerator
+	clock               utils.Clock
+	pushConfigs         PushConfigStore
+	pushDeliverer       *PushDeliverer
+	pushURLPolicy       *utils.URLPolicy
+	fileResolvers       *utils.FileResolvers
+	indexedMetadataKeys map[string]bool
+	eventStore          TaskEventStore
+	taskStore           TaskStore
+	eventBus            EventBus
+	replicaID           string // Origin of the events published on eventBus
+	intern              bool
+
+	pollBuffers map[taskKey]*pollBuffer
+
+	workerPool *WorkerPool // Runs the executions of a DefaultRequestHandler
+
+	scanner    ArtifactScanner
+	scanPolicy ScanPolicy
+
+	scratchRoot   string
+	scratchQuota  int64
+	scratchLock   sync.Mutex // Guards scratchSpaces
+	scratchSpaces map[taskKey]*ScratchSpace
+
+	sessionRetention    time.Duration
+	sessionRetentionSet bool
+	sessionCloseHook    SessionCloseHook
+	nextSessionEviction time.Time // Earliest pending eviction of a closed session, guarded by lock
+
+	heartbeatInterval time.Duration
+	heartbeatLock     sync.Mutex // Guards heartbeatTimers and progressFuncs
+	heartbeatTimers   map[taskKey]utils.Timer
+	progressFuncs     map[taskKey]ProgressFunc
+	pollBufferSize    int
+}
+
+// TaskManagerOption configures optional InMemoryTaskManager behavior
+type TaskManagerOption func(*InMemoryTaskManager)
+
+// WithHistoryCompactor sets the compactor run in the background once a task's history exceeds
+// threshold messages; each run is canceled after a minute
+func WithHistoryCompactor(threshold int, compactor HistoryCompactor) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.historyThreshold = threshold
+		tm.historyCompactor = compactor
+		tm.compacting = make(map[taskKey]bool)
+		tm.compactTimeout = defaultCompactionTimeout
+	}
+}
+
+// WithIDGenerator sets the generator used for task and session ids missing from requests
+func WithIDGenerator(generator utils.IDGenerator) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.idGenerator = generator
+	}
+}
+
+// WithClock sets the clock used for task timestamps and heartbeats; tests can pass a utils.FakeClock
+func WithClock(clock utils.Clock) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.clock = clock
+	}
 }
 
 // NewInMemoryTaskManager creates a new instance of InMemoryTaskManager
-func NewInMemoryTaskManager() *InMemoryTaskManager {
-	return &InMemoryTaskManager{
-		tasks:                 make(map[string]*types.Task),
-		pushNotificationInfos: make(map[string]*types.PushNotificationConfig),
-		taskSSESubscribers:    make(map[string][]chan interface{}),
+func NewInMemoryTaskManager(opts ...TaskManagerOption) *InMemoryTaskManager {
+	tm := &InMemoryTaskManager{
+		tenants:            make(map[string]*tenantStore),
+		taskSSESubscribers: newSubscriberRegistry(),
+		resumeFuncs:        make(map[taskKey]ResumeFunc),
+		idGenerator:        utils.DefaultIDGenerator(),
+		clock:              utils.DefaultClock(),
+		pushConfigs:        NewInMemoryPushConfigStore(),
+		pushURLPolicy:      utils.DefaultURLPolicy(),
+		fileResolvers:      utils.NewFileResolvers(utils.WithFileHTTPClient(utils.DefaultURLPolicy().HTTPClient(0))),
+	}
+	for _, opt := range opts {
+		opt(tm)
+	}
+	if tm.eventBus != nil {
+		tm.replicaID = tm.idGenerator.NewID()
+	}
+	if aware, ok := tm.taskStore.(clockAware); ok {
+		aware.applyClock(tm.clock)
+	}
+	return tm
+}
+
+// store returns the tenantStore for the tenant in ctx, creating it if needed.
+// The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) store(ctx context.Context) *tenantStore {
+	tenant := TenantFromContext(ctx)
+	store := tm.tenants[tenant]
+	if store == nil {
+		store = newTenantStore()
+		tm.tenants[tenant] = store
+	}
+	return store
+}
+
+// ListSessionTasks returns the tasks of a session belonging to the tenant in ctx
+func (tm *InMemoryTaskManager) ListSessionTasks(ctx context.Context, sessionID string) []*types.Task {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	tasks := make([]*types.Task, 0, len(store.sessions[sessionID]))
+	for _, taskID := range store.sessions[sessionID] {
+		if task := store.tasks[taskID]; task != nil {
+			tasks = append(tasks, task)
+		}
 	}
+	return tasks
 }
 
 // OnGetTask handles task retrieval requests
-func (tm *InMemoryTaskManager) OnGetTask(request *types.JSONRPCRequest) *types.GetTaskResponse {
+func (tm *InMemoryTaskManager) OnGetTask(ctx context.Context, request *types.JSONRPCRequest) (*types.GetTaskResponse, error) {
 	taskQueryParams := request.Params.(*types.TaskQueryParams)
 
 	tm.lock.Lock()
-	task := tm.tasks[taskQueryParams.ID]
+	task := tm.store(ctx).tasks[taskQueryParams.ID]
+	notModified := task != nil && taskQueryParams.SinceVersion != nil && task.Version <= *taskQueryParams.SinceVersion
 	tm.lock.Unlock()
 
 	if task == nil {
+		return nil, taskNotFound(taskQueryParams.ID)
+	}
+
+	if notModified {
 		return &types.GetTaskResponse{
-			Result: nil,
-		}
+			NotModified: true,
+		}, nil
 	}
 
 	taskResult := tm.appendTaskHistory(task, taskQueryParams.HistoryLength)
-	return &types.GetTaskResponse{
+	response := &types.GetTaskResponse{
 		Result: taskResult,
 	}
+	if taskQueryParams.IncludeEvents {
+		response.Events = tm.streamEventRecords(ctx, taskQueryParams.ID)
+	}
+	return response, nil
 }
 
 // OnCancelTask handles task cancellation requests
-func (tm *InMemoryTaskManager) OnCancelTask(request *types.JSONRPCRequest) *types.CancelTaskResponse {
+func (tm *InMemoryTaskManager) OnCancelTask(ctx context.Context, request *types.JSONRPCRequest) (*types.CancelTaskResponse, error) {
 	taskIDParams := request.Params.(*types.TaskIdParams)
 
-	tm.lock.Lock()
-	task := tm.tasks[taskIDParams.ID]
-	tm.lock.Unlock()
-
-	if task == nil {
-		return &types.CancelTaskResponse{
-			Result: nil,
-		}
+	task, err := tm.cancelTask(ctx, taskIDParams.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	return &types.CancelTaskResponse{
-		Result: nil,
-	}
+		Result: task,
+	}, nil
 }
 
 // OnSendTask handles task submission requests
-func (tm *InMemoryTaskManager) OnSendTask(request *types.JSONRPCRequest) *types.SendTaskResponse {
-	// To be implemented by concrete implementation
-	return nil
+func (tm *InMemoryTaskManager) OnSendTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskResponse, error) {
+	// Implemented by DefaultRequestHandler, or by types embedding the manager
+	return nil, ErrUnsupportedOperation
 }
 
 // OnSendTaskSubscribe handles task subscription requests
-func (tm *InMemoryTaskManager) OnSendTaskSubscribe(request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
-	// To be implemented by concrete implementation
-	return nil, errors.New("not implemented")
+func (tm *InMemoryTaskManager) OnSendTaskSubscribe(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
+	// Implemented by DefaultRequestHandler, or by types embedding the manager
+	return nil, ErrUnsupportedOperation
 }
 
-// setPushNotificationInfo sets push notification configuration for a task
-func (tm *InMemoryTaskManager) setPushNotificationInfo(taskID string, notificationConfig *types.PushNotificationConfig) error {
+// SetResumeCheckpoint registers the function that continues a task's handler after
+// it has been paused. Handlers call it when they reach a point they can resume from.
+func (tm *InMemoryTaskManager) SetResumeCheckpoint(ctx context.Context, taskID string, resume ResumeFunc) {
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
+	tm.resumeFuncs[subscriberKey(ctx, taskID)] = resume
+}
 
-	task := tm.tasks[taskID]
+// setResumeCheckpointIfPaused registers resume like SetResumeCheckpoint if the task is
+// suspended and, unless replace is set, has no checkpoint yet. It returns the task's state.
+func (tm *InMemoryTaskManager) setResumeCheckpointIfPaused(ctx context.Context, taskID string, resume ResumeFunc, replace bool) types.TaskState {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	task := tm.store(ctx).tasks[taskID]
 	if task == nil {
-		return errors.New("task not found")
+		return types.TaskUnknown
 	}
+	key := subscriberKey(ctx, taskID)
+	if task.Status.State == types.TaskSuspended && (replace || tm.resumeFuncs[key] == nil) {
+		tm.resumeFuncs[key] = resume
+	}
+	return task.Status.State
+}
 
-	tm.pushNotificationInfos[taskID] = notificationConfig
-	return nil
+// AwaitResume blocks while a task is suspended, until it is resumed or ctx is done. It
+// returns right away for tasks that aren't suspended.
+func (tm *InMemoryTaskManager) AwaitResume(ctx context.Context, taskID string) error {
+	resumed := make(chan struct{})
+	state := tm.setResumeCheckpointIfPaused(ctx, taskID, func(ctx context.Context, task *types.Task) error {
+		close(resumed)
+		return nil
+	}, true)
+	switch state {
+	case types.TaskUnknown:
+		return taskNotFound(taskID)
+	case types.TaskSuspended:
+	default:
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		tm.lock.Lock()
+		delete(tm.resumeFuncs, subscriberKey(ctx, taskID))
+		tm.lock.Unlock()
+		return ctx.Err()
+	}
 }
 
-// getPushNotificationInfo retrieves push notification configuration for a task
-func (tm *InMemoryTaskManager) getPushNotificationInfo(taskID string) (*types.PushNotificationConfig, error) {
+// IsPaused reports whether a task has been suspended, so handlers know to stop at their next checkpoint
+func (tm *InMemoryTaskManager) IsPaused(ctx context.Context, taskID string) bool {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	task := tm.store(ctx).tasks[taskID]
+	return task != nil && task.Status.State == types.TaskSuspended
+}
+
+// OnPauseTask handles requests to suspend a working task
+func (tm *InMemoryTaskManager) OnPauseTask(ctx context.Context, request *types.JSONRPCRequest) (*types.PauseTaskResponse, error) {
+	taskIDParams := request.Params.(*types.TaskIdParams)
+
+	task, err := tm.transitionTask(ctx, taskIDParams.ID, types.TaskWorking, types.TaskSuspended)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.PauseTaskResponse{
+		Result: task,
+	}, nil
+}
+
+// OnResumeTask handles requests to continue a suspended task
+func (tm *InMemoryTaskManager) OnResumeTask(ctx context.Context, request *types.JSONRPCRequest) (*types.ResumeTaskResponse, error) {
+	taskIDParams := request.Params.(*types.TaskIdParams)
+
+	task, err := tm.transitionTask(ctx, taskIDParams.ID, types.TaskSuspended, types.TaskWorking)
+	if err != nil {
+		return nil, err
+	}
+
+	key := subscriberKey(ctx, taskIDParams.ID)
+	tm.lock.Lock()
+	resume := tm.resumeFuncs[key]
+	delete(tm.resumeFuncs, key)
+	tm.lock.Unlock()
+
+	if resume != nil {
+		resumeCtx := context.WithoutCancel(ctx)
+		go func() {
+			if err := resume(resumeCtx, task); err != nil {
+				log.Printf("Failed to resume task %s: %v", task.ID, err)
+			}
+		}()
+	}
+
+	return &types.ResumeTaskResponse{
+		Result: task,
+	}, nil
+}
+
+// OnTransferTask handles requests to move a task to a different session
+func (tm *InMemoryTaskManager) OnTransferTask(ctx context.Context, request *types.JSONRPCRequest) (*types.TransferTaskResponse, error) {
+	transferParams := request.Params.(*types.TaskTransferParams)
+
+	task, err := tm.transferTask(ctx, transferParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TransferTaskResponse{
+		Result: task,
+	}, nil
+}
+
+// transferTask reassigns a task to another session and records the move in its metadata
+func (tm *InMemoryTaskManager) transferTask(ctx context.Context, params *types.TaskTransferParams) (*types.Task, error) {
+	if params.SessionID == "" {
+		return nil, invalidParams("target session id is required")
+	}
+
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
-	task := tm.tasks[taskID]
+	store := tm.store(ctx)
+	task := store.tasks[params.ID]
 	if task == nil {
-		return nil, errors.New("task not found")
+		return nil, taskNotFound(params.ID)
 	}
 
-	return tm.pushNotificationInfos[taskID], nil
+	fromSessionID := ""
+	if task.SessionID != nil {
+		fromSessionID = *task.SessionID
+	}
+	if fromSessionID == params.SessionID {
+		snapshot := *task
+		return &snapshot, nil
+	}
+	if err := sessionClosedError(store, fromSessionID, params.SessionID); err != nil {
+		return nil, err
+	}
+
+	transfer := types.TaskTransferRecord{
+		FromSessionID: fromSessionID,
+		ToSessionID:   params.SessionID,
+		Reason:        params.Reason,
+		Timestamp:     tm.clock.Now().Format(time.RFC3339),
+		Metadata:      copyMetadata(params.Metadata),
+	}
+	if err := tm.journal(ctx, TaskLogEntry{
+		TaskID:    params.ID,
+		Version:   task.Version + 1,
+		Kind:      TaskLogTransfer,
+		SessionID: params.SessionID,
+		Transfer:  &transfer,
+	}); err != nil {
+		return nil, err
+	}
+
+	removeSessionTask(store, fromSessionID, params.ID)
+	store.sessions[params.SessionID] = append(store.sessions[params.SessionID], params.ID)
+
+	sessionID := params.SessionID
+	task.SessionID = &sessionID
+	task.Version++
+	task.Transfers = append(task.Transfers[:len(task.Transfers):len(task.Transfers)], transfer)
+
+	snapshot := *task
+	return &snapshot, nil
 }
 
-// hasPushNotificationInfo checks if a task has push notification configuration
-func (tm *InMemoryTaskManager) hasPushNotificationInfo(taskID string) bool {
+// removeSessionTask removes a task from the tasks of a session. The caller must hold tm.lock.
+func removeSessionTask(store *tenantStore, sessionID, taskID string) {
+	sessionTasks := store.sessions[sessionID]
+	for i, id := range sessionTasks {
+		if id == taskID {
+			store.sessions[sessionID] = append(sessionTasks[:i], sessionTasks[i+1:]...)
+			break
+		}
+	}
+	if len(store.sessions[sessionID]) == 0 {
+		delete(store.sessions, sessionID)
+	}
+}
+
+// transitionTask moves a task from one state to another and notifies SSE subscribers
+func (tm *InMemoryTaskManager) transitionTask(ctx context.Context, taskID string, from, to types.TaskState) (*types.Task, error) {
+	tm.lock.Lock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		tm.lock.Unlock()
+		return nil, taskNotFound(taskID)
+	}
+	if task.Status.State != from {
+		tm.lock.Unlock()
+		return nil, invalidTaskState(taskID, task.Status.State, "Task is %s, expected %s", task.Status.State, from)
+	}
+	status := types.TaskStatus{
+		State:     to,
+		Timestamp: tm.clock.Now().Format(time.RFC3339),
+	}
+	if err := tm.journal(ctx, TaskLogEntry{TaskID: taskID, Version: task.Version + 1, Kind: TaskLogStatus, Status: &status}); err != nil {
+		tm.lock.Unlock()
+		return nil, err
+	}
+	task.Status = status
+	task.Version++
+	snapshot := *task
+	tm.lock.Unlock()
+
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: snapshot.Status,
+	})
+	return &snapshot, nil
+}
+
+// taskExists reports whether the tenant in ctx has a task with taskID
+func (tm *InMemoryTaskManager) taskExists(ctx context.Context, taskID string) bool {
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
-	_, exists := tm.pushNotificationInfos[taskID]
-	return exists
+	return tm.store(ctx).tasks[taskID] != nil
+}
+
+// setPushNotificationInfo sets push notification configuration for a task
+func (tm *InMemoryTaskManager) setPushNotificationInfo(ctx context.Context, taskID string, notificationConfig *types.PushNotificationConfig) error {
+	if err := tm.checkPushNotifications(); err != nil {
+		return err
+	}
+	if !tm.taskExists(ctx, taskID) {
+		return taskNotFound(taskID)
+	}
+	if tm.pushURLPolicy != nil {
+		if err := tm.pushURLPolicy.CheckResolved(ctx, notificationConfig.URL); err != nil {
+			return invalidParams("push notification url: %v", err)
+		}
+	}
+	return tm.pushConfigs.SetPushConfig(ctx, taskID, notificationConfig)
+}
+
+// getPushNotificationInfo retrieves push notification configuration for a task
+func (tm *InMemoryTaskManager) getPushNotificationInfo(ctx context.Context, taskID string) (*types.PushNotificationConfig, error) {
+	if err := tm.checkPushNotifications(); err != nil {
+		return nil, err
+	}
+	if !tm.taskExists(ctx, taskID) {
+		return nil, taskNotFound(taskID)
+	}
+	return tm.pushConfigs.GetPushConfig(ctx, taskID)
+}
+
+// checkPushNotifications rejects push notification requests when the agent card served
+// doesn't declare the pushNotifications capability
+func (tm *InMemoryTaskManager) checkPushNotifications() error {
+	tm.lock.Lock()
+	card := tm.agentCard
+	tm.lock.Unlock()
+	if card != nil && !card.Capabilities.PushNotifications {
+		return ErrPushNotificationNotSupported
+	}
+	return nil
+}
+
+// hasPushNotificationInfo checks if a task has push notification configuration
+func (tm *InMemoryTaskManager) hasPushNotificationInfo(ctx context.Context, taskID string) bool {
+	config, err := tm.pushConfigs.GetPushConfig(ctx, taskID)
+	if err != nil {
+		log.Printf("Failed to load push notification config of task %s: %v", taskID, err)
+		return false
+	}
+	return config != nil
 }
 
 // OnSetTaskPushNotification handles setting push notification configuration
-func (tm *InMemoryTaskManager) OnSetTaskPushNotification(request *types.JSONRPCRequest) *types.SetTaskPushNotificationResponse {
+func (tm *InMemoryTaskManager) OnSetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (*types.SetTaskPushNotificationResponse, error) {
 	taskNotificationParams := request.Params.(*types.TaskPushNotificationConfig)
 
-	err := tm.setPushNotificationInfo(taskNotificationParams.ID, &taskNotificationParams.PushNotificationConfig)
+	err := tm.setPushNotificationInfo(ctx, taskNotificationParams.ID, &taskNotificationParams.PushNotificationConfig)
 	if err != nil {
-		return &types.SetTaskPushNotificationResponse{
-			Result: nil,
-		}
+		return nil, err
 	}
 
 	return &types.SetTaskPushNotificationResponse{
 		Result: taskNotificationParams,
-	}
+	}, nil
 }
 
 // OnGetTaskPushNotification handles retrieving push notification configuration
-func (tm *InMemoryTaskManager) OnGetTaskPushNotification(request *types.JSONRPCRequest) *types.GetTaskPushNotificationResponse {
+func (tm *InMemoryTaskManager) OnGetTaskPushNotification(ctx context.Context, request *types.JSONRPCRequest) (*types.GetTaskPushNotificationResponse, error) {
 	taskParams := request.Params.(*types.TaskIdParams)
 
-	notificationInfo, err := tm.getPushNotificationInfo(taskParams.ID)
+	notificationInfo, err := tm.getPushNotificationInfo(ctx, taskParams.ID)
 	if err != nil {
-		return &types.GetTaskPushNotificationResponse{
-			Result: nil,
+		return nil, err
+	}
+	if notificationInfo == nil {
+		return nil, &TaskError{
+			Code:    TaskNotFoundErrorCode,
+			Message: "Task has no push notification config",
+			Data:    &types.TaskErrorData{TaskID: taskParams.ID},
 		}
 	}
 
 	return &types.GetTaskPushNotificationResponse{
 		Result: &types.TaskPushNotificationConfig{
-			ID:                    taskParams.ID,
+			ID:                     taskParams.ID,
 			PushNotificationConfig: *notificationInfo,
 		},
+	}, nil
+}
+
+// reservedMetadataKeys are the task metadata keys the agent sets. Values clients send under
+// them are dropped, so clients can't forge them.
+var reservedMetadataKeys = []string{
+	types.ForkedFromMetadataKey,
+	types.ProgressMetadataKey,
+	types.ProgressNoteMetadataKey,
+	types.HeartbeatMetadataKey,
+}
+
+// clientMetadata returns client metadata without the reserved keys, copying it if needed
+func clientMetadata(metadata map[string]interface{}) map[string]interface{} {
+	for _, key := range reservedMetadataKeys {
+		if _, ok := metadata[key]; ok {
+			metadata = copyMetadata(metadata)
+			for _, key := range reservedMetadataKeys {
+				delete(metadata, key)
+			}
+			return metadata
+		}
 	}
+	return metadata
 }
 
-// upsertTask creates or updates a task
-func (tm *InMemoryTaskManager) upsertTask(taskSendParams *types.TaskSendParams) *types.Task {
+// upsertTask creates or updates a task. Reused task ids are handled according to the
+// DuplicateTaskPolicy; when a task is forked, taskSendParams.ID is set to the new id.
+func (tm *InMemoryTaskManager) upsertTask(ctx context.Context, taskSendParams *types.TaskSendParams) (*types.Task, error) {
+	if taskSendParams.ID == "" {
+		taskSendParams.ID = tm.idGenerator.NewID()
+	}
+	requestedSession := taskSendParams.SessionID
+	if taskSendParams.SessionID == "" {
+		taskSendParams.SessionID = tm.idGenerator.NewID()
+	}
+	if err := tm.scanMessage(ctx, taskSendParams.ID, &taskSendParams.Message); err != nil {
+		return nil, err
+	}
+	tm.evictClosedSessions(ctx)
+
+	defer func() { tm.compactHistory(ctx, taskSendParams.ID) }()
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
-	task := tm.tasks[taskSendParams.ID]
+	store := tm.store(ctx)
+	task := store.tasks[taskSendParams.ID]
+	if task != nil && task.SessionID != nil {
+		if err := sessionClosedError(store, *task.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	if err := sessionClosedError(store, taskSendParams.SessionID); err != nil {
+		return nil, err
+	}
+	var forkedFrom string
+	if task != nil && tm.duplicatePolicy != DuplicateContinue {
+		if reason := taskCollision(task, requestedSession); reason != "" {
+			if tm.duplicatePolicy == DuplicateReject {
+				return nil, &DuplicateTaskIDError{TaskID: taskSendParams.ID, Reason: reason}
+			}
+			forkedFrom = taskSendParams.ID
+			taskSendParams.ID = tm.idGenerator.NewID()
+			task = nil
+		}
+	}
+
 	if task == nil {
+		taskSendParams.Metadata = clientMetadata(taskSendParams.Metadata)
+		if err := tm.validateMetadata(taskSendParams); err != nil {
+			return nil, err
+		}
 		task = &types.Task{
-			ID:       taskSendParams.ID,
+			ID:        taskSendParams.ID,
 			SessionID: &taskSendParams.SessionID,
 			Status: types.TaskStatus{
 				State:     types.TaskSubmitted,
-				Timestamp: time.Now().Format(time.RFC3339),
+				Timestamp: tm.clock.Now().Format(time.RFC3339),
 			},
-			History: []types.Message{taskSendParams.Message},
+			History: []types.Message{tm.internMessage(taskSendParams.Message)},
+			Version: 1,
 		}
-		tm.tasks[taskSendParams.ID] = task
+		if len(taskSendParams.Metadata) > 0 {
+			task.Metadata = make(map[string]interface{}, len(taskSendParams.Metadata))
+			for k, v := range taskSendParams.Metadata {
+				task.Metadata[k] = v
+			}
+		}
+		if forkedFrom != "" {
+			if task.Metadata == nil {
+				task.Metadata = make(map[string]interface{})
+			}
+			task.Metadata[types.ForkedFromMetadataKey] = forkedFrom
+		}
+		if err := tm.journal(ctx, TaskLogEntry{
+			TaskID:              task.ID,
+			Version:             task.Version,
+			Kind:                TaskLogCreated,
+			Task:                task,
+			AcceptedOutputModes: taskSendParams.AcceptedOutputModes,
+		}); err != nil {
+			return nil, err
+		}
+		store.tasks[taskSendParams.ID] = task
+		if principal := PrincipalFromContext(ctx); principal != nil {
+			store.owners[taskSendParams.ID] = principal.Subject
+		}
+		store.sessions[taskSendParams.SessionID] = append(store.sessions[taskSendParams.SessionID], taskSendParams.ID)
+		tm.indexMetadata(store, task)
 	} else {
-		task.History = append(task.History, taskSendParams.Message)
+		message := tm.internMessage(taskSendParams.Message)
+		if err := tm.journal(ctx, TaskLogEntry{
+			TaskID:              task.ID,
+			Version:             task.Version + 1,
+			Kind:                TaskLogMessage,
+			Message:             &message,
+			AcceptedOutputModes: taskSendParams.AcceptedOutputModes,
+		}); err != nil {
+			return nil, err
+		}
+		task.History = append(task.History, message)
+		task.Version++
+	}
+	if taskSendParams.AcceptedOutputModes != nil {
+		store.acceptedOutputModes[taskSendParams.ID] = taskSendParams.AcceptedOutputModes
 	}
 
-	return task
+	return task, nil
 }
 
 // OnResubscribeToTask handles task resubscription requests
-func (tm *InMemoryTaskManager) OnResubscribeToTask(request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
-	return nil, errors.New("not implemented")
+func (tm *InMemoryTaskManager) OnResubscribeToTask(ctx context.Context, request *types.JSONRPCRequest) (*types.SendTaskStreamingResponse, error) {
+	return nil, ErrUnsupportedOperation
 }
 
 // updateStore updates task status and artifacts
-func (tm *InMemoryTaskManager) updateStore(taskID string, status types.TaskStatus, artifacts []types.Artifact) (*types.Task, error) {
+func (tm *InMemoryTaskManager) updateStore(ctx context.Context, taskID string, status types.TaskStatus, artifacts []types.Artifact) (*types.Task, error) {
+	defer tm.compactHistory(ctx, taskID)
+	var scratch *ScratchSpace
+	defer func() { scratch.remove() }()
+	artifacts, err := tm.scanArtifacts(ctx, taskID, artifacts)
+	if err != nil {
+		status, artifacts = tm.rejectedStatus(err), nil
+	}
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
-	task := tm.tasks[taskID]
+	store := tm.store(ctx)
+	task := store.tasks[taskID]
 	if task == nil {
-		return nil, errors.New("task not found")
+		return nil, taskNotFound(taskID)
+	}
+
+	artifacts, err = tm.negotiateOutput(store.acceptedOutputModes[taskID], &status, artifacts)
+	if err != nil {
+		var unsupported *ContentTypeNotSupportedError
+		if errors.As(err, &unsupported) {
+			unsupported.TaskID = taskID
+		}
+		return nil, err
+	}
+	var merged []types.Artifact
+	if artifacts != nil {
+		tm.internArtifacts(artifacts)
+		if merged, err = mergeArtifacts(taskID, task.Artifacts, artifacts); err != nil {
+			return nil, err
+		}
 	}
 
+	if err := tm.journal(ctx, TaskLogEntry{
+		TaskID:    taskID,
+		Version:   task.Version + 1,
+		Kind:      TaskLogStatus,
+		Status:    &status,
+		Artifacts: artifacts,
+	}); err != nil {
+		return nil, err
+	}
 	task.Status = status
+	task.Version++
+	if isTerminalState(status.State) {
+		scratch = tm.finishTask(ctx, taskID)
+	}
 
 	if status.Message != nil {
-		task.History = append(task.History, *status.Message)
+		task.History = append(task.History, tm.internMessage(*status.Message))
 	}
 
 	if artifacts != nil {
-		if task.Artifacts == nil {
-			task.Artifacts = []types.Artifact{}
-		}
-		task.Artifacts = append(task.Artifacts, artifacts...)
+		task.Artifacts = merged
 	}
 
 	return task, nil
 }
 
-// appendTaskHistory limits task history to specified length
+// addArtifact stores a complete artifact at the next index and sends it to subscribers
+func (tm *InMemoryTaskManager) addArtifact(ctx context.Context, taskID string, artifact types.Artifact) error {
+	index, err := tm.NextArtifactIndex(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	artifact.Index = index
+	artifact.Append = nil
+	artifact.LastChunk = nil
+	task, err := tm.updateStore(ctx, taskID, tm.taskStatus(ctx, taskID), []types.Artifact{artifact})
+	if err != nil {
+		return err
+	}
+	for _, stored := range task.Artifacts {
+		if stored.Index == index {
+			tm.enqueueEventsForSSE(ctx, taskID, &types.TaskArtifactUpdateEvent{ID: taskID, Artifact: stored})
+		}
+	}
+	return nil
+}
+
+// setStatus moves a task that isn't finished yet to state and sends the status to subscribers
+func (tm *InMemoryTaskManager) setStatus(ctx context.Context, taskID string, state types.TaskState, message string, final bool) {
+	if isTerminalState(tm.taskStatus(ctx, taskID).State) {
+		return
+	}
+	var statusMessage *types.Message
+	if message != "" {
+		statusMessage = &types.Message{
+			Role:  "agent",
+			Parts: []types.Part{types.TextPart{Type: "text", Text: message}},
+		}
+	}
+	if err := tm.publishStatus(ctx, taskID, state, statusMessage, final); err != nil {
+		log.Printf("Failed to update status of task %s: %v", taskID, err)
+	}
+}
+
+// publishStatus moves a task that isn't finished yet to state with an optional message and
+// sends the status to subscribers. A suspended task only fails or is canceled until resumed.
+func (tm *InMemoryTaskManager) publishStatus(ctx context.Context, taskID string, state types.TaskState, message *types.Message, final bool) error {
+	current := tm.taskStatus(ctx, taskID).State
+	if isTerminalState(current) {
+		return invalidTaskState(taskID, current, "Task is %s and cannot change state", current)
+	}
+	if current == types.TaskSuspended && state != types.TaskFailed && state != types.TaskCanceled {
+		return invalidTaskState(taskID, current, "Task is %s and cannot change state until resumed", current)
+	}
+	status := types.TaskStatus{
+		State:     state,
+		Message:   message,
+		Timestamp: tm.clock.Now().Format(time.RFC3339),
+	}
+	task, err := tm.updateStore(ctx, taskID, status, nil)
+	if err != nil {
+		return err
+	}
+	tm.enqueueEventsForSSE(ctx, taskID, &types.TaskStatusUpdateEvent{
+		ID:     taskID,
+		Status: task.Status,
+		Final:  final,
+	})
+	return nil
+}
+
+// taskStatus returns the current status of a task
+func (tm *InMemoryTaskManager) taskStatus(ctx context.Context, taskID string) types.TaskStatus {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	if task := tm.store(ctx).tasks[taskID]; task != nil {
+		return task.Status
+	}
+	return types.TaskStatus{}
+}
+
+// getTask returns a snapshot of a task
+func (tm *InMemoryTaskManager) getTask(ctx context.Context, taskID string) (*types.Task, error) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+	task := tm.store(ctx).tasks[taskID]
+	if task == nil {
+		return nil, taskNotFound(taskID)
+	}
+	snapshot := *task
+	return &snapshot, nil
+}
+
+// appendTaskHistory limits task history to the requested length, or the default when nil
 func (tm *InMemoryTaskManager) appendTaskHistory(task *types.Task, historyLength *int) *types.Task {
 	newTask := *task
-	if historyLength != nil && *historyLength > 0 {
-		if len(newTask.History) > *historyLength {
-			newTask.History = newTask.History[len(newTask.History)-*historyLength:]
-		}
-	} else {
+	length := tm.historyLength(historyLength)
+	switch {
+	case length < 0:
+		newTask.History = append([]types.Message{}, newTask.History...)
+	case length == 0:
 		newTask.History = []types.Message{}
+	case len(newTask.History) > length:
+		newTask.History = newTask.History[len(newTask.History)-length:]
 	}
 	return &newTask
 }
 
-// setupSSEConsumer sets up SSE consumer for a task
-func (tm *InMemoryTaskManager) setupSSEConsumer(taskID string, isResubscribe bool) (chan interface{}, error) {
-	tm.subscriberLock.Lock()
-	defer tm.subscriberLock.Unlock()
+// sseSubscriber is the event queue of one streaming consumer.
+// done is closed once the consumer has gone away so producers stop sending to it.
+type sseSubscriber struct {
+	events  chan interface{}
+	done    chan struct{}
+	created time.Time
+}
 
-	if _, exists := tm.taskSSESubscribers[taskID]; !exists {
-		if isResubscribe {
-			return nil, errors.New("task not found for resubscription")
+// setupSSEConsumer sets up SSE consumer for a task
+func (tm *InMemoryTaskManager) setupSSEConsumer(ctx context.Context, taskID string, isResubscribe bool) (*sseSubscriber, error) {
+	subscriber := &sseSubscriber{
+		events:  make(chan interface{}),
+		done:    make(chan struct{}),
+		created: tm.clock.Now(),
+	}
+	if isResubscribe {
+		tm.lock.Lock()
+		task := tm.store(ctx).tasks[taskID]
+		tm.lock.Unlock()
+		if task == nil {
+			return nil, taskNotFound(taskID)
 		}
-		tm.taskSSESubscribers[taskID] = []chan interface{}{}
 	}
-
-	sseEventQueue := make(chan interface{})
-	tm.taskSSESubscribers[taskID] = append(tm.taskSSESubscribers[taskID], sseEventQueue)
-	return sseEventQueue, nil
+	tm.taskSSESubscribers.add(subscriberKey(ctx, taskID), subscriber)
+	return subscriber, nil
 }
 
 // enqueueEventsForSSE sends events to SSE subscribers
-func (tm *InMemoryTaskManager) enqueueEventsForSSE(taskID string, taskUpdateEvent interface{}) {
-	tm.subscriberLock.Lock()
-	subscribers, exists := tm.taskSSESubscribers[taskID]
-	tm.subscriberLock.Unlock()
-
-	if !exists {
-		return
+func (tm *InMemoryTaskManager) enqueueEventsForSSE(ctx context.Context, taskID string, taskUpdateEvent interface{}) {
+	subscribers := tm.taskSSESubscribers.snapshot(subscriberKey(ctx, taskID))
+
+	seq := tm.sequenceEvent(ctx, taskID, taskUpdateEvent)
+	tm.recordEvent(ctx, taskID, taskUpdateEvent)
+	tm.publishMultiplexed(ctx, taskID, taskUpdateEvent)
+	tm.publishEvent(ctx, taskID, taskUpdateEvent)
+	tm.trackHeartbeat(ctx, taskID, taskUpdateEvent)
+	if status, isStatus := taskUpdateEvent.(*types.TaskStatusUpdateEvent); isStatus && !status.IsHeartbeat() && tm.pushDeliverer != nil {
+		tm.pushTaskUpdate(ctx, taskID, seq)
 	}
 
+	sendSSE(subscribers, taskUpdateEvent)
+}
+
+// fanOutSSE sends an event to the current SSE subscribers of a task
+func (tm *InMemoryTaskManager) fanOutSSE(ctx context.Context, taskID string, event interface{}) {
+	sendSSE(tm.taskSSESubscribers.snapshot(subscriberKey(ctx, taskID)), event)
+}
+
+// sendSSE sends an event to each subscriber still consuming
+func sendSSE(subscribers []*sseSubscriber, taskUpdateEvent interface{}) {
 	for _, subscriber := range subscribers {
-		subscriber <- taskUpdateEvent
+		select {
+		case subscriber.events <- taskUpdateEvent:
+		case <-subscriber.done:
+		}
 	}
 }
 
-// dequeueEventsForSSE processes events from SSE queue
-func (tm *InMemoryTaskManager) dequeueEventsForSSE(requestID interface{}, taskID string, sseEventQueue chan interface{}) chan *types.SendTaskStreamingResponse {
+// removeSSEConsumer unsubscribes a consumer and releases producers blocked on it
+func (tm *InMemoryTaskManager) removeSSEConsumer(key taskKey, subscriber *sseSubscriber) {
+	tm.taskSSESubscribers.remove(key, subscriber)
+	close(subscriber.done)
+}
+
+// dequeueEventsForSSE processes events from SSE queue until the task finishes or ctx,
+// the streaming request's context, is canceled because the client disconnected
+func (tm *InMemoryTaskManager) dequeueEventsForSSE(ctx context.Context, requestID interface{}, taskID string, subscriber *sseSubscriber) chan *types.SendTaskStreamingResponse {
 	responseChan := make(chan *types.SendTaskStreamingResponse)
+	key := subscriberKey(ctx, taskID)
 
 	go func() {
 		defer close(responseChan)
-		defer func() {
-			tm.subscriberLock.Lock()
-			if subscribers, exists := tm.taskSSESubscribers[taskID]; exists {
-				for i, sub := range subscribers {
-					if sub == sseEventQueue {
-						tm.taskSSESubscribers[taskID] = append(subscribers[:i], subscribers[i+1:]...)
-						break
-					}
-				}
+		defer tm.removeSSEConsumer(key, subscriber)
+
+		send := func(response *types.SendTaskStreamingResponse) bool {
+			select {
+			case responseChan <- response:
+				return true
+			case <-ctx.Done():
+				return false
 			}
-			tm.subscriberLock.Unlock()
-		}()
+		}
 
 		for {
-			event, ok := <-sseEventQueue
+			var event interface{}
+			var ok bool
+			select {
+			case event, ok = <-subscriber.events:
+			case <-ctx.Done():
+				return
+			}
 			if !ok {
-				break
+				return
 			}
 
 			if err, isError := event.(*types.JSONRPCError); isError {
-				responseChan <- &types.SendTaskStreamingResponse{
+				send(&types.SendTaskStreamingResponse{
 					ID:    requestID,
 					Error: err,
-				}
-				break
+				})
+				return
 			}
 
-			responseChan <- &types.SendTaskStreamingResponse{
+			if !send(&types.SendTaskStreamingResponse{
 				ID:     requestID,
 				Result: event,
+			}) {
+				return
 			}
 
 			if statusEvent, isStatusEvent := event.(*types.TaskStatusUpdateEvent); isStatusEvent && statusEvent.Final {
-				break
+				return
 			}
 		}
 	}()
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestUpsertTaskDropsReservedMetadata(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager()
+	metadata := map[string]interface{}{
+		types.ForkedFromMetadataKey: "other-task",
+		types.ProgressMetadataKey:   100,
+		"transfers":                 "not a list",
+		"customer":                  "acme",
+	}
+	task, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("hi"), Metadata: metadata})
+	if err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+	if _, ok := task.Metadata[types.ForkedFromMetadataKey]; ok {
+		t.Fatal("client metadata set the forked-from key")
+	}
+	if _, ok := task.Metadata[types.ProgressMetadataKey]; ok {
+		t.Fatal("client metadata set the progress key")
+	}
+	if task.Metadata["customer"] != "acme" || len(metadata) != 4 {
+		t.Fatalf("metadata = %v, want the client's own keys kept and the params untouched", task.Metadata)
+	}
+
+	// Stores decode tasks whatever metadata clients sent
+	data := `{"id":"t1","status":{"state":"submitted"},"metadata":{"transfers":"not a list"}}`
+	if _, err := decodeStoredTask(data); err != nil {
+		t.Fatalf("decodeStoredTask: %v", err)
+	}
+}
+
+func TestTransferTaskRecordsTransfer(t *testing.T) {
+	ctx := context.Background()
+	tm := NewInMemoryTaskManager()
+	if _, err := tm.upsertTask(ctx, &types.TaskSendParams{ID: "t1", SessionID: "s1", Message: textMessage("hi")}); err != nil {
+		t.Fatalf("upsertTask: %v", err)
+	}
+
+	reason := "escalated"
+	metadata := map[string]interface{}{"ticket": "T-1"}
+	task, err := tm.transferTask(ctx, &types.TaskTransferParams{ID: "t1", SessionID: "s2", Reason: &reason, Metadata: metadata})
+	if err != nil {
+		t.Fatalf("transferTask: %v", err)
+	}
+	metadata["ticket"] = "changed"
+	if _, err := tm.transferTask(ctx, &types.TaskTransferParams{ID: "t1", SessionID: "s3"}); err != nil {
+		t.Fatalf("transferTask: %v", err)
+	}
+
+	if len(task.Transfers) != 1 {
+		t.Fatalf("transfers of the first snapshot = %+v, want one", task.Transfers)
+	}
+	task, err = tm.getTask(ctx, "t1")
+	if err != nil {
+		t.Fatalf("getTask: %v", err)
+	}
+	if len(task.Transfers) != 2 {
+		t.Fatalf("transfers = %+v, want two", task.Transfers)
+	}
+	first := task.Transfers[0]
+	if first.FromSessionID != "s1" || first.ToSessionID != "s2" || *first.Reason != reason || first.Metadata["ticket"] != "T-1" {
+		t.Fatalf("first transfer = %+v, want s1 to s2 with its reason and metadata", first)
+	}
+	if _, ok := task.Metadata["transfers"]; ok {
+		t.Fatal("transfers recorded in the task metadata")
+	}
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"a2a-go/pkg/types"
+)
+
+const (
+	// defaultTaskListLimit is the page size of tasks/list when the request sets none
+	defaultTaskListLimit = 100
+	// maxTaskListLimit caps the page size of tasks/list
+	maxTaskListLimit = 1000
+)
+
+// TaskLister is implemented by task managers that can search their tasks
+type TaskLister interface {
+	OnListTasks(ctx context.Context, request *types.JSONRPCRequest) (*types.ListTasksResponse, error)
+}
+
+// WithMetadataIndex maintains secondary indexes for task metadata keys, e.g. "conversation_id",
+// so tasks/list metadata filters on them don't scan every task of the tenant
+func WithMetadataIndex(keys ...string) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		if tm.indexedMetadataKeys == nil {
+			tm.indexedMetadataKeys = make(map[string]bool)
+		}
+		for _, key := range keys {
+			tm.indexedMetadataKeys[key] = true
+		}
+	}
+}
+
+// metadataIndex maps indexed metadata keys and values to the tasks carrying them
+type metadataIndex struct {
+	tasks  map[string]map[string]map[string]struct{} // Key, value, task ids
+	values map[string]map[string]string              // Indexed values per task id and key
+}
+
+func newMetadataIndex() metadataIndex {
+	return metadataIndex{
+		tasks:  make(map[string]map[string]map[string]struct{}),
+		values: make(map[string]map[string]string),
+	}
+}
+
+// metadataValue returns the comparable form of a metadata value. Values are compared by
+// their JSON encoding, so the number 3 set by a handler matches 3 decoded from a request.
+func metadataValue(v interface{}) (string, bool) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// indexMetadata brings the metadata index up to date with the task's metadata.
+// The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) indexMetadata(store *tenantStore, task *types.Task) {
+	if len(tm.indexedMetadataKeys) == 0 {
+		return
+	}
+	index := store.metadataIndex
+	for key, value := range index.values[task.ID] {
+		delete(index.tasks[key][value], task.ID)
+		if len(index.tasks[key][value]) == 0 {
+			delete(index.tasks[key], value)
+		}
+	}
+	delete(index.values, task.ID)
+
+	for key := range tm.indexedMetadataKeys {
+		raw, ok := task.Metadata[key]
+		if !ok {
+			continue
+		}
+		value, ok := metadataValue(raw)
+		if !ok {
+			continue
+		}
+		if index.tasks[key] == nil {
+			index.tasks[key] = make(map[string]map[string]struct{})
+		}
+		if index.tasks[key][value] == nil {
+			index.tasks[key][value] = make(map[string]struct{})
+		}
+		index.tasks[key][value][task.ID] = struct{}{}
+		if index.values[task.ID] == nil {
+			index.values[task.ID] = make(map[string]string)
+		}
+		index.values[task.ID][key] = value
+	}
+}
+
+// SetTaskMetadata sets a metadata key of a task belonging to the tenant in ctx, e.g. to tag
+// it with a customer id for later search; a nil value removes the key
+func (tm *InMemoryTaskManager) SetTaskMetadata(ctx context.Context, taskID, key string, value interface{}) error {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	task := store.tasks[taskID]
+	if task == nil {
+		return taskNotFound(taskID)
+	}
+
+	if err := tm.journal(ctx, TaskLogEntry{
+		TaskID:  taskID,
+		Version: task.Version + 1,
+		Kind:    TaskLogMetadata,
+		Key:     key,
+		Value:   value,
+	}); err != nil {
+		return err
+	}
+	if value == nil {
+		delete(task.Metadata, key)
+	} else {
+		if task.Metadata == nil {
+			task.Metadata = make(map[string]interface{})
+		}
+		task.Metadata[key] = value
+	}
+	task.Version++
+	tm.indexMetadata(store, task)
+	return nil
+}
+
+// ListTasks returns the tasks of the tenant in ctx matching params, ordered by id
+func (tm *InMemoryTaskManager) ListTasks(ctx context.Context, params *types.TaskListParams) (*types.TaskList, error) {
+	limit := params.Limit
+	switch {
+	case limit < 0:
+		return nil, invalidParams("limit must not be negative")
+	case limit == 0:
+		limit = defaultTaskListLimit
+	case limit > maxTaskListLimit:
+		limit = maxTaskListLimit
+	}
+
+	filter := make(map[string]string, len(params.Metadata))
+	for key, raw := range params.Metadata {
+		value, ok := metadataValue(raw)
+		if !ok {
+			return nil, invalidParams("invalid metadata filter %q", key)
+		}
+		filter[key] = value
+	}
+
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	store := tm.store(ctx)
+	var ids []string
+	for _, taskID := range tm.candidateTasks(store, params.SessionID, filter) {
+		if params.Cursor != "" && taskID <= params.Cursor {
+			continue
+		}
+		if task := store.tasks[taskID]; task != nil && matchesTaskFilter(task, params, filter) {
+			ids = append(ids, taskID)
+		}
+	}
+	sort.Strings(ids)
+
+	list := &types.TaskList{Tasks: make([]types.Task, 0, min(len(ids), limit))}
+	for _, taskID := range ids {
+		if len(list.Tasks) == limit {
+			list.NextCursor = list.Tasks[limit-1].ID
+			break
+		}
+		list.Tasks = append(list.Tasks, *store.tasks[taskID])
+	}
+	return list, nil
+}
+
+// candidateTasks returns the ids of tasks that may match, using the smallest metadata index
+// of the filter or the session if possible. The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) candidateTasks(store *tenantStore, sessionID string, filter map[string]string) []string {
+	var best map[string]struct{}
+	indexed := false
+	for key, value := range filter {
+		if !tm.indexedMetadataKeys[key] {
+			continue
+		}
+		tasks := store.metadataIndex.tasks[key][value]
+		if !indexed || len(tasks) < len(best) {
+			best, indexed = tasks, true
+		}
+	}
+
+	switch {
+	case indexed:
+		ids := make([]string, 0, len(best))
+		for taskID := range best {
+			ids = append(ids, taskID)
+		}
+		return ids
+	case sessionID != "":
+		return store.sessions[sessionID]
+	}
+	ids := make([]string, 0, len(store.tasks))
+	for taskID := range store.tasks {
+		ids = append(ids, taskID)
+	}
+	return ids
+}
+
+// matchesTaskFilter reports whether a task satisfies every filter of params
+func matchesTaskFilter(task *types.Task, params *types.TaskListParams, filter map[string]string) bool {
+	if params.SessionID != "" && (task.SessionID == nil || *task.SessionID != params.SessionID) {
+		return false
+	}
+	if len(params.States) > 0 {
+		found := false
+		for _, state := range params.States {
+			if task.Status.State == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, want := range filter {
+		raw, ok := task.Metadata[key]
+		if !ok {
+			return false
+		}
+		if value, ok := metadataValue(raw); !ok || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// OnListTasks handles tasks/list requests
+func (tm *InMemoryTaskManager) OnListTasks(ctx context.Context, request *types.JSONRPCRequest) (*types.ListTasksResponse, error) {
+	params := request.Params.(*types.TaskListParams)
+	list, err := tm.ListTasks(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ListTasksResponse{Result: list}, nil
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// TaskStore persists tasks, scoped to the tenant in ctx, so they survive restarts.
+// The task manager writes every change through to it and LoadTasks reads the tasks back.
+type TaskStore interface {
+	// Get returns nil without error if the task doesn't exist
+	Get(ctx context.Context, taskID string) (*types.Task, error)
+	// Save creates or replaces a task
+	Save(ctx context.Context, task *types.Task) error
+	Delete(ctx context.Context, taskID string) error
+	// List returns the tasks of the tenant in creation order
+	List(ctx context.Context) ([]*types.Task, error)
+	// AppendHistory adds messages to the end of a task's history and sets its version
+	AppendHistory(ctx context.Context, taskID string, version uint64, messages ...types.Message) error
+	// SaveArtifacts replaces the artifacts of a task with the same index, adds the others and
+	// sets its version
+	SaveArtifacts(ctx context.Context, taskID string, version uint64, artifacts []types.Artifact) error
+}
+
+// WithTaskStore writes every change to a task through to store; call LoadTasks on startup
+// to read them back. Use a persistent PushConfigStore as well to keep push notification configs.
+// Stores implementing RecordedEventStore keep the recorded event streams of tasks as well.
+func WithTaskStore(store TaskStore) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.taskStore = store
+	}
+}
+
+// clockAware is implemented by task stores that timestamp what they store, so they use the
+// clock injected into the task manager
+type clockAware interface {
+	applyClock(clock utils.Clock)
+}
+
+// persist writes the change entry records through to the TaskStore. The caller must hold tm.lock.
+func (tm *InMemoryTaskManager) persist(ctx context.Context, entry TaskLogEntry) error {
+	if tm.taskStore == nil {
+		return nil
+	}
+	var err error
+	switch entry.Kind {
+	case TaskLogMessage:
+		err = tm.taskStore.AppendHistory(ctx, entry.TaskID, entry.Version, *entry.Message)
+	case TaskLogArtifact:
+		err = tm.taskStore.SaveArtifacts(ctx, entry.TaskID, entry.Version, entry.Artifacts)
+	default:
+		var task *types.Task
+		if task, err = applyTaskLogEntry(tm.store(ctx).tasks[entry.TaskID], entry); err == nil {
+			err = tm.taskStore.Save(ctx, task)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to persist task %s: %w", entry.TaskID, err)
+	}
+	return nil
+}
+
+// LoadTasks replaces the tasks of the tenant in ctx with the ones in the TaskStore, e.g.
+// after a restart, and returns how many were loaded
+func (tm *InMemoryTaskManager) LoadTasks(ctx context.Context) (int, error) {
+	if tm.taskStore == nil {
+		return 0, errors.New("no task store is configured")
+	}
+	tasks, err := tm.taskStore.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := newTenantStore()
+	for _, task := range tasks {
+		loaded.tasks[task.ID] = task
+		if task.SessionID != nil {
+			loaded.sessions[*task.SessionID] = append(loaded.sessions[*task.SessionID], task.ID)
+		}
+		tm.indexMetadata(loaded, task)
+	}
+
+	tm.lock.Lock()
+	tm.tenants[TenantFromContext(ctx)] = loaded
+	tm.lock.Unlock()
+	return len(tasks), nil
+}
+
+// InMemoryTaskStore keeps copies of tasks and their recorded events in memory
+type InMemoryTaskStore struct {
+	lock   sync.Mutex
+	tasks  map[taskKey]*types.Task
+	order  map[string][]string // Task ids per tenant in creation order
+	events map[taskKey][]RecordedEvent
+}
+
+// NewInMemoryTaskStore creates an empty InMemoryTaskStore
+func NewInMemoryTaskStore() *InMemoryTaskStore {
+	return &InMemoryTaskStore{
+		tasks:  make(map[taskKey]*types.Task),
+		order:  make(map[string][]string),
+		events: make(map[taskKey][]RecordedEvent),
+	}
+}
+
+func (s *InMemoryTaskStore) Get(ctx context.Context, taskID string) (*types.Task, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	task := s.tasks[subscriberKey(ctx, taskID)]
+	if task == nil {
+		return nil, nil
+	}
+	return copyTask(task), nil
+}
+
+func (s *InMemoryTaskStore) Save(ctx context.Context, task *types.Task) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := subscriberKey(ctx, task.ID)
+	if s.tasks[key] == nil {
+		tenant := TenantFromContext(ctx)
+		s.order[tenant] = append(s.order[tenant], task.ID)
+	}
+	s.tasks[key] = copyTask(task)
+	return nil
+}
+
+func (s *InMemoryTaskStore) Delete(ctx context.Context, taskID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := subscriberKey(ctx, taskID)
+	if s.tasks[key] == nil {
+		return nil
+	}
+	delete(s.tasks, key)
+	delete(s.events, key)
+	tenant := TenantFromContext(ctx)
+	order := s.order[tenant]
+	for i, id := range order {
+		if id == taskID {
+			s.order[tenant] = append(order[:i:i], order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *InMemoryTaskStore) List(ctx context.Context) ([]*types.Task, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	order := s.order[TenantFromContext(ctx)]
+	tasks := make([]*types.Task, 0, len(order))
+	for _, taskID := range order {
+		tasks = append(tasks, copyTask(s.tasks[subscriberKey(ctx, taskID)]))
+	}
+	return tasks, nil
+}
+
+func (s *InMemoryTaskStore) AppendHistory(ctx context.Context, taskID string, version uint64, messages ...types.Message) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	task := s.tasks[subscriberKey(ctx, taskID)]
+	if task == nil {
+		return taskNotFound(taskID)
+	}
+	task.History = append(task.History, messages...)
+	task.Version = version
+	return nil
+}
+
+func (s *InMemoryTaskStore) SaveArtifacts(ctx context.Context, taskID string, version uint64, artifacts []types.Artifact) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	task := s.tasks[subscriberKey(ctx, taskID)]
+	if task == nil {
+		return taskNotFound(taskID)
+	}
+	for _, artifact := range artifacts {
+		task.Artifacts = replaceArtifact(task.Artifacts, artifact)
+	}
+	task.Version = version
+	return nil
+}
+
+func (s *InMemoryTaskStore) AppendEvent(ctx context.Context, taskID string, event RecordedEvent, limit int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	key := subscriberKey(ctx, taskID)
+	if s.tasks[key] == nil {
+		return taskNotFound(taskID)
+	}
+	s.events[key] = appendRecordedEvent(s.events[key], event, limit)
+	return nil
+}
+
+func (s *InMemoryTaskStore) Events(ctx context.Context, taskID string) ([]RecordedEvent, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]RecordedEvent(nil), s.events[subscriberKey(ctx, taskID)]...), nil
+}
+
+// copyTask copies a task deep enough for neither copy to see changes to the other's history,
+// artifacts or metadata
+func copyTask(task *types.Task) *types.Task {
+	copied := *task
+	copied.History = append([]types.Message(nil), task.History...)
+	copied.Artifacts = append([]types.Artifact(nil), task.Artifacts...)
+	copied.Metadata = copyMetadata(task.Metadata)
+	copied.Transfers = append([]types.TaskTransferRecord(nil), task.Transfers...)
+	return &copied
+}
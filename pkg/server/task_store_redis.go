@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisUpdateAttempts is how often an update of a task is retried when another replica
+// changes it concurrently
+const redisUpdateAttempts = 10
+
+// RedisTaskStore persists tasks as JSON Redis strings, with a sorted set per tenant keeping
+// them in creation order. Replicas sharing it share their tasks.
+type RedisTaskStore struct {
+	client redis.UniversalClient
+	prefix string
+	clock  utils.Clock // Scores tasks by creation time, the task manager's clock
+}
+
+// NewRedisTaskStore creates a RedisTaskStore storing keys under prefix
+func NewRedisTaskStore(client redis.UniversalClient, prefix string) *RedisTaskStore {
+	if prefix == "" {
+		prefix = "a2a"
+	}
+	return &RedisTaskStore{client: client, prefix: prefix, clock: utils.DefaultClock()}
+}
+
+func (s *RedisTaskStore) key(ctx context.Context, taskID string) string {
+	return fmt.Sprintf("%s:task:%s:%s", s.prefix, TenantFromContext(ctx), taskID)
+}
+
+// indexKey is the sorted set of the task ids of the tenant in ctx, scored by creation time
+func (s *RedisTaskStore) indexKey(ctx context.Context) string {
+	return fmt.Sprintf("%s:tasks:%s", s.prefix, TenantFromContext(ctx))
+}
+
+func (s *RedisTaskStore) Get(ctx context.Context, taskID string) (*types.Task, error) {
+	data, err := s.client.Get(ctx, s.key(ctx, taskID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeStoredTask(data)
+}
+
+func (s *RedisTaskStore) Save(ctx context.Context, task *types.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.key(ctx, task.ID), data, 0)
+		pipe.ZAddNX(ctx, s.indexKey(ctx), redis.Z{Score: float64(s.clock.Now().UnixNano()), Member: task.ID})
+		return nil
+	})
+	return err
+}
+
+func (s *RedisTaskStore) applyClock(clock utils.Clock) {
+	s.clock = clock
+}
+
+func (s *RedisTaskStore) Delete(ctx context.Context, taskID string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.key(ctx, taskID))
+		pipe.ZRem(ctx, s.indexKey(ctx), taskID)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisTaskStore) List(ctx context.Context) ([]*types.Task, error) {
+	taskIDs, err := s.client.ZRange(ctx, s.indexKey(ctx), 0, -1).Result()
+	if err != nil || len(taskIDs) == 0 {
+		return nil, err
+	}
+	keys := make([]string, len(taskIDs))
+	for i, taskID := range taskIDs {
+		keys[i] = s.key(ctx, taskID)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*types.Task, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Deleted since the index was read
+		}
+		task, err := decodeStoredTask(data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (s *RedisTaskStore) AppendHistory(ctx context.Context, taskID string, version uint64, messages ...types.Message) error {
+	return s.update(ctx, taskID, func(task *types.Task) {
+		task.History = append(task.History, messages...)
+		task.Version = version
+	})
+}
+
+func (s *RedisTaskStore) SaveArtifacts(ctx context.Context, taskID string, version uint64, artifacts []types.Artifact) error {
+	return s.update(ctx, taskID, func(task *types.Task) {
+		for _, artifact := range artifacts {
+			task.Artifacts = replaceArtifact(task.Artifacts, artifact)
+		}
+		task.Version = version
+	})
+}
+
+// update applies change to a stored task in an optimistic transaction, retried if the task
+// changes meanwhile
+func (s *RedisTaskStore) update(ctx context.Context, taskID string, change func(task *types.Task)) error {
+	key := s.key(ctx, taskID)
+	for attempt := 0; attempt < redisUpdateAttempts; attempt++ {
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) {
+				return taskNotFound(taskID)
+			}
+			if err != nil {
+				return err
+			}
+			task, err := decodeStoredTask(data)
+			if err != nil {
+				return err
+			}
+			change(task)
+
+			encoded, err := json.Marshal(task)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, encoded, 0)
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("task %s changed concurrently %d times", taskID, redisUpdateAttempts)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// capturingHook records the commands of pipelines instead of sending them to Redis
+type capturingHook struct {
+	cmds []redis.Cmder
+}
+
+func (h *capturingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, context.Canceled
+	}
+}
+
+func (h *capturingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.cmds = append(h.cmds, cmd)
+		return nil
+	}
+}
+
+func (h *capturingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.cmds = append(h.cmds, cmds...)
+		return nil
+	}
+}
+
+func TestRedisTaskStoreUsesTaskManagerClock(t *testing.T) {
+	hook := &capturingHook{}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	client.AddHook(hook)
+	defer client.Close()
+
+	store := NewRedisTaskStore(client, "")
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	NewInMemoryTaskManager(WithClock(utils.NewFakeClock(now)), WithTaskStore(store))
+
+	if err := store.Save(context.Background(), &types.Task{ID: "t1"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	for _, cmd := range hook.cmds {
+		if cmd.Name() != "zadd" {
+			continue
+		}
+		// zadd key nx score member
+		if score := cmd.Args()[3]; score != float64(now.UnixNano()) {
+			t.Fatalf("task was indexed with score %v, want %v", score, float64(now.UnixNano()))
+		}
+		return
+	}
+	t.Fatalf("store sent no zadd, commands: %v", hook.cmds)
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// SQLTaskStore persists tasks in a SQL table using database/sql, one JSON document per task.
+// The caller provides the *sql.DB and driver, e.g. SQLite or Postgres.
+type SQLTaskStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	table   string
+	clock   utils.Clock // Timestamps the creation of tasks, the task manager's clock
+}
+
+// NewSQLTaskStore creates a SQLTaskStore using table, creating it if missing. Pair it with a
+// SQLPushConfigStore on the same database to keep push notification configs as well.
+func NewSQLTaskStore(ctx context.Context, db *sql.DB, dialect SQLDialect, table string) (*SQLTaskStore, error) {
+	if table == "" {
+		table = "a2a_tasks"
+	}
+	if !sqlTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	s := &SQLTaskStore{db: db, dialect: dialect, table: table, clock: utils.DefaultClock()}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	tenant TEXT NOT NULL,
+	task_id TEXT NOT NULL,
+	created BIGINT NOT NULL,
+	task TEXT NOT NULL,
+	PRIMARY KEY (tenant, task_id)
+)`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLTaskStore) Get(ctx context.Context, taskID string) (*types.Task, error) {
+	return s.get(ctx, s.db, taskID)
+}
+
+func (s *SQLTaskStore) Save(ctx context.Context, task *types.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	query := s.dialect.rebind(fmt.Sprintf(
+		`INSERT INTO %s (tenant, task_id, created, task) VALUES (?, ?, ?, ?)
+ON CONFLICT (tenant, task_id) DO UPDATE SET task = excluded.task`, s.table))
+	_, err = s.db.ExecContext(ctx, query, TenantFromContext(ctx), task.ID, s.clock.Now().UnixNano(), string(data))
+	return err
+}
+
+func (s *SQLTaskStore) applyClock(clock utils.Clock) {
+	s.clock = clock
+}
+
+func (s *SQLTaskStore) Delete(ctx context.Context, taskID string) error {
+	query := s.dialect.rebind(fmt.Sprintf(`DELETE FROM %s WHERE tenant = ? AND task_id = ?`, s.table))
+	_, err := s.db.ExecContext(ctx, query, TenantFromContext(ctx), taskID)
+	return err
+}
+
+func (s *SQLTaskStore) List(ctx context.Context) ([]*types.Task, error) {
+	query := s.dialect.rebind(fmt.Sprintf(`SELECT task FROM %s WHERE tenant = ? ORDER BY created, task_id`, s.table))
+	rows, err := s.db.QueryContext(ctx, query, TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*types.Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		task, err := decodeStoredTask(data)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func (s *SQLTaskStore) AppendHistory(ctx context.Context, taskID string, version uint64, messages ...types.Message) error {
+	return s.update(ctx, taskID, func(task *types.Task) {
+		task.History = append(task.History, messages...)
+		task.Version = version
+	})
+}
+
+func (s *SQLTaskStore) SaveArtifacts(ctx context.Context, taskID string, version uint64, artifacts []types.Artifact) error {
+	return s.update(ctx, taskID, func(task *types.Task) {
+		for _, artifact := range artifacts {
+			task.Artifacts = replaceArtifact(task.Artifacts, artifact)
+		}
+		task.Version = version
+	})
+}
+
+// sqlQuerier is implemented by *sql.DB and *sql.Tx
+type sqlQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (s *SQLTaskStore) get(ctx context.Context, q sqlQuerier, taskID string) (*types.Task, error) {
+	query := s.dialect.rebind(fmt.Sprintf(`SELECT task FROM %s WHERE tenant = ? AND task_id = ?`, s.table))
+
+	var data string
+	err := q.QueryRowContext(ctx, query, TenantFromContext(ctx), taskID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeStoredTask(data)
+}
+
+// update applies change to a stored task within a transaction
+func (s *SQLTaskStore) update(ctx context.Context, taskID string, change func(task *types.Task)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	task, err := s.get(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return taskNotFound(taskID)
+	}
+	change(task)
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	query := s.dialect.rebind(fmt.Sprintf(`UPDATE %s SET task = ? WHERE tenant = ? AND task_id = ?`, s.table))
+	if _, err := tx.ExecContext(ctx, query, string(data), TenantFromContext(ctx), taskID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// decodeStoredTask decodes a task stored as JSON
+func decodeStoredTask(data string) (*types.Task, error) {
+	var task types.Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	return &task, nil
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// recordingDriver is a database/sql driver recording the arguments of every statement it
+// executes, enough to check what SQLTaskStore writes without a database
+type recordingDriver struct {
+	lock  sync.Mutex
+	execs map[string][][]driver.Value // Arguments per query, keyed by its first word
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+type recordingConn struct{ driver *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.lock.Lock()
+	defer s.driver.lock.Unlock()
+	verb := strings.Fields(s.query)[0]
+	s.driver.execs[verb] = append(s.driver.execs[verb], args)
+	return driver.RowsAffected(1), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+var testSQLDriver = &recordingDriver{execs: make(map[string][][]driver.Value)}
+
+func init() {
+	sql.Register("a2a-recording", testSQLDriver)
+}
+
+func TestSQLTaskStoreUsesTaskManagerClock(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("a2a-recording", "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+	store, err := NewSQLTaskStore(ctx, db, SQLDialectSQLite, "")
+	if err != nil {
+		t.Fatalf("NewSQLTaskStore: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	NewInMemoryTaskManager(WithClock(utils.NewFakeClock(now)), WithTaskStore(store))
+
+	if err := store.Save(ctx, &types.Task{ID: "t1"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	testSQLDriver.lock.Lock()
+	defer testSQLDriver.lock.Unlock()
+	inserts := testSQLDriver.execs["INSERT"]
+	if len(inserts) != 1 {
+		t.Fatalf("store ran %d inserts, want 1", len(inserts))
+	}
+	if created := inserts[0][2]; created != now.UnixNano() {
+		t.Fatalf("task was stored as created at %v, want %d", created, now.UnixNano())
+	}
+}
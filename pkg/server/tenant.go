@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// DefaultTenant is the tenant used when no TenantResolver is configured
+const DefaultTenant = ""
+
+// TenantResolver determines the tenant a request belongs to.
+// Resolvers may inspect headers or values placed in r.Context() by
+// authentication middleware (e.g. the authenticated principal).
+type TenantResolver func(r *http.Request) (string, error)
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the given tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by ctx, or DefaultTenant if none
+func TenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return DefaultTenant
+	}
+	if tenant, ok := ctx.Value(tenantContextKey{}).(string); ok {
+		return tenant
+	}
+	return DefaultTenant
+}
+
+// HeaderTenantResolver returns a TenantResolver that reads the tenant from the given header.
+// Requests without the header are rejected.
+func HeaderTenantResolver(header string) TenantResolver {
+	return func(r *http.Request) (string, error) {
+		tenant := strings.TrimSpace(r.Header.Get(header))
+		if tenant == "" {
+			return "", errors.New("missing tenant header " + header)
+		}
+		return tenant, nil
+	}
+}
+
+// taskKey identifies a task within the tenant it belongs to
+type taskKey struct {
+	tenant string
+	taskID string
+}
+
+// subscriberKey scopes an SSE subscriber entry to the tenant in ctx
+func subscriberKey(ctx context.Context, taskID string) taskKey {
+	return taskKey{tenant: TenantFromContext(ctx), taskID: taskID}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// ErrThrottled matches every error created by Throttled through errors.Is
+var ErrThrottled = &TaskError{Code: ThrottledErrorCode, Message: "Too many requests"}
+
+// Throttled returns an error telling the client to retry after retryAfter, for agents
+// and TaskManagers that are saturated
+func Throttled(retryAfter time.Duration, format string, args ...interface{}) error {
+	return &TaskError{
+		Code:    ThrottledErrorCode,
+		Message: fmt.Sprintf(format, args...),
+		Data:    types.RetryAfterData(retryAfter),
+	}
+}
+
+// WithRateLimit limits each caller to rate requests per second with bursts of up to burst
+// requests. Rejected requests get a throttling error with a retry hint. caller defaults to RemoteAddrCaller.
+func WithRateLimit(rate float64, burst int, caller CallerFunc) ServerOption {
+	return func(s *A2AServer) {
+		if rate <= 0 {
+			return
+		}
+		if caller == nil {
+			caller = RemoteAddrCaller
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		s.rateLimiter = &rateLimiter{
+			rate:    rate,
+			burst:   float64(burst),
+			caller:  caller,
+			buckets: make(map[string]*tokenBucket),
+		}
+	}
+}
+
+// tokenBucket holds the tokens left for one caller
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a per-caller token bucket limiter
+type rateLimiter struct {
+	rate      float64
+	burst     float64
+	caller    CallerFunc
+	clock     utils.Clock
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	nextSweep time.Time // When full buckets are dropped next
+}
+
+// allow takes a token for the caller of r, or returns how long until one is available
+func (l *rateLimiter) allow(r *http.Request) (time.Duration, bool) {
+	key := l.caller(r)
+	now := l.clock.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if !now.Before(l.nextSweep) {
+		// Forget callers whose buckets are full again. Sweeping once per the time an empty
+		// bucket takes to refill keeps idle callers for at most twice that.
+		for k, bucket := range l.buckets {
+			if l.refill(bucket, now) >= l.burst && k != key {
+				delete(l.buckets, k)
+			}
+		}
+		l.nextSweep = now.Add(time.Duration(l.burst / l.rate * float64(time.Second)))
+	}
+
+	bucket := l.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	l.refill(bucket, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+	wait := (1 - bucket.tokens) / l.rate
+	return time.Duration(math.Ceil(wait * float64(time.Second))), false
+}
+
+// refill adds the tokens accrued since the bucket was last updated
+func (l *rateLimiter) refill(bucket *tokenBucket, now time.Time) float64 {
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.rate)
+		bucket.last = now
+	}
+	return bucket.tokens
+}
+
+// setRetryAfter sets the Retry-After header for throttling errors and returns the status to
+// send: 429 for them, 200 for other errors, which JSON-RPC reports in the body
+func setRetryAfter(w http.ResponseWriter, rpcErr *types.JSONRPCError) int {
+	retryAfter, ok := rpcErr.RetryAfter()
+	if !ok && rpcErr.Code != ThrottledErrorCode {
+		return http.StatusOK
+	}
+	if ok {
+		seconds := int64(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	return http.StatusTooManyRequests
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/utils"
+)
+
+func TestRateLimiterForgetsIdleCallers(t *testing.T) {
+	clock := utils.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := &rateLimiter{
+		rate:    1,
+		burst:   2,
+		caller:  func(r *http.Request) string { return r.RemoteAddr },
+		clock:   clock,
+		buckets: make(map[string]*tokenBucket),
+	}
+	request := func(caller string) bool {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = caller
+		_, ok := l.allow(r)
+		return ok
+	}
+
+	if !request("a") || !request("a") || request("a") {
+		t.Fatal("caller a was not limited to its burst")
+	}
+	if !request("b") {
+		t.Fatal("caller b was limited by caller a")
+	}
+
+	// Both buckets are full again once the sweep runs
+	clock.Advance(5 * time.Second)
+	request("c")
+	if _, tracked := l.buckets["a"]; tracked {
+		t.Fatal("idle caller a is still tracked")
+	}
+	if !request("a") {
+		t.Fatal("caller a was limited after refilling")
+	}
+}
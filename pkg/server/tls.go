@@ -0,0 +1,95 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// WithTLS makes Start serve HTTPS with the certificate and key in certFile and keyFile
+func WithTLS(certFile, keyFile string) ServerOption {
+	return func(s *A2AServer) {
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+// WithTLSConfig makes Start serve HTTPS with config, e.g. for certificates obtained through
+// GetCertificate, a minimum version or client certificate verification
+func WithTLSConfig(config *tls.Config) ServerOption {
+	return func(s *A2AServer) {
+		s.tlsConfig = config
+	}
+}
+
+// WithClientCAs requires clients to present a certificate issued by one of the CAs in pool,
+// so agents calling each other are mutually authenticated. Pair it with WithAuthorizer and
+// ClientCertificatePrincipal to authorize callers by their certificate.
+func WithClientCAs(pool *x509.CertPool) ServerOption {
+	return func(s *A2AServer) {
+		s.clientCAs = pool
+	}
+}
+
+// WithAutocert makes Start serve HTTPS with certificates obtained from Let's Encrypt through
+// manager, using the TLS-ALPN-01 challenge on the server's own port. Serve manager.HTTPHandler
+// on port 80 as well to use the HTTP-01 challenge.
+func WithAutocert(manager *autocert.Manager) ServerOption {
+	return func(s *A2AServer) {
+		s.autocert = manager
+	}
+}
+
+// ListenAndServeTLS starts the A2A server serving HTTPS with the certificate and key in
+// certFile and keyFile
+func (s *A2AServer) ListenAndServeTLS(certFile, keyFile string) error {
+	s.certFile = certFile
+	s.keyFile = keyFile
+	return s.Start()
+}
+
+// serverTLSConfig returns the TLS settings Start serves with, or nil to serve plain HTTP
+func (s *A2AServer) serverTLSConfig() *tls.Config {
+	if s.tlsConfig == nil && s.autocert == nil && s.clientCAs == nil && s.certFile == "" {
+		return nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.tlsConfig != nil {
+		config = s.tlsConfig.Clone()
+	}
+	if s.autocert != nil {
+		managed := s.autocert.TLSConfig()
+		config.GetCertificate = managed.GetCertificate
+		config.NextProtos = append(config.NextProtos, managed.NextProtos...)
+	}
+	if s.clientCAs != nil {
+		config.ClientCAs = s.clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config
+}
+
+// ClientCertificatePrincipal is a PrincipalResolver identifying callers by the verified
+// certificate they presented over mutual TLS: the subject is its common name and the roles
+// are its organizational units
+func ClientCertificatePrincipal(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, errors.New("no verified client certificate")
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	claims := map[string]interface{}{
+		"serialNumber": cert.SerialNumber.String(),
+		"issuer":       cert.Issuer.String(),
+	}
+	if len(cert.DNSNames) > 0 {
+		claims["dnsNames"] = cert.DNSNames
+	}
+	return &Principal{
+		Subject: cert.Subject.CommonName,
+		Roles:   cert.Subject.OrganizationalUnit,
+		Claims:  claims,
+	}, nil
+}
@@ -34,21 +34,15 @@ func AreModalitiesCompatible(serverOutputModes, clientOutputModes []string) bool
 // NewIncompatibleTypesError creates a new JSONRPCResponse with ContentTypeNotSupportedError
 func NewIncompatibleTypesError(requestID interface{}) *types.JSONRPCResponse {
 	return &types.JSONRPCResponse{
-		ID: requestID,
-		Error: &types.JSONRPCError{
-			Code:    415,
-			Message: "Content type not supported",
-		},
+		ID:    requestID,
+		Error: types.ContentTypeNotSupportedError("", nil, nil),
 	}
 }
 
 // NewNotImplementedError creates a new JSONRPCResponse with UnsupportedOperationError
 func NewNotImplementedError(requestID interface{}) *types.JSONRPCResponse {
 	return &types.JSONRPCResponse{
-		ID: requestID,
-		Error: &types.JSONRPCError{
-			Code:    501,
-			Message: "Operation not implemented",
-		},
+		ID:    requestID,
+		Error: types.UnsupportedOperationError("Operation not implemented", "", ""),
 	}
 } 
\ No newline at end of file
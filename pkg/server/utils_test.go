@@ -0,0 +1,24 @@
+package server
+
+import (
+	"testing"
+
+	"a2a-go/pkg/types"
+)
+
+func TestErrorResponsesUseJSONRPCCodes(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		response *types.JSONRPCResponse
+		wantCode int
+	}{
+		{"incompatible types", NewIncompatibleTypesError(1), types.ContentTypeNotSupportedErrorCode},
+		{"not implemented", NewNotImplementedError(1), types.UnsupportedOperationErrorCode},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.response.Error == nil || test.response.Error.Code != test.wantCode {
+				t.Fatalf("error = %+v, want code %d", test.response.Error, test.wantCode)
+			}
+		})
+	}
+}
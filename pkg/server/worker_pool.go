@@ -0,0 +1,363 @@
+package server
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"a2a-go/pkg/types"
+	"a2a-go/pkg/utils"
+)
+
+// Priority determines the order in which queued tasks are executed
+type Priority int
+
+const (
+	PriorityLow    Priority = 0
+	PriorityNormal Priority = 1
+	PriorityHigh   Priority = 2
+)
+
+// PriorityMetadataKey is the TaskSendParams metadata key holding the task priority
+const PriorityMetadataKey = "priority"
+
+// ErrJobPaused is returned by a JobFunc that yielded at a checkpoint; the job is requeued
+var ErrJobPaused = errors.New("job paused at checkpoint")
+
+// ErrWorkerPoolClosed is returned when submitting to a closed WorkerPool
+var ErrWorkerPoolClosed = errors.New("worker pool is closed")
+
+// String returns the name of the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// PriorityFromMetadata reads the priority from task metadata.
+// It accepts "low", "normal", "high" or a number, and defaults to PriorityNormal.
+func PriorityFromMetadata(metadata map[string]interface{}) Priority {
+	switch v := metadata[PriorityMetadataKey].(type) {
+	case string:
+		switch strings.ToLower(v) {
+		case "low":
+			return PriorityLow
+		case "high":
+			return PriorityHigh
+		}
+	case float64:
+		return clampPriority(int(v))
+	case int:
+		return clampPriority(v)
+	}
+	return PriorityNormal
+}
+
+func clampPriority(p int) Priority {
+	if p < int(PriorityLow) {
+		return PriorityLow
+	}
+	if p > int(PriorityHigh) {
+		return PriorityHigh
+	}
+	return Priority(p)
+}
+
+// JobFunc executes a queued task. Long-running jobs should call
+// Checkpoint.ShouldYield periodically and return ErrJobPaused after saving
+// their progress when it reports true; the job is then requeued and run again.
+type JobFunc func(ctx context.Context, checkpoint *Checkpoint) error
+
+// Job is a unit of work submitted to a WorkerPool
+type Job struct {
+	TaskID   string
+	Priority Priority
+	Run      JobFunc
+	Fail     func(err error) // Called with ErrWorkerPoolClosed instead of Run if the pool closes first
+
+	seq        uint64
+	enqueuedAt time.Time
+	started    bool // Ran before, so requeued after pausing
+}
+
+// Checkpoint lets a running job find out whether it should yield to higher-priority work
+type Checkpoint struct {
+	pool      *WorkerPool
+	job       *Job
+	startedAt time.Time
+}
+
+// ShouldYield reports whether the job should pause so queued higher-priority jobs can run
+func (c *Checkpoint) ShouldYield() bool {
+	if c.pool.preemptAfter <= 0 || c.job.Priority >= PriorityHigh {
+		return false
+	}
+	if c.pool.clock.Now().Sub(c.startedAt) < c.pool.preemptAfter {
+		return false
+	}
+	return c.pool.hasWaitingAbove(c.job.Priority)
+}
+
+// QueueWaitStats aggregates how long jobs of one priority waited in the queue
+type QueueWaitStats struct {
+	Count   int64         // Jobs started; paused jobs count once
+	Total   time.Duration // Including the waits of paused jobs after they were requeued
+	Max     time.Duration
+	Paused  int64
+	Pending int
+}
+
+// Average returns the mean queue wait per job
+func (s QueueWaitStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// WorkerPoolOption configures a WorkerPool
+type WorkerPoolOption func(*WorkerPool)
+
+// WithPreemptAfter enables checkpoint preemption of jobs that ran longer than d
+// while higher-priority jobs are waiting. Executors of a DefaultRequestHandler check
+// for it with EventQueue.ShouldYield.
+func WithPreemptAfter(d time.Duration) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.preemptAfter = d
+	}
+}
+
+// WithPoolClock sets the clock used to measure queue waits and job run times
+func WithPoolClock(clock utils.Clock) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.clock = clock
+	}
+}
+
+// WithWorkerPool runs the executions of a DefaultRequestHandler on pool, highest priority
+// first per the PriorityMetadataKey of the message's params, instead of one goroutine per
+// message. Tasks stay submitted while queued; closing the pool fails the queued ones.
+func WithWorkerPool(pool *WorkerPool) TaskManagerOption {
+	return func(tm *InMemoryTaskManager) {
+		tm.workerPool = pool
+	}
+}
+
+// WorkerPool executes queued tasks on a fixed number of workers, highest priority first
+type WorkerPool struct {
+	preemptAfter time.Duration
+	clock        utils.Clock
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  jobQueue
+	seq    uint64
+	closed bool
+	stats  map[Priority]*QueueWaitStats
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorkerPool creates a WorkerPool and starts its workers
+func NewWorkerPool(workers int, opts ...WorkerPoolOption) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &WorkerPool{
+		stats:  make(map[Priority]*QueueWaitStats),
+		clock:  utils.DefaultClock(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	p.cond = sync.NewCond(&p.lock)
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues a job for execution
+func (p *WorkerPool) Submit(job *Job) error {
+	if job == nil || job.Run == nil {
+		return errors.New("job has no run function")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return ErrWorkerPoolClosed
+	}
+	p.push(job)
+	return nil
+}
+
+// SubmitTask queues run for the task described by params, using the priority from its metadata
+func (p *WorkerPool) SubmitTask(params *types.TaskSendParams, run JobFunc) error {
+	return p.Submit(&Job{
+		TaskID:   params.ID,
+		Priority: PriorityFromMetadata(params.Metadata),
+		Run:      run,
+	})
+}
+
+// Stats returns queue wait statistics per priority
+func (p *WorkerPool) Stats() map[Priority]QueueWaitStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	stats := make(map[Priority]QueueWaitStats, len(p.stats))
+	for priority, s := range p.stats {
+		stats[priority] = *s
+	}
+	for _, job := range p.queue {
+		s := stats[job.Priority]
+		s.Pending++
+		stats[job.Priority] = s
+	}
+	return stats
+}
+
+// Close stops accepting jobs, fails the queued ones, cancels running jobs and waits for
+// workers to exit
+func (p *WorkerPool) Close() {
+	p.lock.Lock()
+	p.closed = true
+	queued := p.queue
+	p.queue = nil
+	p.lock.Unlock()
+
+	p.cancel()
+	p.cond.Broadcast()
+	p.wg.Wait()
+	for _, job := range queued {
+		p.fail(job)
+	}
+}
+
+// fail tells the submitter of a job that it won't run
+func (p *WorkerPool) fail(job *Job) {
+	if job.Fail == nil {
+		log.Printf("Job for task %s dropped: %v", job.TaskID, ErrWorkerPoolClosed)
+		return
+	}
+	job.Fail(ErrWorkerPoolClosed)
+}
+
+// push adds a job to the queue. The caller must hold p.lock.
+func (p *WorkerPool) push(job *Job) {
+	p.seq++
+	job.seq = p.seq
+	job.enqueuedAt = p.clock.Now()
+	heap.Push(&p.queue, job)
+	p.cond.Signal()
+}
+
+// statsFor returns the stats entry of a priority. The caller must hold p.lock.
+func (p *WorkerPool) statsFor(priority Priority) *QueueWaitStats {
+	s := p.stats[priority]
+	if s == nil {
+		s = &QueueWaitStats{}
+		p.stats[priority] = s
+	}
+	return s
+}
+
+// hasWaitingAbove reports whether a job with a priority above the given one is queued
+func (p *WorkerPool) hasWaitingAbove(priority Priority) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.queue) > 0 && p.queue[0].Priority > priority
+}
+
+func (p *WorkerPool) worker() {
+	defer p.wg.Done()
+
+	for {
+		p.lock.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.closed {
+			p.lock.Unlock()
+			return
+		}
+		job := heap.Pop(&p.queue).(*Job)
+		wait := p.clock.Now().Sub(job.enqueuedAt)
+		s := p.statsFor(job.Priority)
+		if !job.started {
+			job.started = true
+			s.Count++
+		}
+		s.Total += wait
+		if wait > s.Max {
+			s.Max = wait
+		}
+		p.lock.Unlock()
+
+		checkpoint := &Checkpoint{pool: p, job: job, startedAt: p.clock.Now()}
+		err := job.Run(p.ctx, checkpoint)
+		if errors.Is(err, ErrJobPaused) {
+			p.lock.Lock()
+			p.statsFor(job.Priority).Paused++
+			closed := p.closed
+			if !closed {
+				p.push(job)
+			}
+			p.lock.Unlock()
+			if closed {
+				p.fail(job)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Job for task %s failed: %v", job.TaskID, err)
+		}
+	}
+}
+
+// jobQueue is a heap of jobs ordered by priority, then submission order
+type jobQueue []*Job
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].Priority != q[j].Priority {
+		return q[i].Priority > q[j].Priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x interface{}) { *q = append(*q, x.(*Job)) }
+
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return job
+}
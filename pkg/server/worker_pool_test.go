@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"a2a-go/pkg/types"
+)
+
+// blockJob returns a job that reports when it starts and runs until release is closed or
+// the pool closes
+func blockJob(started chan<- struct{}, release <-chan struct{}) *Job {
+	return &Job{
+		TaskID: "blocker",
+		Run: func(ctx context.Context, checkpoint *Checkpoint) error {
+			close(started)
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	}
+}
+
+// waitPending waits until the pool has n queued jobs
+func waitPending(t *testing.T, pool *WorkerPool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pending := 0
+		for _, s := range pool.Stats() {
+			pending += s.Pending
+		}
+		if pending == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending jobs = %d, want %d", pending, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkerPoolRunsHighestPriorityFirst(t *testing.T) {
+	pool := NewWorkerPool(1)
+	defer pool.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	if err := pool.Submit(blockJob(started, release)); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-started
+
+	var lock sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, job := range []struct {
+		taskID   string
+		priority Priority
+	}{{"low", PriorityLow}, {"normal1", PriorityNormal}, {"high", PriorityHigh}, {"normal2", PriorityNormal}} {
+		taskID := job.taskID
+		wg.Add(1)
+		err := pool.Submit(&Job{TaskID: taskID, Priority: job.priority, Run: func(ctx context.Context, checkpoint *Checkpoint) error {
+			defer wg.Done()
+			lock.Lock()
+			order = append(order, taskID)
+			lock.Unlock()
+			return nil
+		}})
+		if err != nil {
+			t.Fatalf("Submit %s: %v", taskID, err)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	want := []string{"high", "normal1", "normal2", "low"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestWorkerPoolCloseFailsQueuedJobs(t *testing.T) {
+	pool := NewWorkerPool(1)
+
+	started := make(chan struct{})
+	if err := pool.Submit(blockJob(started, nil)); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-started
+
+	var ran bool
+	var failure error
+	err := pool.Submit(&Job{
+		TaskID: "queued",
+		Run: func(ctx context.Context, checkpoint *Checkpoint) error {
+			ran = true
+			return nil
+		},
+		Fail: func(err error) { failure = err },
+	})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	pool.Close()
+
+	if ran {
+		t.Fatalf("queued job ran after Close")
+	}
+	if !errors.Is(failure, ErrWorkerPoolClosed) {
+		t.Fatalf("queued job failed with %v, want %v", failure, ErrWorkerPoolClosed)
+	}
+	if err := pool.Submit(&Job{Run: func(context.Context, *Checkpoint) error { return nil }}); !errors.Is(err, ErrWorkerPoolClosed) {
+		t.Fatalf("Submit after Close = %v, want %v", err, ErrWorkerPoolClosed)
+	}
+}
+
+func TestWorkerPoolCountsPausedJobsOnce(t *testing.T) {
+	pool := NewWorkerPool(1)
+	defer pool.Close()
+
+	runs := 0
+	done := make(chan struct{})
+	err := pool.Submit(&Job{TaskID: "t1", Priority: PriorityLow, Run: func(ctx context.Context, checkpoint *Checkpoint) error {
+		runs++
+		if runs < 3 {
+			return ErrJobPaused
+		}
+		close(done)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-done
+
+	stats := pool.Stats()[PriorityLow]
+	if stats.Count != 1 || stats.Paused != 2 {
+		t.Fatalf("stats = %+v, want Count 1 and Paused 2", stats)
+	}
+}
+
+// poolExecutor reports the tasks it starts and runs them until released or canceled
+type poolExecutor struct {
+	started chan string
+	release chan struct{}
+}
+
+func (e *poolExecutor) Execute(ctx context.Context, reqCtx RequestContext, queue EventQueue) error {
+	e.started <- reqCtx.TaskID
+	select {
+	case <-e.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *poolExecutor) Cancel(ctx context.Context, reqCtx RequestContext) error {
+	return nil
+}
+
+// sendTaskAsync calls OnSendTask in the background and delivers the resulting task
+func sendTaskAsync(h *DefaultRequestHandler, params *types.TaskSendParams) <-chan *types.Task {
+	result := make(chan *types.Task, 1)
+	go func() {
+		response, err := h.OnSendTask(context.Background(), &types.JSONRPCRequest{Params: params})
+		if err != nil {
+			result <- nil
+			return
+		}
+		result <- response.Result
+	}()
+	return result
+}
+
+func TestDefaultRequestHandlerWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(1)
+	defer pool.Close()
+	executor := &poolExecutor{started: make(chan string, 4), release: make(chan struct{})}
+	h := NewDefaultRequestHandler(executor, WithWorkerPool(pool))
+
+	first := sendTaskAsync(h, &types.TaskSendParams{ID: "first", Message: textMessage("go")})
+	if taskID := <-executor.started; taskID != "first" {
+		t.Fatalf("started %s, want first", taskID)
+	}
+	low := sendTaskAsync(h, &types.TaskSendParams{
+		ID: "low", Message: textMessage("go"), Metadata: map[string]interface{}{PriorityMetadataKey: "low"},
+	})
+	waitPending(t, pool, 1)
+	high := sendTaskAsync(h, &types.TaskSendParams{
+		ID: "high", Message: textMessage("go"), Metadata: map[string]interface{}{PriorityMetadataKey: "high"},
+	})
+	waitPending(t, pool, 2)
+	close(executor.release)
+
+	for _, want := range []string{"high", "low"} {
+		if taskID := <-executor.started; taskID != want {
+			t.Fatalf("started %s, want %s", taskID, want)
+		}
+	}
+	for _, result := range []<-chan *types.Task{first, low, high} {
+		if task := <-result; task == nil || task.Status.State != types.TaskCompleted {
+			t.Fatalf("task = %+v, want it completed", task)
+		}
+	}
+}
+
+func TestDefaultRequestHandlerWorkerPoolClose(t *testing.T) {
+	pool := NewWorkerPool(1)
+	executor := &poolExecutor{started: make(chan string, 4), release: make(chan struct{})}
+	h := NewDefaultRequestHandler(executor, WithWorkerPool(pool))
+
+	running := sendTaskAsync(h, &types.TaskSendParams{ID: "running", Message: textMessage("go")})
+	<-executor.started
+	queued := sendTaskAsync(h, &types.TaskSendParams{ID: "queued", Message: textMessage("go")})
+	waitPending(t, pool, 1)
+	pool.Close()
+
+	// Both the interrupted and the queued task fail instead of staying in progress
+	for _, result := range []<-chan *types.Task{running, queued} {
+		select {
+		case task := <-result:
+			if task == nil || task.Status.State != types.TaskFailed {
+				t.Fatalf("task = %+v, want it failed", task)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("OnSendTask didn't return after the pool closed")
+		}
+	}
+	if len(executor.started) != 0 {
+		t.Fatalf("queued task ran after the pool closed")
+	}
+}
+
+// yieldingExecutor runs the low priority task until it should yield, then finishes every task
+// it runs, reporting them in order
+type yieldingExecutor struct {
+	started  chan struct{}
+	finished chan RequestContext
+}
+
+func (e *yieldingExecutor) Execute(ctx context.Context, reqCtx RequestContext, queue EventQueue) error {
+	if reqCtx.TaskID == "low" && !reqCtx.Resumed {
+		close(e.started)
+		for !queue.ShouldYield() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return ErrJobPaused
+	}
+	e.finished <- reqCtx
+	return nil
+}
+
+func (e *yieldingExecutor) Cancel(ctx context.Context, reqCtx RequestContext) error {
+	return nil
+}
+
+func TestDefaultRequestHandlerYieldsToHigherPriority(t *testing.T) {
+	pool := NewWorkerPool(1, WithPreemptAfter(time.Nanosecond))
+	defer pool.Close()
+	executor := &yieldingExecutor{started: make(chan struct{}), finished: make(chan RequestContext, 2)}
+	h := NewDefaultRequestHandler(executor, WithWorkerPool(pool))
+
+	low := sendTaskAsync(h, &types.TaskSendParams{
+		ID: "low", Message: textMessage("go"), Metadata: map[string]interface{}{PriorityMetadataKey: "low"},
+	})
+	<-executor.started
+	high := sendTaskAsync(h, &types.TaskSendParams{
+		ID: "high", Message: textMessage("go"), Metadata: map[string]interface{}{PriorityMetadataKey: "high"},
+	})
+
+	for _, want := range []string{"high", "low"} {
+		select {
+		case reqCtx := <-executor.finished:
+			if reqCtx.TaskID != want {
+				t.Fatalf("finished %s, want %s", reqCtx.TaskID, want)
+			}
+			if reqCtx.Resumed != (want == "low") {
+				t.Fatalf("%s ran with Resumed %v", want, reqCtx.Resumed)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s didn't run", want)
+		}
+	}
+	for _, result := range []<-chan *types.Task{low, high} {
+		if task := <-result; task == nil || task.Status.State != types.TaskCompleted {
+			t.Fatalf("task = %+v, want it completed", task)
+		}
+	}
+	if stats := pool.Stats()[PriorityLow]; stats.Paused != 1 {
+		t.Fatalf("low priority stats = %+v, want Paused 1", stats)
+	}
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AgentCapabilitiesMethod is the optional JSON-RPC method returning the live capabilities of
+// an agent, beyond what its static card declares
+const AgentCapabilitiesMethod = "agent/capabilities"
+
+// AgentCapabilitiesParams lists the protocol versions the client speaks, for the agent to pick one
+type AgentCapabilitiesParams struct {
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+}
+
+// AgentLimits are the limits an agent enforces; zero means unlimited or not reported
+type AgentLimits struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"` // Sustained request rate per caller
+	RequestBurst      int     `json:"requestBurst,omitempty"`      // Requests per caller allowed at once
+	MaxListTasks      int     `json:"maxListTasks,omitempty"`      // Largest page of tasks/list
+	MaxBatchTasks     int     `json:"maxBatchTasks,omitempty"`     // Most ids of tasks/batchGet and tasks/batchCancel
+}
+
+// AgentCapabilitiesResult describes what an agent supports right now
+type AgentCapabilitiesResult struct {
+	ProtocolVersions []string          `json:"protocolVersions"`
+	ProtocolVersion  string            `json:"protocolVersion,omitempty"` // Highest version shared with the client, if any
+	Methods          []string          `json:"methods"`                   // JSON-RPC methods served, including custom ones
+	Capabilities     AgentCapabilities `json:"capabilities"`
+	Limits           AgentLimits       `json:"limits"`
+}
+
+type AgentCapabilitiesResponse struct {
+	Result *AgentCapabilitiesResult `json:"result,omitempty"`
+}
+
+// SupportsMethod reports whether the agent serves method
+func (r *AgentCapabilitiesResult) SupportsMethod(method string) bool {
+	for _, m := range r.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateProtocolVersion returns the highest version both supported and offered, or "" if
+// they share none
+func NegotiateProtocolVersion(supported, offered []string) string {
+	var best string
+	for _, s := range supported {
+		for _, o := range offered {
+			if CompareProtocolVersions(s, o) == 0 && (best == "" || CompareProtocolVersions(s, best) > 0) {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// CompareProtocolVersions compares dotted numeric versions, treating missing or invalid
+// components as 0
+func CompareProtocolVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,222 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// JSON-RPC error codes
+const (
+	ParseErrorCode          = -32700
+	InvalidRequestErrorCode = -32600
+	MethodNotFoundErrorCode = -32601
+	InvalidParamsErrorCode  = -32602
+	InternalErrorCode       = -32603
+)
+
+// A2A JSON-RPC error codes
+const (
+	TaskNotFoundErrorCode                 = -32001
+	TaskNotCancelableErrorCode            = -32002
+	PushNotificationNotSupportedErrorCode = -32003
+	UnsupportedOperationErrorCode         = -32004
+	ContentTypeNotSupportedErrorCode      = -32005
+	InvalidAgentResponseErrorCode         = -32006
+)
+
+// JSON-RPC error codes of this implementation beyond the A2A ones
+const (
+	// TimeoutErrorCode is returned when a request exceeds its timeoutMs
+	TimeoutErrorCode = -32010
+	// ThrottledErrorCode is returned when a request is rate limited or the agent is
+	// saturated; the error data carries RetryAfterMsKey
+	ThrottledErrorCode = -32029
+	// PermissionDeniedErrorCode is returned for requests the agent's authorizer denied,
+	// with HTTP status 403
+	PermissionDeniedErrorCode = -32040
+)
+
+// TaskErrorData is the data of errors about a task: task not found, task not cancelable and
+// unsupported operation
+type TaskErrorData struct {
+	TaskID string    `json:"taskId"`
+	State  TaskState `json:"state,omitempty"` // State of the task when it didn't allow the operation
+}
+
+// ContentTypeErrorData is the data of content type not supported errors
+type ContentTypeErrorData struct {
+	TaskID   string   `json:"taskId,omitempty"`
+	Offered  []string `json:"offered"`  // Output modes the agent produced
+	Accepted []string `json:"accepted"` // Output modes the client accepts
+}
+
+// ArtifactErrorData is the data of errors about one artifact of a task
+type ArtifactErrorData struct {
+	TaskID string `json:"taskId"`
+	Index  int    `json:"index"`
+}
+
+// MetadataErrorData is the data of invalid params errors for task metadata that doesn't
+// match the metadata schema of the skill
+type MetadataErrorData struct {
+	SkillID    string            `json:"skillId"`
+	Violations []ValidationError `json:"violations"`
+}
+
+// ParamsErrorData is the data of invalid params errors for request params that can't be
+// decoded or fail validation
+type ParamsErrorData struct {
+	Method     string            `json:"method"`
+	Violations []ValidationError `json:"violations"`
+}
+
+// ParseError builds the error for a request body that isn't valid JSON
+func ParseError(message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    ParseErrorCode,
+		Message: "Parse error: " + message,
+	}
+}
+
+// InvalidRequestError builds the error for a request that isn't a valid JSON-RPC request
+func InvalidRequestError(message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    InvalidRequestErrorCode,
+		Message: "Invalid request: " + message,
+	}
+}
+
+// MethodNotFoundError builds the error for a method the agent doesn't know or support
+func MethodNotFoundError(method string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    MethodNotFoundErrorCode,
+		Message: "Method not found",
+		Data:    map[string]interface{}{"method": method},
+	}
+}
+
+// InvalidParamsError builds the error for request params that don't fit the method
+func InvalidParamsError(message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    InvalidParamsErrorCode,
+		Message: "Invalid params: " + message,
+	}
+}
+
+// InternalError builds the error for a request the agent failed to handle
+func InternalError(message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    InternalErrorCode,
+		Message: message,
+	}
+}
+
+// TaskNotFoundError builds the error for a task that doesn't exist
+func TaskNotFoundError(taskID string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    TaskNotFoundErrorCode,
+		Message: "Task not found",
+		Data:    &TaskErrorData{TaskID: taskID},
+	}
+}
+
+// TaskNotCancelableError builds the error for a task that can't be canceled in its state
+func TaskNotCancelableError(taskID string, state TaskState) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    TaskNotCancelableErrorCode,
+		Message: "Task is " + string(state) + " and cannot be canceled",
+		Data:    &TaskErrorData{TaskID: taskID, State: state},
+	}
+}
+
+// UnsupportedOperationError builds the error for an operation a task doesn't allow in its state
+func UnsupportedOperationError(message, taskID string, state TaskState) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    UnsupportedOperationErrorCode,
+		Message: message,
+		Data:    &TaskErrorData{TaskID: taskID, State: state},
+	}
+}
+
+// PushNotificationNotSupportedError builds the error for push notification requests to an
+// agent without the pushNotifications capability
+func PushNotificationNotSupportedError() *JSONRPCError {
+	return &JSONRPCError{
+		Code:    PushNotificationNotSupportedErrorCode,
+		Message: "Push notifications are not supported",
+	}
+}
+
+// ContentTypeNotSupportedError builds the error for output none of the accepted modes can carry
+func ContentTypeNotSupportedError(taskID string, offered, accepted []string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    ContentTypeNotSupportedErrorCode,
+		Message: "Incompatible content types",
+		Data:    &ContentTypeErrorData{TaskID: taskID, Offered: offered, Accepted: accepted},
+	}
+}
+
+// InvalidAgentResponseError builds the error for an agent response that violates the protocol
+func InvalidAgentResponseError(message string) *JSONRPCError {
+	return &JSONRPCError{
+		Code:    InvalidAgentResponseErrorCode,
+		Message: "Invalid agent response: " + message,
+	}
+}
+
+// DecodeData decodes the error data into v, whether it was received as JSON or set by the
+// server as one of the data types
+func (e *JSONRPCError) DecodeData(v interface{}) error {
+	if e == nil || e.Data == nil {
+		return errors.New("error has no data")
+	}
+	encoded, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// TaskErrorData returns the data of task not found, task not cancelable and unsupported
+// operation errors
+func (e *JSONRPCError) TaskErrorData() (*TaskErrorData, bool) {
+	if e == nil {
+		return nil, false
+	}
+	switch e.Code {
+	case TaskNotFoundErrorCode, TaskNotCancelableErrorCode, UnsupportedOperationErrorCode:
+	default:
+		return nil, false
+	}
+	var data TaskErrorData
+	if e.DecodeData(&data) != nil || data.TaskID == "" {
+		return nil, false
+	}
+	return &data, true
+}
+
+// ContentTypeErrorData returns the data of content type not supported errors
+func (e *JSONRPCError) ContentTypeErrorData() (*ContentTypeErrorData, bool) {
+	if e == nil || e.Code != ContentTypeNotSupportedErrorCode {
+		return nil, false
+	}
+	var data ContentTypeErrorData
+	if e.DecodeData(&data) != nil {
+		return nil, false
+	}
+	return &data, true
+}
+
+// AsJSONRPCError returns the JSON-RPC error in err's chain, including the one carried by an
+// A2AClientHTTPError
+func AsJSONRPCError(err error) (*JSONRPCError, bool) {
+	var rpcErr *JSONRPCError
+	if errors.As(err, &rpcErr) && rpcErr != nil {
+		return rpcErr, true
+	}
+	var httpErr *A2AClientHTTPError
+	if errors.As(err, &httpErr) && httpErr.RPCError != nil {
+		return httpErr.RPCError, true
+	}
+	return nil, false
+}
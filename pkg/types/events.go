@@ -0,0 +1,181 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// SendTaskStreamingMethod is the JSON-RPC method sending a task and streaming its events
+	SendTaskStreamingMethod = "send_task_streaming"
+	// ReplayTaskMethod is the JSON-RPC method streaming the recorded events of a task again
+	ReplayTaskMethod = "tasks/replay"
+)
+
+// TaskEvent is a decoded streaming event. Exactly one of Status, Artifact or Error is set.
+type TaskEvent struct {
+	RequestID   interface{}
+	Status      *TaskStatusUpdateEvent
+	Artifact    *TaskArtifactUpdateEvent
+	Error       *JSONRPCError
+	DecodeError *DecodeError // Set with Error for an event that failed to decode
+}
+
+// DecodeError is the error of a streaming event that failed to decode. Streams report it
+// and go on with the next event.
+type DecodeError struct {
+	EventID string // SSE id of the event, "" if none was sent
+	Data    []byte // Raw data of the event
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode event: %v", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// JSONRPCError converts the error into the error of a streaming response
+func (e *DecodeError) JSONRPCError() *JSONRPCError {
+	return InternalError(e.Error())
+}
+
+// IsStatus reports whether the event is a status update
+func (e TaskEvent) IsStatus() bool {
+	return e.Status != nil
+}
+
+// IsArtifact reports whether the event is an artifact update
+func (e TaskEvent) IsArtifact() bool {
+	return e.Artifact != nil
+}
+
+// IsError reports whether the event carries an error
+func (e TaskEvent) IsError() bool {
+	return e.Error != nil
+}
+
+// IsDecodeError reports whether the event failed to decode; the stream continues after it
+func (e TaskEvent) IsDecodeError() bool {
+	return e.DecodeError != nil
+}
+
+// IsFinal reports whether the event ends the stream
+func (e TaskEvent) IsFinal() bool {
+	return (e.Error != nil && e.DecodeError == nil) || (e.Status != nil && e.Status.Final)
+}
+
+// TaskID returns the id of the task the event belongs to
+func (e TaskEvent) TaskID() string {
+	switch {
+	case e.Status != nil:
+		return e.Status.ID
+	case e.Artifact != nil:
+		return e.Artifact.ID
+	}
+	return ""
+}
+
+// Seq returns the event cursor of the event, or 0 if the agent doesn't number events
+func (e TaskEvent) Seq() int {
+	switch {
+	case e.Status != nil:
+		return e.Status.Seq
+	case e.Artifact != nil:
+		return e.Artifact.Seq
+	}
+	return 0
+}
+
+// IsHeartbeat reports whether the event is a heartbeat, sent while a task works without
+// other updates
+func (e TaskEvent) IsHeartbeat() bool {
+	return e.Status != nil && e.Status.IsHeartbeat()
+}
+
+// IsHeartbeat reports whether the event is a heartbeat of a working task
+func (e *TaskStatusUpdateEvent) IsHeartbeat() bool {
+	heartbeat, _ := e.Metadata[HeartbeatMetadataKey].(bool)
+	return heartbeat
+}
+
+// Progress returns the completion percentage carried by the event's metadata
+func (e *TaskStatusUpdateEvent) Progress() (float64, bool) {
+	return MetadataProgress(e.Metadata)
+}
+
+// ProgressNote returns the step description carried by the event's metadata
+func (e *TaskStatusUpdateEvent) ProgressNote() string {
+	note, _ := e.Metadata[ProgressNoteMetadataKey].(string)
+	return note
+}
+
+// MetadataProgress returns the completion percentage of task or event metadata
+func MetadataProgress(metadata map[string]interface{}) (float64, bool) {
+	switch v := metadata[ProgressMetadataKey].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// State returns the task state carried by a status event, or TaskUnknown
+func (e TaskEvent) State() TaskState {
+	if e.Status == nil {
+		return TaskUnknown
+	}
+	return e.Status.Status.State
+}
+
+// DecodeTaskEvent converts a raw streaming response into a TaskEvent
+func DecodeTaskEvent(response *SendTaskStreamingResponse) (TaskEvent, error) {
+	event := TaskEvent{RequestID: response.ID}
+	if response.Error != nil {
+		event.Error = response.Error
+		event.DecodeError = response.DecodeError
+		return event, nil
+	}
+
+	switch result := response.Result.(type) {
+	case *TaskStatusUpdateEvent:
+		event.Status = result
+		return event, nil
+	case *TaskArtifactUpdateEvent:
+		event.Artifact = result
+		return event, nil
+	case nil:
+		return event, fmt.Errorf("streaming response has neither result nor error")
+	}
+
+	raw, err := json.Marshal(response.Result)
+	if err != nil {
+		return event, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return event, fmt.Errorf("event is not an object: %w", err)
+	}
+
+	switch {
+	case fields["status"] != nil:
+		var status TaskStatusUpdateEvent
+		if err := json.Unmarshal(raw, &status); err != nil {
+			return event, fmt.Errorf("failed to decode status event: %w", err)
+		}
+		event.Status = &status
+	case fields["artifact"] != nil:
+		var artifact TaskArtifactUpdateEvent
+		if err := json.Unmarshal(raw, &artifact); err != nil {
+			return event, fmt.Errorf("failed to decode artifact event: %w", err)
+		}
+		event.Artifact = &artifact
+	default:
+		return event, fmt.Errorf("unknown event shape: %s", raw)
+	}
+	return event, nil
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// ExtensionsHeader lists the URIs of the extensions a request activates. Servers answer with
+// the extensions they activated.
+const ExtensionsHeader = "X-A2A-Extensions"
+
+// AgentExtension declares a protocol extension supported by an agent
+type AgentExtension struct {
+	URI         string                 `json:"uri"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"` // Clients must activate it to talk to the agent
+	Params      map[string]interface{} `json:"params,omitempty"`   // Extension specific configuration
+}
+
+// Extension returns the extension the card declares for uri, or nil
+func (c *AgentCard) Extension(uri string) *AgentExtension {
+	for i := range c.Capabilities.Extensions {
+		if c.Capabilities.Extensions[i].URI == uri {
+			return &c.Capabilities.Extensions[i]
+		}
+	}
+	return nil
+}
+
+// ParseExtensionsHeader returns the distinct extension URIs of ExtensionsHeader values
+func ParseExtensionsHeader(values []string) []string {
+	var uris []string
+	for _, value := range values {
+		for _, uri := range strings.Split(value, ",") {
+			uri = strings.TrimSpace(uri)
+			if uri != "" && !slices.Contains(uris, uri) {
+				uris = append(uris, uri)
+			}
+		}
+	}
+	return uris
+}
+
+// FormatExtensionsHeader returns the ExtensionsHeader value listing uris
+func FormatExtensionsHeader(uris []string) string {
+	return strings.Join(uris, ", ")
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DefaultJWKSPath is where agents that don't declare a jwksUrl serve their push notification keys
+const DefaultJWKSPath = "/.well-known/jwks.json"
+
+// ResolveJWKSURL returns the absolute URL of the keys signing the agent's push notifications.
+// A relative jwksUrl is resolved against the card URL; without one, fallback is returned.
+func (c *AgentCard) ResolveJWKSURL(fallback string) (string, error) {
+	if c.JWKSURL == nil || *c.JWKSURL == "" {
+		return fallback, nil
+	}
+	ref, err := url.Parse(*c.JWKSURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid jwksUrl: %w", err)
+	}
+	if ref.IsAbs() {
+		return ref.String(), nil
+	}
+	base, err := url.Parse(c.URL)
+	if err != nil || !base.IsAbs() {
+		return "", fmt.Errorf("cannot resolve relative jwksUrl %q against agent URL %q", *c.JWKSURL, c.URL)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
@@ -0,0 +1,24 @@
+package types
+
+// PollTaskEventsMethod is the JSON-RPC method long-polling the events of a task, for
+// clients behind intermediaries that break SSE streams
+const PollTaskEventsMethod = "tasks/events"
+
+// TaskEventsParams asks for the events of a task after a cursor
+type TaskEventsParams struct {
+	ID     string `json:"id"`
+	Cursor int    `json:"cursor,omitempty"` // Seq of the last event received, 0 for all buffered events
+	Wait   int    `json:"wait,omitempty"`   // Seconds to block while no new events are buffered
+}
+
+// TaskEvents is a batch of polled task events
+type TaskEvents struct {
+	Events    []StreamEventRecord `json:"events"`
+	Cursor    int                 `json:"cursor"`              // Cursor of the next poll
+	Final     bool                `json:"final,omitempty"`     // The task's final event was delivered, polling can stop
+	Truncated bool                `json:"truncated,omitempty"` // Events after the cursor were dropped from the buffer; get_task to resync
+}
+
+type PollTaskEventsResponse struct {
+	Result *TaskEvents `json:"result,omitempty"`
+}
@@ -0,0 +1,236 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// Most messages only hold text, so Message decodes those by hand instead of going through
+// reflection and the extra pass Parts makes to find each part's type. Anything else,
+// including parts with metadata, takes the general encoding/json path. Encoding stays with encoding/json:
+// it validates the output of MarshalJSON methods again, which costs more than reflection
+// saves for all but the shortest texts.
+
+// plainMessage has the fields of Message without its UnmarshalJSON method
+type plainMessage Message
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	if decodeTextMessage(data, m) {
+		return nil
+	}
+	var plain plainMessage
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+	*m = Message(plain)
+	return nil
+}
+
+// decodeTextMessage decodes a message whose parts are all text parts without metadata into
+// m. It returns false, leaving m untouched, for anything else.
+func decodeTextMessage(data []byte, m *Message) bool {
+	d := textDecoder{data: data}
+	if !d.consume('{') {
+		return false
+	}
+	var role string
+	var parts Parts
+	var metadata map[string]interface{}
+	var hasRole, hasParts bool
+	for first := true; !d.consume('}'); first = false {
+		if !first && !d.consume(',') {
+			return false
+		}
+		key, ok := d.string()
+		if !ok || !d.consume(':') {
+			return false
+		}
+		switch key {
+		case "role":
+			if role, ok = d.string(); !ok {
+				return false
+			}
+			hasRole = true
+		case "parts":
+			if parts, ok = d.textParts(); !ok {
+				return false
+			}
+			hasParts = true
+		case "metadata":
+			raw, ok := d.value()
+			if !ok || json.Unmarshal(raw, &metadata) != nil {
+				return false
+			}
+		default:
+			// Keys matched case-insensitively by encoding/json
+			return false
+		}
+	}
+	d.skipSpace()
+	if !hasRole || !hasParts || d.pos != len(d.data) {
+		return false
+	}
+	*m = Message{Role: role, Parts: parts, Metadata: metadata}
+	return true
+}
+
+// textDecoder reads the JSON of text messages
+type textDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *textDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips c and the space before it, if c is next
+func (d *textDecoder) consume(c byte) bool {
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == c {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// string reads a string. Strings with escapes are unquoted by encoding/json.
+func (d *textDecoder) string() (string, bool) {
+	if !d.consume('"') {
+		return "", false
+	}
+	start := d.pos
+	end := bytes.IndexByte(d.data[start:], '"')
+	if end < 0 {
+		return "", false
+	}
+	raw := d.data[start : start+end]
+	if bytes.IndexByte(raw, '\\') < 0 {
+		// encoding/json checked the data for control characters before calling UnmarshalJSON
+		d.pos = start + end + 1
+		if !utf8.Valid(raw) {
+			return d.unquote(start)
+		}
+		return internedString(raw), true
+	}
+
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case '"':
+			d.pos++
+			return d.unquote(start)
+		case '\\':
+			d.pos += 2
+		default:
+			d.pos++
+		}
+	}
+	return "", false
+}
+
+// unquote decodes the string from start to d.pos with encoding/json, which handles escapes
+// and invalid UTF-8
+func (d *textDecoder) unquote(start int) (string, bool) {
+	var s string
+	if err := json.Unmarshal(d.data[start-1:d.pos], &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// internedString returns the common values of roles and part types without allocating
+func internedString(raw []byte) string {
+	switch string(raw) {
+	case "text":
+		return "text"
+	case "user":
+		return "user"
+	case "agent":
+		return "agent"
+	}
+	return string(raw)
+}
+
+// value skips any value, returning its JSON
+func (d *textDecoder) value() ([]byte, bool) {
+	d.skipSpace()
+	start, depth := d.pos, 0
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; {
+		case c == '"':
+			if _, ok := d.string(); !ok {
+				return nil, false
+			}
+			continue
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth == 0 {
+				return d.data[start:d.pos], d.pos > start
+			}
+			depth--
+		case c == ',' && depth == 0:
+			return d.data[start:d.pos], d.pos > start
+		}
+		d.pos++
+	}
+	return nil, false
+}
+
+// textParts reads an array of text parts
+func (d *textDecoder) textParts() (Parts, bool) {
+	if !d.consume('[') {
+		return nil, false
+	}
+	parts := Parts{}
+	for first := true; !d.consume(']'); first = false {
+		if !first && !d.consume(',') {
+			return nil, false
+		}
+		part, ok := d.textPart()
+		if !ok {
+			return nil, false
+		}
+		parts = append(parts, part)
+	}
+	return parts, true
+}
+
+// textPart reads a part with type "text" and text, and no other keys
+func (d *textDecoder) textPart() (TextPart, bool) {
+	var part TextPart
+	if !d.consume('{') {
+		return part, false
+	}
+	var hasText bool
+	for first := true; !d.consume('}'); first = false {
+		if !first && !d.consume(',') {
+			return part, false
+		}
+		key, ok := d.string()
+		if !ok || !d.consume(':') {
+			return part, false
+		}
+		value, ok := d.string()
+		if !ok {
+			return part, false
+		}
+		switch key {
+		case "type":
+			part.Type = value
+		case "text":
+			part.Text, hasText = value, true
+		default:
+			return part, false
+		}
+	}
+	return part, part.Type == "text" && hasText
+}
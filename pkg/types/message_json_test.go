@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMessageUnmarshalTextFastPath(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		fast bool // Whether the text fast path decodes it
+	}{
+		{"text", `{"role":"user","parts":[{"type":"text","text":"hello"}]}`, true},
+		{"spaces", ` { "role" : "agent" , "parts" : [ { "text" : "a" , "type" : "text" } , {"type":"text","text":"b"} ] } `, true},
+		{"escapes", `{"role":"user","parts":[{"type":"text","text":"line\n\"quoted\" é"}]}`, true},
+		{"invalid utf-8", "{\"role\":\"user\",\"parts\":[{\"type\":\"text\",\"text\":\"\xff\"}]}", true},
+		{"no parts", `{"role":"user","parts":[]}`, true},
+		{"metadata", `{"role":"user","parts":[{"type":"text","text":"hi"}],"metadata":{"k":["v",1,{"n":null}]}}`, true},
+		{"part metadata", `{"role":"user","parts":[{"type":"text","text":"hi","metadata":{"k":"v"}}]}`, false},
+		{"extensions", `{"role":"user","parts":[{"type":"text","text":"hi"}],"extensions":["urn:x"]}`, false},
+		{"data part", `{"role":"user","parts":[{"type":"data","data":{"k":"v"}}]}`, false},
+		{"key case", `{"Role":"user","parts":[{"type":"text","text":"hi"}]}`, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var fast Message
+			if ok := decodeTextMessage([]byte(tt.data), &fast); ok != tt.fast {
+				t.Fatalf("fast path decoded it: %v, want %v", ok, tt.fast)
+			}
+
+			var got Message
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			var plain plainMessage
+			if err := json.Unmarshal([]byte(tt.data), &plain); err != nil {
+				t.Fatalf("Unmarshal without the fast path: %v", err)
+			}
+			if want := Message(plain); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Unmarshal = %#v, want %#v", got, want)
+			}
+
+			// Encoding the result and decoding it again yields the same message
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var again Message
+			if err := json.Unmarshal(data, &again); err != nil {
+				t.Fatalf("Unmarshal of %s: %v", data, err)
+			}
+			if !reflect.DeepEqual(again, got) {
+				t.Fatalf("round trip = %#v, want %#v", again, got)
+			}
+		})
+	}
+}
+
+func TestMessageUnmarshalInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"role":"user","parts":[{"type":"text","text":"hi"}]`,
+		`{"role":"user","parts":[{"type":"text","text":"hi"},]}`,
+		`{"role":"user","parts":{"type":"text"}}`,
+	} {
+		var m Message
+		if err := json.Unmarshal([]byte(data), &m); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", data)
+		}
+	}
+}
+
+func BenchmarkMessageUnmarshal(b *testing.B) {
+	message := Message{Role: "user"}
+	for i := 0; i < 4; i++ {
+		message.Parts = append(message.Parts, TextPart{Type: "text", Text: strings.Repeat("lorem ipsum ", 22)})
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("text", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			var m Message
+			if err := json.Unmarshal(data, &m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			var m plainMessage
+			if err := json.Unmarshal(data, &m); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
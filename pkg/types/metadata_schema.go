@@ -0,0 +1,353 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SkillIDMetadataKey is the task metadata key naming the skill a task is sent to
+const SkillIDMetadataKey = "skillId"
+
+// Skill returns the skill of the card with id, or nil if it has none
+func (c *AgentCard) Skill(id string) *AgentSkill {
+	for i := range c.Skills {
+		if c.Skills[i].ID == id {
+			return &c.Skills[i]
+		}
+	}
+	return nil
+}
+
+// SetMetadataSchema registers the JSON Schema the task metadata of a skill must match, so
+// it is advertised in the card and enforced by servers
+func (c *AgentCard) SetMetadataSchema(skillID string, schema map[string]interface{}) error {
+	skill := c.Skill(skillID)
+	if skill == nil {
+		return fmt.Errorf("agent card has no skill %q", skillID)
+	}
+	normalized, ok := normalizeJSON(schema).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("metadata schema of skill %q is not a JSON object", skillID)
+	}
+	if err := CheckSchema(normalized); err != nil {
+		return fmt.Errorf("metadata schema of skill %q: %v", skillID, err)
+	}
+	skill.MetadataSchema = normalized
+	return nil
+}
+
+// MetadataSkill returns the skill task metadata is sent to: the one named by
+// SkillIDMetadataKey, or the only skill of the card. It returns nil if there is none.
+func (c *AgentCard) MetadataSkill(metadata map[string]interface{}) *AgentSkill {
+	if id, ok := metadata[SkillIDMetadataKey].(string); ok {
+		return c.Skill(id)
+	}
+	if len(c.Skills) == 1 {
+		return &c.Skills[0]
+	}
+	return nil
+}
+
+// RequestedSkill returns the id of the skill a task is sent to, from the skillId param or
+// else the SkillIDMetadataKey of the metadata, or "" if the client didn't name one
+func (p *TaskSendParams) RequestedSkill() string {
+	if p.SkillID != "" {
+		return p.SkillID
+	}
+	id, _ := p.Metadata[SkillIDMetadataKey].(string)
+	return id
+}
+
+// ValidateMetadata checks task metadata against the metadata schema of a skill. Every
+// violation is returned as a ValidationError, with the path of the offending value as field.
+func ValidateMetadata(skill *AgentSkill, metadata map[string]interface{}) error {
+	if skill == nil || skill.MetadataSchema == nil {
+		return nil
+	}
+	schema, ok := normalizeJSON(skill.MetadataSchema).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	value := interface{}(metadata)
+	if metadata == nil {
+		value = map[string]interface{}{}
+	}
+	return errors.Join(validateSchema("metadata", schema, normalizeJSON(value))...)
+}
+
+// CheckSchema reports keywords of a decoded JSON schema with values of the wrong kind, so
+// broken schemas are found when registered rather than when the first task is rejected
+func CheckSchema(schema map[string]interface{}) error {
+	for _, keyword := range []string{"properties", "items", "additionalProperties", "not"} {
+		switch v := schema[keyword].(type) {
+		case nil, bool:
+		case map[string]interface{}:
+			if keyword != "properties" {
+				if err := CheckSchema(v); err != nil {
+					return err
+				}
+				continue
+			}
+			for name, property := range v {
+				sub, ok := property.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("property %q is not a schema", name)
+				}
+				if err := CheckSchema(sub); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%s is not a schema", keyword)
+		}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	if _, ok := schema["required"]; ok {
+		if _, ok := stringList(schema["required"]); !ok {
+			return errors.New("required is not a list of strings")
+		}
+	}
+	return nil
+}
+
+// validateSchema checks value at path against a subset of JSON Schema: type, enum, const,
+// properties, required, additionalProperties, items, the length, size and range bounds,
+// pattern, allOf, anyOf, oneOf and not
+func validateSchema(path string, schema map[string]interface{}, value interface{}) []error {
+	violation := func(format string, args ...interface{}) []error {
+		return []error{&ValidationError{Field: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if types, ok := schemaTypes(schema["type"]); ok && !matchesType(types, value) {
+		return violation("must be of type %s, got %s", strings.Join(types, " or "), jsonType(value))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsJSON(enum, value) {
+		return violation("must be one of %s", encodeJSON(enum))
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(normalizeJSON(constant), value) {
+		return violation("must be %s", encodeJSON(constant))
+	}
+
+	var errs []error
+	switch v := value.(type) {
+	case map[string]interface{}:
+		errs = append(errs, validateObject(path, schema, v)...)
+	case []interface{}:
+		if n, ok := schemaInt(schema["minItems"]); ok && len(v) < n {
+			errs = append(errs, violation("must have at least %d items", n)...)
+		}
+		if n, ok := schemaInt(schema["maxItems"]); ok && len(v) > n {
+			errs = append(errs, violation("must have at most %d items", n)...)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errs = append(errs, validateSchema(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if n, ok := schemaInt(schema["minLength"]); ok && length < n {
+			errs = append(errs, violation("must be at least %d characters long", n)...)
+		}
+		if n, ok := schemaInt(schema["maxLength"]); ok && length > n {
+			errs = append(errs, violation("must be at most %d characters long", n)...)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				errs = append(errs, violation("must match %s", pattern)...)
+			}
+		}
+	case float64:
+		if n, ok := schema["minimum"].(float64); ok && v < n {
+			errs = append(errs, violation("must be at least %v", n)...)
+		}
+		if n, ok := schema["maximum"].(float64); ok && v > n {
+			errs = append(errs, violation("must be at most %v", n)...)
+		}
+		if n, ok := schema["exclusiveMinimum"].(float64); ok && v <= n {
+			errs = append(errs, violation("must be greater than %v", n)...)
+		}
+		if n, ok := schema["exclusiveMaximum"].(float64); ok && v >= n {
+			errs = append(errs, violation("must be less than %v", n)...)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if s, ok := sub.(map[string]interface{}); ok {
+				errs = append(errs, validateSchema(path, s, value)...)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && countMatches(path, anyOf, value) == 0 {
+		errs = append(errs, violation("must match at least one of the anyOf schemas")...)
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok && countMatches(path, oneOf, value) != 1 {
+		errs = append(errs, violation("must match exactly one of the oneOf schemas")...)
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(validateSchema(path, not, value)) == 0 {
+		errs = append(errs, violation("must not match the not schema")...)
+	}
+	return errs
+}
+
+// validateObject checks the properties of an object
+func validateObject(path string, schema map[string]interface{}, object map[string]interface{}) []error {
+	var errs []error
+	required, _ := stringList(schema["required"])
+	for _, name := range required {
+		if _, ok := object[name]; !ok {
+			errs = append(errs, &ValidationError{Field: path + "." + name, Message: "is required"})
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			errs = append(errs, validateSchema(path+"."+name, property, object[name])...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				errs = append(errs, &ValidationError{Field: path + "." + name, Message: "is not allowed"})
+			}
+		case map[string]interface{}:
+			errs = append(errs, validateSchema(path+"."+name, additional, object[name])...)
+		}
+	}
+	return errs
+}
+
+// countMatches returns how many of schemas value matches
+func countMatches(path string, schemas []interface{}, value interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		if s, ok := sub.(map[string]interface{}); ok && len(validateSchema(path, s, value)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// schemaTypes returns the types allowed by a "type" keyword
+func schemaTypes(v interface{}) ([]string, bool) {
+	if t, ok := v.(string); ok {
+		return []string{t}, true
+	}
+	return stringList(v)
+}
+
+func stringList(v interface{}) ([]string, bool) {
+	switch list := v.(type) {
+	case []string:
+		return list, true
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			strs = append(strs, s)
+		}
+		return strs, true
+	}
+	return nil, false
+}
+
+func schemaInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
+
+// matchesType reports whether value has one of the JSON types
+func matchesType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		switch {
+		case t == actual:
+			return true
+		case t == "number" && actual == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containsJSON(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(normalizeJSON(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeJSON converts a Go value into its decoded JSON form, so metadata built in Go
+// with ints or structs validates like metadata received over the wire
+func normalizeJSON(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, bool, string, float64:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func encodeJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
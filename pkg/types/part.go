@@ -0,0 +1,110 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Part is a part of a message or artifact: a TextPart, FilePart or DataPart, a pointer to
+// one, or an UnknownPart. Parts decoded from JSON are concrete values, so code can type
+// switch on them.
+type Part interface {
+	PartType() string
+}
+
+func (TextPart) PartType() string { return "text" }
+
+func (FilePart) PartType() string { return "file" }
+
+func (DataPart) PartType() string { return "data" }
+
+// UnknownPart is a part of a type this package doesn't know, kept as received so it is
+// passed on unchanged
+type UnknownPart struct {
+	Type string
+	JSON json.RawMessage
+}
+
+func (p UnknownPart) PartType() string { return p.Type }
+
+func (p UnknownPart) MarshalJSON() ([]byte, error) {
+	return p.JSON, nil
+}
+
+// Parts are the parts of a message or artifact, decoded by their "type" discriminator
+type Parts []Part
+
+func (p *Parts) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	if raws == nil {
+		*p = nil
+		return nil
+	}
+	parts := make(Parts, 0, len(raws))
+	for i, raw := range raws {
+		part, err := UnmarshalPart(raw)
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+		parts = append(parts, part)
+	}
+	*p = parts
+	return nil
+}
+
+// UnmarshalPart decodes a part into the concrete type its "type" names, or an UnknownPart
+func UnmarshalPart(data []byte) (Part, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errors.New("part must be an object with a string type")
+	}
+	switch header.Type {
+	case "text":
+		var part TextPart
+		err := json.Unmarshal(data, &part)
+		return part, err
+	case "file":
+		var part FilePart
+		err := json.Unmarshal(data, &part)
+		return part, err
+	case "data":
+		var part DataPart
+		err := json.Unmarshal(data, &part)
+		return part, err
+	}
+	return UnknownPart{Type: header.Type, JSON: append(json.RawMessage(nil), data...)}, nil
+}
+
+// AsPart converts a part held in an any, including one decoded into a map, e.g. from
+// untyped request params. It returns false for values that aren't parts.
+func AsPart(v any) (Part, bool) {
+	switch p := v.(type) {
+	case *TextPart:
+		return p, p != nil
+	case *FilePart:
+		return p, p != nil
+	case *DataPart:
+		return p, p != nil
+	case Part:
+		return p, true
+	case map[string]interface{}:
+		data, err := json.Marshal(p)
+		if err != nil {
+			return nil, false
+		}
+		part, err := UnmarshalPart(data)
+		return part, err == nil
+	}
+	return nil, false
+}
+
+// NewTextPart returns a text part holding text
+func NewTextPart(text string) TextPart {
+	return TextPart{Type: "text", Text: text}
+}
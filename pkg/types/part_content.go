@@ -0,0 +1,172 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MimeTypeMetadataKey is the part metadata key used to declare a TextPart's content type (e.g. "text/markdown")
+const MimeTypeMetadataKey = "mimeType"
+
+// PartContent is the renderable content of a message or artifact part, whether the part
+// is typed or was decoded from JSON into a map
+type PartContent struct {
+	Mode string                 // Output mode, e.g. "text", "text/markdown", "image/png", "file" or "data"
+	Text string                 // Set for text parts
+	File *FileContent           // Set for file parts
+	Data map[string]interface{} // Set for data parts
+	Part any                    // The part itself
+}
+
+// IsText reports whether the content is a text part
+func (c PartContent) IsText() bool {
+	return c.File == nil && c.Data == nil && c.Mode != ""
+}
+
+// PartMode returns the output mode of a message or artifact part, or "" if it is not a known part
+func PartMode(part any) string {
+	content, ok := DecodePart(part)
+	if !ok {
+		return ""
+	}
+	return content.Mode
+}
+
+// DecodePart extracts the content of a typed or map part
+func DecodePart(part any) (PartContent, bool) {
+	switch p := part.(type) {
+	case TextPart:
+		return PartContent{Mode: textPartMode(p.Metadata), Text: p.Text, Part: part}, true
+	case *TextPart:
+		return PartContent{Mode: textPartMode(p.Metadata), Text: p.Text, Part: part}, true
+	case FilePart:
+		file := p.File
+		return PartContent{Mode: filePartMode(file.MimeType), File: &file, Part: part}, true
+	case *FilePart:
+		return PartContent{Mode: filePartMode(p.File.MimeType), File: &p.File, Part: part}, true
+	case DataPart:
+		return PartContent{Mode: "data", Data: nonNilData(p.Data), Part: part}, true
+	case *DataPart:
+		return PartContent{Mode: "data", Data: nonNilData(p.Data), Part: part}, true
+	case map[string]interface{}:
+		partType, _ := p["type"].(string)
+		switch partType {
+		case "text":
+			text, _ := p["text"].(string)
+			metadata, _ := p["metadata"].(map[string]interface{})
+			return PartContent{Mode: textPartMode(metadata), Text: text, Part: part}, true
+		case "file":
+			var file FileContent
+			if data, err := json.Marshal(p["file"]); err == nil {
+				_ = json.Unmarshal(data, &file)
+			}
+			return PartContent{Mode: filePartMode(file.MimeType), File: &file, Part: part}, true
+		case "data":
+			data, _ := p["data"].(map[string]interface{})
+			return PartContent{Mode: "data", Data: nonNilData(data), Part: part}, true
+		}
+	}
+	return PartContent{}, false
+}
+
+func textPartMode(metadata map[string]interface{}) string {
+	if mimeType, ok := metadata[MimeTypeMetadataKey].(string); ok && mimeType != "" {
+		return mimeType
+	}
+	return "text"
+}
+
+func filePartMode(mimeType *string) string {
+	if mimeType != nil && *mimeType != "" {
+		return *mimeType
+	}
+	return "file"
+}
+
+func nonNilData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return map[string]interface{}{}
+	}
+	return data
+}
+
+// MatchMode reports whether an output mode satisfies a preferred mode. Preferences may be
+// exact ("image/png"), wildcards ("image/*", "*"), or "text", which matches plain text.
+// "text/*" matches every text part, including plain text.
+func MatchMode(mode, preferred string) bool {
+	switch {
+	case preferred == "*" || preferred == mode:
+		return true
+	case preferred == "text" || preferred == "text/plain":
+		return mode == "text" || mode == "text/plain"
+	case strings.HasSuffix(preferred, "/*"):
+		prefix := strings.TrimSuffix(preferred, "*")
+		return strings.HasPrefix(mode, prefix) || (prefix == "text/" && mode == "text")
+	}
+	return false
+}
+
+// BestContent returns the part best matching the preferred modes, tried in order.
+// Without preferences the first known part is returned.
+func BestContent(parts []Part, preferred ...string) (PartContent, bool) {
+	contents := make([]PartContent, 0, len(parts))
+	for _, part := range parts {
+		if content, ok := DecodePart(part); ok {
+			contents = append(contents, content)
+		}
+	}
+	if len(preferred) == 0 && len(contents) > 0 {
+		return contents[0], true
+	}
+	for _, mode := range preferred {
+		for _, content := range contents {
+			if MatchMode(content.Mode, mode) {
+				return content, true
+			}
+		}
+	}
+	return PartContent{}, false
+}
+
+// PartsText joins the text of all text parts with newlines
+func PartsText(parts []Part) string {
+	var texts []string
+	for _, part := range parts {
+		if content, ok := DecodePart(part); ok && content.IsText() {
+			texts = append(texts, content.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// BestContent returns the part of the message best matching the preferred modes
+func (m *Message) BestContent(preferred ...string) (PartContent, bool) {
+	if m == nil {
+		return PartContent{}, false
+	}
+	return BestContent(m.Parts, preferred...)
+}
+
+// Text joins the text parts of the message
+func (m *Message) Text() string {
+	if m == nil {
+		return ""
+	}
+	return PartsText(m.Parts)
+}
+
+// BestContent returns the content best matching the preferred modes from the task's latest
+// output: the artifacts, most recent index first, then the status message
+func (t *Task) BestContent(preferred ...string) (PartContent, bool) {
+	if t == nil {
+		return PartContent{}, false
+	}
+	var parts []Part
+	for i := len(t.Artifacts) - 1; i >= 0; i-- {
+		parts = append(parts, t.Artifacts[i].Parts...)
+	}
+	if t.Status.Message != nil {
+		parts = append(parts, t.Status.Message.Parts...)
+	}
+	return BestContent(parts, preferred...)
+}
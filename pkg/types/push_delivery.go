@@ -0,0 +1,23 @@
+package types
+
+// NotificationTokenHeader carries the signed token of a push notification when the
+// Authorization header holds an OAuth2 token for the receiver instead
+const NotificationTokenHeader = "A2A-Notification-Token"
+
+// PushDeliveryStatus is the delivery receipt of the push notifications of a task to one URL
+type PushDeliveryStatus struct {
+	TaskID          string `json:"taskId"`
+	URL             string `json:"url"`
+	Attempts        int    `json:"attempts"`                  // Delivery attempts, including retries
+	Delivered       int    `json:"delivered"`                 // Notifications accepted by the receiver
+	Failed          int    `json:"failed"`                    // Notifications given up on after the last retry
+	LastStatusCode  int    `json:"lastStatusCode,omitempty"`  // 0 if the last attempt got no response
+	LastError       string `json:"lastError,omitempty"`       // Why the last attempt failed, if it did
+	LastAttemptAt   string `json:"lastAttemptAt,omitempty"`   // RFC 3339
+	LastDeliveredAt string `json:"lastDeliveredAt,omitempty"` // RFC 3339
+	NextRetryAt     string `json:"nextRetryAt,omitempty"`     // RFC 3339, set while a retry is scheduled
+}
+
+type GetPushNotificationStatusResponse struct {
+	Result []PushDeliveryStatus `json:"result"`
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"math"
+	"time"
+)
+
+// RetryAfterMsKey is the JSON-RPC error data field telling clients how long to wait before retrying
+const RetryAfterMsKey = "retryAfterMs"
+
+// RetryAfterData builds error data carrying a retry hint
+func RetryAfterData(retryAfter time.Duration) map[string]interface{} {
+	return map[string]interface{}{RetryAfterMsKey: retryAfter.Milliseconds()}
+}
+
+// RetryAfter returns the retry hint carried in the error data, if any.
+// A "retryAfter" field in seconds is accepted as well.
+func (e *JSONRPCError) RetryAfter() (time.Duration, bool) {
+	if e == nil {
+		return 0, false
+	}
+	data, ok := e.Data.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	if ms, ok := toFloat(data[RetryAfterMsKey]); ok && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	if seconds, ok := toFloat(data["retryAfter"]); ok && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
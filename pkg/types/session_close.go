@@ -0,0 +1,23 @@
+package types
+
+// CloseSessionMethod is the JSON-RPC method ending a session, e.g. when a conversation is over
+const CloseSessionMethod = "sessions/close"
+
+// SessionCloseParams names the session sessions/close ends
+type SessionCloseParams struct {
+	SessionID string `json:"sessionId"`
+	Reason    string `json:"reason,omitempty"` // Why the session ends, passed to the agent's hooks
+}
+
+// ClosedSession describes a session that was closed
+type ClosedSession struct {
+	SessionID string   `json:"sessionId"`
+	Reason    string   `json:"reason,omitempty"`
+	ClosedAt  string   `json:"closedAt"`
+	Canceled  []string `json:"canceled,omitempty"` // Ids of the outstanding tasks canceled by the close
+	EvictAt   string   `json:"evictAt,omitempty"`  // When the session's tasks are dropped, if the agent evicts them
+}
+
+type CloseSessionResponse struct {
+	Result *ClosedSession `json:"result,omitempty"`
+}
@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// ServerShuttingDownErrorCode is the JSON-RPC error code of the final event of streams
+// closed because the server is shutting down
+const ServerShuttingDownErrorCode = -32031
+
+// ResubscribeMethod is the method clients call to resume a task stream
+const ResubscribeMethod = "resubscribe_to_task"
+
+// ResumeHint tells a client how to continue a stream the server closed
+type ResumeHint struct {
+	TaskID     string        // Empty for streams not bound to one task
+	Method     string        // Method to call to resume, e.g. ResubscribeMethod
+	RetryAfter time.Duration // How long to wait before resuming, typically for another replica
+}
+
+// ShutdownError builds the error event sent before a stream is closed for shutdown
+func ShutdownError(hint ResumeHint) *JSONRPCError {
+	data := RetryAfterData(hint.RetryAfter)
+	if hint.TaskID != "" {
+		data["taskId"] = hint.TaskID
+	}
+	if hint.Method != "" {
+		data["resumeMethod"] = hint.Method
+	}
+	return &JSONRPCError{
+		Code:    ServerShuttingDownErrorCode,
+		Message: "Server is shutting down",
+		Data:    data,
+	}
+}
+
+// ResumeHint returns the resume hint of a shutdown error event
+func (e *JSONRPCError) ResumeHint() (ResumeHint, bool) {
+	if e == nil || e.Code != ServerShuttingDownErrorCode {
+		return ResumeHint{}, false
+	}
+	var hint ResumeHint
+	if data, ok := e.Data.(map[string]interface{}); ok {
+		hint.TaskID, _ = data["taskId"].(string)
+		hint.Method, _ = data["resumeMethod"].(string)
+	}
+	hint.RetryAfter, _ = e.RetryAfter()
+	return hint, true
+}
+
+// ResubscribeTokenHeader carries the token that routes a task's resubscribe request to the
+// replica streaming the task. Servers set it on stream responses; clients send it back.
+const ResubscribeTokenHeader = "A2A-Resubscribe-Token"
@@ -0,0 +1,37 @@
+package types
+
+const (
+	// BatchGetTasksMethod is the JSON-RPC method retrieving several tasks at once
+	BatchGetTasksMethod = "tasks/batchGet"
+	// BatchCancelTasksMethod is the JSON-RPC method canceling several tasks at once
+	BatchCancelTasksMethod = "tasks/batchCancel"
+)
+
+// MaxBatchTasks is the largest number of task ids a batch request may list
+const MaxBatchTasks = 100
+
+// TaskBatchParams lists the tasks of tasks/batchGet and tasks/batchCancel
+type TaskBatchParams struct {
+	IDs           []string `json:"ids"`
+	HistoryLength *int     `json:"historyLength,omitempty"` // History of each task returned by tasks/batchGet
+}
+
+// TaskBatchResult is the outcome for one task of a batch, either the task or an error
+type TaskBatchResult struct {
+	ID    string        `json:"id"`
+	Task  *Task         `json:"task,omitempty"`
+	Error *JSONRPCError `json:"error,omitempty"`
+}
+
+// TaskBatch has a result per id of a batch request, in the order of the ids
+type TaskBatch struct {
+	Results []TaskBatchResult `json:"results"`
+}
+
+type BatchGetTasksResponse struct {
+	Result *TaskBatch `json:"result,omitempty"`
+}
+
+type BatchCancelTasksResponse struct {
+	Result *TaskBatch `json:"result,omitempty"`
+}
@@ -0,0 +1,23 @@
+package types
+
+// ListTasksMethod is the JSON-RPC method for searching tasks
+const ListTasksMethod = "tasks/list"
+
+// TaskListParams filters the tasks returned by tasks/list. All set filters must match.
+type TaskListParams struct {
+	SessionID string                 `json:"sessionId,omitempty"`
+	States    []TaskState            `json:"states,omitempty"`   // Any of these states
+	Metadata  map[string]interface{} `json:"metadata,omitempty"` // Metadata keys the task must have with these values
+	Limit     int                    `json:"limit,omitempty"`    // Maximum number of tasks, a server default if zero
+	Cursor    string                 `json:"cursor,omitempty"`   // NextCursor of the previous page
+}
+
+// TaskList is a page of tasks, ordered by id
+type TaskList struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"nextCursor,omitempty"` // Set if more tasks match
+}
+
+type ListTasksResponse struct {
+	Result *TaskList `json:"result,omitempty"`
+}
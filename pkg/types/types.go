@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"time"
 )
 
 // Enums
@@ -12,6 +13,7 @@ const (
 	TaskWorking     TaskState = "working"
 	TaskInputNeeded TaskState = "input-required"
 	TaskCompleted   TaskState = "completed"
+	TaskSuspended   TaskState = "suspended"
 	TaskCanceled    TaskState = "canceled"
 	TaskFailed      TaskState = "failed"
 	TaskUnknown     TaskState = "unknown"
@@ -44,9 +46,10 @@ type DataPart struct {
 }
 
 type Message struct {
-	Role     string      `json:"role"`
-	Parts    []any       `json:"parts"` // Simplified union of TextPart/FilePart/DataPart
-	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Role       string                 `json:"role"`
+	Parts      Parts                  `json:"parts"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Extensions []string               `json:"extensions,omitempty"` // URIs of the extensions the message uses
 }
 
 type TaskStatus struct {
@@ -55,14 +58,18 @@ type TaskStatus struct {
 	Timestamp string    `json:"timestamp"`
 }
 
+// Artifact is an output of a task. Index identifies the artifact within its task: a task
+// holds at most one artifact per index, kept sorted by index. An artifact with Append set
+// extends the parts of the artifact at its index (streamed chunks); without Append, an
+// index already in use is rejected by the task manager.
 type Artifact struct {
-	Name        *string     `json:"name,omitempty"`
-	Description *string     `json:"description,omitempty"`
-	Parts       []any       `json:"parts"` // Simplified
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Parts       Parts                  `json:"parts"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
-	Index       int         `json:"index"`
-	Append      *bool       `json:"append,omitempty"`
-	LastChunk   *bool       `json:"lastChunk,omitempty"`
+	Index       int                    `json:"index"`
+	Append      *bool                  `json:"append,omitempty"`
+	LastChunk   *bool                  `json:"lastChunk,omitempty"`
 }
 
 type Task struct {
@@ -72,6 +79,8 @@ type Task struct {
 	Artifacts []Artifact             `json:"artifacts,omitempty"`
 	History   []Message              `json:"history,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Version   uint64                 `json:"version,omitempty"` // Incremented on every change to the task
+	Transfers []TaskTransferRecord   `json:"transfers,omitempty"` // Session changes of the task, oldest first
 }
 
 type TaskStatusUpdateEvent struct {
@@ -79,12 +88,14 @@ type TaskStatusUpdateEvent struct {
 	Status   TaskStatus             `json:"status"`
 	Final    bool                   `json:"final"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Seq      int                    `json:"seq,omitempty"` // Event cursor, numbering the events of the task from 1
 }
 
 type TaskArtifactUpdateEvent struct {
 	ID       string                 `json:"id"`
 	Artifact Artifact               `json:"artifact"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Seq      int                    `json:"seq,omitempty"` // Event cursor, numbering the events of the task from 1
 }
 
 // Push Notifications
@@ -106,19 +117,59 @@ type TaskIdParams struct {
 
 type TaskQueryParams struct {
 	TaskIdParams
-	HistoryLength *int `json:"historyLength,omitempty"`
+	HistoryLength *int    `json:"historyLength,omitempty"`
+	SinceVersion  *uint64 `json:"sinceVersion,omitempty"`  // Return a not-modified response if the task version has not advanced past this
+	IncludeEvents bool    `json:"includeEvents,omitempty"` // Include the recorded stream events of the task
 }
 
 type TaskSendParams struct {
-	ID                string                  `json:"id"`
-	SessionID         string                  `json:"sessionId"`
-	Message           Message                 `json:"message"`
-	AcceptedOutputModes []string              `json:"acceptedOutputModes,omitempty"`
-	PushNotification  *PushNotificationConfig `json:"pushNotification,omitempty"`
-	HistoryLength     *int                    `json:"historyLength,omitempty"`
-	Metadata          map[string]interface{}  `json:"metadata,omitempty"`
+	ID                  string                  `json:"id"`
+	SessionID           string                  `json:"sessionId"`
+	Message             Message                 `json:"message"`
+	AcceptedOutputModes []string                `json:"acceptedOutputModes,omitempty"`
+	PushNotification    *PushNotificationConfig `json:"pushNotification,omitempty"`
+	HistoryLength       *int                    `json:"historyLength,omitempty"`
+	Metadata            map[string]interface{}  `json:"metadata,omitempty"`
+	SkillID             string                  `json:"skillId,omitempty"` // Skill of the agent the task is for
+}
+
+type TaskReplayParams struct {
+	ID       string                 `json:"id"`
+	Speed    float64                `json:"speed,omitempty"` // 1 replays with original timing, 0 without delays
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type TaskTransferParams struct {
+	ID        string                 `json:"id"`
+	SessionID string                 `json:"sessionId"`
+	Reason    *string                `json:"reason,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskTransferRecord is the audit trail entry recorded when a task changes session
+type TaskTransferRecord struct {
+	FromSessionID string                 `json:"fromSessionId"`
+	ToSessionID   string                 `json:"toSessionId"`
+	Reason        *string                `json:"reason,omitempty"`
+	Timestamp     string                 `json:"timestamp"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"` // Metadata of the transfer request
 }
 
+// RecordStreamMetadataKey is the task metadata flag opting a single task into stream recording
+const RecordStreamMetadataKey = "recordStream"
+
+// ForkedFromMetadataKey is the task metadata key holding the id a forked task was originally sent with
+const ForkedFromMetadataKey = "forkedFrom"
+
+// HeartbeatMetadataKey is the status event metadata flag marking heartbeats of working tasks
+const HeartbeatMetadataKey = "heartbeat"
+
+// ProgressMetadataKey is the metadata key holding the completion percentage of a task, 0 to 100
+const ProgressMetadataKey = "progress"
+
+// ProgressNoteMetadataKey is the metadata key describing the current step of a task
+const ProgressNoteMetadataKey = "progressNote"
+
 type TaskPushNotificationConfig struct {
 	ID                    string                 `json:"id"`
 	PushNotificationConfig PushNotificationConfig `json:"pushNotificationConfig"`
@@ -150,9 +201,13 @@ type SendTaskResponse struct {
 }
 
 type SendTaskStreamingResponse struct {
-	Result interface{} `json:"result,omitempty"`
-	Error  *JSONRPCError `json:"error,omitempty"`
-	ID     interface{} `json:"id"`
+	Result  interface{}   `json:"result,omitempty"`
+	Error   *JSONRPCError `json:"error,omitempty"`
+	ID      interface{}   `json:"id"`
+	EventID string        `json:"-"` // SSE id of the event, sent as Last-Event-ID when resubscribing
+
+	DecodeError *DecodeError `json:"-"` // Set with Error for an event that failed to decode
+	Broken      bool         `json:"-"` // Set with Error when the client lost the stream, e.g. to a failed read
 }
 
 type CancelTaskResponse struct {
@@ -160,6 +215,27 @@ type CancelTaskResponse struct {
 }
 
 type GetTaskResponse struct {
+	Result      *Task               `json:"result,omitempty"`
+	NotModified bool                `json:"notModified,omitempty"`
+	Events      []StreamEventRecord `json:"events,omitempty"`
+}
+
+// StreamEventRecord is a recorded event of a task's stream, in the order clients received it
+type StreamEventRecord struct {
+	Seq       int         `json:"seq"`
+	Timestamp string      `json:"timestamp"`
+	Event     interface{} `json:"event"`
+}
+
+type PauseTaskResponse struct {
+	Result *Task `json:"result,omitempty"`
+}
+
+type ResumeTaskResponse struct {
+	Result *Task `json:"result,omitempty"`
+}
+
+type TransferTaskResponse struct {
 	Result *Task `json:"result,omitempty"`
 }
 
@@ -178,9 +254,10 @@ type AgentProvider struct {
 }
 
 type AgentCapabilities struct {
-	Streaming             bool `json:"streaming"`
-	PushNotifications     bool `json:"pushNotifications"`
-	StateTransitionHistory bool `json:"stateTransitionHistory"`
+	Streaming              bool             `json:"streaming"`
+	PushNotifications      bool             `json:"pushNotifications"`
+	StateTransitionHistory bool             `json:"stateTransitionHistory"`
+	Extensions             []AgentExtension `json:"extensions,omitempty"`
 }
 
 type AgentAuthentication struct {
@@ -189,13 +266,15 @@ type AgentAuthentication struct {
 }
 
 type AgentSkill struct {
-	ID            string   `json:"id"`
-	Name          string   `json:"name"`
-	Description   *string  `json:"description,omitempty"`
-	Tags          []string `json:"tags,omitempty"`
-	Examples      []string `json:"examples,omitempty"`
-	InputModes    []string `json:"inputModes,omitempty"`
-	OutputModes   []string `json:"outputModes,omitempty"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+	InputModes  []string `json:"inputModes,omitempty"`
+	OutputModes []string `json:"outputModes,omitempty"`
+	// MetadataSchema is the JSON Schema the metadata of tasks sent to the skill must match
+	MetadataSchema map[string]interface{} `json:"metadataSchema,omitempty"`
 }
 
 type AgentCard struct {
@@ -204,12 +283,14 @@ type AgentCard struct {
 	URL                string               `json:"url"`
 	Provider           *AgentProvider       `json:"provider,omitempty"`
 	Version            string               `json:"version"`
+	ProtocolVersion    string               `json:"protocolVersion,omitempty"`
 	DocumentationURL   *string              `json:"documentationUrl,omitempty"`
 	Capabilities       AgentCapabilities    `json:"capabilities"`
 	Authentication     *AgentAuthentication `json:"authentication,omitempty"`
 	DefaultInputModes  []string             `json:"defaultInputModes"`
 	DefaultOutputModes []string             `json:"defaultOutputModes"`
 	Skills             []AgentSkill         `json:"skills"`
+	JWKSURL            *string              `json:"jwksUrl,omitempty"` // Keys signing push notifications; may be relative to URL
 }
 
 // A2AClientJSONError represents a JSON parsing error in the A2A client
@@ -225,8 +306,20 @@ func (e *A2AClientJSONError) Error() string {
 type A2AClientHTTPError struct {
 	StatusCode int
 	Message    string
+	RPCError   *JSONRPCError // JSON-RPC error sent in the response body, if any
+	RetryAfter time.Duration // Retry hint from the Retry-After header or the error data
+	Err        error         // Error sending the request, e.g. context.Canceled, if it never got a response
 }
 
 func (e *A2AClientHTTPError) Error() string {
 	return fmt.Sprintf("HTTP error %d: %s", e.StatusCode, e.Message)
 }
+
+func (e *A2AClientHTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements the error interface so handlers can return a JSONRPCError with a specific code
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
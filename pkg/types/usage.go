@@ -0,0 +1,127 @@
+package types
+
+// UsageMetadataKey is the metadata key under which agents report token usage and cost
+const UsageMetadataKey = "usage"
+
+// Usage is the token usage and cost an agent reported for a task
+type Usage struct {
+	PromptTokens     int64   `json:"promptTokens,omitempty"`
+	CompletionTokens int64   `json:"completionTokens,omitempty"`
+	TotalTokens      int64   `json:"totalTokens,omitempty"`
+	Cost             float64 `json:"cost,omitempty"`
+	Currency         string  `json:"currency,omitempty"`
+}
+
+// usageAliases lists the field names agents commonly use for each Usage field
+var usageAliases = struct {
+	prompt, completion, total, cost []string
+}{
+	prompt:     []string{"promptTokens", "prompt_tokens", "inputTokens", "input_tokens"},
+	completion: []string{"completionTokens", "completion_tokens", "outputTokens", "output_tokens"},
+	total:      []string{"totalTokens", "total_tokens"},
+	cost:       []string{"cost", "totalCost", "total_cost"},
+}
+
+// IsZero reports whether no usage was recorded
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// Add returns the sum of u and other. The currency of u is kept unless it is empty.
+func (u Usage) Add(other Usage) Usage {
+	sum := Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		Cost:             u.Cost + other.Cost,
+		Currency:         u.Currency,
+	}
+	if sum.Currency == "" {
+		sum.Currency = other.Currency
+	}
+	return sum
+}
+
+// Sub returns u minus other, used to turn cumulative reports into deltas
+func (u Usage) Sub(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens - other.PromptTokens,
+		CompletionTokens: u.CompletionTokens - other.CompletionTokens,
+		TotalTokens:      u.TotalTokens - other.TotalTokens,
+		Cost:             u.Cost - other.Cost,
+		Currency:         u.Currency,
+	}
+}
+
+// UsageFromMetadata reads the usage reported under UsageMetadataKey. Both camelCase and
+// snake_case field names are accepted; a missing total is derived from prompt and completion tokens.
+func UsageFromMetadata(metadata map[string]interface{}) (Usage, bool) {
+	var fields map[string]interface{}
+	switch v := metadata[UsageMetadataKey].(type) {
+	case map[string]interface{}:
+		fields = v
+	case Usage:
+		return v, true
+	case *Usage:
+		if v == nil {
+			return Usage{}, false
+		}
+		return *v, true
+	default:
+		return Usage{}, false
+	}
+
+	var usage Usage
+	usage.PromptTokens = int64(firstNumber(fields, usageAliases.prompt))
+	usage.CompletionTokens = int64(firstNumber(fields, usageAliases.completion))
+	usage.TotalTokens = int64(firstNumber(fields, usageAliases.total))
+	usage.Cost = firstNumber(fields, usageAliases.cost)
+	usage.Currency, _ = fields["currency"].(string)
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage, !usage.IsZero()
+}
+
+// SetUsage stores usage in metadata under UsageMetadataKey, creating the map if needed
+func SetUsage(metadata map[string]interface{}, usage Usage) map[string]interface{} {
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	fields := map[string]interface{}{
+		"promptTokens":     usage.PromptTokens,
+		"completionTokens": usage.CompletionTokens,
+		"totalTokens":      usage.TotalTokens,
+	}
+	if usage.Cost != 0 {
+		fields["cost"] = usage.Cost
+	}
+	if usage.Currency != "" {
+		fields["currency"] = usage.Currency
+	}
+	metadata[UsageMetadataKey] = fields
+	return metadata
+}
+
+// Usage returns the usage reported in the task metadata
+func (t *Task) Usage() (Usage, bool) {
+	if t == nil {
+		return Usage{}, false
+	}
+	return UsageFromMetadata(t.Metadata)
+}
+
+// firstNumber returns the first numeric value found under one of keys
+func firstNumber(fields map[string]interface{}, keys []string) float64 {
+	for _, key := range keys {
+		switch v := fields[key].(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,294 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidationError describes a field of an incoming object that violates the protocol
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// Valid reports whether the state is one defined by the protocol
+func (s TaskState) Valid() bool {
+	switch s {
+	case TaskSubmitted, TaskWorking, TaskInputNeeded, TaskCompleted, TaskCanceled, TaskFailed, TaskUnknown, TaskSuspended:
+		return true
+	}
+	return false
+}
+
+// ValidateTask checks a task for unknown states, missing required fields and invalid parts
+func ValidateTask(task *Task) error {
+	if task == nil {
+		return nil
+	}
+
+	var errs []error
+	if task.ID == "" {
+		errs = append(errs, &ValidationError{Field: "task.id", Message: "is required"})
+	}
+	errs = append(errs, validateStatus("task.status", task.Status)...)
+	for i, message := range task.History {
+		errs = append(errs, validateMessage(fmt.Sprintf("task.history[%d]", i), &message)...)
+	}
+	for i, artifact := range task.Artifacts {
+		errs = append(errs, validateParts(fmt.Sprintf("task.artifacts[%d].parts", i), artifact.Parts)...)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTaskEvent checks a decoded streaming event
+func ValidateTaskEvent(event TaskEvent) error {
+	var errs []error
+	switch {
+	case event.Status != nil:
+		if event.Status.ID == "" {
+			errs = append(errs, &ValidationError{Field: "event.id", Message: "is required"})
+		}
+		errs = append(errs, validateStatus("event.status", event.Status.Status)...)
+	case event.Artifact != nil:
+		if event.Artifact.ID == "" {
+			errs = append(errs, &ValidationError{Field: "event.id", Message: "is required"})
+		}
+		errs = append(errs, validateParts("event.artifact.parts", event.Artifact.Artifact.Parts)...)
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of a task request for a missing id
+func (p *TaskIdParams) Validate() error {
+	if p.ID == "" {
+		return &ValidationError{Field: "params.id", Message: "is required"}
+	}
+	return nil
+}
+
+// Validate checks the params of get_task for a missing id and a negative history length
+func (p *TaskQueryParams) Validate() error {
+	errs := []error{p.TaskIdParams.Validate()}
+	if p.HistoryLength != nil && *p.HistoryLength < 0 {
+		errs = append(errs, &ValidationError{Field: "params.historyLength", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of send_task for missing ids, an invalid message and a push
+// notification config without URL
+func (p *TaskSendParams) Validate() error {
+	var errs []error
+	if p.ID == "" {
+		errs = append(errs, &ValidationError{Field: "params.id", Message: "is required"})
+	}
+	errs = append(errs, validateMessage("params.message", &p.Message)...)
+	if len(p.Message.Parts) == 0 {
+		errs = append(errs, &ValidationError{Field: "params.message.parts", Message: "must not be empty"})
+	}
+	if p.PushNotification != nil && p.PushNotification.URL == "" {
+		errs = append(errs, &ValidationError{Field: "params.pushNotification.url", Message: "is required"})
+	}
+	if p.HistoryLength != nil && *p.HistoryLength < 0 {
+		errs = append(errs, &ValidationError{Field: "params.historyLength", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of tasks/replay for a missing id and a negative speed
+func (p *TaskReplayParams) Validate() error {
+	var errs []error
+	if p.ID == "" {
+		errs = append(errs, &ValidationError{Field: "params.id", Message: "is required"})
+	}
+	if p.Speed < 0 {
+		errs = append(errs, &ValidationError{Field: "params.speed", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of tasks/transfer for missing ids
+func (p *TaskTransferParams) Validate() error {
+	var errs []error
+	if p.ID == "" {
+		errs = append(errs, &ValidationError{Field: "params.id", Message: "is required"})
+	}
+	if p.SessionID == "" {
+		errs = append(errs, &ValidationError{Field: "params.sessionId", Message: "is required"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of set_task_push_notification for a missing id and URL
+func (p *TaskPushNotificationConfig) Validate() error {
+	var errs []error
+	if p.ID == "" {
+		errs = append(errs, &ValidationError{Field: "params.id", Message: "is required"})
+	}
+	if p.PushNotificationConfig.URL == "" {
+		errs = append(errs, &ValidationError{Field: "params.pushNotificationConfig.url", Message: "is required"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of tasks/events for a missing id and negative numbers
+func (p *TaskEventsParams) Validate() error {
+	var errs []error
+	if p.ID == "" {
+		errs = append(errs, &ValidationError{Field: "params.id", Message: "is required"})
+	}
+	if p.Cursor < 0 {
+		errs = append(errs, &ValidationError{Field: "params.cursor", Message: "must not be negative"})
+	}
+	if p.Wait < 0 {
+		errs = append(errs, &ValidationError{Field: "params.wait", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of tasks/list for unknown states and a negative limit
+func (p *TaskListParams) Validate() error {
+	var errs []error
+	for i, state := range p.States {
+		if !state.Valid() {
+			errs = append(errs, &ValidationError{Field: fmt.Sprintf("params.states[%d]", i), Message: fmt.Sprintf("unknown state %q", state)})
+		}
+	}
+	if p.Limit < 0 {
+		errs = append(errs, &ValidationError{Field: "params.limit", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// ValidatePart checks that a message or artifact part has a known type and its required fields
+func ValidatePart(part any) error {
+	return errors.Join(validatePart("part", part)...)
+}
+
+func validateStatus(field string, status TaskStatus) []error {
+	var errs []error
+	if status.State == "" {
+		errs = append(errs, &ValidationError{Field: field + ".state", Message: "is required"})
+	} else if !status.State.Valid() {
+		errs = append(errs, &ValidationError{Field: field + ".state", Message: fmt.Sprintf("unknown state %q", status.State)})
+	}
+	if status.Message != nil {
+		errs = append(errs, validateMessage(field+".message", status.Message)...)
+	}
+	return errs
+}
+
+func validateMessage(field string, message *Message) []error {
+	var errs []error
+	if message.Role != "user" && message.Role != "agent" {
+		errs = append(errs, &ValidationError{Field: field + ".role", Message: fmt.Sprintf("unknown role %q", message.Role)})
+	}
+	return append(errs, validateParts(field+".parts", message.Parts)...)
+}
+
+func validateParts(field string, parts []Part) []error {
+	var errs []error
+	for i, part := range parts {
+		errs = append(errs, validatePart(fmt.Sprintf("%s[%d]", field, i), part)...)
+	}
+	return errs
+}
+
+func validatePart(field string, part any) []error {
+	switch p := part.(type) {
+	case TextPart, *TextPart:
+		return nil
+	case DataPart:
+		return validateData(field+".data", p.Data)
+	case *DataPart:
+		return validateData(field+".data", p.Data)
+	case UnknownPart:
+		return []error{&ValidationError{Field: field + ".type", Message: fmt.Sprintf("unknown part type %q", p.Type)}}
+	case FilePart:
+		return validateFileContent(field+".file", p.File)
+	case *FilePart:
+		return validateFileContent(field+".file", p.File)
+	case map[string]interface{}:
+		switch p["type"] {
+		case "text":
+			if _, ok := p["text"].(string); !ok {
+				return []error{&ValidationError{Field: field + ".text", Message: "is required"}}
+			}
+		case "file":
+			file, ok := p["file"].(map[string]interface{})
+			if !ok {
+				return []error{&ValidationError{Field: field + ".file", Message: "is required"}}
+			}
+			_, hasBytes := file["bytes"].(string)
+			_, hasURI := file["uri"].(string)
+			if hasBytes == hasURI {
+				return []error{&ValidationError{Field: field + ".file", Message: "must contain exactly one of bytes or uri"}}
+			}
+		case "data":
+			if _, ok := p["data"].(map[string]interface{}); !ok {
+				return []error{&ValidationError{Field: field + ".data", Message: "is required"}}
+			}
+		default:
+			return []error{&ValidationError{Field: field + ".type", Message: fmt.Sprintf("unknown part type %v", p["type"])}}
+		}
+		return nil
+	}
+	return []error{&ValidationError{Field: field, Message: fmt.Sprintf("unsupported part %T", part)}}
+}
+
+func validateData(field string, data map[string]interface{}) []error {
+	if data == nil {
+		return []error{&ValidationError{Field: field, Message: "is required"}}
+	}
+	return nil
+}
+
+func validateFileContent(field string, file FileContent) []error {
+	if (file.Bytes == nil) == (file.URI == nil) {
+		return []error{&ValidationError{Field: field, Message: "must contain exactly one of bytes or uri"}}
+	}
+	return nil
+}
+
+// Validate checks that the offered protocol versions are set
+func (p *AgentCapabilitiesParams) Validate() error {
+	var errs []error
+	for i, version := range p.ProtocolVersions {
+		if version == "" {
+			errs = append(errs, &ValidationError{Field: fmt.Sprintf("params.protocolVersions[%d]", i), Message: "is required"})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that a batch lists between 1 and MaxBatchTasks task ids
+func (p *TaskBatchParams) Validate() error {
+	var errs []error
+	switch {
+	case len(p.IDs) == 0:
+		errs = append(errs, &ValidationError{Field: "params.ids", Message: "is required"})
+	case len(p.IDs) > MaxBatchTasks:
+		errs = append(errs, &ValidationError{Field: "params.ids", Message: fmt.Sprintf("must not list more than %d ids", MaxBatchTasks)})
+	}
+	for i, id := range p.IDs {
+		if id == "" {
+			errs = append(errs, &ValidationError{Field: fmt.Sprintf("params.ids[%d]", i), Message: "is required"})
+		}
+	}
+	if p.HistoryLength != nil && *p.HistoryLength < 0 {
+		errs = append(errs, &ValidationError{Field: "params.historyLength", Message: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the params of sessions/close for a missing session id
+func (p *SessionCloseParams) Validate() error {
+	if p.SessionID == "" {
+		return &ValidationError{Field: "params.sessionId", Message: "is required"}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Clock.go: injectable time source so TTLs, token expiry and timestamps can be tested deterministically
+
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// TimerClock is implemented by clocks that also run functions after a delay, so timers
+// follow the clock as well
+type TimerClock interface {
+	Clock
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function scheduled to run later
+type Timer interface {
+	// Stop keeps the function from running and reports whether it hadn't run yet
+	Stop() bool
+}
+
+// AfterFunc runs f once d passed on clock, or in its own goroutine once d passed on the wall
+// clock if clock doesn't run functions itself
+func AfterFunc(clock Clock, d time.Duration, f func()) Timer {
+	if timers, ok := clock.(TimerClock); ok {
+		return timers.AfterFunc(d, f)
+	}
+	return time.AfterFunc(d, f)
+}
+
+// SystemClock reads the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock is a manually driven clock for tests. Functions scheduled with AfterFunc run
+// when Set or Advance move the clock past their time.
+type FakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a function scheduled on a FakeClock
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	f     func()
+}
+
+// NewFakeClock creates a FakeClock frozen at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Set moves the clock to now, running the functions due by then at their time
+func (c *FakeClock) Set(now time.Time) {
+	c.runUntil(now)
+}
+
+// Advance moves the clock forward by d, running the functions due by then at their time
+func (c *FakeClock) Advance(d time.Duration) {
+	c.runUntil(c.Now().Add(d))
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// runUntil steps the clock through the scheduled functions due by end in order, running
+// each at its time, including those they schedule in turn, and then moves it to end
+func (c *FakeClock) runUntil(end time.Time) {
+	for {
+		c.lock.Lock()
+		next := -1
+		for i, timer := range c.timers {
+			if !timer.at.After(end) && (next < 0 || timer.at.Before(c.timers[next].at)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			c.now = end
+			c.lock.Unlock()
+			return
+		}
+		timer := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		if timer.at.After(c.now) {
+			c.now = timer.at
+		}
+		c.lock.Unlock()
+		timer.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultClock returns the clock used where none is injected, the wall clock
+func DefaultClock() Clock {
+	return SystemClock{}
+}
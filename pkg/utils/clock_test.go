@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULIDGeneratorClock(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(1735689600000))
+	g := NewULIDGenerator(WithIDClock(clock))
+
+	first, second := g.NewID(), g.NewID()
+	if first[:10] != second[:10] || first >= second {
+		t.Fatalf("ids of one millisecond %s and %s are not monotonic with a shared timestamp", first, second)
+	}
+	clock.Advance(time.Millisecond)
+	if third := g.NewID(); third[:10] == first[:10] {
+		t.Fatalf("id %s after the clock advanced kept the timestamp of %s", third, first)
+	}
+}
+
+func TestSnowflakeGeneratorClock(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(snowflakeEpochMs + 1000))
+	g, err := NewSnowflakeGenerator(1, WithIDClock(clock))
+	if err != nil {
+		t.Fatalf("NewSnowflakeGenerator: %v", err)
+	}
+	id, err := strconv.ParseInt(g.NewID(), 10, 64)
+	if err != nil {
+		t.Fatalf("parsing id: %v", err)
+	}
+	if ms := id >> (snowflakeNodeBits + snowflakeSeqBits); ms != 1000 {
+		t.Fatalf("id timestamp = %d ms after the epoch, want 1000", ms)
+	}
+}
+
+func TestInMemoryCacheClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewInMemoryCache(WithCacheClock(clock))
+	ttl := 60
+	cache.Set("k", "v", &ttl)
+
+	clock.Advance(time.Minute)
+	if got := cache.Get("k", nil); got != "v" {
+		t.Fatalf("Get at the end of the ttl = %v, want v", got)
+	}
+	clock.Advance(time.Second)
+	if got := cache.Get("k", nil); got != nil {
+		t.Fatalf("Get after the ttl = %v, want nil", got)
+	}
+}
+
+func TestPushNotificationAuthClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	sender := &PushNotificationSenderAuth{}
+	sender.SetClock(clock)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if err := sender.setKey(key); err != nil {
+		t.Fatalf("setKey: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	receiver := &PushNotificationReceiverAuth{}
+	receiver.SetClock(clock)
+	if err := receiver.LoadJWKS(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))); err != nil {
+		t.Fatalf("LoadJWKS: %v", err)
+	}
+
+	token, err := sender.GenerateJWT(map[string]interface{}{"id": "t1"})
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	if err := receiver.VerifyToken(token); err != nil {
+		t.Fatalf("VerifyToken of a fresh token: %v", err)
+	}
+	clock.Advance(DefaultPushTokenTTL + DefaultJWTLeeway + time.Second)
+	if err := receiver.VerifyToken(token); err == nil {
+		t.Fatal("VerifyToken accepted an expired token")
+	}
+}
+
+func TestFakeClockAfterFunc(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var ran []string
+	AfterFunc(clock, 2*time.Minute, func() { ran = append(ran, "second") })
+	AfterFunc(clock, time.Minute, func() {
+		ran = append(ran, "first")
+		AfterFunc(clock, 0, func() { ran = append(ran, "scheduled by first") })
+	})
+	stopped := AfterFunc(clock, time.Minute, func() { ran = append(ran, "stopped") })
+	if !stopped.Stop() {
+		t.Fatal("Stop of a pending function = false, want true")
+	}
+
+	clock.Advance(time.Minute - time.Second)
+	if len(ran) != 0 {
+		t.Fatalf("ran %v before their time", ran)
+	}
+	clock.Advance(2 * time.Minute)
+	if strings.Join(ran, ",") != "first,scheduled by first,second" {
+		t.Fatalf("ran %v, want first, scheduled by first and second", ran)
+	}
+	if stopped.Stop() {
+		t.Fatal("Stop of a stopped function = true, want false")
+	}
+}
+
+func TestCachedSecretsClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	reads := 0
+	provider := CachedSecrets(SecretProviderFunc(func(ctx context.Context, name string) ([]byte, error) {
+		reads++
+		return []byte(strconv.Itoa(reads)), nil
+	}), time.Minute, WithSecretsClock(clock))
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    string
+	}{
+		{0, "1"},
+		{time.Minute - time.Second, "1"},
+		{time.Second, "2"},
+	} {
+		clock.Advance(step.advance)
+		secret, err := provider.Secret(context.Background(), "key")
+		if err != nil {
+			t.Fatalf("Secret: %v", err)
+		}
+		if string(secret) != step.want {
+			t.Fatalf("Secret after %s = %s, want %s", step.advance, secret, step.want)
+		}
+	}
+}
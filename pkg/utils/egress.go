@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ErrEgressDenied is returned for outbound requests blocked by an EgressPolicy
+var ErrEgressDenied = errors.New("egress denied")
+
+// EgressRule says how an allowed outbound request must leave the process
+type EgressRule struct {
+	Proxy      *url.URL    // Proxy to send the request through; nil connects directly
+	TLS        *tls.Config // TLS settings for the destination, e.g. a minimum version, pinned roots or client certificates
+	RequireTLS bool        // Reject the request unless it uses https
+}
+
+// EgressPolicy is the hook deployments use to control outbound HTTP requests made by the
+// push notification sender and the A2A client
+type EgressPolicy interface {
+	// Egress returns the rule for req, or an error to block it
+	Egress(req *http.Request) (EgressRule, error)
+}
+
+// EgressPolicyFunc adapts a function to an EgressPolicy
+type EgressPolicyFunc func(req *http.Request) (EgressRule, error)
+
+func (f EgressPolicyFunc) Egress(req *http.Request) (EgressRule, error) {
+	return f(req)
+}
+
+// HostEgressPolicy is a static EgressPolicy keyed by host name
+type HostEgressPolicy struct {
+	// AllowedHosts, if set, are the only hosts requests may go to. Entries starting
+	// with "." match the domain and its subdomains.
+	AllowedHosts []string
+	// Rules apply to the matching host, with the same matching as AllowedHosts; Default applies otherwise
+	Rules   map[string]EgressRule
+	Default EgressRule
+}
+
+func (p *HostEgressPolicy) Egress(req *http.Request) (EgressRule, error) {
+	host := req.URL.Hostname()
+	if len(p.AllowedHosts) > 0 && !matchesHost(p.AllowedHosts, host) {
+		return EgressRule{}, fmt.Errorf("%w: host %s is not allowed", ErrEgressDenied, host)
+	}
+	if rule, ok := p.Rules[host]; ok {
+		return rule, nil
+	}
+	for pattern, rule := range p.Rules {
+		if matchesHost([]string{pattern}, host) {
+			return rule, nil
+		}
+	}
+	return p.Default, nil
+}
+
+// EgressTransport enforces an EgressPolicy on every request, including redirects, using a
+// copy of base configured with the proxy and TLS settings of each rule
+type EgressTransport struct {
+	policy EgressPolicy
+	base   *http.Transport
+
+	lock       sync.Mutex
+	transports map[egressKey]*http.Transport
+}
+
+// egressKey identifies the transport variant a rule needs
+type egressKey struct {
+	proxy string
+	tls   *tls.Config
+}
+
+// NewEgressTransport wraps base, or a clone of http.DefaultTransport if nil, with policy
+func NewEgressTransport(policy EgressPolicy, base *http.Transport) *EgressTransport {
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	return &EgressTransport{
+		policy:     policy,
+		base:       base,
+		transports: make(map[egressKey]*http.Transport),
+	}
+}
+
+func (t *EgressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rule, err := t.policy.Egress(req)
+	if err != nil {
+		if !errors.Is(err, ErrEgressDenied) {
+			err = fmt.Errorf("%w: %v", ErrEgressDenied, err)
+		}
+		return nil, err
+	}
+	if rule.RequireTLS && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w: %s requires https", ErrEgressDenied, req.URL.Hostname())
+	}
+	return t.transport(rule).RoundTrip(req)
+}
+
+// transport returns the cached transport variant for rule
+func (t *EgressTransport) transport(rule EgressRule) *http.Transport {
+	if rule.Proxy == nil && rule.TLS == nil {
+		return t.base
+	}
+	key := egressKey{tls: rule.TLS}
+	if rule.Proxy != nil {
+		key.proxy = rule.Proxy.String()
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if transport, ok := t.transports[key]; ok {
+		return transport
+	}
+	transport := t.base.Clone()
+	if rule.Proxy != nil {
+		transport.Proxy = http.ProxyURL(rule.Proxy)
+	}
+	if rule.TLS != nil {
+		transport.TLSClientConfig = rule.TLS.Clone()
+	}
+	t.transports[key] = transport
+	return transport
+}
+
+// CloseIdleConnections closes idle connections of every transport variant
+func (t *EgressTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,347 @@
+package utils
+
+import (
+	"a2a-go/pkg/types"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFileMaxSize is the default limit on the size of a resolved file
+	DefaultFileMaxSize = 32 << 20
+	// DefaultFileTimeout is the default time allowed for opening and reading a file
+	DefaultFileTimeout = 30 * time.Second
+)
+
+var (
+	// ErrFileTooLarge is returned when reading a file beyond the size limit
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrUnsupportedScheme is returned for file URIs with no registered resolver
+	ErrUnsupportedScheme = errors.New("unsupported file uri scheme")
+)
+
+// FileResolver opens the content a file URI points to
+type FileResolver interface {
+	Open(ctx context.Context, uri *url.URL) (io.ReadCloser, error)
+}
+
+// FileResolverFunc adapts a function to a FileResolver
+type FileResolverFunc func(ctx context.Context, uri *url.URL) (io.ReadCloser, error)
+
+func (f FileResolverFunc) Open(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	return f(ctx, uri)
+}
+
+// FileResolversOption configures FileResolvers
+type FileResolversOption func(*FileResolvers)
+
+// WithFileMaxSize limits the bytes read from a file; reads beyond it fail with ErrFileTooLarge
+func WithFileMaxSize(n int64) FileResolversOption {
+	return func(r *FileResolvers) {
+		r.maxSize = n
+	}
+}
+
+// WithFileTimeout limits the time from opening a file until it is closed
+func WithFileTimeout(d time.Duration) FileResolversOption {
+	return func(r *FileResolvers) {
+		r.timeout = d
+	}
+}
+
+// WithFileHTTPClient sets the client used for http and https URIs
+func WithFileHTTPClient(client *http.Client) FileResolversOption {
+	return func(r *FileResolvers) {
+		resolver := &HTTPFileResolver{Client: client}
+		r.resolvers["http"] = resolver
+		r.resolvers["https"] = resolver
+	}
+}
+
+// WithFileTokens authorizes requests for http and https URIs with tokens
+func WithFileTokens(tokens *TokenManager) FileResolversOption {
+	return func(r *FileResolvers) {
+		r.tokens = tokens
+	}
+}
+
+// WithSchemeResolver registers resolver for a URI scheme, e.g. "file" or "s3"
+func WithSchemeResolver(scheme string, resolver FileResolver) FileResolversOption {
+	return func(r *FileResolvers) {
+		r.resolvers[strings.ToLower(scheme)] = resolver
+	}
+}
+
+// FileResolvers is a registry of FileResolvers by URI scheme, used by servers and clients
+// to open the content of file parts whether inline or referenced by URI. The http, https
+// and data schemes are registered by default; file and s3 must be registered explicitly.
+type FileResolvers struct {
+	lock      sync.RWMutex
+	resolvers map[string]FileResolver
+	maxSize   int64
+	timeout   time.Duration
+	tokens    *TokenManager
+}
+
+// NewFileResolvers creates a registry with the default schemes
+func NewFileResolvers(opts ...FileResolversOption) *FileResolvers {
+	httpResolver := &HTTPFileResolver{}
+	r := &FileResolvers{
+		resolvers: map[string]FileResolver{
+			"http":  httpResolver,
+			"https": httpResolver,
+			"data":  FileResolverFunc(openDataURI),
+		},
+		maxSize: DefaultFileMaxSize,
+		timeout: DefaultFileTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.tokens != nil {
+		for _, scheme := range []string{"http", "https"} {
+			if resolver, ok := r.resolvers[scheme].(*HTTPFileResolver); ok {
+				r.resolvers[scheme] = &HTTPFileResolver{Client: resolver.Client, Tokens: r.tokens}
+			}
+		}
+	}
+	return r
+}
+
+// Register adds or replaces the resolver for a URI scheme
+func (r *FileResolvers) Register(scheme string, resolver FileResolver) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resolvers[strings.ToLower(scheme)] = resolver
+}
+
+// Open returns the content of a file part, decoding inline bytes or resolving its URI
+func (r *FileResolvers) Open(ctx context.Context, file *types.FileContent) (io.ReadCloser, error) {
+	if file == nil {
+		return nil, errors.New("missing file content")
+	}
+	if file.Bytes != nil {
+		if r.maxSize > 0 && int64(base64.StdEncoding.DecodedLen(len(*file.Bytes))) > r.maxSize+2 {
+			return nil, ErrFileTooLarge
+		}
+		raw, err := base64.StdEncoding.DecodeString(*file.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file bytes: %w", err)
+		}
+		return r.limit(io.NopCloser(bytes.NewReader(raw)), func() {}), nil
+	}
+	if file.URI == nil {
+		return nil, errors.New("file has neither bytes nor uri")
+	}
+	return r.OpenURI(ctx, *file.URI)
+}
+
+// OpenURI returns the content a URI points to
+func (r *FileResolvers) OpenURI(ctx context.Context, rawURI string) (io.ReadCloser, error) {
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file uri: %w", err)
+	}
+
+	r.lock.RLock()
+	resolver := r.resolvers[strings.ToLower(uri.Scheme)]
+	r.lock.RUnlock()
+	if resolver == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedScheme, uri.Scheme)
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	body, err := resolver.Open(ctx, uri)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return r.limit(body, cancel), nil
+}
+
+// ReadFile returns the whole content of a file part
+func (r *FileResolvers) ReadFile(ctx context.Context, file *types.FileContent) ([]byte, error) {
+	body, err := r.Open(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+func (r *FileResolvers) limit(body io.ReadCloser, cancel context.CancelFunc) io.ReadCloser {
+	return &limitedReadCloser{body: body, remaining: r.maxSize, limited: r.maxSize > 0, cancel: cancel}
+}
+
+// limitedReadCloser fails reads past the size limit and releases the timeout on Close
+type limitedReadCloser struct {
+	body      io.ReadCloser
+	remaining int64
+	limited   bool
+	cancel    context.CancelFunc
+}
+
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if !l.limited {
+		return l.body.Read(p)
+	}
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrFileTooLarge
+	}
+	return n, err
+}
+
+func (l *limitedReadCloser) Close() error {
+	defer l.cancel()
+	return l.body.Close()
+}
+
+// HTTPFileResolver fetches http and https URIs
+type HTTPFileResolver struct {
+	Client *http.Client  // http.DefaultClient if nil
+	Tokens *TokenManager // Authorizes requests if set
+}
+
+func (h *HTTPFileResolver) Open(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if h.Tokens != nil {
+		if err := h.Tokens.SetAuthorization(req); err != nil {
+			return nil, fmt.Errorf("failed to authorize %s: %w", uri.Redacted(), err)
+		}
+	}
+	return fetchFile(h.Client, req)
+}
+
+// fetchFile sends req and returns the body of a successful response
+func fetchFile(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.Redacted(), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch %s: status %d", req.URL.Redacted(), resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// openDataURI decodes an RFC 2397 data URI
+func openDataURI(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	header, data, ok := strings.Cut(uri.Opaque, ",")
+	if !ok {
+		return nil, errors.New("invalid data uri: missing ','")
+	}
+	var raw []byte
+	if strings.HasSuffix(header, ";base64") {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data uri: %w", err)
+		}
+		raw = decoded
+	} else {
+		unescaped, err := url.PathUnescape(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data uri: %w", err)
+		}
+		raw = []byte(unescaped)
+	}
+	return io.NopCloser(bytes.NewReader(raw)), nil
+}
+
+// LocalFileResolver opens file URIs below Root. Paths outside Root, including through
+// symlinks, are rejected, so URIs from untrusted parties can't read arbitrary files.
+type LocalFileResolver struct {
+	Root string
+}
+
+func (l *LocalFileResolver) Open(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	if uri.Host != "" && uri.Host != "localhost" {
+		return nil, fmt.Errorf("file uri host %q is not local", uri.Host)
+	}
+	root, err := filepath.Abs(l.Root)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, filepath.FromSlash(uri.Path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file %s is outside %s", uri.Path, root)
+	}
+
+	dir, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	return dir.Open(rel)
+}
+
+// S3FileResolver fetches s3://bucket/key URIs over the S3 REST API
+type S3FileResolver struct {
+	Region string // us-east-1 if empty
+	// Endpoint, if set, is used with path-style addressing, e.g. for S3-compatible stores;
+	// otherwise the AWS virtual-hosted endpoint of the bucket is used
+	Endpoint string
+	Client   *http.Client // http.DefaultClient if nil
+	// Sign authenticates requests, e.g. with AWS Signature Version 4; unsigned requests
+	// can only read public objects
+	Sign func(req *http.Request) error
+}
+
+func (s *S3FileResolver) Open(ctx context.Context, uri *url.URL) (io.ReadCloser, error) {
+	bucket, key := uri.Host, strings.TrimPrefix(uri.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid s3 uri %s", uri)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(bucket, key), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Sign != nil {
+		if err := s.Sign(req); err != nil {
+			return nil, fmt.Errorf("failed to sign s3 request: %w", err)
+		}
+	}
+	return fetchFile(s.Client, req)
+}
+
+// objectURL returns the https URL of an object
+func (s *S3FileResolver) objectURL(bucket, key string) string {
+	escapedKey := (&url.URL{Path: key}).EscapedPath()
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + url.PathEscape(bucket) + "/" + escapedKey
+	}
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, escapedKey)
+}
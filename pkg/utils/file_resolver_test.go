@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFileResolverStaysInRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "inside.txt"), []byte("inside"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	resolver := &LocalFileResolver{Root: root}
+
+	for _, test := range []struct {
+		path    string
+		host    string
+		allowed bool
+	}{
+		{filepath.Join(root, "inside.txt"), "", true},
+		{filepath.Join(root, "inside.txt"), "localhost", true},
+		{root + "/sub/../inside.txt", "", true},
+		{filepath.Join(dir, "secret.txt"), "", false},
+		{root + "/../secret.txt", "", false},
+		{filepath.Join(root, "link.txt"), "", false},
+		{filepath.Join(root, "inside.txt"), "example.com", false},
+	} {
+		uri := &url.URL{Scheme: "file", Host: test.host, Path: filepath.ToSlash(test.path)}
+		body, err := resolver.Open(context.Background(), uri)
+		if !test.allowed {
+			if err == nil {
+				body.Close()
+				t.Errorf("Open(%s) succeeded, want it rejected", uri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Open(%s) = %v, want the file", uri, err)
+			continue
+		}
+		content, _ := io.ReadAll(body)
+		body.Close()
+		if string(content) != "inside" {
+			t.Errorf("Open(%s) read %q, want %q", uri, content, "inside")
+		}
+	}
+}
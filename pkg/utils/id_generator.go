@@ -0,0 +1,204 @@
+// IDGenerator.go: pluggable generation of task, session and message ids
+
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator creates unique ids for tasks, sessions and messages
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts a function to the IDGenerator interface
+type IDGeneratorFunc func() string
+
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// UUIDv7Generator generates time-ordered UUIDv7 ids
+type UUIDv7Generator struct{}
+
+func (UUIDv7Generator) NewID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// UUIDv4Generator generates random UUIDv4 ids
+type UUIDv4Generator struct{}
+
+func (UUIDv4Generator) NewID() string {
+	return uuid.NewString()
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// IDGeneratorOption configures the time-ordered generators
+type IDGeneratorOption func(*idClock)
+
+// WithIDClock sets the clock time-ordered ids take their timestamp from, the wall clock by default
+func WithIDClock(clock Clock) IDGeneratorOption {
+	return func(c *idClock) {
+		c.clock = clock
+	}
+}
+
+// idClock is the clock of a time-ordered generator
+type idClock struct {
+	clock Clock
+}
+
+func newIDClock(opts []IDGeneratorOption) idClock {
+	var c idClock
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c idClock) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// ULIDGenerator generates 26 character ULIDs; ids created in the same millisecond are monotonic
+type ULIDGenerator struct {
+	lock     sync.Mutex
+	clock    idClock
+	lastMs   uint64
+	lastRand [10]byte
+}
+
+func NewULIDGenerator(opts ...IDGeneratorOption) *ULIDGenerator {
+	return &ULIDGenerator{clock: newIDClock(opts)}
+}
+
+func (g *ULIDGenerator) NewID() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	ms := uint64(g.clock.now().UnixMilli())
+	if ms == g.lastMs {
+		// Increment the random component to keep ids sortable within a millisecond
+		for i := len(g.lastRand) - 1; i >= 0; i-- {
+			g.lastRand[i]++
+			if g.lastRand[i] != 0 {
+				break
+			}
+		}
+	} else {
+		g.lastMs = ms
+		if _, err := rand.Read(g.lastRand[:]); err != nil {
+			panic("utils: failed to read random bytes: " + err.Error())
+		}
+	}
+
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(ms >> (8 * (5 - i)))
+	}
+	copy(raw[6:], g.lastRand[:])
+	return encodeCrockford(raw)
+}
+
+// encodeCrockford encodes 128 bits as 26 Crockford base32 characters
+func encodeCrockford(raw [16]byte) string {
+	out := make([]byte, 26)
+	var acc uint64
+	bits := 2 // 130 bits of output for 128 bits of input, pad the top
+	idx := 0
+	for _, b := range raw {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[idx] = crockfordAlphabet[(acc>>uint(bits))&0x1f]
+			idx++
+		}
+	}
+	return string(out)
+}
+
+const (
+	snowflakeEpochMs  = 1704067200000 // 2024-01-01T00:00:00Z
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeMaxNode  = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSeq   = 1<<snowflakeSeqBits - 1
+)
+
+// SnowflakeGenerator generates 64-bit time-ordered ids (timestamp, node, sequence) as decimal strings
+type SnowflakeGenerator struct {
+	lock   sync.Mutex
+	clock  idClock
+	nodeID int64
+	lastMs int64
+	seq    int64
+}
+
+func NewSnowflakeGenerator(nodeID int64, opts ...IDGeneratorOption) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID > snowflakeMaxNode {
+		return nil, errors.New("snowflake node id must be between 0 and 1023")
+	}
+	return &SnowflakeGenerator{clock: newIDClock(opts), nodeID: nodeID}, nil
+}
+
+func (g *SnowflakeGenerator) NewID() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	ms := g.clock.now().UnixMilli() - snowflakeEpochMs
+	if ms < g.lastMs {
+		ms = g.lastMs
+	}
+	if ms == g.lastMs {
+		g.seq = (g.seq + 1) & snowflakeMaxSeq
+		if g.seq == 0 {
+			// Sequence exhausted for this millisecond, borrow the next one
+			ms++
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+
+	id := ms<<(snowflakeNodeBits+snowflakeSeqBits) | g.nodeID<<snowflakeSeqBits | g.seq
+	return strconv.FormatInt(id, 10)
+}
+
+var (
+	defaultIDGenerator     IDGenerator = UUIDv7Generator{}
+	defaultIDGeneratorLock sync.RWMutex
+)
+
+// SetDefaultIDGenerator replaces the generator used by NewID
+func SetDefaultIDGenerator(g IDGenerator) {
+	defaultIDGeneratorLock.Lock()
+	defer defaultIDGeneratorLock.Unlock()
+	defaultIDGenerator = g
+}
+
+// DefaultIDGenerator returns the generator used by NewID
+func DefaultIDGenerator() IDGenerator {
+	defaultIDGeneratorLock.RLock()
+	defer defaultIDGeneratorLock.RUnlock()
+	return defaultIDGenerator
+}
+
+// NewID creates an id with the default generator
+func NewID() string {
+	return DefaultIDGenerator().NewID()
+}
@@ -4,24 +4,55 @@ package utils
 
 import (
 	"sync"
-	"time"
 )
 
+// Cache stores values by key with an optional time to live in seconds
+type Cache interface {
+	Set(key string, value interface{}, ttlSeconds *int)
+	// Get returns defaultValue for missing and expired keys
+	Get(key string, defaultValue interface{}) interface{}
+	// Delete reports whether the key was present
+	Delete(key string) bool
+	Clear() bool
+}
+
+// InMemoryCache implements Cache with a map
 type InMemoryCache struct {
 	cacheData map[string]interface{}
 	ttl       map[string]float64
 	dataLock  sync.Mutex
+	clock     Clock
+}
+
+// CacheOption configures an InMemoryCache
+type CacheOption func(*InMemoryCache)
+
+// WithCacheClock sets the clock entries expire by, the wall clock by default
+func WithCacheClock(clock Clock) CacheOption {
+	return func(c *InMemoryCache) {
+		c.clock = clock
+	}
 }
 
 var instance *InMemoryCache
 var once sync.Once
 
+// NewInMemoryCache creates an empty InMemoryCache, separate from the shared instance
+func NewInMemoryCache(opts ...CacheOption) *InMemoryCache {
+	c := &InMemoryCache{
+		cacheData: make(map[string]interface{}),
+		ttl:       make(map[string]float64),
+		clock:     DefaultClock(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
 func GetCacheInstance() *InMemoryCache {
 	once.Do(func() {
-		instance = &InMemoryCache{
-			cacheData: make(map[string]interface{}),
-			ttl:       make(map[string]float64),
-		}
+		instance = NewInMemoryCache()
 	})
 	return instance
 }
@@ -32,7 +63,7 @@ func (c *InMemoryCache) Set(key string, value interface{}, ttlSeconds *int) {
 
 	c.cacheData[key] = value
 	if ttlSeconds != nil {
-		c.ttl[key] = float64(time.Now().Unix()) + float64(*ttlSeconds)
+		c.ttl[key] = float64(c.clock.Now().Unix()) + float64(*ttlSeconds)
 	} else {
 		delete(c.ttl, key)
 	}
@@ -42,7 +73,7 @@ func (c *InMemoryCache) Get(key string, defaultValue interface{}) interface{} {
 	c.dataLock.Lock()
 	defer c.dataLock.Unlock()
 
-	if expiration, exists := c.ttl[key]; exists && float64(time.Now().Unix()) > expiration {
+	if expiration, exists := c.ttl[key]; exists && float64(c.clock.Now().Unix()) > expiration {
 		delete(c.cacheData, key)
 		delete(c.ttl, key)
 		return defaultValue
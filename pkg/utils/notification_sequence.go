@@ -0,0 +1,51 @@
+package utils
+
+import "math"
+
+const (
+	// NotificationSequenceKey is the payload field numbering the push notifications of a task from 1
+	NotificationSequenceKey = "sequence"
+	// NotificationResyncedKey marks notifications synthesized by a receiver from a get_task after a gap
+	NotificationResyncedKey = "resynced"
+	// NotificationEventSeqKey is the payload field carrying the event cursor of the status
+	// event a notification was sent for, shared with streams and polls
+	NotificationEventSeqKey = "eventSeq"
+)
+
+// NotificationTaskID returns the id of the task a push notification payload is about
+func NotificationTaskID(payload map[string]interface{}) string {
+	body := payload
+	if result, ok := payload["result"].(map[string]interface{}); ok {
+		body = result
+	}
+	taskID, _ := body["id"].(string)
+	return taskID
+}
+
+// NotificationSequence returns the sequence number of a push notification payload
+func NotificationSequence(payload map[string]interface{}) (uint64, bool) {
+	switch v := payload[NotificationSequenceKey].(type) {
+	case float64:
+		if v >= 1 && v <= math.MaxUint64 && v == math.Trunc(v) {
+			return uint64(v), true
+		}
+	case uint64:
+		return v, v >= 1
+	case int:
+		return uint64(v), v >= 1
+	}
+	return 0, false
+}
+
+// NotificationEventSeq returns the event cursor of a push notification payload
+func NotificationEventSeq(payload map[string]interface{}) (int, bool) {
+	switch v := payload[NotificationEventSeqKey].(type) {
+	case float64:
+		if v >= 1 && v <= math.MaxInt && v == math.Trunc(v) {
+			return int(v), true
+		}
+	case int:
+		return v, v >= 1
+	}
+	return 0, false
+}
@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenRefreshBefore is how long before they expire cached tokens are refreshed
+	DefaultTokenRefreshBefore = time.Minute
+	// maxTokenResponse is the largest token endpoint response read
+	maxTokenResponse = 1 << 20
+)
+
+// Token is an OAuth2 access token
+type Token struct {
+	AccessToken  string
+	TokenType    string // "Bearer" if empty
+	RefreshToken string
+	Expiry       time.Time // Zero if the token doesn't expire
+}
+
+// Type returns the token type for the Authorization header
+func (t *Token) Type() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer"
+	}
+	return t.TokenType
+}
+
+// expiresWithin reports whether the token expires before now+d
+func (t *Token) expiresWithin(now time.Time, d time.Duration) bool {
+	return !t.Expiry.IsZero() && !now.Add(d).Before(t.Expiry)
+}
+
+// TokenSource fetches access tokens for an audience, the URL origin of the API they are
+// used with unless set otherwise. Sources don't cache; wrap them in a TokenManager.
+type TokenSource interface {
+	Token(ctx context.Context, audience string) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface
+type TokenSourceFunc func(ctx context.Context, audience string) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context, audience string) (*Token, error) {
+	return f(ctx, audience)
+}
+
+// TokenError is an error response of a token endpoint
+type TokenError struct {
+	StatusCode  int
+	Code        string // E.g. "invalid_client"
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth2: %s: %s (status %d)", e.Code, e.Description, e.StatusCode)
+	}
+	return fmt.Sprintf("oauth2: %s (status %d)", e.Code, e.StatusCode)
+}
+
+// ClientCredentials fetches tokens with the OAuth2 client credentials grant
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret Secret // Read for every token request, so rotated secrets are picked up
+	Scopes       []string
+	// AudienceParam is the form parameter the audience is sent in, "audience" if empty;
+	// "-" leaves it out for authorization servers issuing one token for every API
+	AudienceParam string
+	Client        *http.Client // http.DefaultClient if nil
+	Clock         Clock        // DefaultClock() if nil
+}
+
+func (c *ClientCredentials) Token(ctx context.Context, audience string) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+	if param := audienceParam(c.AudienceParam); param != "" && audience != "" {
+		form.Set(param, audience)
+	}
+	return fetchToken(ctx, c.Client, c.Clock, c.TokenURL, c.ClientID, c.ClientSecret, form)
+}
+
+// RefreshTokenSource fetches tokens with the OAuth2 refresh token grant, e.g. for users who
+// signed in once. Rotated refresh tokens returned by the endpoint replace the current one.
+type RefreshTokenSource struct {
+	TokenURL      string
+	ClientID      string
+	ClientSecret  Secret // Unset for public clients
+	AudienceParam string // As for ClientCredentials
+	Client        *http.Client
+	Clock         Clock
+
+	lock         sync.Mutex
+	refreshToken string
+}
+
+// NewRefreshTokenSource creates a RefreshTokenSource starting from refreshToken
+func NewRefreshTokenSource(tokenURL, clientID, clientSecret, refreshToken string) *RefreshTokenSource {
+	return &RefreshTokenSource{TokenURL: tokenURL, ClientID: clientID, ClientSecret: Secret{Value: clientSecret}, refreshToken: refreshToken}
+}
+
+// RefreshToken returns the current refresh token, for storing it once rotated
+func (s *RefreshTokenSource) RefreshToken() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.refreshToken
+}
+
+func (s *RefreshTokenSource) Token(ctx context.Context, audience string) (*Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {s.refreshToken}}
+	if param := audienceParam(s.AudienceParam); param != "" && audience != "" {
+		form.Set(param, audience)
+	}
+	token, err := fetchToken(ctx, s.Client, s.Clock, s.TokenURL, s.ClientID, s.ClientSecret, form)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken != "" {
+		s.refreshToken = token.RefreshToken
+	}
+	return token, nil
+}
+
+func audienceParam(param string) string {
+	switch param {
+	case "":
+		return "audience"
+	case "-":
+		return ""
+	}
+	return param
+}
+
+// fetchToken posts a token request and decodes the response
+func fetchToken(ctx context.Context, client *http.Client, clock Clock, tokenURL, clientID string, secret Secret, form url.Values) (*Token, error) {
+	clientSecret, err := secret.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: client secret: %w", err)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if clock == nil {
+		clock = DefaultClock()
+	}
+	if clientSecret == "" && clientID != "" {
+		form.Set("client_id", clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	issued := clock.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponse))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to read token response: %w", err)
+	}
+
+	var payload struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		RefreshToken     string      `json:"refresh_token"`
+		ExpiresIn        json.Number `json:"expires_in"`
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	decodeErr := json.Unmarshal(body, &payload)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || payload.Error != "" {
+		if payload.Error == "" {
+			payload.Error = http.StatusText(resp.StatusCode)
+		}
+		return nil, &TokenError{StatusCode: resp.StatusCode, Code: payload.Error, Description: payload.ErrorDescription}
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("oauth2: invalid token response: %w", decodeErr)
+	}
+	if payload.AccessToken == "" {
+		return nil, errors.New("oauth2: token response has no access_token")
+	}
+
+	token := &Token{AccessToken: payload.AccessToken, TokenType: payload.TokenType, RefreshToken: payload.RefreshToken}
+	if seconds, err := payload.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		token.Expiry = issued.Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// TokenManagerOption configures a TokenManager
+type TokenManagerOption func(*TokenManager)
+
+// WithTokenRefreshBefore refreshes cached tokens d before they expire, DefaultTokenRefreshBefore by default
+func WithTokenRefreshBefore(d time.Duration) TokenManagerOption {
+	return func(m *TokenManager) {
+		m.refreshBefore = d
+	}
+}
+
+// WithTokenClock sets the clock token expiry is checked against
+func WithTokenClock(clock Clock) TokenManagerOption {
+	return func(m *TokenManager) {
+		m.clock = clock
+	}
+}
+
+// WithTokenAudience fixes the audience of every token, instead of the origin of each request
+func WithTokenAudience(audience string) TokenManagerOption {
+	return func(m *TokenManager) {
+		m.audience = audience
+	}
+}
+
+// TokenManager caches the tokens of a TokenSource per audience, sharing them between the
+// client, push notification delivery and file resolution. Tokens about to expire are still
+// used while a fresh one is fetched in the background; concurrent requests for a missing
+// token wait for a single fetch.
+type TokenManager struct {
+	source        TokenSource
+	refreshBefore time.Duration
+	clock         Clock
+	audience      string
+
+	lock    sync.Mutex
+	entries map[string]*tokenEntry
+}
+
+type tokenEntry struct {
+	token    *Token
+	fetching chan struct{} // Closed once the running fetch ends; nil if none is running
+	err      error         // Error of the last fetch
+}
+
+// NewTokenManager creates a TokenManager caching the tokens of source
+func NewTokenManager(source TokenSource, opts ...TokenManagerOption) *TokenManager {
+	m := &TokenManager{
+		source:        source,
+		refreshBefore: DefaultTokenRefreshBefore,
+		entries:       make(map[string]*tokenEntry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.clock == nil {
+		m.clock = DefaultClock()
+	}
+	return m
+}
+
+// Audience returns the audience of tokens for requests to u
+func (m *TokenManager) Audience(u *url.URL) string {
+	if m.audience != "" {
+		return m.audience
+	}
+	return AudienceForURL(u)
+}
+
+// AudienceForURL returns the origin of u, the default audience of its tokens
+func AudienceForURL(u *url.URL) string {
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
+
+// Token returns a valid token for audience, fetching one if none is cached
+func (m *TokenManager) Token(ctx context.Context, audience string) (*Token, error) {
+	for {
+		m.lock.Lock()
+		entry := m.entries[audience]
+		if entry == nil {
+			entry = &tokenEntry{}
+			m.entries[audience] = entry
+		}
+		now := m.clock.Now()
+		if token := entry.token; token != nil && !token.expiresWithin(now, 0) {
+			if token.expiresWithin(now, m.refreshBefore) && entry.fetching == nil {
+				m.startFetch(audience, entry, true)
+			}
+			m.lock.Unlock()
+			return token, nil
+		}
+		fetching := entry.fetching
+		if fetching == nil {
+			fetching = m.startFetch(audience, entry, false)
+		}
+		m.lock.Unlock()
+
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		m.lock.Lock()
+		token, err := entry.token, entry.err
+		m.lock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if token != nil && !token.expiresWithin(m.clock.Now(), 0) {
+			return token, nil
+		}
+		if token != nil {
+			return nil, errors.New("oauth2: token source returned an expired token")
+		}
+	}
+}
+
+// startFetch fetches a token for audience in the background; m.lock must be held
+func (m *TokenManager) startFetch(audience string, entry *tokenEntry, refresh bool) chan struct{} {
+	done := make(chan struct{})
+	entry.fetching = done
+	go func() {
+		// Detached from the requests waiting for it, which may give up and leave others waiting
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		token, err := m.source.Token(ctx, audience)
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		switch {
+		case err == nil:
+			entry.token, entry.err = token, nil
+		case refresh:
+			// The cached token is still valid; the next request tries again
+			log.Printf("Failed to refresh token for %s: %v", audience, err)
+		default:
+			entry.err = err
+		}
+		entry.fetching = nil
+		close(done)
+	}()
+	return done
+}
+
+// Invalidate drops the cached token of audience, e.g. after a server rejected it
+func (m *TokenManager) Invalidate(audience string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if entry := m.entries[audience]; entry != nil {
+		entry.token = nil
+	}
+}
+
+// SetAuthorization sets the Authorization header of req to a token for its destination
+func (m *TokenManager) SetAuthorization(req *http.Request) error {
+	token, err := m.Token(req.Context(), m.Audience(req.URL))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return nil
+}
+
+// AuthTransport adds tokens of a TokenManager to requests. Requests rejected with 401 are
+// sent once more with a fresh token if their body can be replayed.
+type AuthTransport struct {
+	Tokens *TokenManager
+	Base   http.RoundTripper // http.DefaultTransport if nil
+}
+
+// NewAuthTransport creates an AuthTransport sending requests through base
+func NewAuthTransport(tokens *TokenManager, base http.RoundTripper) *AuthTransport {
+	return &AuthTransport{Tokens: tokens, Base: base}
+}
+
+func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Header.Get("Authorization") != "" {
+		return base.RoundTrip(req)
+	}
+
+	authorized := req.Clone(req.Context())
+	if err := t.Tokens.SetAuthorization(authorized); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := base.RoundTrip(authorized)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return resp, err
+	}
+
+	t.Tokens.Invalidate(t.Tokens.Audience(req.URL))
+	retry := req.Clone(req.Context())
+	if req.Body != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	if err := t.Tokens.SetAuthorization(retry); err != nil {
+		return resp, nil
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close()
+	return base.RoundTrip(retry)
+}
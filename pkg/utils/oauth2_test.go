@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForFetch waits until no fetch of audience's token is running
+func waitForFetch(t *testing.T, m *TokenManager, audience string) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		m.lock.Lock()
+		entry := m.entries[audience]
+		done := entry == nil || entry.fetching == nil
+		m.lock.Unlock()
+		if done {
+			return
+		}
+	}
+	t.Fatal("the token fetch didn't end")
+}
+
+func TestTokenManagerCachesAndRefreshes(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	var lock sync.Mutex
+	fetches, failing := 0, false
+	source := TokenSourceFunc(func(ctx context.Context, audience string) (*Token, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		fetches++
+		if failing {
+			return nil, errors.New("unavailable")
+		}
+		return &Token{AccessToken: fmt.Sprintf("%s-%d", audience, fetches), Expiry: clock.Now().Add(10 * time.Minute)}, nil
+	})
+	m := NewTokenManager(source, WithTokenClock(clock), WithTokenRefreshBefore(time.Minute))
+
+	// The steps run in order, each one after the clock advanced by advance
+	for _, step := range []struct {
+		name        string
+		advance     time.Duration
+		audience    string
+		invalidate  bool
+		failing     bool
+		wantToken   string
+		wantErr     bool
+		wantFetches int
+	}{
+		{"first request fetches", 0, "a", false, false, "a-1", false, 1},
+		{"cached", 5 * time.Minute, "a", false, false, "a-1", false, 1},
+		{"other audience", 0, "b", false, false, "b-2", false, 2},
+		{"cached token while refreshing", 4*time.Minute + 30*time.Second, "a", false, false, "a-1", false, 3},
+		{"refreshed", 0, "a", false, false, "a-3", false, 3},
+		{"cached token when the refresh fails", 9*time.Minute + 30*time.Second, "a", false, true, "a-3", false, 4},
+		{"expired token is fetched again", time.Minute, "a", false, false, "a-5", false, 5},
+		{"invalidated", 0, "a", true, false, "a-6", false, 6},
+		{"failed fetch", 0, "a", true, true, "", true, 7},
+		{"failed fetch is retried", 0, "a", false, false, "a-8", false, 8},
+	} {
+		t.Run(step.name, func(t *testing.T) {
+			clock.Advance(step.advance)
+			lock.Lock()
+			failing = step.failing
+			lock.Unlock()
+			if step.invalidate {
+				m.Invalidate(step.audience)
+			}
+
+			token, err := m.Token(context.Background(), step.audience)
+			waitForFetch(t, m, step.audience)
+			switch {
+			case step.wantErr:
+				if err == nil {
+					t.Fatalf("Token = %q, want an error", token.AccessToken)
+				}
+			case err != nil:
+				t.Fatalf("Token: %v", err)
+			case token.AccessToken != step.wantToken:
+				t.Fatalf("Token = %q, want %q", token.AccessToken, step.wantToken)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			if fetches != step.wantFetches {
+				t.Fatalf("%d fetches, want %d", fetches, step.wantFetches)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
@@ -18,12 +20,22 @@ import (
 	"sync"
 	"time"
 
+	"a2a-go/pkg/types"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 const AuthHeaderPrefix = "Bearer "
 
+const (
+	// DefaultPushTokenTTL is how long push notification tokens are valid after they are issued
+	DefaultPushTokenTTL = 5 * time.Minute
+	// DefaultJWTLeeway is the clock skew between sender and receiver tolerated when
+	// checking the iat and exp claims of push notification tokens
+	DefaultJWTLeeway = time.Minute
+)
+
 type PushNotificationAuth struct{}
 
 func (p *PushNotificationAuth) calculateRequestBodySHA256(data map[string]interface{}) (string, error) {
@@ -41,11 +53,82 @@ type PushNotificationSenderAuth struct {
 	privateKey *rsa.PrivateKey
 	publicKeys []map[string]interface{}
 	lock       sync.Mutex
+	sequences  map[string]uint64
+	urlPolicy  *URLPolicy
+	egress     EgressPolicy
+	client     *http.Client
+	tokenTTL   time.Duration
+	tokens     *TokenManager
+	clock      Clock
+}
+
+// SetClock sets the clock tokens are issued by, the wall clock by default
+func (s *PushNotificationSenderAuth) SetClock(clock Clock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.clock = clock
+}
+
+// SetTokenTTL sets how long generated tokens are valid, DefaultPushTokenTTL if not set
+func (s *PushNotificationSenderAuth) SetTokenTTL(ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tokenTTL = ttl
+}
+
+// SetEgressPolicy passes every notification and verification request through policy,
+// e.g. to require a proxy or specific TLS settings per destination. Proxies reached
+// through private networks must be allowed by the URL policy.
+func (s *PushNotificationSenderAuth) SetEgressPolicy(policy EgressPolicy) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.egress = policy
+	s.client = nil
+}
+
+// SetURLPolicy restricts the URLs notifications and verification requests are sent to.
+// DefaultURLPolicy applies until a policy is set.
+func (s *PushNotificationSenderAuth) SetURLPolicy(policy *URLPolicy) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.urlPolicy = policy
+	s.client = nil
+}
+
+// SetTokens authorizes notifications with OAuth2 tokens for their receiver. The signed token
+// of each notification then moves to the types.NotificationTokenHeader header.
+func (s *PushNotificationSenderAuth) SetTokens(tokens *TokenManager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tokens = tokens
+	s.client = nil
+}
+
+// httpClient returns a client enforcing the URL policy
+func (s *PushNotificationSenderAuth) httpClient() *http.Client {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.client == nil {
+		policy := s.urlPolicy
+		if policy == nil {
+			policy = DefaultURLPolicy()
+		}
+		var transport http.RoundTripper = policy.Transport()
+		if s.egress != nil {
+			transport = NewEgressTransport(s.egress, transport.(*http.Transport))
+		}
+		transport = policy.RoundTripper(transport)
+		if s.tokens != nil {
+			transport = NewAuthTransport(s.tokens, transport)
+		}
+		s.client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	}
+	return s.client
 }
 
 func (s *PushNotificationSenderAuth) VerifyPushNotificationURL(url string) bool {
 	validationToken := uuid.NewString()
-	resp, err := http.Get(fmt.Sprintf("%s?validationToken=%s", url, validationToken))
+	resp, err := s.httpClient().Get(fmt.Sprintf("%s?validationToken=%s", url, validationToken))
 	if err != nil {
 		log.Printf("Verification error: %v", err)
 		return false
@@ -58,10 +141,40 @@ func (s *PushNotificationSenderAuth) VerifyPushNotificationURL(url string) bool
 }
 
 func (s *PushNotificationSenderAuth) GenerateRSAKey() error {
-	privateKey, err := rsa.GenerateKey(nil, 2048)
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	return s.setKey(privateKey)
+}
+
+// LoadRSAKey signs notifications with the PEM encoded RSA key secrets hold under name,
+// instead of a key generated at every start
+func (s *PushNotificationSenderAuth) LoadRSAKey(ctx context.Context, secrets SecretProvider, name string) error {
+	data, err := secrets.Secret(ctx, name)
 	if err != nil {
 		return err
 	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return errors.New("signing key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return s.setKey(key)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid signing key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return errors.New("signing key is not an RSA key")
+	}
+	return s.setKey(key)
+}
+
+// setKey signs notifications with privateKey and publishes its public key
+func (s *PushNotificationSenderAuth) setKey(privateKey *rsa.PrivateKey) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.privateKey = privateKey
@@ -86,8 +199,19 @@ func (s *PushNotificationSenderAuth) GenerateJWT(data map[string]interface{}) (s
 	if err != nil {
 		return "", err
 	}
+	s.lock.Lock()
+	ttl, clock := s.tokenTTL, s.clock
+	s.lock.Unlock()
+	if ttl <= 0 {
+		ttl = DefaultPushTokenTTL
+	}
+	if clock == nil {
+		clock = DefaultClock()
+	}
+	now := clock.Now()
 	claims := jwt.MapClaims{
-		"iat":                time.Now().Unix(),
+		"iat":                 now.Unix(),
+		"exp":                 now.Add(ttl).Unix(),
 		"request_body_sha256": shaDigest,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
@@ -96,30 +220,136 @@ func (s *PushNotificationSenderAuth) GenerateJWT(data map[string]interface{}) (s
 	return token.SignedString(s.privateKey)
 }
 
+// nextSequence numbers the notifications of taskID so receivers can order them and detect gaps
+func (s *PushNotificationSenderAuth) nextSequence(taskID string) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.sequences == nil {
+		s.sequences = make(map[string]uint64)
+	}
+	s.sequences[taskID]++
+	return s.sequences[taskID]
+}
+
+// SequenceNotification returns data with the next sequence number of its task in the
+// sequence field; payloads that are not about a task or already numbered are returned as is
+func (s *PushNotificationSenderAuth) SequenceNotification(data map[string]interface{}) map[string]interface{} {
+	taskID := NotificationTaskID(data)
+	if taskID == "" {
+		return data
+	}
+	if _, ok := data[NotificationSequenceKey]; ok {
+		return data
+	}
+	stamped := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		stamped[k] = v
+	}
+	stamped[NotificationSequenceKey] = s.nextSequence(taskID)
+	return stamped
+}
+
+// SendPushNotification numbers data with SequenceNotification, then signs and posts it to url
 func (s *PushNotificationSenderAuth) SendPushNotification(url string, data map[string]interface{}) error {
-	token, err := s.GenerateJWT(data)
+	_, err := s.PostPushNotification(url, s.SequenceNotification(data))
 	if err != nil {
+		log.Printf("Push-notification failed: %v", err)
 		return err
 	}
-	body, _ := json.Marshal(data)
-	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	log.Printf("Push-notification sent to %s", url)
+	return nil
+}
+
+// PostPushNotification signs and posts data to url as is, returning the receiver's status code.
+// Retries should post the same numbered payload again.
+func (s *PushNotificationSenderAuth) PostPushNotification(url string, data map[string]interface{}) (int, error) {
+	token, err := s.GenerateJWT(data)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	client := s.httpClient()
+	s.lock.Lock()
+	oauth := s.tokens != nil
+	s.lock.Unlock()
+	if oauth {
+		req.Header.Set(types.NotificationTokenHeader, token)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Push-notification failed: %v", err)
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
-	log.Printf("Push-notification sent to %s", url)
-	return nil
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
 }
 
 type PushNotificationReceiverAuth struct {
 	PushNotificationAuth
 	publicKey *rsa.PublicKey
+	leeway    *time.Duration
+	clock     Clock
+}
+
+// SetClock sets the clock the iat and exp claims of tokens are checked against, the wall
+// clock by default
+func (r *PushNotificationReceiverAuth) SetClock(clock Clock) {
+	r.clock = clock
+}
+
+// SetLeeway sets the clock skew tolerated when checking the iat and exp claims of tokens,
+// DefaultJWTLeeway if not set
+func (r *PushNotificationReceiverAuth) SetLeeway(leeway time.Duration) {
+	r.leeway = &leeway
+}
+
+// parseToken verifies the signature of a token and its iat and exp claims, allowing for the
+// leeway. Tokens without exp, from older senders, expire DefaultPushTokenTTL after iat.
+func (r *PushNotificationReceiverAuth) parseToken(tokenStr string) (jwt.MapClaims, error) {
+	leeway := DefaultJWTLeeway
+	if r.leeway != nil {
+		leeway = *r.leeway
+	}
+	clock := r.clock
+	if clock == nil {
+		clock = DefaultClock()
+	}
+	parsedToken, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return r.publicKey, nil
+	}, jwt.WithTimeFunc(clock.Now), jwt.WithLeeway(leeway), jwt.WithIssuedAt())
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, errors.New("token has no iat claim")
+	}
+	if expiresAt, err := claims.GetExpirationTime(); err != nil || expiresAt == nil {
+		if clock.Now().Sub(issuedAt.Time) > DefaultPushTokenTTL+leeway {
+			return nil, errors.New("token expired")
+		}
+	}
+	return claims, nil
 }
 
 func (r *PushNotificationReceiverAuth) LoadJWKS(pemData string) error {
@@ -139,29 +369,18 @@ func (r *PushNotificationReceiverAuth) LoadJWKS(pemData string) error {
 }
 
 func (r *PushNotificationReceiverAuth) VerifyPushNotification(req *http.Request) (bool, error) {
-	authHeader := req.Header.Get("Authorization")
-	if !strings.HasPrefix(authHeader, AuthHeaderPrefix) {
-		return false, errors.New("invalid auth header")
-	}
-	tokenStr := strings.TrimPrefix(authHeader, AuthHeaderPrefix)
-
-	parsedToken, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
+	tokenStr := req.Header.Get(types.NotificationTokenHeader)
+	if tokenStr == "" {
+		authHeader := req.Header.Get("Authorization")
+		if !strings.HasPrefix(authHeader, AuthHeaderPrefix) {
+			return false, errors.New("invalid auth header")
 		}
-		return r.publicKey, nil
-	})
-	if err != nil || !parsedToken.Valid {
-		return false, err
-	}
-
-	claims, ok := parsedToken.Claims.(jwt.MapClaims)
-	if !ok || !parsedToken.Valid {
-		return false, errors.New("invalid token claims")
+		tokenStr = strings.TrimPrefix(authHeader, AuthHeaderPrefix)
 	}
 
-	if time.Since(time.Unix(int64(claims["iat"].(float64)), 0)) > 5*time.Minute {
-		return false, errors.New("token expired")
+	claims, err := r.parseToken(tokenStr)
+	if err != nil {
+		return false, err
 	}
 
 	var requestBody map[string]interface{}
@@ -177,24 +396,6 @@ func (r *PushNotificationReceiverAuth) VerifyPushNotification(req *http.Request)
 }
 
 func (r *PushNotificationReceiverAuth) VerifyToken(tokenStr string) error {
-	parsedToken, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return r.publicKey, nil
-	})
-	if err != nil || !parsedToken.Valid {
-		return err
-	}
-
-	claims, ok := parsedToken.Claims.(jwt.MapClaims)
-	if !ok || !parsedToken.Valid {
-		return errors.New("invalid token claims")
-	}
-
-	if time.Since(time.Unix(int64(claims["iat"].(float64)), 0)) > 5*time.Minute {
-		return errors.New("token expired")
-	}
-
-	return nil
+	_, err := r.parseToken(tokenStr)
+	return err
 }
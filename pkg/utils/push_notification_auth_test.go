@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestPushNotificationTokenTimes(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sender := &PushNotificationSenderAuth{}
+	if err := sender.GenerateRSAKey(); err != nil {
+		t.Fatalf("GenerateRSAKey: %v", err)
+	}
+	sender.SetClock(NewFakeClock(issued))
+	token, err := sender.GenerateJWT(map[string]interface{}{"id": "t1"})
+	if err != nil {
+		t.Fatalf("GenerateJWT: %v", err)
+	}
+	// Tokens of older senders have no exp
+	withoutExp, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"iat": issued.Unix()}).SignedString(sender.privateKey)
+	if err != nil {
+		t.Fatalf("signing token without exp: %v", err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		token   string
+		age     time.Duration // Of the token on the receiver's clock
+		leeway  *time.Duration
+		allowed bool
+	}{
+		{"fresh", token, 0, nil, true},
+		{"expired within leeway", token, DefaultPushTokenTTL + 30*time.Second, nil, true},
+		{"expired", token, DefaultPushTokenTTL + DefaultJWTLeeway + time.Second, nil, false},
+		{"expired without leeway", token, DefaultPushTokenTTL + time.Second, new(time.Duration), false},
+		{"issued ahead within leeway", token, -30 * time.Second, nil, true},
+		{"issued ahead", token, -2 * time.Minute, nil, false},
+		{"no exp", withoutExp, DefaultPushTokenTTL - time.Minute, nil, true},
+		{"no exp and too old", withoutExp, DefaultPushTokenTTL + DefaultJWTLeeway + time.Second, nil, false},
+		{"garbage", "not.a.token", 0, nil, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			receiver := &PushNotificationReceiverAuth{}
+			if err := receiver.LoadJWKS(sender.publicKeys[0]["pem"].(string)); err != nil {
+				t.Fatalf("LoadJWKS: %v", err)
+			}
+			receiver.SetClock(NewFakeClock(issued.Add(test.age)))
+			if test.leeway != nil {
+				receiver.SetLeeway(*test.leeway)
+			}
+			if err := receiver.VerifyToken(test.token); (err == nil) != test.allowed {
+				t.Fatalf("VerifyToken = %v, want allowed %v", err, test.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSecretNotFound is returned by providers that have no secret with the requested name
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider looks up credentials by name, so client secrets, signing keys and webhook
+// tokens can be kept out of config files, which then only hold the names
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) ([]byte, error)
+}
+
+// SecretProviderFunc adapts a function to the SecretProvider interface
+type SecretProviderFunc func(ctx context.Context, name string) ([]byte, error)
+
+func (f SecretProviderFunc) Secret(ctx context.Context, name string) ([]byte, error) {
+	return f(ctx, name)
+}
+
+// SecretString returns a secret as a string
+func SecretString(ctx context.Context, provider SecretProvider, name string) (string, error) {
+	secret, err := provider.Secret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// Secret is a credential read from a provider when needed, or a fixed value
+type Secret struct {
+	Provider SecretProvider
+	Name     string
+	Value    string // Used if Provider is nil
+}
+
+// Get returns the value of the secret
+func (s Secret) Get(ctx context.Context) (string, error) {
+	if s.Provider == nil {
+		return s.Value, nil
+	}
+	return SecretString(ctx, s.Provider, s.Name)
+}
+
+// EnvSecrets reads secrets from environment variables, named Prefix followed by the name
+type EnvSecrets struct {
+	Prefix string
+}
+
+func (e EnvSecrets) Secret(ctx context.Context, name string) ([]byte, error) {
+	value, ok := os.LookupEnv(e.Prefix + name)
+	if !ok {
+		return nil, fmt.Errorf("%w: environment variable %s", ErrSecretNotFound, e.Prefix+name)
+	}
+	return []byte(value), nil
+}
+
+// FileSecrets reads secrets from files, e.g. mounted by Docker or Kubernetes. Names are paths
+// relative to Dir, or absolute paths if Dir is empty. A final line break is removed.
+type FileSecrets struct {
+	Dir string
+}
+
+func (f FileSecrets) Secret(ctx context.Context, name string) ([]byte, error) {
+	path := name
+	if f.Dir != "" {
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("invalid secret name %q", name)
+		}
+		path = filepath.Join(f.Dir, name)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: file %s", ErrSecretNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return bytes.TrimSuffix(data, []byte("\r")), nil
+}
+
+// VaultSecrets reads secrets from the KV version 2 engine of HashiCorp Vault. Names are
+// "path#field"; without a field, the "value" field or the only field of the secret is read.
+type VaultSecrets struct {
+	Address   string       // E.g. "https://vault.example.com:8200"
+	Mount     string       // Mount path of the engine, "secret" if empty
+	Namespace string       // Vault Enterprise namespace, if any
+	Token     Secret       // Token authenticating the requests
+	Client    *http.Client // http.DefaultClient if nil
+}
+
+// NewVaultSecrets creates a VaultSecrets for the Vault at VAULT_ADDR, authenticated with
+// VAULT_TOKEN and in VAULT_NAMESPACE
+func NewVaultSecrets() *VaultSecrets {
+	return &VaultSecrets{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Token:     Secret{Provider: EnvSecrets{}, Name: "VAULT_TOKEN"},
+	}
+}
+
+func (v *VaultSecrets) Secret(ctx context.Context, name string) ([]byte, error) {
+	path, field, _ := strings.Cut(name, "#")
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	token, err := v.Token.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault token: %w", err)
+	}
+	endpoint := strings.TrimSuffix(v.Address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	var response struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := doSecretRequest(v.Client, req, &response); err != nil {
+		return nil, fmt.Errorf("vault: %s: %w", path, err)
+	}
+	return secretField(response.Data.Data, field, "vault: "+path)
+}
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager. Names are secret ids or ARNs,
+// followed by "#key" to read one key of a secret stored as a JSON object.
+type AWSSecretsManager struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string       // The regional endpoint if empty
+	Client          *http.Client // http.DefaultClient if nil
+	Clock           Clock        // DefaultClock() if nil
+}
+
+// NewAWSSecretsManager creates an AWSSecretsManager with the credentials and region of the
+// standard AWS environment variables
+func NewAWSSecretsManager() *AWSSecretsManager {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return &AWSSecretsManager{
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+func (a *AWSSecretsManager) Secret(ctx context.Context, name string) ([]byte, error) {
+	id, key, hasKey := strings.Cut(name, "#")
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", a.Region)
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	clock := a.Clock
+	if clock == nil {
+		clock = DefaultClock()
+	}
+	a.sign(req, body, clock.Now())
+
+	var response struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+		Type         string  `json:"__type"`
+		Message      string  `json:"message"`
+	}
+	if err := doSecretRequest(a.Client, req, &response); err != nil {
+		if strings.HasSuffix(response.Type, "ResourceNotFoundException") {
+			return nil, fmt.Errorf("%w: aws: %s", ErrSecretNotFound, id)
+		}
+		return nil, fmt.Errorf("aws: %s: %w", id, err)
+	}
+	secret := response.SecretBinary
+	if response.SecretString != nil {
+		secret = []byte(*response.SecretString)
+	}
+	if !hasKey {
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(secret, &fields); err != nil {
+		return nil, fmt.Errorf("aws: %s is not a JSON object", id)
+	}
+	return secretField(fields, key, "aws: "+id)
+}
+
+// sign adds an AWS Signature Version 4 to req
+func (a *AWSSecretsManager) sign(req *http.Request, body []byte, now time.Time) {
+	const service = "secretsmanager"
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	scope := date + "/" + a.Region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + a.SecretAccessKey)
+	for _, part := range []string{date, a.Region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// doSecretRequest sends req and decodes its JSON response into v, whatever the status, so
+// callers can read error details
+func doSecretRequest(client *http.Client, req *http.Request, v interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, v)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrSecretNotFound
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	case decodeErr != nil:
+		return fmt.Errorf("invalid response: %v", decodeErr)
+	}
+	return nil
+}
+
+// secretField returns field of a secret with several fields: the "value" field or the only
+// field if field is empty
+func secretField(fields map[string]interface{}, field, secret string) ([]byte, error) {
+	if field == "" {
+		if _, ok := fields["value"]; ok || len(fields) != 1 {
+			field = "value"
+		} else {
+			for name := range fields {
+				field = name
+			}
+		}
+	}
+	value, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no field %q", ErrSecretNotFound, secret, field)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
+
+// SecretResolver reads secrets from references naming their provider, e.g.
+// "env:A2A_CLIENT_SECRET", "file:/run/secrets/key", "vault:a2a/push#key" or
+// "aws:prod/a2a#clientSecret", as found in config files and flags
+type SecretResolver struct {
+	lock      sync.RWMutex
+	providers map[string]SecretProvider
+}
+
+// NewSecretResolver creates a resolver for the "env" and "file" schemes, plus "vault" if
+// VAULT_ADDR is set and "aws" if AWS credentials are
+func NewSecretResolver() *SecretResolver {
+	r := &SecretResolver{providers: map[string]SecretProvider{
+		"env":  EnvSecrets{},
+		"file": FileSecrets{},
+	}}
+	if os.Getenv("VAULT_ADDR") != "" {
+		r.providers["vault"] = NewVaultSecrets()
+	}
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		r.providers["aws"] = NewAWSSecretsManager()
+	}
+	return r
+}
+
+// Register adds or replaces the provider of a scheme
+func (r *SecretResolver) Register(scheme string, provider SecretProvider) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.providers[strings.ToLower(scheme)] = provider
+}
+
+// Secret resolves a reference; it implements SecretProvider with references as names
+func (r *SecretResolver) Secret(ctx context.Context, ref string) ([]byte, error) {
+	scheme, name, ok := strings.Cut(ref, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid secret reference %q, expected scheme:name", ref)
+	}
+	r.lock.RLock()
+	provider := r.providers[strings.ToLower(scheme)]
+	r.lock.RUnlock()
+	if provider == nil {
+		return nil, fmt.Errorf("no secret provider for %q", scheme)
+	}
+	return provider.Secret(ctx, name)
+}
+
+// Ref returns a Secret read from a reference
+func (r *SecretResolver) Ref(ref string) Secret {
+	return Secret{Provider: r, Name: ref}
+}
+
+// cachedSecret is a secret read by CachedSecrets
+type cachedSecret struct {
+	value   []byte
+	fetched time.Time
+}
+
+// CachedSecretsOption configures the provider returned by CachedSecrets
+type CachedSecretsOption func(*cachedSecrets)
+
+// WithSecretsClock sets the clock the age of cached secrets is checked against
+func WithSecretsClock(clock Clock) CachedSecretsOption {
+	return func(c *cachedSecrets) {
+		c.clock = clock
+	}
+}
+
+// cachedSecrets is the provider returned by CachedSecrets
+type cachedSecrets struct {
+	provider SecretProvider
+	ttl      time.Duration
+	clock    Clock
+
+	lock  sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// CachedSecrets returns a provider keeping the secrets of provider for ttl, so rotated
+// secrets are picked up without reading them for every use
+func CachedSecrets(provider SecretProvider, ttl time.Duration, opts ...CachedSecretsOption) SecretProvider {
+	c := &cachedSecrets{provider: provider, ttl: ttl, cache: make(map[string]cachedSecret)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.clock == nil {
+		c.clock = DefaultClock()
+	}
+	return c
+}
+
+func (c *cachedSecrets) Secret(ctx context.Context, name string) ([]byte, error) {
+	now := c.clock.Now()
+	c.lock.Lock()
+	cached, ok := c.cache[name]
+	c.lock.Unlock()
+	if ok && now.Sub(cached.fetched) < c.ttl {
+		return cached.value, nil
+	}
+	value, err := c.provider.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	c.cache[name] = cachedSecret{value: value, fetched: now}
+	c.lock.Unlock()
+	return value, nil
+}
+
+// SecretKey reads a binary key, e.g. for WithSignedArtifactURLs or WithReplica. Keys stored
+// base64 encoded, as binary keys are in most secret stores, are decoded.
+func SecretKey(ctx context.Context, provider SecretProvider, name string) ([]byte, error) {
+	secret, err := provider.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(secret)); err == nil && len(decoded) >= 16 {
+		return decoded, nil
+	}
+	return secret, nil
+}
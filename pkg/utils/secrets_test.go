@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifySigV4 checks the AWS Signature Version 4 of a request to Secrets Manager the way AWS
+// does, rebuilding the canonical request from the headers it names as signed
+func verifySigV4(r *http.Request, body []byte, secretAccessKey string) error {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return fmt.Errorf("unsigned request")
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth, ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+	_, scope, _ := strings.Cut(fields["Credential"], "/")
+	parts := strings.Split(scope, "/")
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(parts) != 4 || parts[2] != "secretsmanager" || parts[3] != "aws4_request" || !strings.HasPrefix(amzDate, parts[0]) {
+		return fmt.Errorf("invalid credential scope %q", scope)
+	}
+
+	var canonicalHeaders strings.Builder
+	signed := strings.Split(fields["SignedHeaders"], ";")
+	for _, name := range signed {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+	for _, required := range []string{"host", "x-amz-date", "x-amz-target"} {
+		if !strings.Contains(";"+fields["SignedHeaders"]+";", ";"+required+";") {
+			return fmt.Errorf("%s isn't signed", required)
+		}
+	}
+	if r.Header.Get("X-Amz-Security-Token") != "" && !strings.Contains(fields["SignedHeaders"], "x-amz-security-token") {
+		return fmt.Errorf("x-amz-security-token isn't signed")
+	}
+
+	bodyHash := sha256.Sum256(body)
+	requestHash := sha256.Sum256([]byte(strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders.String(), fields["SignedHeaders"], hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range parts {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])))
+	if want := hex.EncodeToString(mac.Sum(nil)); fields["Signature"] != want {
+		return fmt.Errorf("signature %s, want %s", fields["Signature"], want)
+	}
+	return nil
+}
+
+func TestAWSSecretsManagerSignsRequests(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	const secretAccessKey = "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := verifySigV4(r, body, secretAccessKey); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"__type":"InvalidSignatureException","message":%q}`, err.Error())
+			return
+		}
+		if r.Header.Get("X-Amz-Date") != "20250102T030405Z" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type":"InvalidSignatureException","message":"wrong date"}`)
+			return
+		}
+		fmt.Fprint(w, `{"SecretString":"{\"clientSecret\":\"s3cr3t\"}"}`)
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name            string
+		endpoint        string
+		secretAccessKey string
+		sessionToken    string
+		wantErr         string
+	}{
+		{"signed", server.URL + "/", secretAccessKey, "", ""},
+		{"session token", server.URL + "/", secretAccessKey, "token", ""},
+		{"endpoint path", server.URL + "/secrets%20manager/", secretAccessKey, "", ""},
+		{"wrong secret access key", server.URL + "/", "other", "", "signature"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			manager := &AWSSecretsManager{
+				Region:          "eu-west-1",
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: test.secretAccessKey,
+				SessionToken:    test.sessionToken,
+				Endpoint:        test.endpoint,
+				Clock:           clock,
+			}
+			secret, err := manager.Secret(context.Background(), "prod/a2a#clientSecret")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Secret = %q, %v, want an error mentioning %q", secret, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Secret: %v", err)
+			}
+			if string(secret) != "s3cr3t" {
+				t.Fatalf("Secret = %q, want s3cr3t", secret)
+			}
+		})
+	}
+}
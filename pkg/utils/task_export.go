@@ -0,0 +1,173 @@
+// task_export.go: conversion of tasks into JSONL transcripts and Markdown documents
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"a2a-go/pkg/types"
+)
+
+// ExportFormat selects the output of ExportTask
+type ExportFormat string
+
+const (
+	ExportJSONL    ExportFormat = "jsonl"
+	ExportMarkdown ExportFormat = "markdown"
+)
+
+// TranscriptEntry is one line of a JSONL task transcript
+type TranscriptEntry struct {
+	Type      string                 `json:"type"` // "task", "message" or "artifact"
+	TaskID    string                 `json:"taskId,omitempty"`
+	SessionID string                 `json:"sessionId,omitempty"`
+	State     types.TaskState        `json:"state,omitempty"`
+	Timestamp string                 `json:"timestamp,omitempty"`
+	Role      string                 `json:"role,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	Index     *int                   `json:"index,omitempty"`
+	Content   string                 `json:"content,omitempty"`
+	Parts     types.Parts            `json:"parts,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ExportTask writes task in format to w
+func ExportTask(w io.Writer, task *types.Task, format ExportFormat) error {
+	switch format {
+	case ExportJSONL:
+		return WriteTaskJSONL(w, task)
+	case ExportMarkdown:
+		return WriteTaskMarkdown(w, task)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// WriteTaskJSONL writes a task header line followed by one line per message and artifact
+func WriteTaskJSONL(w io.Writer, task *types.Task) error {
+	encoder := json.NewEncoder(w)
+
+	header := TranscriptEntry{
+		Type:      "task",
+		TaskID:    task.ID,
+		State:     task.Status.State,
+		Timestamp: task.Status.Timestamp,
+		Metadata:  task.Metadata,
+	}
+	if task.SessionID != nil {
+		header.SessionID = *task.SessionID
+	}
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	for _, message := range task.History {
+		if err := encoder.Encode(TranscriptEntry{
+			Type:     "message",
+			Role:     message.Role,
+			Content:  types.PartsText(message.Parts),
+			Parts:    message.Parts,
+			Metadata: message.Metadata,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, artifact := range task.Artifacts {
+		index := artifact.Index
+		entry := TranscriptEntry{
+			Type:     "artifact",
+			Role:     "agent",
+			Index:    &index,
+			Content:  types.PartsText(artifact.Parts),
+			Parts:    artifact.Parts,
+			Metadata: artifact.Metadata,
+		}
+		if artifact.Name != nil {
+			entry.Name = *artifact.Name
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTaskMarkdown writes a human-readable Markdown document of the task
+func WriteTaskMarkdown(w io.Writer, task *types.Task) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Task %s\n\n", task.ID)
+	if task.SessionID != nil {
+		fmt.Fprintf(&b, "- **Session:** %s\n", *task.SessionID)
+	}
+	fmt.Fprintf(&b, "- **State:** %s\n", task.Status.State)
+	if task.Status.Timestamp != "" {
+		fmt.Fprintf(&b, "- **Updated:** %s\n", task.Status.Timestamp)
+	}
+
+	if len(task.History) > 0 {
+		b.WriteString("\n## Conversation\n")
+		for _, message := range task.History {
+			fmt.Fprintf(&b, "\n### %s\n\n", roleTitle(message.Role))
+			writeMarkdownParts(&b, message.Parts)
+		}
+	}
+
+	if len(task.Artifacts) > 0 {
+		b.WriteString("\n## Artifacts\n")
+		for _, artifact := range task.Artifacts {
+			name := fmt.Sprintf("Artifact %d", artifact.Index)
+			if artifact.Name != nil && *artifact.Name != "" {
+				name = *artifact.Name
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", name)
+			if artifact.Description != nil && *artifact.Description != "" {
+				fmt.Fprintf(&b, "_%s_\n\n", *artifact.Description)
+			}
+			writeMarkdownParts(&b, artifact.Parts)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownParts(b *strings.Builder, parts []types.Part) {
+	for _, part := range parts {
+		p, ok := types.DecodePart(part)
+		switch {
+		case !ok:
+		case p.File != nil:
+			name := "file"
+			if p.File.Name != nil {
+				name = *p.File.Name
+			}
+			if p.File.URI != nil {
+				fmt.Fprintf(b, "[%s](%s)\n\n", name, *p.File.URI)
+			} else {
+				fmt.Fprintf(b, "_Attached file: %s_\n\n", name)
+			}
+		case p.Data != nil:
+			data, err := json.MarshalIndent(p.Data, "", "  ")
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(b, "```json\n%s\n```\n\n", data)
+		default:
+			b.WriteString(p.Text)
+			b.WriteString("\n\n")
+		}
+	}
+}
+
+// roleTitle capitalizes a message role for headings
+func roleTitle(role string) string {
+	if role == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
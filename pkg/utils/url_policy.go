@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrURLNotAllowed is returned for URLs and addresses rejected by a URLPolicy
+var ErrURLNotAllowed = errors.New("url not allowed")
+
+// DefaultDeniedNetworks are the loopback, private, link-local, benchmarking, NAT64 and cloud
+// metadata ranges push notification URLs may not point into by default. Use AllowLoopback to
+// reach agents and receivers run side by side in development.
+var DefaultDeniedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"), // Link-local, incl. 169.254.169.254 metadata
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, maps to any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+	netip.MustParsePrefix("fc00::/7"),       // Unique local, incl. fd00:ec2::254 metadata
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// LoopbackNetworks are the loopback ranges AllowLoopback exempts from the denied networks
+var LoopbackNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// URLPolicy restricts the outbound URLs an agent calls on behalf of clients, such as push
+// notification URLs, against SSRF. Addresses are checked when connecting, after DNS
+// resolution, so a host can't pass the check and then rebind to a denied address.
+type URLPolicy struct {
+	AllowedSchemes []string // Accepted URL schemes, http and https if empty
+
+	// AllowedHosts, if set, are the only hosts that may be called. DeniedHosts are never
+	// called. Entries starting with "." match the domain and its subdomains.
+	AllowedHosts []string
+	DeniedHosts  []string
+
+	// AllowedNetworks are exempt from DeniedNetworks, e.g. a known internal receiver
+	AllowedNetworks []netip.Prefix
+	DeniedNetworks  []netip.Prefix
+}
+
+// DefaultURLPolicy allows http and https URLs outside DefaultDeniedNetworks
+func DefaultURLPolicy() *URLPolicy {
+	return &URLPolicy{
+		AllowedSchemes: []string{"https", "http"},
+		DeniedNetworks: append([]netip.Prefix(nil), DefaultDeniedNetworks...),
+	}
+}
+
+// AllowLoopback lets the policy reach loopback addresses, for running agents and push
+// notification receivers on one machine in development. It returns p.
+func (p *URLPolicy) AllowLoopback() *URLPolicy {
+	p.AllowedNetworks = append(p.AllowedNetworks, LoopbackNetworks...)
+	return p
+}
+
+// CheckURL validates the scheme and host of rawURL; literal IP hosts are checked against the networks
+func (p *URLPolicy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	schemes := p.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"https", "http"}
+	}
+	if !containsFold(schemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q", ErrURLNotAllowed, u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrURLNotAllowed)
+	}
+	if matchesHost(p.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %s is denied", ErrURLNotAllowed, host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchesHost(p.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrURLNotAllowed, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.CheckAddr(addr)
+	}
+	return nil
+}
+
+// CheckAddr validates an IP address against the allowed and denied networks
+func (p *URLPolicy) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range p.AllowedNetworks {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	for _, prefix := range p.DeniedNetworks {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: address %s is in denied network %s", ErrURLNotAllowed, addr, prefix)
+		}
+	}
+	return nil
+}
+
+// CheckResolved validates rawURL and every address its host currently resolves to.
+// Use it to reject configs early; connections are still checked by HTTPClient.
+func (p *URLPolicy) CheckResolved(ctx context.Context, rawURL string) error {
+	if err := p.CheckURL(rawURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(rawURL)
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if err := p.CheckAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// control rejects connections to denied addresses once the dialer has resolved them
+func (p *URLPolicy) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	return p.CheckAddr(addrPort.Addr())
+}
+
+// HTTPClient returns a client enforcing the policy on every request, redirect and
+// connection. Proxies from the environment are not used, since they would hide the
+// address actually connected to.
+func (p *URLPolicy) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: p.RoundTripper(nil)}
+}
+
+// RoundTripper enforces the policy on requests sent through next, which must dial with
+// Transport's dialer; nil uses Transport itself
+func (p *URLPolicy) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = p.Transport()
+	}
+	return &policyTransport{policy: p, base: next}
+}
+
+// Transport returns a transport whose connections are checked against the policy
+func (p *URLPolicy) Transport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: p.control}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// policyTransport checks the URL of each request, including redirects, before sending it
+type policyTransport struct {
+	policy *URLPolicy
+	base   http.RoundTripper
+}
+
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.CheckURL(req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+func matchesHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == host || (strings.HasPrefix(pattern, ".") && (strings.HasSuffix(host, pattern) || host == pattern[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefaultURLPolicyDeniesInternalAddresses(t *testing.T) {
+	policy := DefaultURLPolicy()
+	for _, rawURL := range []string{
+		"http://127.0.0.1:5000/notify",
+		"http://127.1.2.3/notify",
+		"http://[::1]:5000/notify",
+		"http://[::ffff:127.0.0.1]/notify",
+		"http://10.0.0.1/notify",
+		"http://169.254.169.254/latest/meta-data",
+		"http://198.18.0.1/notify",
+		"http://[64:ff9b::a9fe:a9fe]/notify",
+		"http://[fd00:ec2::254]/notify",
+		"ftp://example.com/notify",
+	} {
+		if err := policy.CheckURL(rawURL); !errors.Is(err, ErrURLNotAllowed) {
+			t.Errorf("CheckURL(%q) = %v, want ErrURLNotAllowed", rawURL, err)
+		}
+	}
+
+	for _, rawURL := range []string{
+		"https://example.com/notify",
+		"http://93.184.216.34/notify",
+		"http://[2606:2800:220:1::1]/notify",
+	} {
+		if err := policy.CheckURL(rawURL); err != nil {
+			t.Errorf("CheckURL(%q) = %v, want nil", rawURL, err)
+		}
+	}
+}
+
+func TestURLPolicyAllowLoopback(t *testing.T) {
+	policy := DefaultURLPolicy().AllowLoopback()
+	for _, rawURL := range []string{
+		"http://127.0.0.1:5000/notify",
+		"http://[::1]:5000/notify",
+	} {
+		if err := policy.CheckURL(rawURL); err != nil {
+			t.Errorf("CheckURL(%q) = %v, want nil", rawURL, err)
+		}
+	}
+	if err := policy.CheckURL("http://10.0.0.1/notify"); !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("CheckURL of a private address = %v, want ErrURLNotAllowed", err)
+	}
+	if len(DefaultURLPolicy().AllowedNetworks) != 0 {
+		t.Error("AllowLoopback changed the default policy")
+	}
+}